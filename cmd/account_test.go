@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sportwhiz/gdcli/internal/app"
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
+	"github.com/sportwhiz/gdcli/internal/store"
 )
 
 func TestRunAccountOrdersListJSON(t *testing.T) {
@@ -45,6 +50,45 @@ func TestRunAccountOrdersListJSON(t *testing.T) {
 	}
 }
 
+func TestRunAccountOrdersFilterReportsMatchedAndRejectsSubscriptionFilters(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[{"orderId":"1","createdAt":"2025-11-05T12:37:45.000Z","currency":"USD","pricing":{"total":10690000}},{"orderId":"2","createdAt":"2025-11-06T12:37:45.000Z","currency":"USD","pricing":{"total":40000000}}],"pagination":{"total":9}}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	if err := runAccount(rt, []string{"orders", "list", "--limit", "2", "--min-total", "20"}); err != nil {
+		t.Fatalf("runAccount: %v", err)
+	}
+	var env struct {
+		Result struct {
+			Orders     []map[string]any `json:"orders"`
+			Matched    int              `json:"matched"`
+			Pagination map[string]any   `json:"pagination"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if len(env.Result.Orders) != 1 || env.Result.Matched != 1 || env.Result.Pagination["total"] != float64(9) {
+		t.Fatalf("expected one matched order and the provider's pagination, got %+v", env.Result)
+	}
+
+	calls = 0
+	for _, flag := range []string{"--since", "--min-total", "--sort"} {
+		err := runAccount(rt, []string{"subscriptions", "list", flag, "2025-01-01"})
+		if apperr.CodeOf(err) != apperr.CodeValidation {
+			t.Fatalf("expected subscriptions list %s to be rejected, got %v", flag, err)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("expected no provider calls for rejected flags, got %d", calls)
+	}
+}
+
 func TestRunAccountSubscriptionsNDJSON(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/v1/subscriptions" {
@@ -69,6 +113,145 @@ func TestRunAccountSubscriptionsNDJSON(t *testing.T) {
 	}
 }
 
+func TestRunAccountSubscriptionGetAndSetAutoRenew(t *testing.T) {
+	renewAuto := true
+	var patched string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/subscriptions/757644825:2" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			var body map[string]bool
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			renewAuto = body["renewAuto"]
+			patched = fmt.Sprint(body)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"subscriptionId":"757644825:2","status":"ACTIVE","label":"EXAMPLE.COM","renewable":true,"renewAuto":%t}`, renewAuto)
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	if err := runAccount(rt, []string{"subscriptions", "get", "757644825:2"}); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !strings.Contains(out.String(), `"subscription_id":"757644825:2"`) || !strings.Contains(out.String(), `"renew_auto":true`) {
+		t.Fatalf("unexpected get output: %s", out.String())
+	}
+
+	out.Reset()
+	if err := runAccount(rt, []string{"subscriptions", "set-auto-renew", "757644825:2", "--enabled", "false"}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if patched != "" || !strings.Contains(out.String(), `"dry_run":true`) {
+		t.Fatalf("expected a dry-run plan without PATCH: %s", out.String())
+	}
+
+	out.Reset()
+	if err := runAccount(rt, []string{"subscriptions", "set-auto-renew", "757644825:2", "--enabled", "false", "--apply"}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if patched != "map[renewAuto:false]" || !strings.Contains(out.String(), `"verified":true`) || !strings.Contains(out.String(), `"renew_auto":false`) {
+		t.Fatalf("unexpected apply (patched=%q): %s", patched, out.String())
+	}
+
+	if err := runAccount(rt, []string{"subscriptions", "set-auto-renew", "757644825:2", "--enabled", "maybe"}); err == nil {
+		t.Fatalf("expected --enabled validation error")
+	}
+}
+
+func TestRunAccountOrdersListAllStreamsPages(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/orders" {
+			http.NotFound(w, r)
+			return
+		}
+		calls++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		var orders []string
+		for i := offset; i < offset+2 && i < 5; i++ {
+			orders = append(orders, fmt.Sprintf(`{"orderId":"o-%d","currency":"USD","pricing":{"total":1000000}}`, i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"orders":[%s],"pagination":{"next":"n","total":5}}`, strings.Join(orders, ","))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, false, true)
+	if err := runAccount(rt, []string{"orders", "list", "--limit", "2", "--all"}); err != nil {
+		t.Fatalf("runAccount: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if calls != 3 || len(lines) != 5 {
+		t.Fatalf("expected 3 pages and 5 records, got %d pages and %d records", calls, len(lines))
+	}
+	var last map[string]any
+	if err := json.Unmarshal([]byte(lines[4]), &last); err != nil {
+		t.Fatalf("decode ndjson record: %v", err)
+	}
+	row, _ := last["result"].(map[string]any)
+	order, _ := row["result"].(map[string]any)
+	if row["index"] != float64(4) || order["order_id"] != "o-4" {
+		t.Fatalf("unexpected last record: %+v", last)
+	}
+
+	calls = 0
+	rt, out = testRuntime(t, srv.URL, true, false)
+	if err := runAccount(rt, []string{"orders", "list", "--limit", "2", "--all", "--max-pages", "2"}); err != nil {
+		t.Fatalf("runAccount: %v", err)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	res, _ := env["result"].(map[string]any)
+	orders, _ := res["orders"].([]any)
+	if calls != 2 || len(orders) != 4 || res["truncated"] != true {
+		t.Fatalf("expected truncated result after 2 pages, got %d calls: %+v", calls, res)
+	}
+}
+
+func TestRunAccountOperationsFiltersNewestFirst(t *testing.T) {
+	rt, out := testRuntime(t, "http://127.0.0.1:1", false, true)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, op := range []store.Operation{
+		{OperationID: "1", Type: "purchase", Domain: "a.com", Amount: 10, Currency: "USD", CreatedAt: base, Status: "succeeded"},
+		{OperationID: "2", Type: "renew", Domain: "a.com", Amount: 12, Currency: "USD", CreatedAt: base.Add(time.Hour), Status: "failed"},
+		{OperationID: "3", Type: "purchase", Domain: "b.com", Amount: 11, Currency: "USD", CreatedAt: base.Add(2 * time.Hour), Status: "succeeded"},
+		{OperationID: "4", Type: "purchase", Domain: "c.com", Amount: 9, Currency: "USD", CreatedAt: base.AddDate(0, 0, -10), Status: "succeeded"},
+	} {
+		if err := store.AppendOperation(op); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if err := runAccount(rt, []string{"operations", "--type", "purchase", "--status", "succeeded", "--since", "2026-02-25"}); err != nil {
+		t.Fatalf("operations: %v", err)
+	}
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var env struct {
+			Result store.Operation `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(line), &env); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		ids = append(ids, env.Result.OperationID)
+	}
+	if strings.Join(ids, ",") != "3,1" {
+		t.Fatalf("expected newest-first succeeded purchases since the cutoff, got %v", ids)
+	}
+
+	err := runAccount(rt, []string{"operations", "--since", "last tuesday"})
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("expected validation error for bad --since, got %v", err)
+	}
+}
+
 func TestRunAccountValidationLimit(t *testing.T) {
 	srv := httptest.NewServer(http.NotFoundHandler())
 	defer srv.Close()
@@ -124,3 +307,99 @@ func testRuntime(t *testing.T, baseURL string, jsonMode, ndjsonMode bool) (*app.
 	}
 	return rt, out
 }
+
+func TestRunAccountWhoamiWithShopperLookup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/orders":
+			_, _ = w.Write([]byte(`{"orders":[],"pagination":{"total":0}}`))
+		case "/v1/shoppers/123456789":
+			_, _ = w.Write([]byte(`{"shopperId":"123456789","customerId":"cust-123","email":"owner@example.com","nameFirst":"Pat"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.ShopperID = "123456789"
+	if err := runAccount(rt, []string{"whoami"}); err != nil {
+		t.Fatalf("account whoami: %v", err)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	lookup, _ := result["shopper_lookup"].(map[string]any)
+	if lookup["ok"] != true {
+		t.Fatalf("expected successful shopper lookup, got %+v", result)
+	}
+	if result["v2_ready"] != false {
+		t.Fatalf("expected v2_ready=false without customer id, got %v", result["v2_ready"])
+	}
+	creds, _ := result["credentials"].(map[string]any)
+	if creds["valid"] != true {
+		t.Fatalf("expected valid credentials, got %+v", result["credentials"])
+	}
+}
+
+func TestRunAccountWhoamiRejectedKeyIsAuthError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"code":"UNABLE_TO_AUTHENTICATE","message":"Unable to authenticate"}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	err := runAccount(rt, []string{"whoami"})
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeAuth {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"doc_url":"`+apperr.TroubleshootingURL+`#auth_error"`) {
+		t.Fatalf("expected troubleshooting link in error envelope: %s", out.String())
+	}
+}
+
+func TestRunAccountWhoamiForbiddenOrdersIsLimitedScope(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"code":"ACCESS_DENIED","message":"Authenticated user is not allowed access"}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	if err := runAccount(rt, []string{"whoami"}); err != nil {
+		t.Fatalf("expected a 403 from the orders probe to keep whoami working, got %v", err)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	creds, _ := result["credentials"].(map[string]any)
+	if creds["valid"] != true || creds["scope"] != "limited" {
+		t.Fatalf("expected valid credentials with limited scope, got %+v", creds)
+	}
+}
+
+func TestRunAccountWhoamiWithoutIdentity(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	if err := runAccount(rt, []string{"identity", "whoami"}); err != nil {
+		t.Fatalf("account identity whoami: %v", err)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	guidance, _ := result["guidance"].([]any)
+	if len(guidance) == 0 {
+		t.Fatalf("expected guidance when identity is missing, got %+v", result)
+	}
+}
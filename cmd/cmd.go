@@ -5,24 +5,51 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/sportwhiz/gdcli/internal/app"
+	"github.com/sportwhiz/gdcli/internal/budget"
 	"github.com/sportwhiz/gdcli/internal/config"
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
 	"github.com/sportwhiz/gdcli/internal/godaddy"
 	"github.com/sportwhiz/gdcli/internal/output"
+	"github.com/sportwhiz/gdcli/internal/rate"
 	"github.com/sportwhiz/gdcli/internal/safety"
 	"github.com/sportwhiz/gdcli/internal/services"
+	"github.com/sportwhiz/gdcli/internal/store"
+	upd "github.com/sportwhiz/gdcli/internal/update"
 )
 
 type globalFlags struct {
-	json   bool
-	ndjson bool
-	quiet  bool
+	json       bool
+	ndjson     bool
+	csv        bool
+	quiet      bool
+	config     string
+	profile    string
+	noKeychain bool
+	timeout    string
+	proxy      string
+	verbose    int
+	fields     []string
+	pretty     bool
+	color      string
+	deadline   string
+	apiEnv     string
+	allowProd  bool
+	noRetry    bool
+	dryRun     bool
 }
 
 func Execute() {
@@ -42,10 +69,58 @@ func run(args []string) error {
 	if len(rest) == 0 {
 		return usageError("missing command")
 	}
-	rt, err := app.NewRuntime(context.Background(), os.Stdout, os.Stderr, g.json || !g.ndjson, g.ndjson, g.quiet, requestID())
+	if err := config.SetPath(g.config); err != nil {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "invalid --config path", Cause: err}
+	}
+	if err := config.SetProfile(g.profile); err != nil {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "invalid --profile name", Cause: err}
+	}
+	machineOutput := g.json || g.ndjson || g.csv
+	path, helpAsked := helpRequest(rest)
+	if helpAsked && helpAsText(machineOutput, os.Stdout) {
+		writeHelp(os.Stdout, path)
+		return nil
+	}
+	app.SetNoKeychain(g.noKeychain)
+	app.SetProxy(g.proxy)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// After the first signal, restore the default handling so a second Ctrl-C kills outright.
+		<-ctx.Done()
+		stop()
+	}()
+	if g.deadline != "" {
+		d, _ := parseDurationFlag("--deadline", g.deadline, 0)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	rt, err := app.NewRuntime(ctx, os.Stdout, os.Stderr, g.json || !g.ndjson, g.ndjson, g.quiet, requestID())
 	if err != nil {
 		return err
 	}
+	applyOutputDefault(rt, g)
+	if output.UseColor(g.color, rt.ErrOut, os.Getenv) {
+		rt.ErrOut = &output.ColorWriter{Writer: rt.ErrOut}
+	}
+	rt.CSV = g.csv
+	rt.HTTPTimeout, _ = parseDurationFlag("--timeout", g.timeout, 0)
+	rt.APIEnvOverride = g.apiEnv
+	rt.AllowProd = g.allowProd
+	rt.NoRetry = g.noRetry
+	rt.DryRun = g.dryRun
+	rt.Log = output.NewLogger(rt.ErrOut, g.verbose)
+	rt.Out.Fields = g.fields
+	rt.Out.Pretty = g.pretty
+	if helpAsked && path != "" && !isGroupPath(path) {
+		d, _ := findDoc(path)
+		flags := make([]map[string]string, 0, len(d.Flags))
+		for _, f := range d.Flags {
+			flags = append(flags, map[string]string{"flag": f[0], "description": f[1]})
+		}
+		return emitSuccess(rt, path+" help", map[string]any{"usage": "gdcli " + d.Usage, "summary": d.Summary, "flags": flags, "examples": d.Examples})
+	}
 	maybeStartUpdateNotifier(rt, rest[0])
 
 	switch rest[0] {
@@ -72,28 +147,225 @@ func run(args []string) error {
 	}
 }
 
+// checkBulkItems enforces max_bulk_items, overridable per run with --max-items.
+func checkBulkItems(rt *app.Runtime, command string, count int, flags map[string]string) error {
+	limit := rt.Cfg.MaxBulkItems
+	if v := strings.TrimSpace(flags["max-items"]); v != "" {
+		if limit = parseIntDefault(v, -1); limit < 0 {
+			err := usageError("--max-items must be a whole number >= 0")
+			emitError(rt, command, err)
+			return err
+		}
+	}
+	if err := services.CheckBulkItems(count, limit); err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	return nil
+}
+
+// concurrencyFlag reads --<name> and enforces max_concurrency, so an absurd worker count fails
+// with validation_error instead of being silently clamped. When the flag is absent, def is
+// lowered to max_concurrency rather than rejected.
+func concurrencyFlag(rt *app.Runtime, command string, flags map[string]string, name string, def int) (int, error) {
+	v := strings.TrimSpace(flags[name])
+	if v == "" {
+		ceiling := rt.Cfg.MaxConcurrency
+		if ceiling <= 0 {
+			ceiling = services.DefaultMaxConcurrency
+		}
+		return min(def, ceiling), nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "--" + name + " must be a whole number", Details: map[string]any{"flag": "--" + name, "value": v}}
+		emitError(rt, command, ae)
+		return 0, ae
+	}
+	if err := services.CheckConcurrency(name, n, rt.Cfg.MaxConcurrency); err != nil {
+		emitError(rt, command, err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// stopReason says why the run's context ended early: "interrupted" for SIGINT/SIGTERM,
+// "deadline reached" for --deadline, or "" while it is still live.
+func stopReason(ctx context.Context) string {
+	switch {
+	case ctx == nil || ctx.Err() == nil:
+		return ""
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "deadline reached"
+	default:
+		return "interrupted"
+	}
+}
+
+// emitRows emits per-domain results even when some rows failed, then returns err (nil or
+// partial_failure) so the exit code still reflects the failures.
+func emitRows(rt *app.Runtime, command string, result any, err error) error {
+	if emitErr := emitSuccess(rt, command, result); emitErr != nil {
+		return emitErr
+	}
+	return err
+}
+
+// helpAsText reports whether --help should print text rather than an envelope.
+func helpAsText(machineOutput bool, stdout io.Writer) bool {
+	return !machineOutput && output.IsTerminal(stdout)
+}
+
+// applyOutputDefault switches to the config's output_default when no output flag was passed.
+func applyOutputDefault(rt *app.Runtime, g globalFlags) {
+	if g.json || g.ndjson || g.csv {
+		return
+	}
+	if strings.EqualFold(strings.TrimSpace(rt.Cfg.OutputDefault), "ndjson") {
+		rt.JSON = false
+		rt.NDJSON = true
+	}
+}
+
 func parseGlobalFlags(args []string) (globalFlags, []string, error) {
 	var g globalFlags
 	rest := make([]string, 0, len(args))
-	for _, a := range args {
-		switch a {
-		case "--json":
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		// --timeout, --api-environment, and --dry-run are global only before the command, since
+		// domains watch, init, settings profile add, and the bulk commands have their own.
+		beforeCommand := len(rest) == 0
+		switch {
+		case a == "--json":
 			g.json = true
-		case "--ndjson":
+		case a == "--ndjson":
 			g.ndjson = true
-		case "--quiet":
+		case a == "--csv":
+			g.csv = true
+		case a == "--quiet":
 			g.quiet = true
+		case a == "--pretty":
+			g.pretty = true
+		case a == "--no-color":
+			g.color = output.ColorNever
+		case a == "--color":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return g, nil, usageError("--color requires auto, always, or never")
+			}
+			g.color = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--color="):
+			g.color = strings.TrimPrefix(a, "--color=")
+		case a == "--no-keychain":
+			g.noKeychain = true
+		case a == "--allow-prod":
+			g.allowProd = true
+		case a == "--no-retry":
+			g.noRetry = true
+		case a == "--verbose" || a == "-v":
+			g.verbose++
+		case a == "-vv":
+			g.verbose += 2
+		case a == "--config":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return g, nil, usageError("--config requires a file path")
+			}
+			g.config = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--config="):
+			g.config = strings.TrimPrefix(a, "--config=")
+		case a == "--profile":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return g, nil, usageError("--profile requires a profile name")
+			}
+			g.profile = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--profile="):
+			g.profile = strings.TrimPrefix(a, "--profile=")
+		case a == "--proxy":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return g, nil, usageError("--proxy requires a proxy URL")
+			}
+			g.proxy = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--proxy="):
+			g.proxy = strings.TrimPrefix(a, "--proxy=")
+		case a == "--fields" || strings.HasPrefix(a, "--fields="):
+			v, ok := strings.CutPrefix(a, "--fields=")
+			if !ok {
+				if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+					return g, nil, usageError("--fields requires a comma-separated field list")
+				}
+				v = args[i+1]
+				i++
+			}
+			fields, err := output.ParseFields(v)
+			if err != nil {
+				return g, nil, usageError(err.Error())
+			}
+			g.fields = fields
+		case beforeCommand && a == "--dry-run":
+			g.dryRun = true
+		case beforeCommand && a == "--timeout":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return g, nil, usageError("--timeout requires a duration")
+			}
+			g.timeout = args[i+1]
+			i++
+		case beforeCommand && strings.HasPrefix(a, "--timeout="):
+			g.timeout = strings.TrimPrefix(a, "--timeout=")
+		case beforeCommand && a == "--api-environment":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return g, nil, usageError("--api-environment requires prod or ote")
+			}
+			g.apiEnv = args[i+1]
+			i++
+		case beforeCommand && strings.HasPrefix(a, "--api-environment="):
+			g.apiEnv = strings.TrimPrefix(a, "--api-environment=")
+		case a == "--deadline":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return g, nil, usageError("--deadline requires a duration")
+			}
+			g.deadline = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--deadline="):
+			g.deadline = strings.TrimPrefix(a, "--deadline=")
 		default:
 			rest = append(rest, a)
 		}
 	}
+	if g.csv && (g.json || g.ndjson) {
+		return g, nil, usageError("--csv cannot be combined with --json or --ndjson")
+	}
+	if g.color == "" {
+		g.color = output.ColorAuto
+	}
+	if !output.ValidColorMode(g.color) {
+		return g, nil, usageError("--color must be auto, always, or never")
+	}
+	if g.pretty && g.ndjson {
+		return g, nil, usageError("--pretty cannot be combined with --ndjson, which keeps one object per line")
+	}
+	if g.timeout != "" {
+		if _, err := parseDurationFlag("--timeout", g.timeout, 0); err != nil {
+			return g, nil, err
+		}
+	}
+	if g.deadline != "" {
+		if _, err := parseDurationFlag("--deadline", g.deadline, 0); err != nil {
+			return g, nil, err
+		}
+	}
+	if g.apiEnv != "" && g.apiEnv != "prod" && g.apiEnv != "ote" {
+		return g, nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "api-environment must be prod or ote"}
+	}
 	return g, rest, nil
 }
 
 func runInit(rt *app.Runtime, args []string) error {
 	if len(args) > 0 && isHelpToken(args[0]) {
 		return emitSuccess(rt, "init help", map[string]any{
-			"usage": "gdcli init [--api-environment prod|ote] [--max-price N] [--max-daily-spend N] [--max-domains-per-day N] [--shopper-id ID|$GDCLI_SHOPPER_ID --resolve-customer-id] [--enable-auto-purchase --ack \"I UNDERSTAND PURCHASES ARE FINAL\"] [--store-keychain --api-key KEY --api-secret SECRET] [--verify]",
+			"usage": "gdcli " + usageOf("init"),
 		})
 	}
 
@@ -139,11 +411,110 @@ func runInit(rt *app.Runtime, args []string) error {
 		rt.Cfg.MaxDomainsPerDay = n
 		changed["max_domains_per_day"] = n
 	}
+	if v := strings.TrimSpace(flags["max-monthly-spend"]); v != "" {
+		n := parseFloatDefault(v, -1)
+		if n < 0 {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "max-monthly-spend must be >= 0"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.MaxMonthlySpend = n
+		changed["max_monthly_spend"] = n
+	}
+	if v := strings.TrimSpace(flags["confirm-token-ttl-minutes"]); v != "" {
+		n := parseIntDefault(v, -1)
+		if err := safety.CheckTokenTTLMinutes(n); err != nil {
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.ConfirmTokenTTLMinutes = n
+		changed["confirm_token_ttl_minutes"] = n
+	}
+	if v := strings.TrimSpace(flags["min-plausible-price"]); v != "" {
+		n := parseFloatDefault(v, -1)
+		if n < 0 {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "min-plausible-price must be >= 0"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.MinPlausiblePrice = n
+		changed["min_plausible_price"] = n
+	}
+	if v := strings.TrimSpace(flags["max-concurrency"]); v != "" {
+		n := parseIntDefault(v, -1)
+		if n <= 0 {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "max-concurrency must be > 0"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.MaxConcurrency = n
+		changed["max_concurrency"] = n
+	}
+	if v := strings.TrimSpace(flags["retry-attempts"]); v != "" {
+		n := parseIntDefault(v, -1)
+		if n < 1 || n > 10 {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "retry-attempts must be between 1 and 10"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.RetryAttempts = n
+		changed["retry_attempts"] = n
+	}
+	if v := strings.TrimSpace(flags["retry-base-ms"]); v != "" {
+		n := parseIntDefault(v, -1)
+		if n < 10 || n > 60000 {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "retry-base-ms must be between 10 and 60000"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.RetryBaseMs = n
+		changed["retry_base_ms"] = n
+	}
+	if v := strings.TrimSpace(flags["http-timeout-seconds"]); v != "" {
+		n := parseIntDefault(v, -1)
+		if n <= 0 {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "http-timeout-seconds must be > 0"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.HTTPTimeoutSeconds = n
+		changed["http_timeout_seconds"] = n
+	}
+	if v := strings.TrimSpace(flags["update-notice"]); v != "" {
+		if v != "stderr" && v != "off" {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "update-notice must be stderr or off"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.UpdateNoticeStream = v
+		changed["update_notice_stream"] = v
+	}
+	if v := strings.TrimSpace(flags["update-channel"]); v != "" {
+		ch, ok := upd.NormalizeChannel(v)
+		if !ok {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "update-channel must be stable or beta"}
+			emitError(rt, "init", err)
+			return err
+		}
+		rt.Cfg.UpdateChannel = ch
+		changed["update_channel"] = ch
+	}
 	if v := strings.TrimSpace(flags["shopper-id"]); v != "" {
 		rt.Cfg.ShopperID = v
 		changed["shopper_id"] = v
 	}
 
+	if v := strings.TrimSpace(flags["require-ote-first"]); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "require-ote-first must be true or false"}
+			emitError(rt, "init", ae)
+			return ae
+		}
+		rt.Cfg.RequireOTEFirst = on
+		changed["require_ote_first"] = on
+	}
+
 	if hasBoolFlag(args, "enable-auto-purchase") {
 		ack := strings.TrimSpace(flags["ack"])
 		hash, err := safety.EnableAutoPurchase(ack)
@@ -203,28 +574,64 @@ func runInit(rt *app.Runtime, args []string) error {
 			emitError(rt, "init", err)
 			return err
 		}
-		if err := app.StoreCredentialsInKeychain(apiKey, apiSecret); err != nil {
+		if err := app.StoreCredentialsInKeychain(rt.Cfg.Profile(), apiKey, apiSecret); err != nil {
 			emitError(rt, "init", err)
 			return err
 		}
 		keychainStored = true
 	}
 
+	fileStored := false
+	credentialsPath := ""
+	if hasBoolFlag(args, "store-file") {
+		apiKey := strings.TrimSpace(flags["api-key"])
+		apiSecret := strings.TrimSpace(flags["api-secret"])
+		if apiKey == "" || apiSecret == "" {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "--store-file requires --api-key and --api-secret"}
+			emitError(rt, "init", err)
+			return err
+		}
+		path, err := app.StoreCredentialsInFile(rt.Cfg.Profile(), apiKey, apiSecret)
+		if err != nil {
+			emitError(rt, "init", err)
+			return err
+		}
+		fileStored = true
+		credentialsPath = path
+	}
+
 	verified := false
 	verifyResult := map[string]any{"ok": false}
+	deep := hasBoolFlag(args, "deep")
+	if deep && !hasBoolFlag(args, "verify") {
+		err := usageError("--deep requires --verify")
+		emitError(rt, "init", err)
+		return err
+	}
 	if hasBoolFlag(args, "verify") {
 		svc, err := newService(rt)
 		if err != nil {
 			emitError(rt, "init", err)
 			return err
 		}
-		avail, err := svc.Availability(rt.Ctx, "example.com")
+		checks, err := svc.VerifySetup(rt.Ctx, deep)
 		if err != nil {
+			if deep {
+				code := apperr.CodeInternal
+				var ae *apperr.AppError
+				if apperr.As(err, &ae) {
+					code = ae.Code
+				}
+				err = &apperr.AppError{Code: code, Message: "setup verification failed", Details: map[string]any{"checks": checks}, Cause: err}
+			}
 			emitError(rt, "init", err)
 			return err
 		}
 		verified = true
-		verifyResult = map[string]any{"ok": true, "sample_domain": avail.Domain}
+		verifyResult = map[string]any{"ok": true, "sample_domain": checks[0]["sample_domain"]}
+		if deep {
+			verifyResult["checks"] = checks
+		}
 	}
 
 	configPath, _ := config.Path()
@@ -233,6 +640,7 @@ func runInit(rt *app.Runtime, args []string) error {
 		"changed":           changed,
 		"config_path":       configPath,
 		"keychain_stored":   keychainStored,
+		"file_stored":       fileStored,
 		"verified":          verified,
 		"customer_resolved": customerResolved,
 		"env_identity": map[string]any{
@@ -241,18 +649,21 @@ func runInit(rt *app.Runtime, args []string) error {
 		},
 		"verification_info": verifyResult,
 		"next_steps": []string{
-			"set GODADDY_API_KEY and GODADDY_API_SECRET (or use --store-keychain on macOS)",
+			"set GODADDY_API_KEY and GODADDY_API_SECRET (or use --store-keychain on macOS, or --store-file with GDCLI_PASSPHRASE)",
 			"run: gdcli settings show --json",
 			"run: gdcli domains avail example.com --json",
 		},
 	}
+	if fileStored {
+		res["credentials_path"] = credentialsPath
+	}
 	return emitSuccess(rt, "init", res)
 }
 
 func runDomains(rt *app.Runtime, args []string) error {
 	if len(args) == 0 || isHelpToken(args[0]) {
 		return emitSuccess(rt, "domains help", map[string]any{
-			"subcommands": []string{"suggest", "avail", "avail-bulk", "purchase", "renew", "renew-bulk", "list", "portfolio", "detail", "actions", "usage", "maintenances", "notifications", "contacts", "nameservers", "dnssec", "forwarding", "privacy-forwarding", "register", "transfer", "redeem"},
+			"subcommands": []string{"suggest", "bulk-suggest", "discover", "tlds", "agreements", "avail", "watch", "avail-bulk", "purchase", "purchase-bulk", "renew", "renew-bulk", "list", "portfolio", "detail", "actions", "usage", "maintenances", "notifications", "contacts", "nameservers", "lock", "records", "dnssec", "forwarding", "privacy-forwarding", "register", "transfer", "redeem", "plan"},
 		})
 	}
 	if len(args) == 0 {
@@ -270,7 +681,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 	switch sub {
 	case "suggest":
 		if len(rest) == 0 {
-			err := usageError("domains suggest <query>")
+			err := usageError(usageOf("domains suggest"))
 			emitError(rt, "domains suggest", err)
 			return err
 		}
@@ -278,15 +689,147 @@ func runDomains(rt *app.Runtime, args []string) error {
 		flags := parseKVFlags(rest[1:])
 		tlds := splitCSV(flags["tlds"])
 		limit := parseIntDefault(flags["limit"], 20)
-		res, err := svc.Suggest(rt.Ctx, query, tlds, limit)
+		concurrency, err := concurrencyFlag(rt, "domains suggest", flags, "concurrency", 10)
+		if err != nil {
+			return err
+		}
+		minScore := 0.0
+		if v := strings.TrimSpace(flags["min-score"]); v != "" {
+			if minScore = parseFloatDefault(v, -1); !(minScore >= 0) {
+				err := usageError("--min-score must be a number >= 0")
+				emitError(rt, "domains suggest", err)
+				return err
+			}
+		}
+		filter := services.SuggestFilter{
+			MinScore:      minScore,
+			SortBy:        strings.TrimSpace(flags["sort"]),
+			AvailableOnly: hasBoolFlag(rest[1:], "available-only"),
+			Concurrency:   concurrency,
+		}
+		if filter.SortBy != "" && !slices.Contains(services.SuggestSortFields, filter.SortBy) {
+			err := usageError("--sort must be one of " + strings.Join(services.SuggestSortFields, ", "))
+			emitError(rt, "domains suggest", err)
+			return err
+		}
+		res, err := svc.Suggest(rt.Ctx, query, tlds, limit, filter)
 		if err != nil {
 			emitError(rt, "domains suggest", err)
 			return err
 		}
 		return emitSuccess(rt, "domains suggest", res)
+	case "discover":
+		flags := parseKVFlags(rest)
+		if strings.TrimSpace(flags["seeds"]) == "" {
+			err := usageError(usageOf("domains discover"))
+			emitError(rt, "domains discover", err)
+			return err
+		}
+		seeds, err := services.LoadDomainFile(flags["seeds"])
+		if err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading seed list", Cause: err}
+			emitError(rt, "domains discover", ae)
+			return ae
+		}
+		if err := checkBulkItems(rt, "domains discover", len(seeds), flags); err != nil {
+			return err
+		}
+		suggestConcurrency, err := concurrencyFlag(rt, "domains discover", flags, "suggest-concurrency", 4)
+		if err != nil {
+			return err
+		}
+		checkConcurrency, err := concurrencyFlag(rt, "domains discover", flags, "check-concurrency", 4)
+		if err != nil {
+			return err
+		}
+		opts := services.DiscoverOptions{
+			TLDs:               splitCSV(flags["tlds"]),
+			Limit:              parseIntDefault(flags["limit"], 20),
+			MaxPrice:           parseFloatDefault(flags["max-price"], rt.Cfg.MaxPricePerDomain),
+			SuggestConcurrency: suggestConcurrency,
+			CheckConcurrency:   checkConcurrency,
+			BatchSize:          parseIntDefault(flags["batch-size"], 50),
+			Definitive:         hasBoolFlag(rest, "definitive"),
+		}
+		candidates, err := svc.Discover(rt.Ctx, seeds, opts)
+		availableOnly := hasBoolFlag(rest, "available-only")
+		recs := make([]any, 0, len(candidates))
+		var buyable []string
+		for _, c := range candidates {
+			if c.Buyable {
+				buyable = append(buyable, c.Domain)
+			}
+			if availableOnly && !c.Available {
+				continue
+			}
+			recs = append(recs, c)
+		}
+		outPath := strings.TrimSpace(flags["out"])
+		if outPath != "" {
+			body := strings.Join(buyable, "\n")
+			if body != "" {
+				body += "\n"
+			}
+			if writeErr := os.WriteFile(filepath.Clean(outPath), []byte(body), 0o600); writeErr != nil {
+				ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed writing candidates file", Cause: writeErr, Details: map[string]any{"path": outPath}}
+				emitError(rt, "domains discover", ae)
+				return ae
+			}
+		}
+		if rt.NDJSON {
+			if emitErr := emitSuccess(rt, "domains discover", recs); emitErr != nil {
+				return emitErr
+			}
+		} else {
+			res := map[string]any{"seeds": len(seeds), "results": recs, "buyable": len(buyable), "total": len(candidates)}
+			if outPath != "" {
+				res["out"] = outPath
+			}
+			if emitErr := emitSuccess(rt, "domains discover", res); emitErr != nil {
+				return emitErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return nil
+	case "bulk-suggest":
+		file, flagArgs := splitFileArg(rest)
+		if file == "" {
+			err := usageError(usageOf("domains bulk-suggest"))
+			emitError(rt, "domains bulk-suggest", err)
+			return err
+		}
+		seeds, err := services.LoadDomainFile(file)
+		if err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading seed list", Cause: err}
+			emitError(rt, "domains bulk-suggest", ae)
+			return ae
+		}
+		flags := parseKVFlags(flagArgs)
+		if err := checkBulkItems(rt, "domains bulk-suggest", len(seeds), flags); err != nil {
+			return err
+		}
+		concurrency, err := concurrencyFlag(rt, "domains bulk-suggest", flags, "concurrency", 4)
+		if err != nil {
+			return err
+		}
+		merged, failures, err := svc.BulkSuggest(rt.Ctx, seeds, splitCSV(flags["tlds"]), parseIntDefault(flags["limit"], 20), concurrency)
+		if rt.NDJSON {
+			recs := make([]any, 0, len(merged))
+			for _, m := range merged {
+				recs = append(recs, m)
+			}
+			return emitRows(rt, "domains bulk-suggest", recs, err)
+		}
+		res := map[string]any{"seeds": len(seeds), "suggestions": merged, "count": len(merged)}
+		if len(failures) > 0 {
+			res["failed_seeds"] = failures
+		}
+		return emitRows(rt, "domains bulk-suggest", res, err)
 	case "avail":
 		if len(rest) == 0 {
-			err := usageError("domains avail <domain>")
+			err := usageError(usageOf("domains avail"))
 			emitError(rt, "domains avail", err)
 			return err
 		}
@@ -296,20 +839,60 @@ func runDomains(rt *app.Runtime, args []string) error {
 			return err
 		}
 		return emitSuccess(rt, "domains avail", res)
+	case "watch":
+		return runDomainsWatch(rt, svc, rest)
+	case "agreements":
+		flags := parseKVFlags(rest)
+		tlds := splitCSV(flags["tlds"])
+		if len(tlds) == 0 {
+			err := usageError(usageOf("domains agreements"))
+			emitError(rt, "domains agreements", err)
+			return err
+		}
+		res, err := svc.Agreements(rt.Ctx, tlds, hasBoolFlag(rest, "privacy"), hasBoolFlag(rest, "for-transfer"))
+		if err != nil {
+			emitError(rt, "domains agreements", err)
+			return err
+		}
+		withText := hasBoolFlag(rest, "with-text")
+		keys := make([]string, 0, len(res))
+		agreements := make([]any, 0, len(res))
+		for _, a := range res {
+			keys = append(keys, a.Key)
+			row := map[string]any{"key": a.Key, "title": a.Title, "url": a.URL}
+			if withText {
+				row["content"] = a.Content
+			}
+			agreements = append(agreements, row)
+		}
+		return emitSuccess(rt, "domains agreements", map[string]any{"tlds": tlds, "agreements": agreements, "agreement_keys": keys})
+	case "tlds":
+		flags := parseKVFlags(rest)
+		filter := splitCSV(flags["tld"])
+		withPrices := hasBoolFlag(rest, "with-prices") || len(filter) > 0
+		res, err := svc.TLDs(rt.Ctx, filter, withPrices)
+		if err != nil && res == nil {
+			emitError(rt, "domains tlds", err)
+			return err
+		}
+		if emitErr := emitSuccess(rt, "domains tlds", map[string]any{"tlds": res, "count": len(res), "priced": withPrices}); emitErr != nil {
+			return emitErr
+		}
+		return err
 	case "avail-bulk":
-		if len(rest) == 0 {
-			err := usageError("domains avail-bulk <file>")
-			emitError(rt, "domains avail-bulk", err)
+		file, flagArgs := splitFileArg(rest)
+		flags := parseKVFlags(flagArgs)
+		domains, err := bulkDomains(rt, "domains avail-bulk", file, flags)
+		if err != nil {
+			return err
+		}
+		if err := checkBulkItems(rt, "domains avail-bulk", len(domains), flags); err != nil {
 			return err
 		}
-		domains, err := services.LoadDomainFile(rest[0])
+		concurrency, err := concurrencyFlag(rt, "domains avail-bulk", flags, "concurrency", 10)
 		if err != nil {
-			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading domain list", Cause: err}
-			emitError(rt, "domains avail-bulk", ae)
-			return ae
+			return err
 		}
-		flags := parseKVFlags(rest[1:])
-		concurrency := parseIntDefault(flags["concurrency"], 10)
 		res, err := svc.AvailabilityBulkConcurrent(rt.Ctx, domains, concurrency)
 		recs := make([]any, 0, len(res))
 		for _, r := range res {
@@ -318,15 +901,20 @@ func runDomains(rt *app.Runtime, args []string) error {
 				"input":       r.Input,
 				"success":     r.Success,
 				"duration_ms": r.Duration,
+				"attempts":    r.Attempts,
+			}
+			if r.LastStatus != 0 {
+				row["last_status"] = r.LastStatus
 			}
 			if r.Success {
 				row["result"] = r.Result
+				row["definitive"] = r.Definitive
 			} else {
 				row["error"] = r.Error
 			}
 			recs = append(recs, row)
 		}
-		if rt.NDJSON {
+		if rt.NDJSON || rt.CSV {
 			if emitErr := emitSuccess(rt, "domains avail-bulk", recs); emitErr != nil {
 				return emitErr
 			}
@@ -341,7 +929,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return nil
 	case "purchase":
 		if len(rest) == 0 {
-			err := usageError("domains purchase <domain>")
+			err := usageError(usageOf("domains purchase"))
 			emitError(rt, "domains purchase", err)
 			return err
 		}
@@ -349,25 +937,31 @@ func runDomains(rt *app.Runtime, args []string) error {
 		domain := rest[0]
 		flags := parseKVFlags(rest[1:])
 		years := parseIntDefault(flags["years"], 1)
+		floor, err := purchaseFloor(rt, rest[1:], flags)
+		if err != nil {
+			emitError(rt, "domains purchase", err)
+			return err
+		}
 		confirm := flags["confirm"]
 		auto := hasBoolFlag(rest[1:], "auto")
 		if auto {
-			res, err := svc.PurchaseAuto(rt.Ctx, domain, years)
+			res, err := svc.PurchaseAuto(rt.Ctx, domain, years, floor)
 			if err != nil {
 				emitError(rt, "domains purchase", err)
 				return err
 			}
-			return emitSuccess(rt, "domains purchase", res)
+			return emitSuccess(rt, "domains purchase", purchaseOutput(res))
 		}
 		if confirm != "" {
-			res, err := svc.PurchaseConfirm(rt.Ctx, domain, confirm, years)
+			// Without --years the token's own term is used.
+			res, err := svc.PurchaseConfirm(rt.Ctx, domain, confirm, parseIntDefault(flags["years"], 0))
 			if err != nil {
 				emitError(rt, "domains purchase", err)
 				return err
 			}
-			return emitSuccess(rt, "domains purchase", res)
+			return emitSuccess(rt, "domains purchase", purchaseOutput(res))
 		}
-		res, err := svc.PurchaseDryRun(rt.Ctx, domain, years)
+		res, err := svc.PurchaseDryRun(rt.Ctx, domain, years, floor)
 		if err != nil {
 			emitError(rt, "domains purchase", err)
 			return err
@@ -375,7 +969,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains purchase", res)
 	case "renew":
 		if len(rest) == 0 {
-			err := usageError("domains renew <domain> --years <n>")
+			err := usageError(usageOf("domains renew"))
 			emitError(rt, "domains renew", err)
 			return err
 		}
@@ -383,62 +977,168 @@ func runDomains(rt *app.Runtime, args []string) error {
 		domain := rest[0]
 		flags := parseKVFlags(rest[1:])
 		years := parseIntDefault(flags["years"], 1)
-		dryRun := hasBoolFlag(rest[1:], "dry-run")
+		dryRun := rt.DryRun || hasBoolFlag(rest[1:], "dry-run")
 		autoApprove := hasBoolFlag(rest[1:], "auto-approve") || hasBoolFlag(rest[1:], "apply")
-		res, err := svc.Renew(rt.Ctx, domain, years, dryRun, autoApprove)
+		var res map[string]any
+		var err error
+		switch {
+		case strings.TrimSpace(flags["confirm"]) != "":
+			res, err = svc.RenewConfirm(rt.Ctx, domain, strings.TrimSpace(flags["confirm"]), parseIntDefault(flags["years"], 0))
+		case dryRun || !autoApprove:
+			res, err = svc.RenewDryRun(rt.Ctx, domain, years)
+		default:
+			res, err = svc.Renew(rt.Ctx, domain, years, false, true)
+		}
 		if err != nil {
 			emitError(rt, "domains renew", err)
 			return err
 		}
 		return emitSuccess(rt, "domains renew", res)
-	case "renew-bulk":
-		if len(rest) == 0 {
-			err := usageError("domains renew-bulk <file>")
-			emitError(rt, "domains renew-bulk", err)
+	case "purchase-bulk":
+		file, flagArgs := splitFileArg(rest)
+		flags := parseKVFlags(flagArgs)
+		auto := hasBoolFlag(flagArgs, "auto")
+		if auto && hasBoolFlag(flagArgs, "confirm-each") {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "--auto and --confirm-each are mutually exclusive"}
+			emitError(rt, "domains purchase-bulk", err)
 			return err
 		}
-		app.MaybeWarnProdFinancial(rt, "domains renew-bulk")
-		domains, err := services.LoadDomainFile(rest[0])
+		floor, err := purchaseFloor(rt, flagArgs, flags)
 		if err != nil {
-			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading domain list", Cause: err}
-			emitError(rt, "domains renew-bulk", ae)
-			return ae
+			emitError(rt, "domains purchase-bulk", err)
+			return err
+		}
+		domains, err := bulkDomains(rt, "domains purchase-bulk", file, flags)
+		if err != nil {
+			return err
+		}
+		app.MaybeWarnProdFinancial(rt, "domains purchase-bulk")
+		if err := checkBulkItems(rt, "domains purchase-bulk", len(domains), flags); err != nil {
+			return err
 		}
-		flags := parseKVFlags(rest[1:])
 		years := parseIntDefault(flags["years"], 1)
-		dryRun := hasBoolFlag(rest[1:], "dry-run")
-		autoApprove := hasBoolFlag(rest[1:], "auto-approve") || hasBoolFlag(rest[1:], "apply")
+		continueOnError := hasBoolFlag(flagArgs, "continue-on-error")
 		results := make([]any, 0, len(domains))
 		failed := 0
+		skipped := 0
+		var failedItems []services.FailedItem
+		// capErr is the spend cap error that stopped the run; later rows are skipped under it.
+		var capErr error
 		for i, d := range domains {
-			res, err := svc.Renew(rt.Ctx, d, years, dryRun, autoApprove)
+			if capErr != nil {
+				skipped++
+				failedItems = append(failedItems, services.NewFailedItem(d, capErr))
+				results = append(results, map[string]any{"index": i, "input": d, "success": false, "skipped": true, "error": "skipped: spend cap reached"})
+				continue
+			}
+			if reason := stopReason(rt.Ctx); reason != "" {
+				skipped++
+				failedItems = append(failedItems, services.NewFailedItem(d, rt.Ctx.Err()))
+				results = append(results, map[string]any{"index": i, "input": d, "success": false, "skipped": true, "error": "skipped: " + reason})
+				continue
+			}
+			start := time.Now()
+			ctx, stats := rate.WithStats(rt.Ctx)
+			var res any
+			var err error
+			if auto {
+				var r godaddy.PurchaseResult
+				r, err = svc.PurchaseAuto(ctx, d, years, floor)
+				if err == nil {
+					res = purchaseOutput(r)
+				}
+			} else {
+				res, err = svc.PurchaseDryRun(ctx, d, years, floor)
+			}
+			row := map[string]any{"index": i, "input": d, "success": err == nil, "duration_ms": time.Since(start).Milliseconds(), "attempts": stats.Attempts()}
+			if status := services.AttemptStatus(stats); status != 0 {
+				row["last_status"] = status
+			}
 			if err != nil {
 				failed++
-				results = append(results, map[string]any{"index": i, "input": d, "success": false, "error": err.Error(), "duration_ms": 0})
-				continue
+				failedItems = append(failedItems, services.NewFailedItem(d, err))
+				row["error"] = err.Error()
+				// Caps only tighten as the run goes on, so stop rather than fail every remaining row.
+				if budget.IsCapExceeded(err) && !continueOnError {
+					capErr = err
+				}
+			} else {
+				row["result"] = res
 			}
-			results = append(results, map[string]any{"index": i, "input": d, "success": true, "result": res, "duration_ms": 0})
+			results = append(results, row)
 		}
-		if err := emitSuccess(rt, "domains renew-bulk", results); err != nil {
+		if err := emitSuccess(rt, "domains purchase-bulk", results); err != nil {
 			return err
 		}
-		if failed > 0 {
-			return &apperr.AppError{Code: apperr.CodePartial, Message: fmt.Sprintf("%d renewals failed", failed), Details: map[string]any{"failed": failed, "total": len(domains)}}
+		if failed+skipped > 0 {
+			return &apperr.AppError{Code: apperr.CodePartial, Message: fmt.Sprintf("%d purchases failed", failed+skipped), Details: map[string]any{"failed": failed, "skipped": skipped, "total": len(domains), "failed_items": failedItems}}
 		}
 		return nil
-	case "list":
-		flags := parseKVFlags(rest)
-		expiring := parseIntDefault(flags["expiring-in"], 0)
-		tld := flags["tld"]
-		contains := flags["contains"]
-		withNameservers := hasBoolFlag(rest, "with-nameservers")
-		if withNameservers {
-			concurrency := parseIntDefault(flags["concurrency"], 5)
-			res, err := svc.PortfolioWithNameservers(rt.Ctx, expiring, tld, contains, concurrency)
-			if err != nil {
+	case "renew-bulk":
+		file, flagArgs := splitFileArg(rest)
+		flags := parseKVFlags(flagArgs)
+		domains, err := bulkDomains(rt, "domains renew-bulk", file, flags)
+		if err != nil {
+			return err
+		}
+		app.MaybeWarnProdFinancial(rt, "domains renew-bulk")
+		if err := checkBulkItems(rt, "domains renew-bulk", len(domains), flags); err != nil {
+			return err
+		}
+		years := parseIntDefault(flags["years"], 1)
+		dryRun := rt.DryRun || hasBoolFlag(flagArgs, "dry-run")
+		autoApprove := hasBoolFlag(flagArgs, "auto-approve") || hasBoolFlag(flagArgs, "apply")
+		results := make([]any, 0, len(domains))
+		skipped := 0
+		var failed []services.FailedItem
+		for i, d := range domains {
+			if reason := stopReason(rt.Ctx); reason != "" {
+				skipped++
+				failed = append(failed, services.NewFailedItem(d, rt.Ctx.Err()))
+				results = append(results, map[string]any{"index": i, "input": d, "success": false, "skipped": true, "error": "skipped: " + reason})
+				continue
+			}
+			start := time.Now()
+			ctx, stats := rate.WithStats(rt.Ctx)
+			res, err := svc.Renew(ctx, d, years, dryRun, autoApprove)
+			row := map[string]any{"index": i, "input": d, "success": err == nil, "duration_ms": time.Since(start).Milliseconds(), "attempts": stats.Attempts()}
+			if status := services.AttemptStatus(stats); status != 0 {
+				row["last_status"] = status
+			}
+			if err != nil {
+				failed = append(failed, services.NewFailedItem(d, err))
+				row["error"] = err.Error()
+			} else {
+				row["result"] = res
+			}
+			results = append(results, row)
+		}
+		if err := emitSuccess(rt, "domains renew-bulk", results); err != nil {
+			return err
+		}
+		if len(failed) > 0 {
+			return &apperr.AppError{Code: apperr.CodePartial, Message: fmt.Sprintf("%d renewals failed", len(failed)), Details: map[string]any{"failed": len(failed) - skipped, "skipped": skipped, "total": len(domains), "failed_items": failed}}
+		}
+		return nil
+	case "list":
+		flags := parseKVFlags(rest)
+		expiring := parseIntDefault(flags["expiring-in"], 0)
+		tld := flags["tld"]
+		contains := flags["contains"]
+		withNameservers := hasBoolFlag(rest, "with-nameservers")
+		if withNameservers {
+			concurrency, err := concurrencyFlag(rt, "domains list", flags, "concurrency", 5)
+			if err != nil {
+				return err
+			}
+			res, err := svc.PortfolioWithNameservers(rt.Ctx, expiring, tld, contains, concurrency)
+			if err != nil {
 				emitError(rt, "domains list", err)
 				return err
 			}
+			if rt.CSV {
+				return emitSuccess(rt, "domains list", res)
+			}
 			return emitSuccess(rt, "domains list", map[string]any{"domains": res, "source": "portfolio_with_details"})
 		}
 		res, err := svc.ListPortfolio(rt.Ctx, expiring, tld, contains)
@@ -446,15 +1146,21 @@ func runDomains(rt *app.Runtime, args []string) error {
 			emitError(rt, "domains list", err)
 			return err
 		}
+		if rt.CSV {
+			return emitSuccess(rt, "domains list", res)
+		}
 		return emitSuccess(rt, "domains list", map[string]any{"domains": res})
 	case "portfolio":
 		flags := parseKVFlags(rest)
 		expiring := parseIntDefault(flags["expiring-in"], 0)
 		tld := flags["tld"]
 		contains := flags["contains"]
-		concurrency := parseIntDefault(flags["concurrency"], 5)
+		concurrency, err := concurrencyFlag(rt, "domains portfolio", flags, "concurrency", 5)
+		if err != nil {
+			return err
+		}
 		res, err := svc.PortfolioWithNameservers(rt.Ctx, expiring, tld, contains, concurrency)
-		if rt.NDJSON {
+		if rt.NDJSON || rt.CSV {
 			rows := make([]any, 0, len(res))
 			for _, item := range res {
 				rows = append(rows, item)
@@ -473,7 +1179,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return nil
 	case "detail":
 		if len(rest) == 0 {
-			err := usageError("domains detail <domain> [--includes a,b,c]")
+			err := usageError(usageOf("domains detail"))
 			emitError(rt, "domains detail", err)
 			return err
 		}
@@ -487,7 +1193,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains detail", res)
 	case "actions":
 		if len(rest) == 0 {
-			err := usageError("domains actions <domain> [--type <actionType>]")
+			err := usageError(usageOf("domains actions"))
 			emitError(rt, "domains actions", err)
 			return err
 		}
@@ -510,7 +1216,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains actions", res)
 	case "change-of-registrant":
 		if len(rest) == 0 {
-			err := usageError("domains change-of-registrant <domain>")
+			err := usageError(usageOf("domains change-of-registrant"))
 			emitError(rt, "domains change-of-registrant", err)
 			return err
 		}
@@ -527,13 +1233,14 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains change-of-registrant", res)
 	case "auth-code":
 		if len(rest) < 2 || rest[0] != "regenerate" {
-			err := usageError("domains auth-code regenerate <domain> [--apply]")
+			err := usageError(usageOf("domains auth-code"))
 			emitError(rt, "domains auth-code", err)
 			return err
 		}
 		domain := rest[1]
 		if !hasBoolFlag(rest[2:], "apply") {
-			return emitSuccess(rt, "domains auth-code regenerate", map[string]any{"dry_run": true, "domain": domain})
+			plan := services.NewPlan("domains auth-code regenerate", "POST", "/v2/customers/{customerId}/domains/"+domain+"/regenerateAuthCode", map[string]any{})
+			return emitSuccess(rt, "domains auth-code regenerate", map[string]any{"dry_run": true, "domain": domain, "plan": plan})
 		}
 		path, err := svc.V2PathCustomer("/v2/customers/{customerId}/domains/" + domain + "/regenerateAuthCode")
 		if err != nil {
@@ -548,7 +1255,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains auth-code regenerate", res)
 	case "usage":
 		if len(rest) == 0 {
-			err := usageError("domains usage <yyyymm>")
+			err := usageError(usageOf("domains usage"))
 			emitError(rt, "domains usage", err)
 			return err
 		}
@@ -577,7 +1284,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains maintenances", res)
 	case "notifications":
 		if len(rest) == 0 {
-			err := usageError("domains notifications <next|optin|schema|ack>")
+			err := usageError(usageOf("domains notifications"))
 			emitError(rt, "domains notifications", err)
 			return err
 		}
@@ -617,7 +1324,8 @@ func runDomains(rt *app.Runtime, args []string) error {
 				flags := parseKVFlags(rest[2:])
 				types := splitCSV(flags["types"])
 				if !hasBoolFlag(rest[2:], "apply") {
-					return emitSuccess(rt, "domains notifications optin set", map[string]any{"dry_run": true, "would_set_notification_types": types})
+					plan := services.NewPlan("domains notifications optin set", "PUT", "/v2/customers/{customerId}/domains/notifications/optIn", map[string]any{"notificationTypes": types})
+					return emitSuccess(rt, "domains notifications optin set", map[string]any{"dry_run": true, "would_set_notification_types": types, "plan": plan})
 				}
 				res, err := svc.V2Apply(rt.Ctx, "PUT", path, map[string]any{"notificationTypes": types}, "")
 				if err != nil {
@@ -655,7 +1363,8 @@ func runDomains(rt *app.Runtime, args []string) error {
 				return err
 			}
 			if !hasBoolFlag(rest[2:], "apply") {
-				return emitSuccess(rt, "domains notifications ack", map[string]any{"dry_run": true, "would_acknowledge_notification_id": rest[1]})
+				plan := services.NewPlan("domains notifications ack", "POST", "/v2/customers/{customerId}/domains/notifications/"+rest[1]+"/acknowledge", map[string]any{})
+				return emitSuccess(rt, "domains notifications ack", map[string]any{"dry_run": true, "would_acknowledge_notification_id": rest[1], "plan": plan})
 			}
 			res, err := svc.V2Apply(rt.Ctx, "POST", path, map[string]any{}, "")
 			if err != nil {
@@ -664,12 +1373,20 @@ func runDomains(rt *app.Runtime, args []string) error {
 			}
 			return emitSuccess(rt, "domains notifications ack", res)
 		}
-		err := usageError("domains notifications <next|optin|schema|ack>")
+		err := usageError(usageOf("domains notifications"))
 		emitError(rt, "domains notifications", err)
 		return err
 	case "contacts":
+		if len(rest) == 2 && rest[0] == "get" {
+			res, err := svc.DomainContacts(rt.Ctx, rest[1])
+			if err != nil {
+				emitError(rt, "domains contacts get", err)
+				return err
+			}
+			return emitSuccess(rt, "domains contacts get", res)
+		}
 		if len(rest) < 2 || rest[0] != "set" {
-			err := usageError("domains contacts set <domain> --body-json '<json>' [--apply]")
+			err := usageError(usageOf("domains contacts"))
 			emitError(rt, "domains contacts", err)
 			return err
 		}
@@ -684,7 +1401,8 @@ func runDomains(rt *app.Runtime, args []string) error {
 			}
 		}
 		if !hasBoolFlag(rest[2:], "apply") {
-			return emitSuccess(rt, "domains contacts set", map[string]any{"dry_run": true, "domain": domain, "body": body})
+			plan := services.NewPlan("domains contacts set", "PATCH", "/v2/customers/{customerId}/domains/"+domain+"/contacts", body)
+			return emitSuccess(rt, "domains contacts set", map[string]any{"dry_run": true, "domain": domain, "body": body, "plan": plan})
 		}
 		path, err := svc.V2PathCustomer("/v2/customers/{customerId}/domains/" + domain + "/contacts")
 		if err != nil {
@@ -699,11 +1417,15 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains contacts set", res)
 	case "nameservers":
 		if len(rest) < 2 || rest[0] != "set" {
-			err := usageError("domains nameservers set <domain> --nameservers ns1,ns2 [--apply]")
+			err := usageError(usageOf("domains nameservers"))
 			emitError(rt, "domains nameservers", err)
 			return err
 		}
-		domain := rest[1]
+		domain, err := godaddy.NormalizeDomain(rest[1])
+		if err != nil {
+			emitError(rt, "domains nameservers set", err)
+			return err
+		}
 		flags := parseKVFlags(rest[2:])
 		ns := splitCSV(flags["nameservers"])
 		if len(ns) == 0 {
@@ -711,8 +1433,14 @@ func runDomains(rt *app.Runtime, args []string) error {
 			emitError(rt, "domains nameservers set", err)
 			return err
 		}
+		ns, err = services.NormalizeNameservers(ns)
+		if err != nil {
+			emitError(rt, "domains nameservers set", err)
+			return err
+		}
 		if !hasBoolFlag(rest[2:], "apply") {
-			return emitSuccess(rt, "domains nameservers set", map[string]any{"dry_run": true, "domain": domain, "nameservers": ns})
+			plan := services.NewPlan("domains nameservers set", "PUT", "/v2/customers/{customerId}/domains/"+domain+"/nameServers", map[string]any{"nameServers": ns})
+			return emitSuccess(rt, "domains nameservers set", map[string]any{"dry_run": true, "domain": domain, "nameservers": ns, "plan": plan})
 		}
 		apiVersion, err := svc.SetNameserversSmart(rt.Ctx, domain, ns)
 		if err != nil {
@@ -720,9 +1448,75 @@ func runDomains(rt *app.Runtime, args []string) error {
 			return err
 		}
 		return emitSuccess(rt, "domains nameservers set", map[string]any{"domain": domain, "nameservers": ns, "api_version": apiVersion, "applied": true})
+	case "lock":
+		if len(rest) < 2 || (rest[0] != "get" && rest[0] != "set") {
+			err := usageError(usageOf("domains lock"))
+			emitError(rt, "domains lock", err)
+			return err
+		}
+		command := "domains lock " + rest[0]
+		domain := rest[1]
+		if rest[0] == "get" {
+			res, err := svc.DomainLock(rt.Ctx, domain)
+			if err != nil {
+				emitError(rt, command, err)
+				return err
+			}
+			return emitSuccess(rt, command, res)
+		}
+		locked, err := strconv.ParseBool(strings.TrimSpace(parseKVFlags(rest[2:])["enabled"]))
+		if err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "--enabled must be true or false"}
+			emitError(rt, command, ae)
+			return ae
+		}
+		if !hasBoolFlag(rest[2:], "apply") {
+			plan := services.NewPlan(command, "PATCH", "/v2/customers/{customerId}/domains/"+domain, map[string]any{"locked": locked})
+			return emitSuccess(rt, command, map[string]any{"dry_run": true, "domain": domain, "locked": locked, "plan": plan})
+		}
+		res, err := svc.SetDomainLock(rt.Ctx, domain, locked)
+		if err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		return emitSuccess(rt, command, res)
+	case "records":
+		if len(rest) < 2 {
+			err := usageError(usageOf("domains records"))
+			emitError(rt, "domains records", err)
+			return err
+		}
+		action, domain := rest[0], rest[1]
+		command := "domains records " + action
+		flags := parseKVFlags(rest[2:])
+		rec, err := recordFromFlags(action, flags)
+		if err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		apply := hasBoolFlag(rest[2:], "apply") && !rt.DryRun
+		var res map[string]any
+		switch action {
+		case "list":
+			recs, listErr := svc.RecordsList(rt.Ctx, domain)
+			res, err = map[string]any{"domain": domain, "records": recs}, listErr
+		case "add":
+			res, err = svc.RecordsAdd(rt.Ctx, domain, rec, apply)
+		case "delete":
+			res, err = svc.RecordsDelete(rt.Ctx, domain, rec, apply)
+		case "replace":
+			res, err = svc.RecordsReplace(rt.Ctx, domain, rec, apply)
+		default:
+			err = usageError("unknown records action: " + action)
+		}
+		if err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		return emitSuccess(rt, command, res)
 	case "dnssec":
 		if len(rest) < 2 || rest[0] != "add" {
-			err := usageError("domains dnssec add <domain> --body-json '<json>' [--apply]")
+			err := usageError(usageOf("domains dnssec"))
 			emitError(rt, "domains dnssec", err)
 			return err
 		}
@@ -737,7 +1531,8 @@ func runDomains(rt *app.Runtime, args []string) error {
 			}
 		}
 		if !hasBoolFlag(rest[2:], "apply") {
-			return emitSuccess(rt, "domains dnssec add", map[string]any{"dry_run": true, "domain": domain, "body": body})
+			plan := services.NewPlan("domains dnssec add", "PATCH", "/v2/customers/{customerId}/domains/"+domain+"/dnssecRecords", body)
+			return emitSuccess(rt, "domains dnssec add", map[string]any{"dry_run": true, "domain": domain, "body": body, "plan": plan})
 		}
 		path, err := svc.V2PathCustomer("/v2/customers/{customerId}/domains/" + domain + "/dnssecRecords")
 		if err != nil {
@@ -752,7 +1547,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains dnssec add", res)
 	case "forwarding":
 		if len(rest) < 2 {
-			err := usageError("domains forwarding <get|create|update> <fqdn> [--body-json '<json>'] [--apply]")
+			err := usageError(usageOf("domains forwarding"))
 			emitError(rt, "domains forwarding", err)
 			return err
 		}
@@ -781,13 +1576,14 @@ func runDomains(rt *app.Runtime, args []string) error {
 					return ae
 				}
 			}
-			if !hasBoolFlag(rest[2:], "apply") {
-				return emitSuccess(rt, "domains forwarding "+action, map[string]any{"dry_run": true, "fqdn": fqdn, "body": body})
-			}
 			method := "POST"
 			if action == "update" {
 				method = "PUT"
 			}
+			if !hasBoolFlag(rest[2:], "apply") {
+				plan := services.NewPlan("domains forwarding "+action, method, "/v2/customers/{customerId}/domains/forwards/"+fqdn, body)
+				return emitSuccess(rt, "domains forwarding "+action, map[string]any{"dry_run": true, "fqdn": fqdn, "body": body, "plan": plan})
+			}
 			res, err := svc.V2Apply(rt.Ctx, method, path, body, "")
 			if err != nil {
 				emitError(rt, "domains forwarding "+action, err)
@@ -795,12 +1591,12 @@ func runDomains(rt *app.Runtime, args []string) error {
 			}
 			return emitSuccess(rt, "domains forwarding "+action, res)
 		}
-		err = usageError("domains forwarding <get|create|update> <fqdn>")
+		err = usageError(usageOf("domains forwarding"))
 		emitError(rt, "domains forwarding", err)
 		return err
 	case "privacy-forwarding":
 		if len(rest) < 2 {
-			err := usageError("domains privacy-forwarding <get|set> <domain> [--body-json '<json>'] [--apply]")
+			err := usageError(usageOf("domains privacy-forwarding"))
 			emitError(rt, "domains privacy-forwarding", err)
 			return err
 		}
@@ -830,7 +1626,8 @@ func runDomains(rt *app.Runtime, args []string) error {
 				}
 			}
 			if !hasBoolFlag(rest[2:], "apply") {
-				return emitSuccess(rt, "domains privacy-forwarding set", map[string]any{"dry_run": true, "domain": domain, "body": body})
+				plan := services.NewPlan("domains privacy-forwarding set", "PATCH", "/v2/customers/{customerId}/domains/"+domain+"/privacy/forwarding", body)
+				return emitSuccess(rt, "domains privacy-forwarding set", map[string]any{"dry_run": true, "domain": domain, "body": body, "plan": plan})
 			}
 			res, err := svc.V2Apply(rt.Ctx, "PATCH", path, body, "")
 			if err != nil {
@@ -839,12 +1636,12 @@ func runDomains(rt *app.Runtime, args []string) error {
 			}
 			return emitSuccess(rt, "domains privacy-forwarding set", res)
 		}
-		err = usageError("domains privacy-forwarding <get|set> <domain>")
+		err = usageError(usageOf("domains privacy-forwarding"))
 		emitError(rt, "domains privacy-forwarding", err)
 		return err
 	case "register":
 		if len(rest) == 0 {
-			err := usageError("domains register <schema|validate|purchase> ...")
+			err := usageError(usageOf("domains register"))
 			emitError(rt, "domains register", err)
 			return err
 		}
@@ -876,14 +1673,17 @@ func runDomains(rt *app.Runtime, args []string) error {
 					return ae
 				}
 			}
-			if !hasBoolFlag(rest[1:], "apply") {
-				return emitSuccess(rt, "domains register "+rest[0], map[string]any{"dry_run": true, "body": body})
-			}
 			suffix := "register/validate"
 			if rest[0] == "purchase" {
-				app.MaybeWarnProdFinancial(rt, "domains register purchase")
 				suffix = "register"
 			}
+			plan := services.NewPlan("domains register "+rest[0], "POST", "/v2/customers/{customerId}/domains/"+suffix, body)
+			if !hasBoolFlag(rest[1:], "apply") {
+				return emitSuccess(rt, "domains register "+rest[0], map[string]any{"dry_run": true, "body": body, "plan": plan})
+			}
+			if err := guardPlanSpend(rt, "domains register "+rest[0], plan); err != nil {
+				return err
+			}
 			path, err := svc.V2PathCustomer("/v2/customers/{customerId}/domains/" + suffix)
 			if err != nil {
 				emitError(rt, "domains register "+rest[0], err)
@@ -896,17 +1696,20 @@ func runDomains(rt *app.Runtime, args []string) error {
 			}
 			return emitSuccess(rt, "domains register "+rest[0], res)
 		}
-		err := usageError("domains register <schema|validate|purchase>")
+		err := usageError(usageOf("domains register"))
 		emitError(rt, "domains register", err)
 		return err
 	case "transfer":
 		if len(rest) < 2 {
-			err := usageError("domains transfer <status|validate|start|in-accept|in-cancel|in-restart|in-retry|out|out-accept|out-reject> <domain> [--body-json '<json>'] [--apply]")
+			err := usageError(usageOf("domains transfer"))
 			emitError(rt, "domains transfer", err)
 			return err
 		}
 		action := rest[0]
 		domain := rest[1]
+		if action == "watch" {
+			return runTransferWatch(rt, svc, domain, rest[2:])
+		}
 		flags := parseKVFlags(rest[2:])
 		suffix := map[string]string{
 			"status":     "transfer",
@@ -921,7 +1724,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 			"out-reject": "transferOutReject",
 		}[action]
 		if suffix == "" {
-			err := usageError("domains transfer <status|validate|start|in-accept|in-cancel|in-restart|in-retry|out|out-accept|out-reject> <domain>")
+			err := usageError(usageOf("domains transfer"))
 			emitError(rt, "domains transfer", err)
 			return err
 		}
@@ -946,10 +1749,19 @@ func runDomains(rt *app.Runtime, args []string) error {
 				return ae
 			}
 		}
+		plan := services.NewPlan("domains transfer "+action, "POST", "/v2/customers/{customerId}/domains/"+domain+"/"+suffix, body)
 		if !hasBoolFlag(rest[2:], "apply") {
-			return emitSuccess(rt, "domains transfer "+action, map[string]any{"dry_run": true, "domain": domain, "body": body})
+			return emitSuccess(rt, "domains transfer "+action, map[string]any{"dry_run": true, "domain": domain, "body": body, "plan": plan})
+		}
+		if !hasBoolFlag(rest[2:], "force") {
+			if err := svc.CheckTransferAction(rt.Ctx, domain, action); err != nil {
+				emitError(rt, "domains transfer "+action, err)
+				return err
+			}
+		}
+		if err := guardPlanSpend(rt, "domains transfer "+action, plan); err != nil {
+			return err
 		}
-		app.MaybeWarnProdFinancial(rt, "domains transfer "+action)
 		res, err := svc.V2Apply(rt.Ctx, "POST", path, body, "")
 		if err != nil {
 			emitError(rt, "domains transfer "+action, err)
@@ -958,7 +1770,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 		return emitSuccess(rt, "domains transfer "+action, res)
 	case "redeem":
 		if len(rest) < 1 {
-			err := usageError("domains redeem <domain> [--body-json '<json>'] [--apply]")
+			err := usageError(usageOf("domains redeem"))
 			emitError(rt, "domains redeem", err)
 			return err
 		}
@@ -972,10 +1784,13 @@ func runDomains(rt *app.Runtime, args []string) error {
 				return ae
 			}
 		}
+		plan := services.NewPlan("domains redeem", "POST", "/v2/customers/{customerId}/domains/"+domain+"/redeem", body)
 		if !hasBoolFlag(rest[1:], "apply") {
-			return emitSuccess(rt, "domains redeem", map[string]any{"dry_run": true, "domain": domain, "body": body})
+			return emitSuccess(rt, "domains redeem", map[string]any{"dry_run": true, "domain": domain, "body": body, "plan": plan})
+		}
+		if err := guardPlanSpend(rt, "domains redeem", plan); err != nil {
+			return err
 		}
-		app.MaybeWarnProdFinancial(rt, "domains redeem")
 		path, err := svc.V2PathCustomer("/v2/customers/{customerId}/domains/" + domain + "/redeem")
 		if err != nil {
 			emitError(rt, "domains redeem", err)
@@ -987,6 +1802,34 @@ func runDomains(rt *app.Runtime, args []string) error {
 			return err
 		}
 		return emitSuccess(rt, "domains redeem", res)
+	case "plan":
+		flags := parseKVFlags(rest)
+		if strings.TrimSpace(flags["plan-file"]) == "" {
+			err := usageError(usageOf("domains plan"))
+			emitError(rt, "domains plan", err)
+			return err
+		}
+		plan, err := services.LoadPlanFile(flags["plan-file"])
+		if err != nil {
+			var ae *apperr.AppError
+			if !apperr.As(err, &ae) {
+				ae = &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading plan file", Cause: err}
+			}
+			emitError(rt, "domains plan", ae)
+			return ae
+		}
+		if !hasBoolFlag(rest, "apply") {
+			return emitSuccess(rt, "domains plan", map[string]any{"dry_run": true, "plan": plan})
+		}
+		if err := guardPlanSpend(rt, "domains plan", plan); err != nil {
+			return err
+		}
+		res, err := svc.ApplyPlan(rt.Ctx, plan)
+		if err != nil {
+			emitError(rt, "domains plan", err)
+			return err
+		}
+		return emitSuccess(rt, "domains plan", map[string]any{"applied": true, "plan": plan, "result": res})
 	default:
 		err := usageError("unknown domains subcommand: " + sub)
 		emitError(rt, "domains", err)
@@ -997,7 +1840,7 @@ func runDomains(rt *app.Runtime, args []string) error {
 func runDNS(rt *app.Runtime, args []string) error {
 	if len(args) == 0 || isHelpToken(args[0]) {
 		return emitSuccess(rt, "dns help", map[string]any{
-			"subcommands": []string{"audit", "apply"},
+			"subcommands": []string{"audit", "apply", "export"},
 		})
 	}
 	if len(args) == 0 {
@@ -1015,45 +1858,93 @@ func runDNS(rt *app.Runtime, args []string) error {
 	flags := parseKVFlags(rest)
 	switch sub {
 	case "audit":
-		file := flags["domains"]
-		if file == "" {
-			err := usageError("dns audit --domains <file>")
-			emitError(rt, "dns audit", err)
+		domains, err := bulkDomains(rt, "dns audit", flags["domains"], flags)
+		if err != nil {
 			return err
 		}
-		domains, err := services.LoadDomainFile(file)
+		if err := checkBulkItems(rt, "dns audit", len(domains), flags); err != nil {
+			return err
+		}
+		concurrency, err := concurrencyFlag(rt, "dns audit", flags, "concurrency", 5)
 		if err != nil {
-			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading domain list", Cause: err}
-			emitError(rt, "dns audit", ae)
-			return ae
+			return err
 		}
-		res, err := svc.DNSAudit(rt.Ctx, domains)
+		if path := strings.TrimSpace(flags["rules"]); path != "" {
+			if flags["checks"] != "" {
+				err := usageError("dns audit: use either --rules or --checks, not both")
+				emitError(rt, "dns audit", err)
+				return err
+			}
+			rules, err := services.LoadDNSAuditRules(path)
+			if err != nil {
+				emitError(rt, "dns audit", err)
+				return err
+			}
+			res, err := svc.DNSAuditWithRules(rt.Ctx, domains, rules, concurrency)
+			if res == nil {
+				emitError(rt, "dns audit", err)
+				return err
+			}
+			return emitRows(rt, "dns audit", res, err)
+		}
+		checks, err := services.ParseDNSAuditChecks(splitCSV(flags["checks"]))
 		if err != nil {
 			emitError(rt, "dns audit", err)
 			return err
 		}
-		return emitSuccess(rt, "dns audit", res)
+		res, err := svc.DNSAudit(rt.Ctx, domains, checks, concurrency)
+		if res == nil {
+			emitError(rt, "dns audit", err)
+			return err
+		}
+		return emitRows(rt, "dns audit", res, err)
 	case "apply":
-		file := flags["domains"]
 		tmpl := flags["template"]
-		dryRun := hasBoolFlag(rest, "dry-run")
-		if file == "" || tmpl == "" {
-			err := usageError("dns apply --template <t> --domains <file>")
+		dryRun := rt.DryRun || hasBoolFlag(rest, "dry-run")
+		if tmpl == "" {
+			err := usageError(usageOf("dns apply"))
 			emitError(rt, "dns apply", err)
 			return err
 		}
-		domains, err := services.LoadDomainFile(file)
+		domains, err := bulkDomains(rt, "dns apply", flags["domains"], flags)
 		if err != nil {
-			ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading domain list", Cause: err}
-			emitError(rt, "dns apply", ae)
-			return ae
+			return err
+		}
+		if err := checkBulkItems(rt, "dns apply", len(domains), flags); err != nil {
+			return err
 		}
-		res, err := svc.DNSApplyTemplate(rt.Ctx, tmpl, domains, dryRun)
+		vars, err := parseTemplateVars(flagValues(rest, "var"))
 		if err != nil {
 			emitError(rt, "dns apply", err)
 			return err
 		}
-		return emitSuccess(rt, "dns apply", res)
+		onlyChanged := hasBoolFlag(rest, "only-changed")
+		concurrency, err := concurrencyFlag(rt, "dns apply", flags, "concurrency", 5)
+		if err != nil {
+			return err
+		}
+		res, err := svc.DNSApplyTemplate(rt.Ctx, tmpl, domains, vars, dryRun, onlyChanged, concurrency)
+		if res == nil {
+			emitError(rt, "dns apply", err)
+			return err
+		}
+		if onlyChanged && !rt.NDJSON {
+			changed, unchanged, failed := 0, 0, 0
+			for _, row := range res {
+				switch {
+				case row["error"] != nil:
+					failed++
+				case row["unchanged"] == true:
+					unchanged++
+				default:
+					changed++
+				}
+			}
+			return emitRows(rt, "dns apply", map[string]any{"results": res, "changed": changed, "unchanged": unchanged, "failed": failed, "total": len(res)}, err)
+		}
+		return emitRows(rt, "dns apply", res, err)
+	case "export":
+		return runDNSExport(rt, svc, rest, flags)
 	default:
 		err := usageError("unknown dns subcommand: " + sub)
 		emitError(rt, "dns", err)
@@ -1061,29 +1952,83 @@ func runDNS(rt *app.Runtime, args []string) error {
 	}
 }
 
+// runDNSExport writes a domain's current DNS as a template for dns apply. With --out - the
+// bare template goes to stdout for piping; otherwise the file is written and a summary emitted.
+func runDNSExport(rt *app.Runtime, svc *services.Service, rest []string, flags map[string]string) error {
+	out := strings.TrimSpace(flags["out"])
+	if len(rest) == 0 || strings.HasPrefix(rest[0], "--") || out == "" {
+		err := usageError(usageOf("dns export"))
+		emitError(rt, "dns export", err)
+		return err
+	}
+	if out != "-" && !strings.HasSuffix(strings.ToLower(out), ".json") {
+		err := &apperr.AppError{Code: apperr.CodeValidation, Message: "--out must end in .json so dns apply --template can read it back", Details: map[string]any{"out": out}}
+		emitError(rt, "dns export", err)
+		return err
+	}
+	domain := rest[0]
+	tmpl, err := svc.DNSExport(rt.Ctx, domain)
+	if err != nil {
+		emitError(rt, "dns export", err)
+		return err
+	}
+	b, err := json.MarshalIndent(tmpl, "", "  ")
+	if err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed encoding template", Cause: err}
+		emitError(rt, "dns export", ae)
+		return ae
+	}
+	b = append(b, '\n')
+	if out == "-" {
+		_, err := rt.Out.Out.Write(b)
+		return err
+	}
+	if err := os.WriteFile(filepath.Clean(out), b, 0o600); err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed writing template file", Details: map[string]any{"out": out}, Cause: err}
+		emitError(rt, "dns export", ae)
+		return ae
+	}
+	return emitSuccess(rt, "dns export", map[string]any{
+		"domain":      domain,
+		"out":         out,
+		"nameservers": len(tmpl.NameServers),
+		"records":     len(tmpl.Records),
+		"restore":     "gdcli dns apply --template " + out + " --domains <file>",
+	})
+}
+
 func runAccount(rt *app.Runtime, args []string) error {
 	if len(args) == 0 || isHelpToken(args[0]) {
 		return emitSuccess(rt, "account help", map[string]any{
-			"subcommands": []string{"orders list", "subscriptions list", "identity show", "identity set", "identity resolve"},
+			"subcommands": []string{"orders list", "subscriptions list", "subscriptions get", "subscriptions set-auto-renew", "operations", "operations resolve", "whoami", "identity show", "identity set", "identity resolve", "identity whoami"},
 		})
 	}
 	if args[0] == "identity" {
 		return runAccountIdentity(rt, args[1:])
 	}
+	if args[0] == "whoami" {
+		return runAccountWhoami(rt)
+	}
+	if args[0] == "operations" {
+		return runAccountOperations(rt, args[1:])
+	}
 	svc, err := newService(rt)
 	if err != nil {
 		emitError(rt, "account", err)
 		return err
 	}
 	if len(args) < 2 {
-		err := usageError("account <orders|subscriptions> list [--limit N] [--offset N]")
+		err := usageError(usageOf("account orders"))
 		emitError(rt, "account", err)
 		return err
 	}
 	group := args[0]
 	action := args[1]
+	if group == "subscriptions" && action != "list" {
+		return runAccountSubscription(rt, svc, action, args[2:])
+	}
 	if action != "list" {
-		err := usageError("account <orders|subscriptions> list [--limit N] [--offset N]")
+		err := usageError(usageOf("account orders"))
 		emitError(rt, "account", err)
 		return err
 	}
@@ -1102,9 +2047,26 @@ func runAccount(rt *app.Runtime, args []string) error {
 		return err
 	}
 
+	var filter services.OrderFilter
+	if group == "orders" {
+		filter, err = services.ParseOrderFilter(flags["since"], flags["min-total"], flags["sort"])
+		if err != nil {
+			emitError(rt, "account orders list", err)
+			return err
+		}
+	} else if flags["since"] != "" || flags["min-total"] != "" || flags["sort"] != "" {
+		err := usageError("--since, --min-total and --sort only apply to account orders list")
+		emitError(rt, "account "+group+" list", err)
+		return err
+	}
+
+	if hasBoolFlag(args[2:], "all") {
+		return runAccountListAll(rt, svc, group, limit, offset, parseIntDefault(flags["max-pages"], 100), filter)
+	}
+
 	switch group {
 	case "orders":
-		res, err := svc.OrdersList(rt.Ctx, limit, offset)
+		res, err := svc.OrdersList(rt.Ctx, limit, offset, filter)
 		if err != nil {
 			emitError(rt, "account orders list", err)
 			return err
@@ -1112,16 +2074,7 @@ func runAccount(rt *app.Runtime, args []string) error {
 		if rt.NDJSON {
 			orders, _ := res["orders"].([]godaddy.Order)
 			pg, _ := res["pagination"].(godaddy.Pagination)
-			rows := make([]any, 0, len(orders))
-			for i, order := range orders {
-				rows = append(rows, map[string]any{
-					"index":        i,
-					"success":      true,
-					"result":       order,
-					"page_context": map[string]any{"limit": pg.Limit, "offset": pg.Offset, "total": pg.Total},
-				})
-			}
-			return emitSuccess(rt, "account orders list", rows)
+			return emitSuccess(rt, "account orders list", pageRows(orders, pg, 0))
 		}
 		return emitSuccess(rt, "account orders list", res)
 	case "subscriptions":
@@ -1133,32 +2086,182 @@ func runAccount(rt *app.Runtime, args []string) error {
 		if rt.NDJSON {
 			subs, _ := res["subscriptions"].([]godaddy.Subscription)
 			pg, _ := res["pagination"].(godaddy.Pagination)
-			rows := make([]any, 0, len(subs))
-			for i, sub := range subs {
-				rows = append(rows, map[string]any{
-					"index":        i,
-					"success":      true,
-					"result":       sub,
-					"page_context": map[string]any{"limit": pg.Limit, "offset": pg.Offset, "total": pg.Total},
-				})
-			}
-			return emitSuccess(rt, "account subscriptions list", rows)
+			return emitSuccess(rt, "account subscriptions list", pageRows(subs, pg, 0))
 		}
 		return emitSuccess(rt, "account subscriptions list", res)
 	default:
-		err := usageError("account <orders|subscriptions> list [--limit N] [--offset N]")
+		err := usageError(usageOf("account orders"))
 		emitError(rt, "account", err)
 		return err
 	}
 }
 
+// runAccountOperations lists the local operations log (purchases, renewals) newest first.
+// It reads only local state, so it works without credentials.
+func runAccountOperations(rt *app.Runtime, args []string) error {
+	const command = "account operations"
+	if len(args) > 0 && args[0] == "resolve" {
+		return runAccountOperationsResolve(rt, args[1:])
+	}
+	flags := parseKVFlags(args)
+	since, err := services.ParseSince(flags["since"])
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	ops, err := store.ReadOperations()
+	if err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed reading operations log", Cause: err}
+		emitError(rt, command, ae)
+		return ae
+	}
+	filtered := services.FilterOperations(ops, services.OperationFilter{
+		Status: strings.TrimSpace(flags["status"]),
+		Type:   strings.TrimSpace(flags["type"]),
+		Domain: strings.TrimSpace(flags["domain"]),
+		Since:  since,
+	})
+	if rt.NDJSON || rt.CSV {
+		rows := make([]any, 0, len(filtered))
+		for _, op := range filtered {
+			rows = append(rows, op)
+		}
+		return emitSuccess(rt, command, rows)
+	}
+	return emitSuccess(rt, command, map[string]any{"operations": filtered, "count": len(filtered), "total": len(ops)})
+}
+
+// runAccountOperationsResolve settles a pending operation after checking account orders. Like
+// the listing it only touches the local log, so the service is built without a client.
+func runAccountOperationsResolve(rt *app.Runtime, args []string) error {
+	const command = "account operations resolve"
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		err := usageError(usageOf(command))
+		emitError(rt, command, err)
+		return err
+	}
+	flags := parseKVFlags(args[1:])
+	op, err := services.New(rt, nil).ResolveOperation(args[0], strings.TrimSpace(flags["status"]), strings.TrimSpace(flags["order-id"]))
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	return emitSuccess(rt, command, op)
+}
+
+// runAccountSubscription handles the single-subscription actions: get and set-auto-renew.
+func runAccountSubscription(rt *app.Runtime, svc *services.Service, action string, args []string) error {
+	command := "account subscriptions " + action
+	if (action != "get" && action != "set-auto-renew") || len(args) == 0 {
+		err := usageError("account subscriptions <get|set-auto-renew> <subscription-id> [--enabled true|false] [--apply]")
+		emitError(rt, "account subscriptions", err)
+		return err
+	}
+	id := args[0]
+	if action == "get" {
+		res, err := svc.Subscription(rt.Ctx, id)
+		if err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		return emitSuccess(rt, command, res)
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(parseKVFlags(args[1:])["enabled"]))
+	if err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "--enabled must be true or false"}
+		emitError(rt, command, ae)
+		return ae
+	}
+	app.MaybeWarnProdFinancial(rt, command)
+	if !hasBoolFlag(args[1:], "apply") {
+		plan := services.NewPlan(command, "PATCH", "/v1/subscriptions/"+id, map[string]any{"renewAuto": enabled})
+		return emitSuccess(rt, command, map[string]any{"dry_run": true, "subscription_id": id, "renew_auto": enabled, "plan": plan})
+	}
+	res, err := svc.SetSubscriptionAutoRenew(rt.Ctx, id, enabled)
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	return emitSuccess(rt, command, res)
+}
+
+// pageRows wraps one page of items as NDJSON rows; start is the index of the first item.
+func pageRows[T any](items []T, pg godaddy.Pagination, start int) []any {
+	rows := make([]any, 0, len(items))
+	for i, item := range items {
+		rows = append(rows, map[string]any{
+			"index":        start + i,
+			"success":      true,
+			"result":       item,
+			"page_context": map[string]any{"limit": pg.Limit, "offset": pg.Offset, "total": pg.Total},
+		})
+	}
+	return rows
+}
+
+// runAccountListAll follows pagination for --all. NDJSON streams each page as it arrives;
+// JSON returns one combined page. A --sort on orders needs every page before the first row,
+// so it disables streaming.
+func runAccountListAll(rt *app.Runtime, svc *services.Service, group string, limit, offset, maxPages int, filter services.OrderFilter) error {
+	command := "account " + group + " list"
+	streamed := 0
+	var res map[string]any
+	var err error
+	switch group {
+	case "orders":
+		var onPage func([]godaddy.Order, godaddy.Pagination) error
+		if rt.NDJSON && filter.SortBy == "" {
+			onPage = func(items []godaddy.Order, pg godaddy.Pagination) error {
+				rows := pageRows(items, pg, streamed)
+				streamed += len(items)
+				return emitSuccess(rt, command, rows)
+			}
+		}
+		res, err = svc.OrdersListAll(rt.Ctx, limit, offset, maxPages, filter, onPage)
+		if err == nil && rt.NDJSON && onPage == nil {
+			orders, _ := res["orders"].([]godaddy.Order)
+			pg := godaddy.Pagination{Limit: limit, Offset: offset, Total: len(orders)}
+			if emitErr := emitSuccess(rt, command, pageRows(orders, pg, 0)); emitErr != nil {
+				return emitErr
+			}
+		}
+	case "subscriptions":
+		var onPage func([]godaddy.Subscription, godaddy.Pagination) error
+		if rt.NDJSON {
+			onPage = func(items []godaddy.Subscription, pg godaddy.Pagination) error {
+				rows := pageRows(items, pg, streamed)
+				streamed += len(items)
+				return emitSuccess(rt, command, rows)
+			}
+		}
+		res, err = svc.SubscriptionsListAll(rt.Ctx, limit, offset, maxPages, onPage)
+	default:
+		err := usageError(usageOf("account orders"))
+		emitError(rt, "account", err)
+		return err
+	}
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	if truncated, _ := res["truncated"].(bool); truncated && !rt.Quiet {
+		output.LogErr(rt.ErrOut, "warning: stopped after %d pages (--max-pages); results are incomplete", res["pages"])
+	}
+	if rt.NDJSON {
+		return nil
+	}
+	return emitSuccess(rt, command, res)
+}
+
 func runAccountIdentity(rt *app.Runtime, args []string) error {
 	if len(args) == 0 || isHelpToken(args[0]) {
 		return emitSuccess(rt, "account identity help", map[string]any{
-			"subcommands": []string{"show", "set", "resolve"},
+			"subcommands": []string{"show", "set", "resolve", "whoami"},
 		})
 	}
 	switch args[0] {
+	case "whoami":
+		return runAccountWhoami(rt)
 	case "show":
 		svc, err := newService(rt)
 		if err != nil {
@@ -1221,16 +2324,30 @@ func runAccountIdentity(rt *app.Runtime, args []string) error {
 			"customer_id_resolved_at": rt.Cfg.CustomerIDResolved,
 		})
 	default:
-		err := usageError("account identity <show|set|resolve>")
+		err := usageError(usageOf("account identity"))
 		emitError(rt, "account identity", err)
 		return err
 	}
 }
 
+func runAccountWhoami(rt *app.Runtime) error {
+	svc, err := newService(rt)
+	if err != nil {
+		emitError(rt, "account whoami", err)
+		return err
+	}
+	res, err := svc.Whoami(rt.Ctx)
+	if err != nil {
+		emitError(rt, "account whoami", err)
+		return err
+	}
+	return emitSuccess(rt, "account whoami", res)
+}
+
 func runSettings(rt *app.Runtime, args []string) error {
 	if len(args) == 0 || isHelpToken(args[0]) {
 		return emitSuccess(rt, "settings help", map[string]any{
-			"subcommands": []string{"auto-purchase enable", "auto-purchase disable", "caps set", "show"},
+			"subcommands": []string{"auto-purchase enable", "auto-purchase disable", "caps set", "show", "budget", "reset --confirm [--all]", "prune-operations --older-than 90d [--dry-run]", "profile list", "profile use", "profile add", "profile remove", "tokens list", "tokens revoke"},
 		})
 	}
 	if len(args) == 0 {
@@ -1241,7 +2358,7 @@ func runSettings(rt *app.Runtime, args []string) error {
 	switch args[0] {
 	case "auto-purchase":
 		if len(args) < 2 {
-			err := usageError("settings auto-purchase <enable|disable>")
+			err := usageError(usageOf("settings auto-purchase"))
 			emitError(rt, "settings auto-purchase", err)
 			return err
 		}
@@ -1272,51 +2389,112 @@ func runSettings(rt *app.Runtime, args []string) error {
 			}
 			return emitSuccess(rt, "settings auto-purchase disable", map[string]any{"auto_purchase_enabled": false})
 		default:
-			err := usageError("settings auto-purchase <enable|disable>")
+			err := usageError(usageOf("settings auto-purchase"))
 			emitError(rt, "settings auto-purchase", err)
 			return err
 		}
 	case "caps":
 		if len(args) < 2 || args[1] != "set" {
-			err := usageError("settings caps set --max-price <usd> --max-daily-spend <usd> --max-domains-per-day <n>")
+			err := usageError(usageOf("settings caps"))
 			emitError(rt, "settings caps", err)
 			return err
 		}
 		flags := parseKVFlags(args[2:])
-		maxPrice := parseFloatDefault(flags["max-price"], -1)
-		maxDaily := parseFloatDefault(flags["max-daily-spend"], -1)
-		maxDomains := parseIntDefault(flags["max-domains-per-day"], -1)
+		maxPrice := parseFloatDefault(flags["max-price"], rt.Cfg.MaxPricePerDomain)
+		maxDaily := parseFloatDefault(flags["max-daily-spend"], rt.Cfg.MaxDailySpend)
+		maxDomains := parseIntDefault(flags["max-domains-per-day"], rt.Cfg.MaxDomainsPerDay)
 		if maxPrice <= 0 || maxDaily <= 0 || maxDomains <= 0 {
 			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "cap values must be positive"}
 			emitError(rt, "settings caps set", err)
 			return err
 		}
+		tldCaps, err := parseTLDCaps(rt.Cfg.MaxPricePerTLD, flagValues(args[2:], "max-price-tld"))
+		if err != nil {
+			emitError(rt, "settings caps set", err)
+			return err
+		}
+		maxMonthly := rt.Cfg.MaxMonthlySpend
+		if v := strings.TrimSpace(flags["max-monthly-spend"]); v != "" {
+			maxMonthly = parseFloatDefault(v, -1)
+			if maxMonthly < 0 {
+				err := &apperr.AppError{Code: apperr.CodeValidation, Message: "max-monthly-spend must be >= 0 (0 disables the monthly cap)"}
+				emitError(rt, "settings caps set", err)
+				return err
+			}
+		}
+		tokenTTL := rt.Cfg.ConfirmTokenTTLMinutes
+		if v := strings.TrimSpace(flags["confirm-token-ttl-minutes"]); v != "" {
+			tokenTTL = parseIntDefault(v, -1)
+			if err := safety.CheckTokenTTLMinutes(tokenTTL); err != nil {
+				emitError(rt, "settings caps set", err)
+				return err
+			}
+		}
 		rt.Cfg.MaxPricePerDomain = maxPrice
+		rt.Cfg.MaxPricePerTLD = tldCaps
 		rt.Cfg.MaxDailySpend = maxDaily
 		rt.Cfg.MaxDomainsPerDay = maxDomains
+		rt.Cfg.MaxMonthlySpend = maxMonthly
+		rt.Cfg.ConfirmTokenTTLMinutes = tokenTTL
 		if err := config.Save(rt.Cfg); err != nil {
 			ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed saving config", Cause: err}
 			emitError(rt, "settings caps set", ae)
 			return ae
 		}
-		return emitSuccess(rt, "settings caps set", map[string]any{"max_price_per_domain": maxPrice, "max_daily_spend": maxDaily, "max_domains_per_day": maxDomains})
+		return emitSuccess(rt, "settings caps set", map[string]any{"max_price_per_domain": maxPrice, "max_price_per_tld": tldCaps, "max_daily_spend": maxDaily, "max_domains_per_day": maxDomains, "max_monthly_spend": maxMonthly, "confirm_token_ttl_minutes": tokenTTL})
 	case "show":
-		redacted := map[string]any{
-			"api_environment":             rt.Cfg.APIEnvironment,
-			"shopper_id":                  rt.Cfg.ShopperID,
-			"customer_id":                 rt.Cfg.CustomerID,
-			"customer_id_resolved_at":     rt.Cfg.CustomerIDResolved,
-			"customer_id_source":          rt.Cfg.CustomerIDSource,
-			"auto_purchase_enabled":       rt.Cfg.AutoPurchaseEnabled,
-			"acknowledgment_hash_present": rt.Cfg.AcknowledgmentHash != "",
-			"max_price_per_domain":        rt.Cfg.MaxPricePerDomain,
-			"max_daily_spend":             rt.Cfg.MaxDailySpend,
-			"max_domains_per_day":         rt.Cfg.MaxDomainsPerDay,
-			"default_years":               rt.Cfg.DefaultYears,
-			"default_dns_template":        rt.Cfg.DefaultDNSTemplate,
-			"output_default":              rt.Cfg.OutputDefault,
-		}
-		return emitSuccess(rt, "settings show", redacted)
+		return emitSuccess(rt, "settings show", settingsView(rt))
+	case "budget":
+		report, err := budget.BuildReport(rt.Cfg, time.Now())
+		if err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed reading operations log", Cause: err}
+			emitError(rt, "settings budget", ae)
+			return ae
+		}
+		return emitSuccess(rt, "settings budget", report)
+	case "reset":
+		if !hasBoolFlag(args[1:], "confirm") {
+			err := &apperr.AppError{Code: apperr.CodeConfirmation, Message: "settings reset clears caps and settings; re-run with --confirm", Details: map[string]any{"preserves_identity": !hasBoolFlag(args[1:], "all")}}
+			emitError(rt, "settings reset", err)
+			return err
+		}
+		rt.Cfg.Reset(!hasBoolFlag(args[1:], "all"))
+		if err := config.Save(rt.Cfg); err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed saving config", Cause: err}
+			emitError(rt, "settings reset", ae)
+			return ae
+		}
+		return emitSuccess(rt, "settings reset", settingsView(rt))
+	case "prune-operations":
+		flags := parseKVFlags(args[1:])
+		if strings.TrimSpace(flags["older-than"]) == "" {
+			err := usageError(usageOf("settings prune-operations"))
+			emitError(rt, "settings prune-operations", err)
+			return err
+		}
+		age, err := parseAgeFlag("--older-than", flags["older-than"], 0)
+		if err != nil {
+			emitError(rt, "settings prune-operations", err)
+			return err
+		}
+		now := time.Now()
+		cutoff := now.Add(-age)
+		// Caps read this month's operations, so never prune into the current month.
+		if monthStart, _ := budget.MonthBounds(now); cutoff.After(monthStart) {
+			cutoff = monthStart
+		}
+		dryRun := rt.DryRun || hasBoolFlag(args[1:], "dry-run")
+		removed, err := store.CompactOperations(cutoff, dryRun)
+		if err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed compacting operations log", Cause: err}
+			emitError(rt, "settings prune-operations", ae)
+			return ae
+		}
+		return emitSuccess(rt, "settings prune-operations", map[string]any{"removed": removed, "cutoff": cutoff.UTC().Format(time.RFC3339), "dry_run": dryRun})
+	case "profile":
+		return runSettingsProfile(rt, args[1:])
+	case "tokens":
+		return runSettingsTokens(rt, args[1:])
 	default:
 		err := usageError("unknown settings subcommand: " + args[0])
 		emitError(rt, "settings", err)
@@ -1324,6 +2502,214 @@ func runSettings(rt *app.Runtime, args []string) error {
 	}
 }
 
+// settingsView is the redacted config shown by settings show and settings reset.
+func settingsView(rt *app.Runtime) map[string]any {
+	redacted := map[string]any{
+		"api_environment":             rt.APIEnvironment(),
+		"shopper_id":                  rt.Cfg.ShopperID,
+		"customer_id":                 rt.Cfg.CustomerID,
+		"customer_id_resolved_at":     rt.Cfg.CustomerIDResolved,
+		"customer_id_source":          rt.Cfg.CustomerIDSource,
+		"auto_purchase_enabled":       rt.Cfg.AutoPurchaseEnabled,
+		"acknowledgment_hash_present": rt.Cfg.AcknowledgmentHash != "",
+		"auto_require_definitive":     rt.Cfg.AutoRequireDefinitive,
+		"require_ote_first":           rt.Cfg.RequireOTEFirst,
+		"max_price_per_domain":        rt.Cfg.MaxPricePerDomain,
+		"max_price_per_tld":           rt.Cfg.MaxPricePerTLD,
+		"max_daily_spend":             rt.Cfg.MaxDailySpend,
+		"max_domains_per_day":         rt.Cfg.MaxDomainsPerDay,
+		"max_monthly_spend":           rt.Cfg.MaxMonthlySpend,
+		"base_currency":               rt.Cfg.BaseCurrency,
+		"currency_rates":              rt.Cfg.CurrencyRates,
+		"confirm_token_ttl_minutes":   rt.Cfg.ConfirmTokenTTLMinutes,
+		"min_plausible_price":         rt.Cfg.MinPlausiblePrice,
+		"default_years":               rt.Cfg.DefaultYears,
+		"default_dns_template":        rt.Cfg.DefaultDNSTemplate,
+		"output_default":              rt.Cfg.OutputDefault,
+		"update_notice_stream":        rt.Cfg.UpdateNoticeStream,
+		"update_channel":              updateChannel(rt),
+		"max_bulk_items":              rt.Cfg.MaxBulkItems,
+		"max_concurrency":             rt.Cfg.MaxConcurrency,
+		"retry_attempts":              rt.Cfg.RetryAttempts,
+		"retry_base_ms":               rt.Cfg.RetryBaseMs,
+		"http_timeout_seconds":        rt.Cfg.HTTPTimeoutSeconds,
+	}
+	if configPath, err := config.Path(); err == nil {
+		redacted["config_path"] = configPath
+	}
+	redacted["profile"] = rt.Cfg.Profile()
+	return redacted
+}
+
+// runSettingsTokens lists and revokes pending purchase confirmation tokens. Token IDs are
+// shortened to their first 8 characters unless --full is passed.
+func runSettingsTokens(rt *app.Runtime, args []string) error {
+	if len(args) == 0 || isHelpToken(args[0]) {
+		return emitSuccess(rt, "settings tokens help", map[string]any{
+			"subcommands": []string{"list [--full]", "revoke <token-id|--all> [--full]"},
+		})
+	}
+	command := "settings tokens " + args[0]
+	full := hasBoolFlag(args[1:], "full")
+	view := func(tokens []store.ConfirmToken) []map[string]any {
+		out := make([]map[string]any, 0, len(tokens))
+		for _, t := range tokens {
+			id := t.TokenID
+			if !full && len(id) > 8 {
+				id = id[:8]
+			}
+			action := t.Action
+			if action == "" {
+				action = safety.ActionPurchase
+			}
+			out = append(out, map[string]any{
+				"token_id":     id,
+				"action":       action,
+				"domain":       t.Domain,
+				"quoted_price": t.QuotedPrice,
+				"currency":     t.Currency,
+				"issued_at":    t.IssuedAt,
+				"expires_at":   t.ExpiresAt,
+			})
+		}
+		return out
+	}
+	switch args[0] {
+	case "list":
+		tokens, err := safety.ActiveTokens(time.Now())
+		if err != nil {
+			ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed reading confirmation tokens", Cause: err}
+			emitError(rt, command, ae)
+			return ae
+		}
+		return emitSuccess(rt, command, map[string]any{"tokens": view(tokens), "count": len(tokens)})
+	case "revoke":
+		all := hasBoolFlag(args[1:], "all")
+		tokenID := ""
+		if len(args) > 1 && !strings.HasPrefix(args[1], "--") {
+			tokenID = strings.TrimSpace(args[1])
+		}
+		if all == (tokenID != "") {
+			err := usageError("settings tokens revoke <token-id|--all> [--full]")
+			emitError(rt, command, err)
+			return err
+		}
+		revoked, err := safety.RevokeTokens(tokenID, all, time.Now())
+		if err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		return emitSuccess(rt, command, map[string]any{"revoked": view(revoked), "count": len(revoked)})
+	default:
+		err := usageError("unknown settings tokens subcommand: " + args[0])
+		emitError(rt, "settings tokens", err)
+		return err
+	}
+}
+
+func runSettingsProfile(rt *app.Runtime, args []string) error {
+	if len(args) == 0 || isHelpToken(args[0]) {
+		return emitSuccess(rt, "settings profile help", map[string]any{
+			"subcommands": []string{"list", "use <name>", "add <name> [--api-environment prod|ote]", "remove <name>"},
+		})
+	}
+	command := "settings profile " + args[0]
+	root, err := config.LoadRoot()
+	if err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed loading config", Cause: err}
+		emitError(rt, command, ae)
+		return ae
+	}
+	activeName := root.ActiveProfile
+	if activeName == "" {
+		activeName = config.DefaultProfile
+	}
+	name := ""
+	if len(args) > 1 {
+		name = strings.TrimSpace(args[1])
+	}
+	if args[0] != "list" && name == "" {
+		err := usageError(command + " <name>")
+		emitError(rt, command, err)
+		return err
+	}
+	_, exists := root.Profiles[name]
+	exists = exists || name == config.DefaultProfile
+
+	switch args[0] {
+	case "list":
+		rows := make([]any, 0, len(root.Profiles)+1)
+		for _, n := range root.ProfileNames() {
+			env := root.APIEnvironment
+			if p, ok := root.Profiles[n]; ok {
+				env = p.APIEnvironment
+			}
+			rows = append(rows, map[string]any{"name": n, "active": n == activeName, "api_environment": env})
+		}
+		return emitSuccess(rt, command, map[string]any{"profiles": rows, "active": activeName, "current": rt.Cfg.Profile()})
+	case "use":
+		if !exists {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "unknown profile", Details: map[string]any{"profile": name}}
+			emitError(rt, command, err)
+			return err
+		}
+		root.ActiveProfile = name
+		if name == config.DefaultProfile {
+			root.ActiveProfile = ""
+		}
+	case "add":
+		if !config.ValidProfileName(name) {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "profile names use lowercase letters, digits, '-' and '_' (max 32)", Details: map[string]any{"profile": name}}
+			emitError(rt, command, err)
+			return err
+		}
+		if exists {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "profile already exists", Details: map[string]any{"profile": name}}
+			emitError(rt, command, err)
+			return err
+		}
+		p := *config.Default()
+		if env := strings.TrimSpace(parseKVFlags(args[2:])["api-environment"]); env != "" {
+			if env != "prod" && env != "ote" {
+				err := &apperr.AppError{Code: apperr.CodeValidation, Message: "api-environment must be prod or ote"}
+				emitError(rt, command, err)
+				return err
+			}
+			p.APIEnvironment = env
+		}
+		if root.Profiles == nil {
+			root.Profiles = map[string]config.Config{}
+		}
+		root.Profiles[name] = p
+	case "remove":
+		if name == config.DefaultProfile || !exists {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "only existing named profiles can be removed", Details: map[string]any{"profile": name}}
+			emitError(rt, command, err)
+			return err
+		}
+		if name == activeName {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "cannot remove the active profile; switch with settings profile use first", Details: map[string]any{"profile": name}}
+			emitError(rt, command, err)
+			return err
+		}
+		delete(root.Profiles, name)
+	default:
+		err := usageError("unknown settings profile subcommand: " + args[0])
+		emitError(rt, "settings profile", err)
+		return err
+	}
+	if err := config.Save(root); err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeInternal, Message: "failed saving config", Cause: err}
+		emitError(rt, command, ae)
+		return ae
+	}
+	active := root.ActiveProfile
+	if active == "" {
+		active = config.DefaultProfile
+	}
+	return emitSuccess(rt, command, map[string]any{"profile": name, "active": active, "profiles": root.ProfileNames()})
+}
+
 func parseKVFlags(args []string) map[string]string {
 	out := map[string]string{}
 	for i := 0; i < len(args); i++ {
@@ -1347,6 +2733,62 @@ func parseKVFlags(args []string) map[string]string {
 	return out
 }
 
+// flagValues collects every value of a repeatable flag given as --name v or --name=v.
+func flagValues(args []string, name string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		if v, ok := strings.CutPrefix(args[i], "--"+name+"="); ok {
+			out = append(out, v)
+			continue
+		}
+		if args[i] == "--"+name && i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			out = append(out, args[i+1])
+			i++
+		}
+	}
+	return out
+}
+
+// parseTLDCaps applies --max-price-tld .tld=usd pairs on top of the current per-TLD caps.
+// A value of 0 removes the TLD's entry so it falls back to max_price_per_domain.
+func parseTLDCaps(current map[string]float64, pairs []string) (map[string]float64, error) {
+	caps := make(map[string]float64, len(current)+len(pairs))
+	for tld, limit := range current {
+		caps[tld] = limit
+	}
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		tld := budget.NormalizeTLD(k)
+		limit := parseFloatDefault(strings.TrimSpace(v), -1)
+		if !ok || tld == "" || limit < 0 {
+			return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "--max-price-tld expects .tld=usd with a non-negative price", Details: map[string]any{"value": pair}}
+		}
+		if limit == 0 {
+			delete(caps, tld)
+			continue
+		}
+		caps[tld] = limit
+	}
+	if len(caps) == 0 {
+		return nil, nil
+	}
+	return caps, nil
+}
+
+// parseTemplateVars turns --var key=value pairs into a map for template placeholders.
+func parseTemplateVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "--var must be key=value", Details: map[string]any{"var": p}}
+		}
+		vars[k] = v
+	}
+	return vars, nil
+}
+
 func hasBoolFlag(args []string, name string) bool {
 	needleA := "--" + name
 	needleB := "--" + name + "=true"
@@ -1358,6 +2800,47 @@ func hasBoolFlag(args []string, name string) bool {
 	return false
 }
 
+// splitFileArg separates a bulk command's optional leading file argument from its flags.
+func splitFileArg(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "--") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+// bulkDomains loads a bulk command's domains from file or from the --domains-inline comma list,
+// emitting the error itself. Giving both is a validation error; giving neither reports the
+// command's usage.
+func bulkDomains(rt *app.Runtime, command, file string, flags map[string]string) ([]string, error) {
+	inline, hasInline := flags["domains-inline"]
+	if file != "" && hasInline {
+		err := &apperr.AppError{Code: apperr.CodeValidation, Message: "use either a domain file or --domains-inline, not both"}
+		emitError(rt, command, err)
+		return nil, err
+	}
+	if hasInline {
+		domains := splitCSV(inline)
+		if len(domains) == 0 {
+			err := &apperr.AppError{Code: apperr.CodeValidation, Message: "--domains-inline needs at least one domain"}
+			emitError(rt, command, err)
+			return nil, err
+		}
+		return domains, nil
+	}
+	if file == "" {
+		err := usageError(usageOf(command))
+		emitError(rt, command, err)
+		return nil, err
+	}
+	domains, err := services.LoadDomainFile(file)
+	if err != nil {
+		ae := &apperr.AppError{Code: apperr.CodeValidation, Message: "failed reading domain list", Cause: err}
+		emitError(rt, command, ae)
+		return nil, ae
+	}
+	return domains, nil
+}
+
 func splitCSV(v string) []string {
 	if strings.TrimSpace(v) == "" {
 		return nil
@@ -1373,6 +2856,42 @@ func splitCSV(v string) []string {
 	return out
 }
 
+// recordFromFlags builds the record for domains records add/delete/replace. MX records need
+// --priority and SRV records --priority, --weight and --port when written; delete matches on
+// type, name and data, so it takes them as given.
+func recordFromFlags(action string, flags map[string]string) (godaddy.DNSRecord, error) {
+	rec := godaddy.DNSRecord{Type: flags["type"], Name: flags["name"], Data: flags["data"], TTL: parseIntDefault(flags["ttl"], 0)}
+	var required []string
+	if action == "add" || action == "replace" {
+		switch strings.ToUpper(strings.TrimSpace(rec.Type)) {
+		case "MX":
+			required = []string{"priority"}
+		case "SRV":
+			required = []string{"priority", "weight", "port"}
+		}
+	}
+	for _, name := range required {
+		if strings.TrimSpace(flags[name]) == "" {
+			return rec, usageError(fmt.Sprintf("%s records require --%s", strings.ToUpper(strings.TrimSpace(rec.Type)), name))
+		}
+	}
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{{"priority", &rec.Priority}, {"weight", &rec.Weight}, {"port", &rec.Port}} {
+		v := strings.TrimSpace(flags[f.name])
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return rec, usageError("--" + f.name + " must be a whole number")
+		}
+		*f.dst = n
+	}
+	return rec, nil
+}
+
 func parseIntDefault(v string, d int) int {
 	if v == "" {
 		return d
@@ -1403,15 +2922,205 @@ func isHelpToken(v string) bool {
 	return v == "--help" || v == "-h" || v == "help"
 }
 
+// runDomainsWatch streams one NDJSON record per poll and a final record when the domain
+// becomes available, the timeout passes, or the user interrupts.
+func runDomainsWatch(rt *app.Runtime, svc *services.Service, args []string) error {
+	const command = "domains watch"
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		err := usageError(usageOf("domains watch"))
+		emitError(rt, command, err)
+		return err
+	}
+	domain := args[0]
+	flags := parseKVFlags(args[1:])
+	interval, err := parseAgeFlag("interval", flags["interval"], 30*time.Second)
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	timeout, err := parseAgeFlag("timeout", flags["timeout"], 24*time.Hour)
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	purchase := hasBoolFlag(args[1:], "purchase-on-available")
+	var floor float64
+	if purchase {
+		if floor, err = purchaseFloor(rt, args[1:], flags); err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		if !hasBoolFlag(args[1:], "confirm") {
+			err := &apperr.AppError{Code: apperr.CodeConfirmation, Message: "--purchase-on-available requires --confirm"}
+			emitError(rt, command, err)
+			return err
+		}
+		if err := safety.RequireAutoEnabled(rt.Cfg.AutoPurchaseEnabled, rt.Cfg.AcknowledgmentHash); err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(rt.Ctx, timeout)
+	defer cancel()
+
+	last, err := svc.Watch(ctx, domain, interval, func(p services.WatchPoll) error {
+		return rt.Out.EmitNDJSON(command, rt.RequestID, []any{p})
+	})
+	final := map[string]any{"domain": domain, "done": true, "available": last.Available, "polls": last.Poll}
+	if err != nil {
+		if ctx.Err() == nil {
+			emitError(rt, command, err)
+			return err
+		}
+		reason := "timeout"
+		if stopReason(rt.Ctx) == "interrupted" {
+			reason = "interrupted"
+		}
+		final["reason"] = reason
+		if emitErr := rt.Out.EmitNDJSON(command, rt.RequestID, []any{final}); emitErr != nil {
+			return emitErr
+		}
+		return &apperr.AppError{Code: apperr.CodePartial, Message: fmt.Sprintf("watch ended (%s) before %s became available", reason, domain), Details: map[string]any{"domain": domain, "polls": last.Poll, "reason": reason}}
+	}
+	final["price"] = last.Price
+	final["currency"] = last.Currency
+	if purchase {
+		res, err := svc.PurchaseAuto(rt.Ctx, domain, parseIntDefault(flags["years"], rt.Cfg.DefaultYears), floor)
+		if err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		final["purchase"] = purchaseOutput(res)
+	}
+	return rt.Out.EmitNDJSON(command, rt.RequestID, []any{final})
+}
+
+// runTransferWatch polls a transfer until it completes, fails, or is cancelled, emitting an
+// NDJSON record per status change and a final record.
+func runTransferWatch(rt *app.Runtime, svc *services.Service, domain string, args []string) error {
+	const command = "domains transfer watch"
+	flags := parseKVFlags(args)
+	interval, err := parseAgeFlag("interval", flags["interval"], time.Hour)
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	timeout, err := parseAgeFlag("timeout", flags["timeout"], 7*24*time.Hour)
+	if err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(rt.Ctx, timeout)
+	defer cancel()
+	last, err := svc.WatchTransfer(ctx, domain, interval, func(p services.TransferPoll) error {
+		return rt.Out.EmitNDJSON(command, rt.RequestID, []any{p})
+	})
+	final := map[string]any{"domain": domain, "done": true, "status": last.Status, "polls": last.Poll}
+	if err != nil {
+		if ctx.Err() == nil {
+			emitError(rt, command, err)
+			return err
+		}
+		reason := "timeout"
+		if stopReason(rt.Ctx) == "interrupted" {
+			reason = "interrupted"
+		}
+		final["reason"] = reason
+		if emitErr := rt.Out.EmitNDJSON(command, rt.RequestID, []any{final}); emitErr != nil {
+			return emitErr
+		}
+		return &apperr.AppError{Code: apperr.CodePartial, Message: fmt.Sprintf("transfer watch ended (%s) before %s reached a final status", reason, domain), Details: map[string]any{"domain": domain, "polls": last.Poll, "status": last.Status, "reason": reason}}
+	}
+	final["previous_status"] = last.Previous
+	return rt.Out.EmitNDJSON(command, rt.RequestID, []any{final})
+}
+
+// guardPlanSpend checks a v2 call that can charge the account (see Plan.Spends) before it is
+// sent: require_ote_first refuses it against prod without --allow-prod, otherwise prod only warns.
+func guardPlanSpend(rt *app.Runtime, command string, plan services.Plan) error {
+	if !plan.Spends() {
+		return nil
+	}
+	if err := app.GuardProdFinancial(rt, command); err != nil {
+		emitError(rt, command, err)
+		return err
+	}
+	app.WarnProdSpend(rt, command)
+	return nil
+}
+
+// purchaseFloor is the price floor for one purchase: min_plausible_price, replaced by
+// --min-price or turned off by --allow-below-floor. Config is left untouched.
+func purchaseFloor(rt *app.Runtime, args []string, flags map[string]string) (float64, error) {
+	if hasBoolFlag(args, "allow-below-floor") {
+		return 0, nil
+	}
+	if v := strings.TrimSpace(flags["min-price"]); v != "" {
+		n := parseFloatDefault(v, -1)
+		if n < 0 {
+			return 0, &apperr.AppError{Code: apperr.CodeValidation, Message: "min-price must be >= 0"}
+		}
+		return n, nil
+	}
+	return rt.Cfg.MinPlausiblePrice, nil
+}
+
+// purchaseOutput makes idempotent replays and global dry runs explicit instead of looking like
+// an empty fresh order.
+func purchaseOutput(res godaddy.PurchaseResult) any {
+	if res.DryRun {
+		out := map[string]any{"domain": res.Domain, "years": res.Years, "dry_run": true}
+		if res.Currency != "" {
+			out["price"] = res.Price
+			out["currency"] = res.Currency
+		}
+		return out
+	}
+	if !res.AlreadyBought {
+		return res
+	}
+	out := map[string]any{
+		"domain":            res.Domain,
+		"already_purchased": true,
+		"message":           "this purchase already succeeded earlier today; no new order was placed",
+		"price":             res.Price,
+		"currency":          res.Currency,
+		"operation_key":     res.OperationKey,
+	}
+	if res.OrderID != "" {
+		out["order_id"] = res.OrderID
+	} else {
+		out["order_id"] = nil
+		out["order_lookup"] = "original order id not recorded; check gdcli account orders list"
+	}
+	return out
+}
+
 func newService(rt *app.Runtime) (*services.Service, error) {
-	creds, err := app.LoadCredentials()
+	creds, err := app.LoadCredentials(rt.Cfg.Profile())
 	if err != nil {
 		return nil, err
 	}
-	client, err := godaddy.NewHTTPClient(app.BaseURL(rt.Cfg.APIEnvironment), creds.APIKey(), creds.APISecret())
+	client, err := godaddy.NewHTTPClient(app.BaseURL(rt.APIEnvironment()), creds.APIKey(), creds.APISecret())
 	if err != nil {
 		return nil, err
 	}
+	client.ConfigurePool(rt.Cfg.HTTPMaxIdleConnsPerHost, time.Duration(rt.Cfg.HTTPIdleConnTimeoutSeconds)*time.Second)
+	timeout := rt.HTTPTimeout
+	if timeout <= 0 {
+		timeout = time.Duration(rt.Cfg.HTTPTimeoutSeconds) * time.Second
+	}
+	client.SetTimeout(timeout)
+	client.SetLogger(rt.Log)
+	client.SetRequestID(rt.RequestID)
+	client.SetDryRun(rt.DryRun)
+	if proxy := app.Proxy(); proxy != "" {
+		if err := client.SetProxy(proxy); err != nil {
+			return nil, err
+		}
+	}
 	return services.New(rt, client), nil
 }
 
@@ -1422,6 +3131,14 @@ func requestID() string {
 }
 
 func emitSuccess(rt *app.Runtime, command string, result any) error {
+	if rt.CSV {
+		columns, rows, err := csvTable(result)
+		if err != nil {
+			emitError(rt, command, err)
+			return err
+		}
+		return rt.Out.EmitCSV(columns, rows)
+	}
 	if rt.NDJSON {
 		records, ok := result.([]any)
 		if !ok {
@@ -1440,13 +3157,128 @@ func emitSuccess(rt *app.Runtime, command string, result any) error {
 	return rt.Out.EmitJSON(command, rt.RequestID, result, nil)
 }
 
+// csvColumns is the preferred column order for bulk rows; other scalar fields follow alphabetically.
+var csvColumns = []string{"index", "input", "domain", "success", "available", "price", "currency", "expires", "nameServers", "error"}
+
+// csvTable flattens a list of row objects into CSV. Scalar fields of a nested "result" object are
+// lifted into the row; arrays of scalars are joined with ";". Anything else is refused.
+func csvTable(result any) ([]string, [][]string, error) {
+	notFlat := &apperr.AppError{Code: apperr.CodeValidation, Message: "--csv is only supported for commands that return a flat list of rows"}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	var list []map[string]any
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, nil, notFlat
+	}
+	rows := make([]map[string]string, 0, len(list))
+	seen := map[string]bool{}
+	for _, item := range list {
+		row := map[string]string{}
+		for k, v := range item {
+			if nested, ok := v.(map[string]any); ok && k == "result" {
+				for nk, nv := range nested {
+					if _, exists := item[nk]; exists {
+						continue
+					}
+					if s, ok := csvCell(nv); ok {
+						row[nk] = s
+					}
+				}
+				continue
+			}
+			s, ok := csvCell(v)
+			if !ok {
+				return nil, nil, notFlat
+			}
+			row[k] = s
+		}
+		for k := range row {
+			seen[k] = true
+		}
+		rows = append(rows, row)
+	}
+	columns := make([]string, 0, len(seen))
+	for _, c := range csvColumns {
+		if seen[c] {
+			columns = append(columns, c)
+			delete(seen, c)
+		}
+	}
+	extra := make([]string, 0, len(seen))
+	for c := range seen {
+		extra = append(extra, c)
+	}
+	sort.Strings(extra)
+	columns = append(columns, extra...)
+	out := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		line := make([]string, len(columns))
+		for i, c := range columns {
+			line[i] = row[c]
+		}
+		out = append(out, line)
+	}
+	return columns, out, nil
+}
+
+func csvCell(v any) (string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return x, true
+	case bool:
+		return strconv.FormatBool(x), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, e := range x {
+			s, ok := csvCell(e)
+			if !ok {
+				return "", false
+			}
+			if _, nested := e.([]any); nested {
+				return "", false
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ";"), true
+	}
+	return "", false
+}
+
 func emitError(rt *app.Runtime, command string, err error) {
 	var ae *apperr.AppError
 	if !apperr.As(err, &ae) {
 		ae = &apperr.AppError{Code: apperr.CodeInternal, Message: err.Error()}
+		if reason := stopReason(rt.Ctx); reason != "" {
+			ae.Message = "command stopped: " + reason
+			ae.Details = map[string]any{"reason": reason}
+		}
+	}
+	if ae.DocURL == "" {
+		if url := apperr.DocURLFor(ae.Code); url != "" {
+			withDoc := *ae
+			withDoc.DocURL = url
+			ae = &withDoc
+		}
+	}
+	// CSV consumers read stdout as rows, so there the error goes to stderr only, even with --quiet.
+	if rt.CSV {
+		output.LogErr(rt.ErrOut, "error: %s: %s", ae.Code, err)
+		if ae.DocURL != "" {
+			output.LogErr(rt.ErrOut, "see: %s", ae.DocURL)
+		}
+		return
 	}
 	_ = rt.Out.EmitJSON(command, rt.RequestID, nil, ae)
 	if !rt.Quiet {
 		output.LogErr(rt.ErrOut, "error: %s", err)
+		if ae.DocURL != "" {
+			output.LogErr(rt.ErrOut, "see: %s", ae.DocURL)
+		}
 	}
 }
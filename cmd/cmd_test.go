@@ -0,0 +1,542 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sportwhiz/gdcli/internal/app"
+	"github.com/sportwhiz/gdcli/internal/config"
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
+	"github.com/sportwhiz/gdcli/internal/store"
+)
+
+func TestParseGlobalFlagsConfig(t *testing.T) {
+	g, rest, err := parseGlobalFlags([]string{"--config", "/tmp/ote.json", "domains", "avail", "x.com", "--json"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if g.config != "/tmp/ote.json" || !g.json || len(rest) != 3 || rest[0] != "domains" {
+		t.Fatalf("unexpected parse: %+v %v", g, rest)
+	}
+	g, _, err = parseGlobalFlags([]string{"--config=/tmp/prod.json", "settings", "show"})
+	if err != nil || g.config != "/tmp/prod.json" {
+		t.Fatalf("expected --config= form, got %+v %v", g, err)
+	}
+	if _, _, err := parseGlobalFlags([]string{"settings", "show", "--config"}); err == nil {
+		t.Fatalf("expected error for --config without a path")
+	}
+}
+
+func TestCSVOutputFlattensBulkRows(t *testing.T) {
+	rt, out := testRuntime(t, "http://127.0.0.1:1", false, false)
+	rt.CSV = true
+	rows := []any{
+		map[string]any{"index": 0, "input": "a.com", "success": true, "duration_ms": 12, "result": map[string]any{"domain": "a.com", "available": true, "price": 12.99, "currency": "USD"}},
+		map[string]any{"index": 1, "input": "b.com", "success": false, "duration_ms": 3, "error": "provider error, retry"},
+	}
+	if err := emitSuccess(rt, "domains avail-bulk", rows); err != nil {
+		t.Fatalf("emit csv: %v", err)
+	}
+	want := "index,input,domain,success,available,price,currency,error,duration_ms\n" +
+		"0,a.com,a.com,true,true,12.99,USD,,12\n" +
+		"1,b.com,,false,,,,\"provider error, retry\",3\n"
+	if out.String() != want {
+		t.Fatalf("unexpected csv:\n%s", out.String())
+	}
+
+	out.Reset()
+	err := emitSuccess(rt, "settings show", map[string]any{"max_daily_spend": 100})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("expected non-list result to be refused in csv mode, got %v", err)
+	}
+	errOut := &bytes.Buffer{}
+	rt.ErrOut = errOut
+	emitError(rt, "settings show", err)
+	if out.Len() != 0 || !strings.Contains(errOut.String(), "error: validation_error:") {
+		t.Fatalf("expected csv errors on stderr only, got stdout=%q stderr=%q", out.String(), errOut.String())
+	}
+	if _, _, err := parseGlobalFlags([]string{"--csv", "--ndjson", "domains", "list"}); err == nil {
+		t.Fatalf("expected --csv with --ndjson to be rejected")
+	}
+}
+
+func TestParseGlobalTimeoutOnlyBeforeCommand(t *testing.T) {
+	g, rest, err := parseGlobalFlags([]string{"--timeout", "45s", "domains", "watch", "x.com", "--timeout", "1h"})
+	if err != nil || g.timeout != "45s" {
+		t.Fatalf("expected global --timeout, got %+v %v", g, err)
+	}
+	if strings.Join(rest, " ") != "domains watch x.com --timeout 1h" {
+		t.Fatalf("expected command --timeout to be left for the command, got %v", rest)
+	}
+	if _, _, err := parseGlobalFlags([]string{"--timeout=0", "domains", "list"}); err == nil {
+		t.Fatalf("expected non-positive --timeout to be rejected")
+	}
+}
+
+func TestParseGlobalDeadline(t *testing.T) {
+	g, rest, err := parseGlobalFlags([]string{"domains", "avail-bulk", "d.txt", "--deadline", "10m"})
+	if err != nil || g.deadline != "10m" || strings.Join(rest, " ") != "domains avail-bulk d.txt" {
+		t.Fatalf("expected --deadline anywhere, got %+v %v %v", g, rest, err)
+	}
+	if _, _, err := parseGlobalFlags([]string{"--deadline=soon", "domains", "list"}); err == nil {
+		t.Fatalf("expected invalid --deadline to be rejected")
+	}
+}
+
+func TestParseGlobalAPIEnvironment(t *testing.T) {
+	g, rest, err := parseGlobalFlags([]string{"--api-environment", "ote", "settings", "profile", "add", "x", "--api-environment", "prod"})
+	if err != nil || g.apiEnv != "ote" || strings.Join(rest, " ") != "settings profile add x --api-environment prod" {
+		t.Fatalf("expected global override before the command only, got %+v %v %v", g, rest, err)
+	}
+	if _, _, err := parseGlobalFlags([]string{"--api-environment=staging", "domains", "list"}); apperr.ExitCode(err) != 2 {
+		t.Fatalf("expected invalid environment to be a validation error, got %v", err)
+	}
+
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	rt.APIEnvOverride = g.apiEnv
+	if rt.APIEnvironment() != "ote" || rt.Cfg.APIEnvironment != "prod" {
+		t.Fatalf("expected override without touching config, got %q / %q", rt.APIEnvironment(), rt.Cfg.APIEnvironment)
+	}
+}
+
+func TestParseGlobalNoRetry(t *testing.T) {
+	g, rest, err := parseGlobalFlags([]string{"domains", "avail", "example.com", "--no-retry"})
+	if err != nil || !g.noRetry || strings.Join(rest, " ") != "domains avail example.com" {
+		t.Fatalf("expected --no-retry to be global, got %+v %v %v", g, rest, err)
+	}
+
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	rt.Cfg.RetryAttempts = 5
+	if p := rt.RetryPolicy(); p.Attempts != 5 || p.Base != 250*time.Millisecond {
+		t.Fatalf("expected configured policy, got %+v", p)
+	}
+	rt.NoRetry = true
+	if p := rt.RetryPolicy(); p.Attempts != 1 {
+		t.Fatalf("expected --no-retry to force one attempt, got %+v", p)
+	}
+}
+
+func TestParseGlobalVerbosity(t *testing.T) {
+	cases := map[string]int{"-v": 1, "--verbose": 1, "-vv": 2}
+	for flag, want := range cases {
+		g, rest, err := parseGlobalFlags([]string{"domains", "list", flag})
+		if err != nil || g.verbose != want || len(rest) != 2 {
+			t.Fatalf("%s: expected verbosity %d, got %+v %v %v", flag, want, g, rest, err)
+		}
+	}
+	g, _, _ := parseGlobalFlags([]string{"-v", "-v", "domains", "list"})
+	if g.verbose != 2 {
+		t.Fatalf("expected repeated -v to stack, got %d", g.verbose)
+	}
+}
+
+func TestParseGlobalFields(t *testing.T) {
+	g, rest, err := parseGlobalFlags([]string{"domains", "avail", "x.com", "--fields", "domain,price"})
+	if err != nil || strings.Join(g.fields, ",") != "domain,price" || len(rest) != 3 {
+		t.Fatalf("unexpected parse: %+v %v %v", g, rest, err)
+	}
+	if g, _, err := parseGlobalFlags([]string{"--fields=available", "domains", "avail", "x.com"}); err != nil || len(g.fields) != 1 {
+		t.Fatalf("unexpected = form parse: %+v %v", g, err)
+	}
+	if _, _, err := parseGlobalFlags([]string{"domains", "avail", "x.com", "--fields=,"}); err == nil {
+		t.Fatalf("expected empty --fields to fail")
+	}
+}
+
+func TestParseGlobalPretty(t *testing.T) {
+	if g, _, err := parseGlobalFlags([]string{"version", "--pretty"}); err != nil || !g.pretty {
+		t.Fatalf("expected --pretty parsed: %+v %v", g, err)
+	}
+	if _, _, err := parseGlobalFlags([]string{"--ndjson", "--pretty", "domains", "list"}); err == nil {
+		t.Fatalf("expected --pretty with --ndjson to fail")
+	}
+}
+
+func TestParseGlobalColor(t *testing.T) {
+	cases := map[string][]string{
+		"auto":   {"domains", "list"},
+		"always": {"--color", "always", "domains", "list"},
+		"never":  {"domains", "list", "--no-color"},
+	}
+	for want, args := range cases {
+		g, rest, err := parseGlobalFlags(args)
+		if err != nil || g.color != want || len(rest) != 2 {
+			t.Fatalf("%v: expected color %s, got %+v %v %v", args, want, g, rest, err)
+		}
+	}
+	if _, _, err := parseGlobalFlags([]string{"--color=rainbow", "domains", "list"}); err == nil {
+		t.Fatalf("expected invalid --color to fail")
+	}
+}
+
+func TestApplyOutputDefault(t *testing.T) {
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	rt.Cfg.OutputDefault = "ndjson"
+
+	applyOutputDefault(rt, globalFlags{json: true})
+	if !rt.JSON || rt.NDJSON {
+		t.Fatalf("expected --json to win over output_default")
+	}
+	applyOutputDefault(rt, globalFlags{})
+	if rt.JSON || !rt.NDJSON {
+		t.Fatalf("expected output_default ndjson without flags, got json=%v ndjson=%v", rt.JSON, rt.NDJSON)
+	}
+
+	rt, _ = testRuntime(t, "http://127.0.0.1:1", true, false)
+	applyOutputDefault(rt, globalFlags{})
+	if !rt.JSON || rt.NDJSON {
+		t.Fatalf("expected json with default config")
+	}
+}
+
+func TestNoKeychainSkipsLookupAndRejectsStore(t *testing.T) {
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	g, _, err := parseGlobalFlags([]string{"--no-keychain", "init"})
+	if err != nil || !g.noKeychain {
+		t.Fatalf("expected --no-keychain to parse, got %+v %v", g, err)
+	}
+
+	t.Setenv(app.NoKeychainEnvVar, "1")
+	err = runInit(rt, []string{"--store-keychain", "--api-key", "k", "--api-secret", "s"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation || !strings.Contains(ae.Message, "disabled") {
+		t.Fatalf("expected keychain disabled validation error, got %v", err)
+	}
+
+	t.Setenv("GODADDY_API_KEY", "")
+	t.Setenv("GODADDY_API_SECRET", "")
+	_, err = app.LoadCredentials(config.DefaultProfile)
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeAuth {
+		t.Fatalf("expected missing-credentials error, got %v", err)
+	}
+}
+
+func TestInitVerifyDeepReportsEachCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/domains/available":
+			_, _ = w.Write([]byte(`{"domain":"example.com","available":false}`))
+		case "/v1/orders":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":"ACCESS_DENIED","message":"Authenticated user is not allowed access"}`))
+		case "/v1/shoppers/123456789":
+			_, _ = w.Write([]byte(`{"shopperId":"123456789","customerId":"cust-123"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	if err := runInit(rt, []string{"--verify"}); err != nil {
+		t.Fatalf("lightweight verify should only check availability: %v", err)
+	}
+
+	out.Reset()
+	err := runInit(rt, []string{"--shopper-id", "123456789", "--verify", "--deep"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeAuth {
+		t.Fatalf("expected auth error from the orders check, got %v", err)
+	}
+	checks, _ := ae.Details["checks"].([]map[string]any)
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 checks, got %+v", ae.Details)
+	}
+	want := map[string]bool{"availability": true, "orders": false, "customer_id": true}
+	for _, c := range checks {
+		name, _ := c["check"].(string)
+		if c["ok"] != want[name] {
+			t.Fatalf("unexpected result for %s: %+v", name, c)
+		}
+	}
+	if checks[2]["customer_id"] != "cust-123" {
+		t.Fatalf("expected resolved customer id, got %+v", checks[2])
+	}
+
+	if err := runInit(rt, []string{"--deep"}); err == nil {
+		t.Fatalf("expected --deep without --verify to be rejected")
+	}
+}
+
+func TestHTTPTimeoutSecondsMustBePositive(t *testing.T) {
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	for _, v := range []string{"0", "-5", "soon"} {
+		err := runInit(rt, []string{"--http-timeout-seconds", v})
+		var ae *apperr.AppError
+		if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation {
+			t.Fatalf("expected validation error for --http-timeout-seconds %s, got %v", v, err)
+		}
+	}
+	if err := runInit(rt, []string{"--http-timeout-seconds", "45"}); err != nil || rt.Cfg.HTTPTimeoutSeconds != 45 {
+		t.Fatalf("expected timeout 45, got %d (%v)", rt.Cfg.HTTPTimeoutSeconds, err)
+	}
+
+	path, _ := config.Path()
+	if err := os.WriteFile(path, []byte(`{"http_timeout_seconds": 0}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	loaded, err := app.NewRuntime(context.Background(), &bytes.Buffer{}, &stderr, true, false, false, "req-test")
+	if err != nil || loaded.Cfg.HTTPTimeoutSeconds != config.Default().HTTPTimeoutSeconds {
+		t.Fatalf("expected http_timeout_seconds 0 to fall back to the default, got %+v (%v)", loaded, err)
+	}
+	if !strings.Contains(stderr.String(), "warning: http_timeout_seconds 0") {
+		t.Fatalf("expected a warning about http_timeout_seconds, got %q", stderr.String())
+	}
+}
+
+func TestInitStoreFileEncryptsCredentials(t *testing.T) {
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	t.Setenv(config.HomeEnvVar, "")
+
+	t.Setenv(app.PassphraseEnvVar, "")
+	err := runInit(rt, []string{"--store-file", "--api-key", "file-key", "--api-secret", "file-secret"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("expected validation error without a passphrase, got %v", err)
+	}
+
+	t.Setenv(app.PassphraseEnvVar, "correct horse")
+	if err := runInit(rt, []string{"--store-file", "--api-key", "file-key", "--api-secret", "file-secret"}); err != nil {
+		t.Fatalf("init --store-file: %v", err)
+	}
+	dir, _ := config.HomeDir()
+	raw, err := os.ReadFile(filepath.Join(dir, app.CredentialsFile))
+	if err != nil {
+		t.Fatalf("read credentials file: %v", err)
+	}
+	if strings.Contains(string(raw), "file-secret") {
+		t.Fatalf("credentials file holds the secret in plaintext: %s", raw)
+	}
+
+	t.Setenv("GODADDY_API_KEY", "")
+	t.Setenv("GODADDY_API_SECRET", "")
+	t.Setenv(app.NoKeychainEnvVar, "1")
+	creds, err := app.LoadCredentials(config.DefaultProfile)
+	if err != nil || creds.APIKey() != "file-key" || creds.APISecret() != "file-secret" {
+		t.Fatalf("expected credentials from the encrypted file, got %q/%q (%v)", creds.APIKey(), creds.APISecret(), err)
+	}
+
+	t.Setenv(app.PassphraseEnvVar, "wrong")
+	if _, err := app.LoadCredentials(config.DefaultProfile); !errors.As(err, &ae) || ae.Code != apperr.CodeAuth {
+		t.Fatalf("expected auth error with the wrong passphrase, got %v", err)
+	}
+}
+
+func TestConfigPathPrecedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnvVar, "")
+	t.Cleanup(func() { _ = config.SetPath("") })
+
+	if got, _ := config.Path(); got != filepath.Join(home, ".gdcli", "config.json") {
+		t.Fatalf("expected default path, got %s", got)
+	}
+
+	envDir := filepath.Join(home, "env-home")
+	t.Setenv(config.HomeEnvVar, envDir)
+	if got, _ := config.Path(); got != filepath.Join(envDir, "config.json") {
+		t.Fatalf("expected env var path, got %s", got)
+	}
+
+	flagPath := filepath.Join(home, "ote", "ote.json")
+	if err := config.SetPath(flagPath); err != nil {
+		t.Fatalf("set path: %v", err)
+	}
+	if got, _ := config.Path(); got != flagPath {
+		t.Fatalf("expected flag path to win, got %s", got)
+	}
+	if dir, _ := config.HomeDir(); dir != filepath.Dir(flagPath) {
+		t.Fatalf("expected state files next to the chosen config, got %s", dir)
+	}
+
+	rt, out := testRuntime(t, "http://127.0.0.1:1", true, false)
+	if err := runSettings(rt, []string{"show"}); err != nil {
+		t.Fatalf("settings show: %v", err)
+	}
+	if !strings.Contains(out.String(), flagPath) {
+		t.Fatalf("expected settings show to report %s, got %s", flagPath, out.String())
+	}
+}
+
+func TestSettingsProfilesAddUseAndOverride(t *testing.T) {
+	t.Setenv(config.HomeEnvVar, "")
+	t.Cleanup(func() { _ = config.SetProfile("") })
+
+	rt, out := testRuntime(t, "http://127.0.0.1:1", true, false)
+	if err := runSettings(rt, []string{"profile", "add", "ote-lab", "--api-environment", "ote"}); err != nil {
+		t.Fatalf("profile add: %v", err)
+	}
+	if err := runSettings(rt, []string{"profile", "add", "Bad Name"}); err == nil {
+		t.Fatalf("expected invalid profile name to be rejected")
+	}
+	if err := runSettings(rt, []string{"profile", "use", "ote-lab"}); err != nil {
+		t.Fatalf("profile use: %v", err)
+	}
+
+	// A fresh runtime resolves the active profile, and saves land in that profile.
+	rt, out = testRuntimeKeepHome(t, "http://127.0.0.1:1")
+	if rt.Cfg.Profile() != "ote-lab" || rt.Cfg.APIEnvironment != "ote" || rt.Cfg.MaxDailySpend != 100 {
+		t.Fatalf("expected ote-lab profile with defaults, got %s %+v", rt.Cfg.Profile(), rt.Cfg)
+	}
+	if err := runSettings(rt, []string{"caps", "set", "--max-price", "10", "--max-daily-spend", "20", "--max-domains-per-day", "2"}); err != nil {
+		t.Fatalf("caps set: %v", err)
+	}
+	root, err := config.LoadRoot()
+	if err != nil {
+		t.Fatalf("load root: %v", err)
+	}
+	if root.MaxDailySpend != 100 || root.Profiles["ote-lab"].MaxDailySpend != 20 {
+		t.Fatalf("expected caps saved to the profile only: root=%v profile=%v", root.MaxDailySpend, root.Profiles["ote-lab"].MaxDailySpend)
+	}
+
+	// --profile overrides the active profile for one invocation.
+	if err := config.SetProfile(config.DefaultProfile); err != nil {
+		t.Fatalf("set profile: %v", err)
+	}
+	rt, out = testRuntimeKeepHome(t, "http://127.0.0.1:1")
+	if rt.Cfg.Profile() != config.DefaultProfile || rt.Cfg.APIEnvironment != "prod" {
+		t.Fatalf("expected default profile override, got %s", rt.Cfg.Profile())
+	}
+	if err := runSettings(rt, []string{"profile", "remove", "ote-lab"}); err == nil {
+		t.Fatalf("expected removing the active profile to fail")
+	}
+	out.Reset()
+	if err := runSettings(rt, []string{"profile", "list"}); err != nil {
+		t.Fatalf("profile list: %v", err)
+	}
+	if !strings.Contains(out.String(), `"active":"ote-lab"`) || !strings.Contains(out.String(), `"current":"default"`) {
+		t.Fatalf("unexpected profile list: %s", out.String())
+	}
+
+	if err := config.SetProfile("missing"); err != nil {
+		t.Fatalf("set profile: %v", err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Fatalf("expected unknown profile error")
+	}
+}
+
+func TestSettingsResetRequiresConfirmAndKeepsIdentity(t *testing.T) {
+	t.Setenv(config.HomeEnvVar, "")
+	rt, out := testRuntime(t, "http://127.0.0.1:1", true, false)
+	rt.Cfg.ShopperID = "123456789"
+	rt.Cfg.CustomerID = "cust-123"
+	rt.Cfg.MaxDailySpend = 900
+	rt.Cfg.AutoPurchaseEnabled = true
+	if err := config.Save(rt.Cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	err := runSettings(rt, []string{"reset"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeConfirmation {
+		t.Fatalf("expected confirmation error, got %v", err)
+	}
+	if rt.Cfg.MaxDailySpend != 900 {
+		t.Fatalf("config changed without --confirm")
+	}
+
+	out.Reset()
+	if err := runSettings(rt, []string{"reset", "--confirm"}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if saved.MaxDailySpend != 100 || saved.AutoPurchaseEnabled || saved.ShopperID != "123456789" || saved.CustomerID != "cust-123" {
+		t.Fatalf("unexpected config after reset: %+v", saved)
+	}
+	if !strings.Contains(out.String(), `"command":"settings reset"`) || !strings.Contains(out.String(), `"max_daily_spend":100`) {
+		t.Fatalf("unexpected reset output: %s", out.String())
+	}
+
+	if err := runSettings(rt, []string{"reset", "--confirm", "--all"}); err != nil {
+		t.Fatalf("reset --all: %v", err)
+	}
+	saved, err = config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if saved.ShopperID != "" || saved.CustomerID != "" {
+		t.Fatalf("expected identity cleared with --all: %+v", saved)
+	}
+}
+
+// testRuntimeKeepHome builds a runtime against the HOME already set by an earlier testRuntime call.
+func testRuntimeKeepHome(t *testing.T, baseURL string) (*app.Runtime, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("GDCLI_BASE_URL", baseURL)
+	out := &bytes.Buffer{}
+	rt, err := app.NewRuntime(context.Background(), out, os.Stderr, true, false, true, "req-test")
+	if err != nil {
+		t.Fatalf("new runtime: %v", err)
+	}
+	return rt, out
+}
+
+func TestSettingsCapsSetPerTLDPrice(t *testing.T) {
+	t.Setenv(config.HomeEnvVar, "")
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	if err := runSettings(rt, []string{"caps", "set", "--max-price-tld", ".AI=80", "--max-price-tld", "io=40"}); err != nil {
+		t.Fatalf("caps set: %v", err)
+	}
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if saved.MaxPricePerTLD["ai"] != 80 || saved.MaxPricePerTLD["io"] != 40 || saved.MaxPricePerDomain != 25 {
+		t.Fatalf("unexpected caps: %+v %v", saved.MaxPricePerTLD, saved.MaxPricePerDomain)
+	}
+	if err := runSettings(rt, []string{"caps", "set", "--max-price-tld", ".io=0"}); err != nil {
+		t.Fatalf("caps set: %v", err)
+	}
+	if _, ok := rt.Cfg.MaxPricePerTLD["io"]; ok || rt.Cfg.MaxPricePerTLD["ai"] != 80 {
+		t.Fatalf("expected .io removed and .ai kept: %+v", rt.Cfg.MaxPricePerTLD)
+	}
+	err = runSettings(rt, []string{"caps", "set", "--max-price-tld", "ai"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestSettingsPruneOperationsKeepsCurrentMonth(t *testing.T) {
+	t.Setenv(config.HomeEnvVar, "")
+	rt, out := testRuntime(t, "http://127.0.0.1:1", true, false)
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	for _, op := range []store.Operation{
+		{OperationID: "last-year", Type: "purchase", Status: "succeeded", CreatedAt: now.AddDate(-1, 0, 0)},
+		{OperationID: "this-month", Type: "purchase", Status: "succeeded", CreatedAt: monthStart.Add(time.Minute)},
+	} {
+		if err := store.AppendOperation(op); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if err := runSettings(rt, []string{"prune-operations", "--older-than", "0d"}); err == nil {
+		t.Fatalf("expected zero age to be rejected")
+	}
+	out.Reset()
+	if err := runSettings(rt, []string{"prune-operations", "--older-than", "1d"}); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if !strings.Contains(out.String(), `"removed":1`) {
+		t.Fatalf("unexpected prune output: %s", out.String())
+	}
+	ops, err := store.ReadOperations()
+	if err != nil || len(ops) != 1 || ops[0].OperationID != "this-month" {
+		t.Fatalf("expected this month's operation kept: %+v (%v)", ops, err)
+	}
+}
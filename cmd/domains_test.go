@@ -0,0 +1,763 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sportwhiz/gdcli/internal/config"
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
+	"github.com/sportwhiz/gdcli/internal/safety"
+	"github.com/sportwhiz/gdcli/internal/services"
+	"github.com/sportwhiz/gdcli/internal/store"
+)
+
+func TestDomainsDryRunPlanReplaysWithPlanFile(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.CustomerID = "cust-123"
+	if err := runDomains(rt, []string{"contacts", "set", "example.com", "--body-json", `{"contactAdmin":{"email":"a@example.com"}}`}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if gotMethod != "" {
+		t.Fatalf("dry run should not call the API, got %s %s", gotMethod, gotPath)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	plan, _ := result["plan"].(map[string]any)
+	if plan["method"] != "PATCH" || plan["path"] != "/v2/customers/{customerId}/domains/example.com/contacts" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(planFile, out.Bytes(), 0o600); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+	out.Reset()
+	if err := runDomains(rt, []string{"plan", "--plan-file", planFile}); err != nil {
+		t.Fatalf("plan review: %v", err)
+	}
+	if gotMethod != "" {
+		t.Fatalf("plan without --apply should not call the API")
+	}
+	if err := runDomains(rt, []string{"plan", "--plan-file", planFile, "--apply"}); err != nil {
+		t.Fatalf("plan apply: %v", err)
+	}
+	if gotMethod != http.MethodPatch || gotPath != "/v2/customers/cust-123/domains/example.com/contacts" {
+		t.Fatalf("unexpected request: %s %s", gotMethod, gotPath)
+	}
+	if gotBody != `{"contactAdmin":{"email":"a@example.com"}}` {
+		t.Fatalf("unexpected body: %s", gotBody)
+	}
+}
+
+func TestGlobalDryRunSendsNoWrites(t *testing.T) {
+	var writes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes = append(writes, r.Method+" "+r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.CustomerID = "cust-123"
+	rt.DryRun = true
+	if err := runDomains(rt, []string{"auth-code", "regenerate", "example.com", "--apply"}); err != nil {
+		t.Fatalf("auth-code regenerate: %v", err)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	if result["dry_run"] != true || result["method"] != "POST" || result["path"] != "/v2/customers/cust-123/domains/example.com/regenerateAuthCode" {
+		t.Fatalf("expected the request to be echoed: %+v", result)
+	}
+
+	// Writes that bypass V2Apply are refused by the client itself.
+	err := runAccount(rt, []string{"subscriptions", "set-auto-renew", "757644825:2", "--enabled", "false", "--apply"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeSafety || ae.Details["method"] != http.MethodPatch {
+		t.Fatalf("expected dry-run safety error, got %v", err)
+	}
+	if len(writes) != 0 {
+		t.Fatalf("dry run sent writes: %v", writes)
+	}
+}
+
+func TestGlobalDryRunPurchaseAndRenewTouchNoLedger(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.RequireOTEFirst = true
+	rt.APIEnvOverride = "prod"
+	rt.DryRun = true
+	for _, action := range []string{"purchase", "renew"} {
+		err := runDomains(rt, []string{action, "example.com", "--confirm", "tok-unknown"})
+		if apperr.CodeOf(err) != apperr.CodeConfirmation {
+			t.Fatalf("expected %s --dry-run with an unknown token to be rejected, got %v", action, err)
+		}
+	}
+
+	now := time.Now()
+	tokens := map[string]string{}
+	for action, safetyAction := range map[string]string{"purchase": safety.ActionPurchase, "renew": safety.ActionRenew} {
+		tok, err := safety.IssueToken(safetyAction, "example.com", 1, 11.5, "USD", "op-"+action, now, 0)
+		if err != nil {
+			t.Fatalf("issue %s token: %v", action, err)
+		}
+		tokens[action] = tok.TokenID
+		out.Reset()
+		if err := runDomains(rt, []string{action, "example.com", "--confirm", tok.TokenID}); err != nil {
+			t.Fatalf("%s --confirm under --dry-run: %v", action, err)
+		}
+		var env map[string]any
+		if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+			t.Fatalf("decode envelope: %v", err)
+		}
+		result, _ := env["result"].(map[string]any)
+		if result["dry_run"] != true || result["domain"] != "example.com" || result["years"] != float64(1) || result["price"] != 11.5 || result["currency"] != "USD" {
+			t.Fatalf("expected %s to echo the token's quote: %+v", action, result)
+		}
+	}
+	for action, safetyAction := range map[string]string{"purchase": safety.ActionPurchase, "renew": safety.ActionRenew} {
+		if _, err := safety.ValidateToken(safetyAction, tokens[action], "example.com", now); err != nil {
+			t.Fatalf("dry run should leave the %s token usable: %v", action, err)
+		}
+	}
+	ops, err := store.ReadOperations()
+	if err != nil {
+		t.Fatalf("read operations: %v", err)
+	}
+	if len(ops) != 0 || len(calls) != 0 {
+		t.Fatalf("dry run reserved %d operations and sent %v", len(ops), calls)
+	}
+}
+
+func TestDomainsPlanRejectsNonV2Path(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	rt, _ := testRuntime(t, srv.URL, true, false)
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(planFile, []byte(`{"command":"x","method":"DELETE","path":"/v1/domains/example.com","body":{}}`), 0o600); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+	if err := runDomains(rt, []string{"plan", "--plan-file", planFile, "--apply"}); err == nil {
+		t.Fatalf("expected invalid plan to be rejected")
+	}
+}
+
+func TestDomainsPurchaseAlreadyPurchasedOutput(t *testing.T) {
+	purchases := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/domains/available":
+			_, _ = w.Write([]byte(`{"domain":"example.com","available":true,"definitive":true,"price":12990000,"currency":"USD"}`))
+		case "/v1/domains/purchase":
+			purchases++
+			_, _ = w.Write([]byte(`{"domain":"example.com","price":12.99,"currency":"USD","order_id":"order-777"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.AutoPurchaseEnabled = true
+	rt.Cfg.AcknowledgmentHash = "ack"
+	if err := runDomains(rt, []string{"purchase", "example.com", "--auto"}); err != nil {
+		t.Fatalf("first purchase: %v", err)
+	}
+	out.Reset()
+	if err := runDomains(rt, []string{"purchase", "example.com", "--auto"}); err != nil {
+		t.Fatalf("retried purchase: %v", err)
+	}
+	if purchases != 1 {
+		t.Fatalf("expected a single provider purchase, got %d", purchases)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	if result["already_purchased"] != true || result["order_id"] != "order-777" || result["domain"] != "example.com" {
+		t.Fatalf("unexpected already-purchased output: %+v", result)
+	}
+	if msg, _ := result["message"].(string); msg == "" {
+		t.Fatalf("expected explanatory message: %+v", result)
+	}
+}
+
+func TestAvailBulkRefusesInputAboveCap(t *testing.T) {
+	rt, out := testRuntime(t, "http://127.0.0.1:1", true, false)
+	rt.Cfg.MaxBulkItems = 2
+	file := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(file, []byte("a.com\nb.com\nc.com\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err := runDomains(rt, []string{"avail-bulk", file})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation || ae.Details["count"] != 3 || ae.Details["max_items"] != 2 {
+		t.Fatalf("expected bulk cap validation error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"max_items":2`) {
+		t.Fatalf("expected cap in error output: %s", out.String())
+	}
+
+	for _, v := range []string{"lots", "-1"} {
+		err := runDomains(rt, []string{"avail-bulk", file, "--max-items", v})
+		if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation || !strings.Contains(ae.Message, "--max-items") {
+			t.Fatalf("expected --max-items %s to be rejected, got %v", v, err)
+		}
+	}
+
+	// An explicit 0 (no cap) must survive a save and reload rather than reverting to the default.
+	rt.Cfg.MaxBulkItems = 0
+	if err := config.Save(rt.Cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg.MaxBulkItems != 0 {
+		t.Fatalf("expected max_bulk_items 0 to round-trip, got %d (%v)", cfg.MaxBulkItems, err)
+	}
+}
+
+func TestConcurrencyAboveConfiguredMaxIsRejected(t *testing.T) {
+	rt, out := testRuntime(t, "http://127.0.0.1:1", true, false)
+	err := runDomains(rt, []string{"avail-bulk", "--domains-inline", "a.com,b.com", "--concurrency", "5000"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation || ae.Details["concurrency"] != 5000 || ae.Details["max_concurrency"] != 20 {
+		t.Fatalf("expected concurrency validation error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"max_concurrency":20`) {
+		t.Fatalf("expected max in error output: %s", out.String())
+	}
+
+	rt, _ = testRuntime(t, "http://127.0.0.1:1", true, false)
+	rt.Cfg.MaxConcurrency = 4
+	seeds := filepath.Join(t.TempDir(), "seeds.txt")
+	if err := os.WriteFile(seeds, []byte("coffee\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, args := range [][]string{
+		{"portfolio", "--concurrency", "5"},
+		{"discover", "--seeds", seeds, "--check-concurrency", "5"},
+		{"avail-bulk", "--domains-inline", "a.com", "--concurrency", "0"},
+	} {
+		err := runDomains(rt, args)
+		if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation || ae.Details["max_concurrency"] != 4 {
+			t.Fatalf("%v: expected concurrency validation error, got %v", args, err)
+		}
+	}
+	if err := runDNS(rt, []string{"audit", "--domains-inline", "a.com", "--concurrency", "9"}); !errors.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("dns audit: expected concurrency validation error, got %v", err)
+	}
+}
+
+func TestConcurrencyDefaultIsLoweredToConfiguredMax(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"` + r.URL.Query().Get("domain") + `","available":true,"price":12990000,"currency":"USD"}`))
+	}))
+	defer srv.Close()
+
+	rt, _ := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.MaxConcurrency = 4
+	if err := runDomains(rt, []string{"avail-bulk", "--domains-inline", "a.com,b.com"}); err != nil {
+		t.Fatalf("expected the default concurrency of 10 to be lowered to 4, got %v", err)
+	}
+}
+
+func TestAvailBulkAcceptsInlineDomains(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"` + r.URL.Query().Get("domain") + `","available":true,"price":12990000,"currency":"USD"}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	if err := runDomains(rt, []string{"avail-bulk", "--domains-inline", "a.com, b.com,c.com"}); err != nil {
+		t.Fatalf("avail-bulk inline: %v", err)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	if rows, _ := result["results"].([]any); len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", result)
+	}
+
+	file := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(file, []byte("a.com\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err := runDomains(rt, []string{"avail-bulk", file, "--domains-inline", "b.com"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("expected validation error for file plus inline list, got %v", err)
+	}
+}
+
+func TestTransferInRetryChecksStatusUnlessForced(t *testing.T) {
+	var posts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet && r.URL.Path == "/v2/customers/cust-123/domains/example.com/transfer" {
+			_, _ = w.Write([]byte(`{"status":"PENDING_OWNER_APPROVAL"}`))
+			return
+		}
+		if r.Method == http.MethodPost && r.URL.Path == "/v2/customers/cust-123/domains/example.com/transferInRetry" {
+			posts++
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	rt, _ := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.CustomerID = "cust-123"
+	err := runDomains(rt, []string{"transfer", "in-retry", "example.com", "--apply"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Message != "cannot retry: transfer status is PENDING_OWNER_APPROVAL, not FAILED" {
+		t.Fatalf("expected status gate error, got %v", err)
+	}
+	if posts != 0 {
+		t.Fatalf("gated action should not be sent")
+	}
+	if err := runDomains(rt, []string{"transfer", "in-retry", "example.com", "--apply", "--force"}); err != nil {
+		t.Fatalf("forced retry: %v", err)
+	}
+	if posts != 1 {
+		t.Fatalf("expected forced retry to be sent once, got %d", posts)
+	}
+}
+
+func TestTransferWatchEmitsStatusChangesUntilTerminal(t *testing.T) {
+	statuses := []string{"PENDING_OWNER_APPROVAL", "PENDING_OWNER_APPROVAL", "PENDING_REGISTRY", "COMPLETED"}
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v2/customers/cust-123/domains/example.com/transfer" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"` + statuses[min(polls, len(statuses)-1)] + `"}`))
+		polls++
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, false, true)
+	rt.Cfg.CustomerID = "cust-123"
+	if err := runDomains(rt, []string{"transfer", "watch", "example.com", "--interval", "5ms", "--timeout", "10s"}); err != nil {
+		t.Fatalf("transfer watch: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || polls != 4 {
+		t.Fatalf("expected two changes and a final record after 4 polls (got %d):\n%s", polls, out.String())
+	}
+	for i, want := range []string{`"status":"PENDING_OWNER_APPROVAL"`, `"status":"PENDING_REGISTRY","previous_status":"PENDING_OWNER_APPROVAL"`, `"status":"COMPLETED"`} {
+		if !strings.Contains(lines[i], want) {
+			t.Fatalf("line %d: expected %s in %s", i, want, lines[i])
+		}
+	}
+}
+
+func TestDomainsWatchStopsWithTheRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if polls++; polls == 2 {
+			cancel()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"example.com","available":false}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, false, true)
+	rt.Ctx = ctx
+	// Both watch commands take the same durations, including whole days.
+	err := runDomains(rt, []string{"watch", "example.com", "--interval", "5ms", "--timeout", "1d"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodePartial || ae.Details["reason"] != "interrupted" {
+		t.Fatalf("expected the run context to interrupt the watch, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"reason":"interrupted"`) {
+		t.Fatalf("expected a final interrupted record:\n%s", out.String())
+	}
+}
+
+func TestDomainsWatchPurchaseHonorsMinPrice(t *testing.T) {
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", false, true)
+	err := runDomains(rt, []string{"watch", "example.com", "--purchase-on-available", "--confirm", "--min-price", "cheap"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation || !strings.Contains(ae.Message, "min-price") {
+		t.Fatalf("expected --min-price to be validated like domains purchase, got %v", err)
+	}
+}
+
+func TestDNSExportRoundTripsThroughApplyTemplate(t *testing.T) {
+	var putRecords string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/domains/example.com":
+			_, _ = w.Write([]byte(`{"nameServers":["ns1.example.net","ns2.example.net"]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/domains/example.com/records":
+			_, _ = w.Write([]byte(`[{"type":"A","name":"@","data":"192.0.2.1","ttl":600}]`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/v1/domains/example.com":
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/domains/example.com/records":
+			b, _ := io.ReadAll(r.Body)
+			putRecords = string(b)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	if err := runDNS(rt, []string{"export", "example.com", "--out", "-"}); err != nil {
+		t.Fatalf("export to stdout: %v", err)
+	}
+	var tmpl map[string]any
+	if err := json.Unmarshal(out.Bytes(), &tmpl); err != nil || tmpl["nameservers"] == nil || tmpl["records"] == nil {
+		t.Fatalf("expected a bare template on stdout, got %s (%v)", out.String(), err)
+	}
+
+	dir := t.TempDir()
+	zone := filepath.Join(dir, "zone.json")
+	out.Reset()
+	if err := runDNS(rt, []string{"export", "example.com", "--out", zone}); err != nil {
+		t.Fatalf("export to file: %v", err)
+	}
+	if !strings.Contains(out.String(), `"records":1`) {
+		t.Fatalf("expected export summary, got %s", out.String())
+	}
+	domains := filepath.Join(dir, "domains.txt")
+	if err := os.WriteFile(domains, []byte("example.com\n"), 0o600); err != nil {
+		t.Fatalf("write domains: %v", err)
+	}
+	if err := runDNS(rt, []string{"apply", "--template", zone, "--domains", domains}); err != nil {
+		t.Fatalf("apply exported template: %v", err)
+	}
+	if !strings.Contains(putRecords, `"data":"192.0.2.1"`) {
+		t.Fatalf("expected exported records to be restored, got %s", putRecords)
+	}
+
+	if err := runDNS(rt, []string{"export", "example.com", "--out", filepath.Join(dir, "zone.txt")}); err == nil {
+		t.Fatalf("expected non-.json --out to be rejected")
+	}
+}
+
+func TestDomainsContactsGetNormalizesV2AndV1(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/customers/cust-123/domains/example.com/contacts":
+			_, _ = w.Write([]byte(`{"contactAdmin":{"email":"admin@example.com"},"contactRegistrant":{"email":"owner@example.com"}}`))
+		case "/v1/domains/example.com":
+			_, _ = w.Write([]byte(`{"domain":"example.com","contactTech":{"email":"tech@example.com"},"status":"ACTIVE"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	contactsOf := func() map[string]any {
+		t.Helper()
+		var env map[string]any
+		if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+			t.Fatalf("decode envelope: %v", err)
+		}
+		result, _ := env["result"].(map[string]any)
+		contacts, _ := result["contacts"].(map[string]any)
+		return contacts
+	}
+
+	if err := runDomains(rt, []string{"contacts", "get", "example.com"}); err != nil {
+		t.Fatalf("contacts get via v1: %v", err)
+	}
+	if c := contactsOf(); len(c) != 1 || c["tech"] == nil {
+		t.Fatalf("expected v1 detail contacts by role, got %+v", c)
+	}
+
+	out.Reset()
+	rt.Cfg.CustomerID = "cust-123"
+	if err := runDomains(rt, []string{"contacts", "get", "example.com"}); err != nil {
+		t.Fatalf("contacts get via v2: %v", err)
+	}
+	if c := contactsOf(); len(c) != 2 || c["admin"] == nil || c["registrant"] == nil {
+		t.Fatalf("expected v2 contacts by role, got %+v", c)
+	}
+}
+
+func TestDomainsPurchaseBulkStopsAtCap(t *testing.T) {
+	purchases := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/domains/available":
+			d := r.URL.Query().Get("domain")
+			_, _ = w.Write([]byte(`{"domain":"` + d + `","available":true,"definitive":true,"price":12990000,"currency":"USD"}`))
+		case "/v1/domains/purchase":
+			purchases++
+			_, _ = w.Write([]byte(`{"price":12.99,"currency":"USD","order_id":"order-1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.AutoPurchaseEnabled = true
+	rt.Cfg.AcknowledgmentHash = "ack"
+	rt.Cfg.MaxDomainsPerDay = 1
+	file := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(file, []byte("a.com\nb.com\nc.com\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	rows := func() []map[string]any {
+		var env struct {
+			Result []map[string]any `json:"result"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+			t.Fatalf("decode envelope: %v", err)
+		}
+		return env.Result
+	}
+
+	err := runDomains(rt, []string{"purchase-bulk", file, "--auto"})
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial || ae.Details["skipped"] != 1 {
+		t.Fatalf("expected partial failure with one skipped row, got %v", err)
+	}
+	failedItems, _ := ae.Details["failed_items"].([]services.FailedItem)
+	if len(failedItems) != 2 || failedItems[0].Domain != "b.com" || failedItems[1].Domain != "c.com" || failedItems[1].Code != failedItems[0].Code {
+		t.Fatalf("expected the capped and skipped rows in failed_items, got %+v", ae.Details["failed_items"])
+	}
+	got := rows()
+	if len(got) != 3 || got[0]["success"] != true || got[1]["success"] != false || got[2]["skipped"] != true || purchases != 1 {
+		t.Fatalf("unexpected rows (purchases=%d): %+v", purchases, got)
+	}
+
+	out.Reset()
+	err = runDomains(rt, []string{"purchase-bulk", file, "--auto", "--continue-on-error"})
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial || ae.Details["failed"] != 2 || ae.Details["skipped"] != 0 {
+		t.Fatalf("expected every capped row attempted, got %v", err)
+	}
+	for _, row := range rows()[1:] {
+		if row["skipped"] != nil || !strings.Contains(row["error"].(string), "cap") {
+			t.Fatalf("expected cap error per row: %+v", row)
+		}
+	}
+	if purchases != 1 {
+		t.Fatalf("expected no further provider purchases, got %d", purchases)
+	}
+
+	if err := runDomains(rt, []string{"purchase-bulk", file, "--auto", "--confirm-each"}); err == nil {
+		t.Fatalf("expected --auto with --confirm-each to be rejected")
+	}
+}
+
+func TestDomainsPurchaseBulkSkipsRemainingAfterInterrupt(t *testing.T) {
+	quotes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		quotes++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"a.com","available":true,"definitive":true,"price":12990000,"currency":"USD"}`))
+	}))
+	defer srv.Close()
+
+	rt, out := testRuntime(t, srv.URL, true, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rt.Ctx = ctx
+	file := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(file, []byte("a.com\nb.com\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	err := runDomains(rt, []string{"purchase-bulk", file})
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial || ae.Details["skipped"] != 2 {
+		t.Fatalf("expected every row skipped as partial failure, got %v", err)
+	}
+	var env struct {
+		Result []map[string]any `json:"result"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if len(env.Result) != 2 || env.Result[0]["error"] != "skipped: interrupted" || quotes != 0 {
+		t.Fatalf("expected interrupted rows and no provider calls (quotes=%d): %+v", quotes, env.Result)
+	}
+
+	out.Reset()
+	err = runDomains(rt, []string{"renew-bulk", file, "--auto-approve"})
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial || ae.Details["skipped"] != 2 || ae.Details["failed"] != 0 {
+		t.Fatalf("expected renew-bulk to count interrupted rows as skipped, got %v", err)
+	}
+	if stopReason(context.Background()) != "" {
+		t.Fatalf("expected no stop reason for a live context")
+	}
+}
+
+func TestDomainsPurchaseAllowBelowFloorLeavesConfigAlone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v1/domains/available" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"domain":"example.com","available":true,"definitive":true,"price":0.99,"currency":"USD"}`))
+	}))
+	defer srv.Close()
+
+	rt, _ := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.MinPlausiblePrice = 5
+	if err := runDomains(rt, []string{"purchase", "example.com", "--allow-below-floor"}); err != nil {
+		t.Fatalf("quote below floor with override: %v", err)
+	}
+	if rt.Cfg.MinPlausiblePrice != 5 {
+		t.Fatalf("--allow-below-floor changed min_plausible_price to %v", rt.Cfg.MinPlausiblePrice)
+	}
+	if err := runDomains(rt, []string{"purchase", "example.com"}); err == nil {
+		t.Fatalf("expected the floor to apply again without the override")
+	}
+
+	// purchase-bulk takes the same floor flags, and --domains-inline in place of a file.
+	if err := runDomains(rt, []string{"purchase-bulk", "--domains-inline", "example.com", "--allow-below-floor"}); err != nil {
+		t.Fatalf("bulk quote below floor with override: %v", err)
+	}
+	if err := runDomains(rt, []string{"purchase-bulk", "--domains-inline", "example.com", "--min-price", "0.5"}); err != nil {
+		t.Fatalf("bulk quote above --min-price: %v", err)
+	}
+	var ae *apperr.AppError
+	if err := runDomains(rt, []string{"purchase-bulk", "--domains-inline", "example.com"}); !errors.As(err, &ae) || ae.Code != apperr.CodePartial {
+		t.Fatalf("expected the floor to fail the bulk row without the override, got %v", err)
+	}
+}
+
+func TestDomainsSuggestRejectsInvalidMinScore(t *testing.T) {
+	rt, _ := testRuntime(t, "http://127.0.0.1:1", true, false)
+	for _, v := range []string{"high", "-1", "NaN"} {
+		err := runDomains(rt, []string{"suggest", "coffee", "--min-score", v})
+		var ae *apperr.AppError
+		if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation || !strings.Contains(ae.Message, "--min-score") {
+			t.Fatalf("expected a usage error for --min-score %s, got %v", v, err)
+		}
+	}
+}
+
+func TestRegisterPurchaseApplyHonorsRequireOTEFirst(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	rt, _ := testRuntime(t, srv.URL, true, false)
+	rt.Cfg.CustomerID = "cust-123"
+	rt.Cfg.RequireOTEFirst = true
+	rt.APIEnvOverride = "prod"
+	args := []string{"register", "purchase", "--body-json", `{"domain":"example.com"}`, "--apply"}
+
+	err := runDomains(rt, args)
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeSafety || ae.Details["override"] != "--allow-prod" {
+		t.Fatalf("expected require_ote_first to refuse the registration, got %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no request to the provider, got %v", calls)
+	}
+
+	if err := runDomains(rt, []string{"register", "validate", "--body-json", `{"domain":"example.com"}`, "--apply"}); err != nil {
+		t.Fatalf("expected validation, which spends nothing, to pass: %v", err)
+	}
+	rt.AllowProd = true
+	if err := runDomains(rt, args); err != nil {
+		t.Fatalf("expected --allow-prod to let the registration through: %v", err)
+	}
+	if len(calls) != 2 || calls[1] != "POST /v2/customers/cust-123/domains/register" {
+		t.Fatalf("unexpected provider calls: %v", calls)
+	}
+}
+
+func TestNameserversSetNormalizesDomain(t *testing.T) {
+	rt, out := testRuntime(t, "http://127.0.0.1:1", true, false)
+	if err := runDomains(rt, []string{"nameservers", "set", " Example.COM. ", "--nameservers", "ns1.afternic.com,ns2.afternic.com"}); err != nil {
+		t.Fatalf("nameservers set dry run: %v", err)
+	}
+	var env map[string]any
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	result, _ := env["result"].(map[string]any)
+	plan, _ := result["plan"].(map[string]any)
+	if result["domain"] != "example.com" || plan["path"] != "/v2/customers/{customerId}/domains/example.com/nameServers" {
+		t.Fatalf("expected the normalized domain in the plan, got %+v", result)
+	}
+
+	err := runDomains(rt, []string{"nameservers", "set", "https://example.com/", "--nameservers", "ns1.afternic.com,ns2.afternic.com"})
+	var ae *apperr.AppError
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("expected a URL to be rejected, got %v", err)
+	}
+}
+
+func TestRecordFromFlagsRequiresMXAndSRVFields(t *testing.T) {
+	if _, err := recordFromFlags("add", map[string]string{"type": "mx", "name": "@", "data": "mx.a.com"}); apperr.CodeOf(err) != apperr.CodeValidation {
+		t.Fatalf("expected MX without --priority to be rejected, got %v", err)
+	}
+	if _, err := recordFromFlags("replace", map[string]string{"type": "SRV", "name": "_sip._tls", "data": "sip.a.com", "priority": "100", "weight": "1"}); err == nil || !strings.Contains(err.Error(), "--port") {
+		t.Fatalf("expected SRV without --port to be rejected, got %v", err)
+	}
+	if _, err := recordFromFlags("add", map[string]string{"type": "MX", "priority": "high"}); apperr.CodeOf(err) != apperr.CodeValidation {
+		t.Fatalf("expected a non-numeric --priority to be rejected, got %v", err)
+	}
+	rec, err := recordFromFlags("add", map[string]string{"type": "SRV", "name": "_sip._tls", "data": "sip.a.com", "priority": "100", "weight": "0", "port": "443"})
+	if err != nil || rec.Priority != 100 || rec.Weight != 0 || rec.Port != 443 {
+		t.Fatalf("expected SRV fields parsed, got %+v %v", rec, err)
+	}
+	if _, err := recordFromFlags("delete", map[string]string{"type": "MX", "name": "@", "data": "mx.a.com"}); err != nil {
+		t.Fatalf("expected delete to match MX by data alone: %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// commandDoc is the human-readable help for one command path. Usage is also the text of the
+// command's usage errors, so the two never drift apart.
+type commandDoc struct {
+	Path     string
+	Summary  string
+	Usage    string
+	Flags    [][2]string
+	Examples []string
+}
+
+// commandDocs lists every command path; group entries (init excepted) have no Usage of their
+// own and list their children instead.
+var commandDocs = []commandDoc{
+	{Path: "init", Summary: "Write config, caps, and identity, optionally store credentials and verify them",
+		Usage: "init [--api-environment prod|ote] [--max-price N] [--max-daily-spend N] [--max-domains-per-day N] [--max-monthly-spend N] [--confirm-token-ttl-minutes N] [--min-plausible-price N] [--max-concurrency N] [--retry-attempts N] [--retry-base-ms N] [--http-timeout-seconds N] [--update-notice stderr|off] [--update-channel stable|beta] [--require-ote-first true|false] [--shopper-id ID|$GDCLI_SHOPPER_ID --resolve-customer-id] [--enable-auto-purchase --ack \"I UNDERSTAND PURCHASES ARE FINAL\"] [--store-keychain|--store-file --api-key KEY --api-secret SECRET] [--verify [--deep]]",
+		Flags: [][2]string{
+			{"--api-environment prod|ote", "API environment to call"},
+			{"--max-price N", "per-domain price cap"},
+			{"--max-daily-spend N", "daily spend cap"},
+			{"--max-domains-per-day N", "daily purchase/renew count cap"},
+			{"--max-concurrency N", "highest --concurrency any command accepts (default 20)"},
+			{"--retry-attempts N", "attempts per API call, 1-10 (default 3)"},
+			{"--retry-base-ms N", "backoff before the first retry, doubling after (default 250)"},
+			{"--http-timeout-seconds N", "per-request timeout, > 0 (default 20)"},
+			{"--require-ote-first true|false", "refuse prod purchases and renewals unless --allow-prod is passed"},
+			{"--shopper-id ID", "shopper ID; add --resolve-customer-id to look up the v2 customer ID"},
+			{"--store-keychain", "store --api-key/--api-secret in the OS keychain"},
+			{"--store-file", "store --api-key/--api-secret encrypted with GDCLI_PASSPHRASE"},
+			{"--verify [--deep]", "check the credentials; --deep also checks orders and identity"},
+		},
+		Examples: []string{"gdcli init --api-environment ote --max-price 25 --verify"}},
+	{Path: "version", Summary: "Print the version, optionally checking for updates", Usage: "version [--check] [--update-timeout 3s]"},
+	{Path: "self-update", Summary: "Show or apply the latest release", Usage: "self-update [--apply] [--update-timeout 3s]"},
+
+	{Path: "domains", Summary: "Search, buy, renew, and manage domains"},
+	{Path: "domains suggest", Summary: "Suggest domain names for a query",
+		Usage: "domains suggest <query> [--tlds com,ai] [--limit N] [--min-score N] [--sort score|domain] [--available-only [--concurrency N]]",
+		Flags: [][2]string{
+			{"--min-score N", "drop suggestions scoring below N"},
+			{"--sort score|domain", "highest score first, or alphabetical"},
+			{"--available-only", "check each suggestion and drop taken names"},
+		}},
+	{Path: "domains bulk-suggest", Summary: "Suggest names for many seed keywords and merge the results",
+		Usage: "domains bulk-suggest <file> [--tlds com,ai] [--limit N] [--concurrency N]",
+		Flags: [][2]string{
+			{"--limit N", "suggestions per seed (default 20)"},
+			{"--concurrency N", "seeds queried in parallel (default 4)"},
+		}},
+	{Path: "domains discover", Summary: "Suggest from seed words and keep the buyable names",
+		Usage: "domains discover --seeds <file> [--tlds com,ai] [--available-only] [--max-price N] [--limit N] [--out FILE] [--suggest-concurrency N] [--check-concurrency N] [--batch-size N] [--definitive]",
+		Flags: [][2]string{
+			{"--available-only", "drop candidates that are not available"},
+			{"--batch-size N", "domains per FAST availability request (default 50)"},
+			{"--definitive", "re-check unsure FAST results with a FULL lookup each"},
+		}},
+	{Path: "domains tlds", Summary: "List supported TLDs, optionally with prices", Usage: "domains tlds [--tld ai,io] [--with-prices]"},
+	{Path: "domains agreements", Summary: "List the legal agreements required to register", Usage: "domains agreements --tlds com,ai [--privacy] [--for-transfer] [--with-text]"},
+	{Path: "domains avail", Summary: "Check whether a domain is available", Usage: "domains avail <domain>",
+		Examples: []string{"gdcli domains avail example.com"}},
+	{Path: "domains watch", Summary: "Poll a domain until it becomes available",
+		Usage: "domains watch <domain> [--interval 30s] [--timeout 24h] [--purchase-on-available --confirm [--years N] [--min-price N] [--allow-below-floor]]",
+		Flags: [][2]string{
+			{"--interval D", "time between polls"},
+			{"--timeout D", "give up after this long"},
+			{"--purchase-on-available --confirm", "buy with purchase --auto once available"},
+			{"--min-price N", "refuse quotes below this price"},
+			{"--allow-below-floor", "accept quotes below min_plausible_price"},
+		}},
+	{Path: "domains avail-bulk", Summary: "Check availability for a list of domains",
+		Usage: "domains avail-bulk <file>|--domains-inline a.com,b.com [--concurrency N] [--max-items N]",
+		Flags: [][2]string{
+			{"--domains-inline LIST", "comma list instead of a file"},
+			{"--concurrency N", "parallel lookups (default 10)"},
+			{"--max-items N", "override max_bulk_items"},
+		},
+		Examples: []string{"gdcli domains avail-bulk domains.txt --ndjson", "gdcli domains avail-bulk --domains-inline a.com,b.com"}},
+	{Path: "domains purchase", Summary: "Quote a domain, then buy it with the confirmation token or --auto",
+		Usage: "domains purchase <domain> [--years N] [--confirm TOKEN|--auto] [--min-price N] [--allow-below-floor]",
+		Flags: [][2]string{
+			{"--years N", "registration period in years (default 1; with --confirm, the token's)"},
+			{"--confirm TOKEN", "buy using the token from a previous quote"},
+			{"--auto", "buy without a token; needs auto-purchase enabled"},
+			{"--min-price N", "refuse quotes below this price"},
+			{"--allow-below-floor", "accept quotes below min_plausible_price"},
+		},
+		Examples: []string{"gdcli domains purchase example.com", "gdcli domains purchase example.com --confirm <token>"}},
+	{Path: "domains purchase-bulk", Summary: "Quote or buy a list of domains",
+		Usage: "domains purchase-bulk <file>|--domains-inline a.com,b.com [--years N] [--auto|--confirm-each] [--continue-on-error] [--min-price N] [--allow-below-floor]",
+		Flags: [][2]string{
+			{"--domains-inline LIST", "comma list instead of a file"},
+			{"--auto", "buy each domain under the auto-purchase rules"},
+			{"--confirm-each", "quote each domain and issue a token (default)"},
+			{"--continue-on-error", "keep going after a spend cap is hit"},
+			{"--min-price N", "refuse quotes below this price"},
+			{"--allow-below-floor", "accept quotes below min_plausible_price"},
+		}},
+	{Path: "domains renew", Summary: "Quote or apply a renewal",
+		Usage: "domains renew <domain> --years <n> [--dry-run|--confirm <token>|--auto-approve]",
+		Flags: [][2]string{
+			{"--years N", "renewal period in years (with --confirm, the token's)"},
+			{"--confirm TOKEN", "renew using the token from a previous quote"},
+			{"--auto-approve", "renew without a token"},
+		}},
+	{Path: "domains renew-bulk", Summary: "Renew a list of domains",
+		Usage: "domains renew-bulk <file>|--domains-inline a.com,b.com --years N [--dry-run] [--auto-approve]"},
+	{Path: "domains list", Summary: "List domains in the account", Usage: "domains list [--expiring-in N] [--tld TLD] [--contains TEXT] [--with-nameservers] [--concurrency N]"},
+	{Path: "domains portfolio", Summary: "List domains with expiry and renewal details", Usage: "domains portfolio [--expiring-in N] [--tld TLD] [--contains TEXT] [--concurrency N]"},
+	{Path: "domains detail", Summary: "Show v2 domain detail", Usage: "domains detail <domain> [--includes a,b,c]"},
+	{Path: "domains actions", Summary: "List recent actions on a domain", Usage: "domains actions <domain> [--type <actionType>]"},
+	{Path: "domains change-of-registrant", Summary: "Show the pending change of registrant", Usage: "domains change-of-registrant <domain>"},
+	{Path: "domains auth-code", Summary: "Regenerate the transfer auth code", Usage: "domains auth-code regenerate <domain> [--apply]"},
+	{Path: "domains usage", Summary: "Show API usage for a month", Usage: "domains usage <yyyymm>"},
+	{Path: "domains maintenances", Summary: "List upcoming maintenance windows", Usage: "domains maintenances [--id MAINTENANCE_ID]"},
+	{Path: "domains notifications", Summary: "Read, opt into, and acknowledge notifications", Usage: "domains notifications <next|optin|schema|ack>"},
+	{Path: "domains contacts", Summary: "Read or replace domain contacts", Usage: "domains contacts <get <domain> | set <domain> --body-json '<json>' [--apply]>"},
+	{Path: "domains nameservers", Summary: "Replace a domain's nameservers", Usage: "domains nameservers set <domain> --nameservers ns1,ns2 [--apply]"},
+	{Path: "domains lock", Summary: "Read or change the transfer lock", Usage: "domains lock <get|set> <domain> [--enabled true|false] [--apply]"},
+	{Path: "domains records", Summary: "List and edit DNS records", Usage: "domains records <list|add|delete|replace> <domain> [--type T --name N --data D [--ttl N] [--priority N] [--weight N --port N]] [--apply]",
+		Flags: [][2]string{
+			{"--priority N", "MX and SRV priority; required to add or replace them"},
+			{"--weight N", "SRV weight; required to add or replace SRV"},
+			{"--port N", "SRV target port; required to add or replace SRV"},
+		}},
+	{Path: "domains dnssec", Summary: "Add DNSSEC records", Usage: "domains dnssec add <domain> --body-json '<json>' [--apply]"},
+	{Path: "domains forwarding", Summary: "Read or set domain forwarding", Usage: "domains forwarding <get|create|update> <fqdn> [--body-json '<json>'] [--apply]"},
+	{Path: "domains privacy-forwarding", Summary: "Read or set privacy email forwarding", Usage: "domains privacy-forwarding <get|set> <domain> [--body-json '<json>'] [--apply]"},
+	{Path: "domains register", Summary: "Register with a full v2 request body", Usage: "domains register <schema|validate|purchase> ..."},
+	{Path: "domains transfer", Summary: "Inspect and drive domain transfers",
+		Usage: "domains transfer <status|watch|validate|start|in-accept|in-cancel|in-restart|in-retry|out|out-accept|out-reject> <domain> [--body-json '<json>'] [--apply] [--force]"},
+	{Path: "domains redeem", Summary: "Redeem an expired domain", Usage: "domains redeem <domain> [--body-json '<json>'] [--apply]"},
+	{Path: "domains plan", Summary: "Replay a saved dry-run plan", Usage: "domains plan --plan-file <file> [--apply]"},
+
+	{Path: "account", Summary: "Orders, subscriptions, identity, and the local operations log"},
+	{Path: "account orders", Summary: "List orders",
+		Usage: "account orders list [--limit N] [--offset N] [--all [--max-pages N]] [--since DATE] [--min-total USD] [--sort created_at|total]"},
+	{Path: "account subscriptions", Summary: "List, read, and change subscriptions",
+		Usage: "account subscriptions list [--limit N] [--offset N] [--all [--max-pages N]] | <get|set-auto-renew> <subscription-id> [--enabled true|false] [--apply]"},
+	{Path: "account operations", Summary: "List the local operations log", Usage: "account operations [--status pending|succeeded|failed] [--type purchase|renew] [--domain D] [--since DATE]"},
+	{Path: "account operations resolve", Summary: "Settle a pending operation after checking account orders",
+		Usage: "account operations resolve <operation-key> --status succeeded|failed [--order-id ID]",
+		Flags: [][2]string{
+			{"--status S", "succeeded if the order went through, failed to release its reservation"},
+			{"--order-id ID", "the order account orders shows for it"},
+		}},
+	{Path: "account whoami", Summary: "Check the credentials and show the configured identity", Usage: "account whoami"},
+	{Path: "account identity", Summary: "Show, set, or resolve shopper and customer IDs", Usage: "account identity <show|set|resolve|whoami>"},
+
+	{Path: "dns", Summary: "Audit and template DNS across many domains"},
+	{Path: "dns audit", Summary: "Check domains against built-in checks or your own rules",
+		Usage:    "dns audit --domains <file>|--domains-inline a.com,b.com [--checks afternic,txt,a,spf,dmarc,caa,mx | --rules rules.json] [--concurrency N]",
+		Examples: []string{"gdcli dns audit --domains portfolio.txt --checks spf,dmarc"}},
+	{Path: "dns apply", Summary: "Write a DNS template to many domains",
+		Usage:    "dns apply --template <t> --domains <file>|--domains-inline a.com,b.com [--var key=value ...] [--dry-run] [--only-changed] [--concurrency N]",
+		Examples: []string{"gdcli dns apply --template parking --domains portfolio.txt --dry-run"}},
+	{Path: "dns export", Summary: "Save a domain's DNS as a reusable template", Usage: "dns export <domain> --out <file.json|->"},
+
+	{Path: "settings", Summary: "Caps, auto-purchase, profiles, tokens, and local state"},
+	{Path: "settings auto-purchase", Summary: "Enable or disable auto-purchase", Usage: "settings auto-purchase <enable|disable>"},
+	{Path: "settings caps", Summary: "Change spend caps",
+		Usage: "settings caps set [--max-price <usd>] [--max-daily-spend <usd>] [--max-domains-per-day <n>] [--max-monthly-spend <usd>] [--max-price-tld <.tld=usd> ...] [--confirm-token-ttl-minutes <n>]"},
+	{Path: "settings show", Summary: "Show the effective settings", Usage: "settings show"},
+	{Path: "settings budget", Summary: "Show today's and this month's spend against the caps", Usage: "settings budget"},
+	{Path: "settings reset", Summary: "Restore default settings", Usage: "settings reset --confirm [--all]"},
+	{Path: "settings prune-operations", Summary: "Drop old entries from the operations log", Usage: "settings prune-operations --older-than <age, e.g. 90d> [--dry-run]"},
+	{Path: "settings profile", Summary: "Manage named profiles", Usage: "settings profile <list|use <name>|add <name> [--api-environment prod|ote]|remove <name>>"},
+	{Path: "settings tokens", Summary: "List or revoke purchase confirmation tokens", Usage: "settings tokens <list [--full]|revoke <token-id|--all> [--full]>"},
+}
+
+// globalFlagDocs is shown in the top-level help.
+var globalFlagDocs = [][2]string{
+	{"--json", "single JSON envelope (the default)"},
+	{"--ndjson", "one JSON envelope per record"},
+	{"--csv", "CSV for list-style results"},
+	{"--pretty", "indent the JSON envelope"},
+	{"--fields a,b.c", "keep only these result fields"},
+	{"--quiet", "suppress warnings and notices"},
+	{"--config PATH", "config file to use"},
+	{"--profile NAME", "profile to use for this run"},
+	{"--api-environment prod|ote", "API environment for this run, without changing config"},
+	{"--allow-prod", "let purchases and renewals reach prod despite require_ote_first"},
+	{"--no-retry", "make each API call once; fail fast instead of retrying"},
+	{"--dry-run", "before the command: send no API writes; v2 writes echo the request instead"},
+	{"--timeout D", "per-request HTTP timeout"},
+	{"--deadline D", "stop the whole run after D; Ctrl-C also stops cleanly"},
+	{"--proxy URL", "proxy for API requests"},
+	{"--color auto|always|never", "color stderr messages"},
+	{"-v, -vv", "log API requests to stderr"},
+}
+
+// usageOf returns the usage line for a command path, for usage errors.
+func usageOf(path string) string {
+	if d, ok := findDoc(path); ok {
+		return d.Usage
+	}
+	return path
+}
+
+func findDoc(path string) (commandDoc, bool) {
+	for _, d := range commandDocs {
+		if d.Path == path {
+			return d, true
+		}
+	}
+	return commandDoc{}, false
+}
+
+// helpRequest reports whether args ask for help and which command path they name: "help" as
+// the first word, "help" right after a command group, or --help/-h anywhere. The path is the
+// longest known command built from the leading words; "" means the top-level overview.
+func helpRequest(args []string) (string, bool) {
+	asked := false
+	var words []string
+	for i, a := range args {
+		switch {
+		case a == "--help" || a == "-h":
+			asked = true
+		case a == "help" && (i == 0 || isGroupPath(strings.Join(words, " "))):
+			asked = true
+		case !strings.HasPrefix(a, "-"):
+			words = append(words, a)
+		}
+		if asked {
+			break
+		}
+	}
+	if !asked {
+		return "", false
+	}
+	path := ""
+	for n := 1; n <= len(words); n++ {
+		candidate := strings.Join(words[:n], " ")
+		if _, ok := findDoc(candidate); !ok {
+			break
+		}
+		path = candidate
+	}
+	return path, true
+}
+
+func isGroupPath(path string) bool {
+	d, ok := findDoc(path)
+	return ok && d.Usage == ""
+}
+
+// writeHelp prints the human-readable help for path ("" for the top level).
+func writeHelp(w io.Writer, path string) {
+	if path == "" {
+		fmt.Fprintln(w, "Usage: gdcli [global flags] <command> [args]")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Commands:")
+		writeChildren(w, "")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Global flags:")
+		writeFlags(w, globalFlagDocs)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, `Run "gdcli <command> --help" for details, or add --json for machine-readable help.`)
+		return
+	}
+	d, _ := findDoc(path)
+	if d.Usage == "" {
+		fmt.Fprintf(w, "Usage: gdcli %s <command> [args]\n\n%s\n\nCommands:\n", path, d.Summary)
+		writeChildren(w, path)
+		fmt.Fprintf(w, "\nRun \"gdcli %s <command> --help\" for details.\n", path)
+		return
+	}
+	fmt.Fprintf(w, "Usage: gdcli %s\n\n%s\n", d.Usage, d.Summary)
+	if len(d.Flags) > 0 {
+		fmt.Fprintln(w, "\nFlags:")
+		writeFlags(w, d.Flags)
+	}
+	if len(d.Examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, e := range d.Examples {
+			fmt.Fprintf(w, "  %s\n", e)
+		}
+	}
+}
+
+// writeChildren lists the commands one level below parent.
+func writeChildren(w io.Writer, parent string) {
+	prefix := parent + " "
+	if parent == "" {
+		prefix = ""
+	}
+	var rows [][2]string
+	for _, d := range commandDocs {
+		rest, ok := strings.CutPrefix(d.Path, prefix)
+		if !ok || rest == "" || strings.Contains(rest, " ") {
+			continue
+		}
+		rows = append(rows, [2]string{rest, d.Summary})
+	}
+	writeFlags(w, rows)
+}
+
+func writeFlags(w io.Writer, rows [][2]string) {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r[0]))
+	}
+	for _, r := range rows {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, r[0], r[1])
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHelpRequestFindsCommandPath(t *testing.T) {
+	cases := []struct {
+		args   []string
+		path   string
+		wanted bool
+	}{
+		{[]string{"--help"}, "", true},
+		{[]string{"help"}, "", true},
+		{[]string{"domains", "help"}, "domains", true},
+		{[]string{"domains", "purchase", "--help"}, "domains purchase", true},
+		{[]string{"domains", "purchase", "example.com", "--years", "2", "-h"}, "domains purchase", true},
+		{[]string{"domains", "avail", "help"}, "", false},
+		{[]string{"domains", "purchase", "example.com"}, "", false},
+	}
+	for _, c := range cases {
+		path, ok := helpRequest(c.args)
+		if ok != c.wanted || path != c.path {
+			t.Fatalf("helpRequest(%v) = %q, %v; want %q, %v", c.args, path, ok, c.path, c.wanted)
+		}
+	}
+}
+
+func TestWriteHelpShowsCommandFlags(t *testing.T) {
+	var buf bytes.Buffer
+	writeHelp(&buf, "domains purchase")
+	for _, want := range []string{"Usage: gdcli domains purchase <domain>", "--years N", "--confirm TOKEN", "--auto", "Examples:"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected %q in help:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	writeHelp(&buf, "domains")
+	if !strings.Contains(buf.String(), "avail-bulk") || strings.Contains(buf.String(), "dns audit") {
+		t.Fatalf("expected only domains subcommands:\n%s", buf.String())
+	}
+}
+
+func TestHelpAsTextOnlyOnTerminal(t *testing.T) {
+	if helpAsText(false, &bytes.Buffer{}) {
+		t.Fatalf("expected piped help to be an envelope")
+	}
+	if helpAsText(true, os.Stdout) {
+		t.Fatalf("expected --json/--ndjson/--csv help to be an envelope")
+	}
+}
+
+func TestUsageOfReferencesKnownCommands(t *testing.T) {
+	src, err := os.ReadFile("cmd.go")
+	if err != nil {
+		t.Fatalf("read cmd.go: %v", err)
+	}
+	for _, m := range regexp.MustCompile(`usageOf\("([^"]+)"\)`).FindAllStringSubmatch(string(src), -1) {
+		if d, ok := findDoc(m[1]); !ok || d.Usage == "" {
+			t.Fatalf("usageOf(%q) has no usage in commandDocs", m[1])
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// faults makes the mock slow or failing on demand so clients can exercise timeouts, retries,
+// Retry-After handling, and error classification. The zero value injects nothing.
+type faults struct {
+	// rate is the chance, 0-1, that an eligible request fails.
+	rate float64
+	// first fails this many eligible requests before any succeed.
+	first int
+	// statusFor limits failures to these paths and picks their status; other paths fail with
+	// 503 when it is empty.
+	statusFor statusFlag
+	// retryAfter, when positive, is sent as Retry-After (seconds) on injected 429s.
+	retryAfter int
+	// latency delays every response; latencyFor overrides it per path.
+	latency    time.Duration
+	latencyFor latencyFlag
+
+	mu   sync.Mutex
+	seen int
+}
+
+// statusFlag is a repeatable --status-for PATH=STATUS flag.
+type statusFlag map[string]int
+
+func (f *statusFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(*f))
+	for p, code := range *f {
+		parts = append(parts, p+"="+strconv.Itoa(code))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (f *statusFlag) Set(v string) error {
+	path, code, ok := strings.Cut(v, "=")
+	n, err := strconv.Atoi(strings.TrimSpace(code))
+	if !ok || !strings.HasPrefix(path, "/") || err != nil || n < 400 || n > 599 {
+		return fmt.Errorf("want PATH=STATUS with a 4xx/5xx status, got %q", v)
+	}
+	if *f == nil {
+		*f = statusFlag{}
+	}
+	(*f)[path] = n
+	return nil
+}
+
+// latencyFlag is a repeatable --latency-for PATH=DURATION flag.
+type latencyFlag map[string]time.Duration
+
+func (f *latencyFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(*f))
+	for p, d := range *f {
+		parts = append(parts, p+"="+d.String())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (f *latencyFlag) Set(v string) error {
+	path, dur, ok := strings.Cut(v, "=")
+	d, err := time.ParseDuration(strings.TrimSpace(dur))
+	if !ok || !strings.HasPrefix(path, "/") || err != nil || d < 0 {
+		return fmt.Errorf("want PATH=DURATION, got %q", v)
+	}
+	if *f == nil {
+		*f = latencyFlag{}
+	}
+	(*f)[path] = d
+	return nil
+}
+
+func (f *faults) enabled() bool {
+	return f.rate > 0 || f.first > 0 || len(f.statusFor) > 0 || f.latency > 0 || len(f.latencyFor) > 0
+}
+
+// delay waits out the latency for r. It returns false if the client gave up first, in which
+// case there is no one left to answer.
+func (f *faults) delay(r *http.Request) bool {
+	d := f.latency
+	if pd, ok := f.latencyFor[r.URL.Path]; ok {
+		d = pd
+	}
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// status returns the status to fail r with, or 0 to serve it normally.
+func (f *faults) status(r *http.Request) int {
+	code := http.StatusServiceUnavailable
+	if len(f.statusFor) > 0 {
+		c, ok := f.statusFor[r.URL.Path]
+		if !ok {
+			return 0
+		}
+		code = c
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seen++
+	switch {
+	case f.seen <= f.first:
+	case f.rate > 0 && rand.Float64() < f.rate: // #nosec G404 -- fault injection needs no crypto randomness
+	case len(f.statusFor) > 0 && f.first == 0 && f.rate == 0:
+		// --status-for alone fails its paths every time.
+	default:
+		return 0
+	}
+	return code
+}
+
+// wrap delays each request by its latency, then serves next unless the request is picked to
+// fail.
+func (f *faults) wrap(next http.Handler) http.Handler {
+	if !f.enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !f.delay(r) {
+			return
+		}
+		code := f.status(r)
+		if code == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if code == http.StatusTooManyRequests && f.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(f.retryAfter))
+		}
+		writeJSON(w, code, map[string]any{"code": "INJECTED_FAULT", "message": "injected by mock-godaddy"})
+	})
+}
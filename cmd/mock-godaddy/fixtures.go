@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// fixtures is the --fixtures file, and the --state-file format. Each section that is present
+// replaces the built-in default for it; sections left out keep the defaults.
+type fixtures struct {
+	Portfolio    []portfolioDomain         `json:"portfolio"`
+	Availability map[string]availability   `json:"availability"`
+	Nameservers  map[string][]string       `json:"nameservers"`
+	Records      map[string][]dnsRecord    `json:"records"`
+	Orders       []mockOrder               `json:"orders"`
+	Subs         []mockSubscription        `json:"subscriptions"`
+	Locked       map[string]bool           `json:"locked"`
+	RenewAuto    map[string]bool           `json:"renew_auto"`
+	Contacts     map[string]map[string]any `json:"contacts"`
+
+	// Written by --state-file so a restart picks up where the last run left off.
+	OrderCounter      int                         `json:"order_counter,omitempty"`
+	Forwards          map[string]map[string]any   `json:"forwards,omitempty"`
+	PrivacyForwarding map[string]map[string]any   `json:"privacy_forwarding,omitempty"`
+	Actions           map[string][]map[string]any `json:"actions,omitempty"`
+	OptIn             []string                    `json:"opt_in,omitempty"`
+}
+
+// loadState returns the built-in state, overlaid with the fixtures file at path if one is
+// given. Unknown keys are an error so a typo does not silently leave a default in place.
+func loadState(path string) (*state, error) {
+	s := newState()
+	if strings.TrimSpace(path) == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- the fixtures path is chosen by whoever runs the mock.
+	if err != nil {
+		return nil, err
+	}
+	var fx fixtures
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fx); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if fx.Portfolio != nil {
+		s.portfolio = fx.Portfolio
+	}
+	if fx.Availability != nil {
+		s.availability = map[string]availability{}
+		for d, a := range fx.Availability {
+			d = strings.ToLower(strings.TrimSpace(d))
+			a.Domain = d
+			if a.Currency == "" {
+				a.Currency = "USD"
+			}
+			s.availability[d] = a
+		}
+	}
+	if fx.Nameservers != nil {
+		s.nameservers = fx.Nameservers
+	}
+	if fx.Records != nil {
+		s.records = fx.Records
+	}
+	if fx.Orders != nil {
+		s.orders = fx.Orders
+	}
+	if fx.Subs != nil {
+		s.subs = fx.Subs
+	}
+	if fx.Locked != nil {
+		s.locked = fx.Locked
+	}
+	if fx.RenewAuto != nil {
+		s.renewAuto = fx.RenewAuto
+	}
+	if fx.Contacts != nil {
+		s.contacts = fx.Contacts
+	}
+	s.orderCounter = max(s.orderCounter, fx.OrderCounter)
+	if fx.Forwards != nil {
+		s.forwards = fx.Forwards
+	}
+	if fx.PrivacyForwarding != nil {
+		s.privacyForwarding = fx.PrivacyForwarding
+	}
+	if fx.Actions != nil {
+		s.actions = fx.Actions
+	}
+	if fx.OptIn != nil {
+		s.optIn = fx.OptIn
+	}
+	return s, nil
+}
+
+// snapshot returns every section of s in fixtures form. The caller holds s.mu.
+func (s *state) snapshot() fixtures {
+	return fixtures{
+		Portfolio:         s.portfolio,
+		Availability:      s.availability,
+		Nameservers:       s.nameservers,
+		Records:           s.records,
+		Orders:            s.orders,
+		Subs:              s.subs,
+		Locked:            s.locked,
+		RenewAuto:         s.renewAuto,
+		Contacts:          s.contacts,
+		OrderCounter:      s.orderCounter,
+		Forwards:          s.forwards,
+		PrivacyForwarding: s.privacyForwarding,
+		Actions:           s.actions,
+		OptIn:             s.optIn,
+	}
+}
@@ -19,10 +19,11 @@ type suggestion struct {
 }
 
 type availability struct {
-	Domain    string  `json:"domain"`
-	Available bool    `json:"available"`
-	Price     float64 `json:"price"`
-	Currency  string  `json:"currency"`
+	Domain     string  `json:"domain"`
+	Available  bool    `json:"available"`
+	Definitive bool    `json:"definitive"`
+	Price      float64 `json:"price"`
+	Currency   string  `json:"currency"`
 }
 
 type purchaseResult struct {
@@ -90,15 +91,95 @@ type state struct {
 	orders       []mockOrder
 	subs         []mockSubscription
 	orderCounter int
+	// The rest backs the v2 routes (and v1 domain detail).
+	locked            map[string]bool
+	renewAuto         map[string]bool
+	contacts          map[string]map[string]any
+	privacyForwarding map[string]map[string]any
+	forwards          map[string]map[string]any
+	actions           map[string][]map[string]any
+	optIn             []string
+	// idempotent maps "<scope>|<X-Idempotency-Key>" to the first response for that key,
+	// mirroring GoDaddy replaying the original order on a retried request.
+	idempotent map[string]any
+
+	// stateFile, when set, receives the whole state after every write; saveMu orders saves.
+	stateFile string
+	saveMu    sync.Mutex
 }
 
 const maxRequestBodyBytes = int64(1 << 20)
 
 func main() {
 	listen := flag.String("listen", defaultListenAddr(), "listen address for mock server")
+	fixturesPath := flag.String("fixtures", "", "JSON file with the initial portfolio, availability, DNS, orders, and subscriptions")
+	stateFile := flag.String("state-file", "", "JSON file to load state from on start and save it to after each write")
+	recordDir := flag.String("record", "", "proxy to --upstream and save each exchange to this directory")
+	upstream := flag.String("upstream", "https://api.ote-godaddy.com", "real API that --record proxies to")
+	replayDir := flag.String("replay", "", "answer from the recordings in this directory instead of the built-in mock")
+	f := &faults{}
+	flag.Float64Var(&f.rate, "fail-rate", 0, "fraction of requests, 0-1, answered with an injected error")
+	flag.IntVar(&f.first, "fail-first", 0, "fail this many requests before serving normally")
+	flag.Var(&f.statusFor, "status-for", "PATH=STATUS: fail only this path, with this status (repeatable)")
+	flag.IntVar(&f.retryAfter, "retry-after", 0, "Retry-After seconds sent with injected 429s")
+	flag.DurationVar(&f.latency, "latency", 0, "delay before every response, e.g. 500ms")
+	flag.Var(&f.latencyFor, "latency-for", "PATH=DURATION: delay for this path instead of --latency (repeatable)")
 	flag.Parse()
+	if f.rate < 0 || f.rate > 1 {
+		log.Fatalf("--fail-rate must be between 0 and 1, got %v", f.rate)
+	}
+
+	var handler http.Handler
+	switch {
+	case *recordDir != "" && *replayDir != "":
+		log.Fatal("--record and --replay cannot be combined")
+	case *recordDir != "":
+		rec, err := newRecorder(*upstream, *recordDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("recording %s into %s", *upstream, *recordDir)
+		handler = rec
+	case *replayDir != "":
+		rp, err := newReplayer(*replayDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handler = rp
+	default:
+		st, err := openState(*fixturesPath, *stateFile)
+		if err != nil {
+			log.Fatalf("loading state: %v", err)
+		}
+		handler = st.persistWrites(st.routes())
+	}
+	addr := *listen
+	log.Printf("mock godaddy listening on %s", addr)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           f.wrap(handler),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if err := srv.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	s := &state{
+// newState returns the mock's seeded portfolio, availability, DNS, orders, subscriptions, and
+// v2 domain settings.
+func newState() *state {
+	return &state{
+		idempotent: map[string]any{},
+		locked:     map[string]bool{"alpha.com": true, "brand.ai": false},
+		renewAuto:  map[string]bool{"alpha.com": true, "brand.ai": true},
+		contacts: map[string]map[string]any{
+			"alpha.com": {"contactRegistrant": demoContact(), "contactAdmin": demoContact(), "contactTech": demoContact(), "contactBilling": demoContact()},
+			"brand.ai":  {"contactRegistrant": demoContact(), "contactAdmin": demoContact(), "contactTech": demoContact(), "contactBilling": demoContact()},
+		},
+		privacyForwarding: map[string]map[string]any{},
+		forwards:          map[string]map[string]any{},
+		actions:           map[string][]map[string]any{},
+		optIn:             []string{},
 		portfolio: []portfolioDomain{
 			{Domain: "alpha.com", Expires: "2026-12-31"},
 			{Domain: "brand.ai", Expires: "2026-03-20"},
@@ -146,26 +227,22 @@ func main() {
 			}(),
 		},
 	}
+}
 
+// routes maps the mocked GoDaddy endpoints onto s.
+func (s *state) routes() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/v1/domains/suggest", s.handleSuggest)
 	mux.HandleFunc("/v1/domains/available", s.handleAvailable)
 	mux.HandleFunc("/v1/domains/purchase", s.handlePurchase)
+	mux.HandleFunc("/v1/domains/tlds", s.handleTLDs)
 	mux.HandleFunc("/v1/domains", s.handleDomains)
 	mux.HandleFunc("/v1/domains/", s.handleDomainSub)
 	mux.HandleFunc("/v1/orders", s.handleOrders)
 	mux.HandleFunc("/v1/subscriptions", s.handleSubscriptions)
-
-	addr := *listen
-	log.Printf("mock godaddy listening on %s", addr)
-	srv := &http.Server{
-		Addr:              addr,
-		Handler:           mux,
-		ReadHeaderTimeout: 5 * time.Second,
-	}
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatal(err)
-	}
+	mux.HandleFunc("/v1/shoppers/", s.handleShopper)
+	mux.HandleFunc("/v2/customers/", s.handleV2)
+	return mux
 }
 
 func defaultListenAddr() string {
@@ -190,6 +267,19 @@ func writeDecodeErr(w http.ResponseWriter, err error) {
 	writeJSON(w, http.StatusBadRequest, map[string]any{"message": "invalid json"})
 }
 
+func (s *state) handleTLDs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, []map[string]any{
+		{"name": "com", "type": "GENERIC"},
+		{"name": "net", "type": "GENERIC"},
+		{"name": "ai", "type": "COUNTRY_CODE"},
+		{"name": "io", "type": "COUNTRY_CODE"},
+	})
+}
+
 func (s *state) handleOrders(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
@@ -294,11 +384,13 @@ func (s *state) handleAvailable(w http.ResponseWriter, r *http.Request) {
 			writeJSON(w, http.StatusBadRequest, map[string]any{"message": "domain required"})
 			return
 		}
+		// Single lookups model checkType=FULL, which is always definitive.
 		if a, ok := s.availability[domain]; ok {
+			a.Definitive = true
 			writeJSON(w, http.StatusOK, a)
 			return
 		}
-		writeJSON(w, http.StatusOK, availability{Domain: domain, Available: true, Price: 12.99, Currency: "USD"})
+		writeJSON(w, http.StatusOK, availability{Domain: domain, Available: true, Definitive: true, Price: 12.99, Currency: "USD"})
 	case http.MethodPost:
 		var req struct {
 			Domains []string `json:"domains"`
@@ -341,12 +433,42 @@ func (s *state) handlePurchase(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	d := strings.ToLower(strings.TrimSpace(req.Domain))
+	// A replay answers with the original order even if the domain has since been taken.
+	key := idempotencyScope(r, "purchase:"+d)
+	echoIdempotencyKey(w, r)
+	if prev, ok := s.idempotent[key]; ok && key != "" {
+		w.Header().Set("X-Idempotent-Replay", "true")
+		writeJSON(w, http.StatusOK, prev)
+		return
+	}
 	if a, ok := s.availability[d]; ok && !a.Available {
 		writeJSON(w, http.StatusConflict, map[string]any{"message": "domain not available"})
 		return
 	}
 	s.orderCounter++
-	writeJSON(w, http.StatusOK, purchaseResult{Domain: d, Price: 12.99 * float64(req.Period), Currency: "USD", OrderID: "mock-order-" + strconv.Itoa(s.orderCounter)})
+	res := purchaseResult{Domain: d, Price: 12.99 * float64(req.Period), Currency: "USD", OrderID: "mock-order-" + strconv.Itoa(s.orderCounter)}
+	if key != "" {
+		s.idempotent[key] = res
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// idempotencyScope returns the replay-cache key for a request, or "" when the
+// client did not send X-Idempotency-Key.
+func idempotencyScope(r *http.Request, scope string) string {
+	key := strings.TrimSpace(r.Header.Get("X-Idempotency-Key"))
+	if key == "" {
+		return ""
+	}
+	return scope + "|" + key
+}
+
+// echoIdempotencyKey returns the request's X-Idempotency-Key on the response, so a test can
+// confirm which key the CLI sent.
+func echoIdempotencyKey(w http.ResponseWriter, r *http.Request) {
+	if key := strings.TrimSpace(r.Header.Get("X-Idempotency-Key")); key != "" {
+		w.Header().Set("X-Idempotency-Key", key)
+	}
 }
 
 func (s *state) handleDomains(w http.ResponseWriter, r *http.Request) {
@@ -378,20 +500,22 @@ func (s *state) handleDomainSub(w http.ResponseWriter, r *http.Request) {
 	if len(parts) == 1 {
 		switch r.Method {
 		case http.MethodGet:
-			ns := s.nameservers[domain]
-			if len(ns) == 0 {
-				ns = []string{"ns1.notafternic.com", "ns2.notafternic.com"}
-			}
-			writeJSON(w, http.StatusOK, map[string]any{"nameServers": ns})
+			writeJSON(w, http.StatusOK, s.domainDetail(domain))
 		case http.MethodPatch:
 			var req struct {
 				NameServers []string `json:"nameServers"`
+				Locked      *bool    `json:"locked"`
 			}
 			if err := decodeJSONBody(w, r, &req); err != nil {
 				writeDecodeErr(w, err)
 				return
 			}
-			s.nameservers[domain] = req.NameServers
+			if req.NameServers != nil {
+				s.nameservers[domain] = req.NameServers
+			}
+			if req.Locked != nil {
+				s.locked[domain] = *req.Locked
+			}
 			writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 		default:
 			writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
@@ -404,8 +528,19 @@ func (s *state) handleDomainSub(w http.ResponseWriter, r *http.Request) {
 			writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
 			return
 		}
+		key := idempotencyScope(r, "renew:"+domain)
+		echoIdempotencyKey(w, r)
+		if prev, ok := s.idempotent[key]; ok && key != "" {
+			w.Header().Set("X-Idempotent-Replay", "true")
+			writeJSON(w, http.StatusOK, prev)
+			return
+		}
 		s.orderCounter++
-		writeJSON(w, http.StatusOK, renewResult{Domain: domain, Price: 12.99, Currency: "USD", OrderID: "mock-renew-" + strconv.Itoa(s.orderCounter)})
+		res := renewResult{Domain: domain, Price: 12.99, Currency: "USD", OrderID: "mock-renew-" + strconv.Itoa(s.orderCounter)}
+		if key != "" {
+			s.idempotent[key] = res
+		}
+		writeJSON(w, http.StatusOK, res)
 		return
 	}
 
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/sportwhiz/gdcli/internal/godaddy"
 )
 
 func TestDefaultListenAddr(t *testing.T) {
@@ -36,3 +42,329 @@ func TestDecodeJSONBodyEnforcesMaxBytes(t *testing.T) {
 		t.Fatalf("expected MaxBytesError, got %T", err)
 	}
 }
+
+func TestRepeatedIdempotencyKeyReplaysOrder(t *testing.T) {
+	st := newState()
+	srv := httptest.NewServer(st.routes())
+	defer srv.Close()
+
+	post := func(path, body, key string) (string, http.Header) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		if key != "" {
+			req.Header.Set("X-Idempotency-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var out struct {
+			OrderID string `json:"order_id"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.OrderID == "" {
+			t.Fatalf("%s: expected an order id, got %+v %v", path, out, err)
+		}
+		return out.OrderID, resp.Header
+	}
+
+	purchase := `{"domain":"example.com","period":1}`
+	first, h := post("/v1/domains/purchase", purchase, "key-1")
+	if h.Get("X-Idempotency-Key") != "key-1" || h.Get("X-Idempotent-Replay") != "" {
+		t.Fatalf("expected the key echoed on the first order, got %v", h)
+	}
+	again, h := post("/v1/domains/purchase", purchase, "key-1")
+	if again != first || h.Get("X-Idempotency-Key") != "key-1" || h.Get("X-Idempotent-Replay") != "true" {
+		t.Fatalf("expected replay of %s, got %s (%v)", first, again, h)
+	}
+	// The replay must not have consumed an order number.
+	if next, _ := post("/v1/domains/purchase", purchase, "key-2"); next != "mock-order-2" {
+		t.Fatalf("expected a new key to place mock-order-2, got %s", next)
+	}
+	// Keys are scoped per domain, so reusing one for another domain places a new order.
+	if other, h := post("/v1/domains/purchase", `{"domain":"other.com","period":1}`, "key-1"); other == first || h.Get("X-Idempotent-Replay") != "" {
+		t.Fatalf("expected key-1 on other.com to place a new order, got %s (%v)", other, h)
+	}
+	// A replay still returns the order after the domain is taken.
+	st.mu.Lock()
+	st.availability["example.com"] = availability{Domain: "example.com", Available: false}
+	st.mu.Unlock()
+	if again, _ := post("/v1/domains/purchase", purchase, "key-1"); again != first {
+		t.Fatalf("expected replay of %s after the domain was taken, got %s", first, again)
+	}
+
+	renew := `{"period":1}`
+	first, h = post("/v1/domains/alpha.com/renew", renew, "renew-key")
+	if h.Get("X-Idempotency-Key") != "renew-key" || h.Get("X-Idempotent-Replay") != "" {
+		t.Fatalf("expected the key echoed on the first renewal, got %v", h)
+	}
+	again, h = post("/v1/domains/alpha.com/renew", renew, "renew-key")
+	if again != first || h.Get("X-Idempotency-Key") != "renew-key" || h.Get("X-Idempotent-Replay") != "true" {
+		t.Fatalf("expected renew replay of %s, got %s (%v)", first, again, h)
+	}
+	other, h := post("/v1/domains/alpha.com/renew", renew, "")
+	if other == first || h.Get("X-Idempotency-Key") != "" || h.Get("X-Idempotent-Replay") != "" {
+		t.Fatalf("expected a request without a key to place a new renewal without idempotency headers, got %s (%v)", other, h)
+	}
+}
+
+func TestFailFirstInjectsRateLimitThenServes(t *testing.T) {
+	f := &faults{first: 2, statusFor: statusFlag{"/v1/domains/available": http.StatusTooManyRequests}, retryAfter: 3}
+	srv := httptest.NewServer(f.wrap(newState().routes()))
+	defer srv.Close()
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := get("/v1/domains/tlds"); resp.StatusCode == http.StatusTooManyRequests {
+		t.Fatalf("paths outside --status-for must not fail")
+	}
+	for i := 0; i < 2; i++ {
+		resp := get("/v1/domains/available?domain=example.com")
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "3" {
+			t.Fatalf("request %d: expected 429 with Retry-After 3, got %d %q", i+1, resp.StatusCode, resp.Header.Get("Retry-After"))
+		}
+	}
+	if resp := get("/v1/domains/available?domain=example.com"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected success after --fail-first, got %d", resp.StatusCode)
+	}
+}
+
+func TestFailRateOneFailsEveryRequest(t *testing.T) {
+	f := &faults{rate: 1}
+	srv := httptest.NewServer(f.wrap(newState().routes()))
+	defer srv.Close()
+
+	for i := 0; i < 5; i++ {
+		resp, err := http.Get(srv.URL + "/v1/domains/available?domain=example.com")
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected injected 503, got %d", resp.StatusCode)
+		}
+	}
+}
+
+func TestStatusForFlagRejectsBadValues(t *testing.T) {
+	var f statusFlag
+	for _, v := range []string{"/v1/x", "v1/x=500", "/v1/x=200", "/v1/x=abc"} {
+		if err := f.Set(v); err == nil {
+			t.Fatalf("expected %q to be rejected", v)
+		}
+	}
+	if err := f.Set("/v1/x=429"); err != nil || f["/v1/x"] != 429 {
+		t.Fatalf("expected /v1/x=429 to parse, got %v %v", f, err)
+	}
+}
+
+func TestLatencyDelaysAndStopsOnCancel(t *testing.T) {
+	f := &faults{latency: time.Hour, latencyFor: latencyFlag{"/v1/domains/tlds": 50 * time.Millisecond}}
+	srv := httptest.NewServer(f.wrap(newState().routes()))
+	defer srv.Close()
+
+	start := time.Now()
+	resp, err := http.Get(srv.URL + "/v1/domains/tlds")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("expected a delayed 200, got %d after %s", resp.StatusCode, time.Since(start))
+	}
+
+	client := &http.Client{Timeout: 100 * time.Millisecond}
+	start = time.Now()
+	if _, err := client.Get(srv.URL + "/v1/domains/available?domain=example.com"); err == nil {
+		t.Fatalf("expected the client timeout to fire")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("client waited %s for a cancelled request", time.Since(start))
+	}
+}
+
+func TestV2RoutesShareStateWithV1(t *testing.T) {
+	srv := httptest.NewServer(newState().routes())
+	defer srv.Close()
+	c, err := godaddy.NewHTTPClient(srv.URL, "key", "secret")
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	ctx := context.Background()
+
+	customerID, err := c.ResolveCustomerID(ctx, "12345")
+	if err != nil || customerID != mockCustomerID {
+		t.Fatalf("expected the stable customer ID, got %q %v", customerID, err)
+	}
+	detail, err := c.DomainDetailV2(ctx, customerID, "alpha.com", []string{"contacts"})
+	if err != nil || detail["locked"] != true || detail["contactRegistrant"] == nil {
+		t.Fatalf("expected seeded v2 detail with contacts, got %v %v", detail, err)
+	}
+	if _, err := c.DomainDetailV2(ctx, "someone-else", "alpha.com", nil); err == nil {
+		t.Fatalf("expected an unknown customer ID to be refused")
+	}
+
+	if err := c.SetLockV2(ctx, customerID, "alpha.com", false); err != nil {
+		t.Fatalf("set lock: %v", err)
+	}
+	if err := c.SetNameserversV2(ctx, customerID, "alpha.com", []string{"ns1.example.net", "ns2.example.net"}); err != nil {
+		t.Fatalf("set nameservers: %v", err)
+	}
+	v1, err := c.DomainDetailV1(ctx, "alpha.com")
+	if err != nil || v1["locked"] != false {
+		t.Fatalf("expected v1 detail to see the v2 unlock, got %v %v", v1, err)
+	}
+	ns, err := c.GetNameservers(ctx, "alpha.com")
+	if err != nil || len(ns) != 2 || ns[0] != "ns1.example.net" {
+		t.Fatalf("expected v1 to see the v2 nameservers, got %v %v", ns, err)
+	}
+
+	res, err := c.RenewV2(ctx, customerID, "alpha.com", godaddy.RenewV2Request{Expires: "2026-12-31", Period: 1}, "")
+	if err != nil || res.OrderID == "" || res.Price != 12.99 {
+		t.Fatalf("expected a v2 renewal, got %+v %v", res, err)
+	}
+	var actions []map[string]any
+	if err := c.V2Get(ctx, "/v2/customers/"+customerID+"/domains/alpha.com/actions", nil, &actions); err != nil || len(actions) != 3 {
+		t.Fatalf("expected three recorded actions, got %v %v", actions, err)
+	}
+}
+
+func TestFixturesReplaceOnlyTheirSections(t *testing.T) {
+	s, err := loadState("testdata/fixtures.json")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(s.portfolio) != 1 || s.portfolio[0].Domain != "scenario.io" {
+		t.Fatalf("expected the fixture portfolio, got %+v", s.portfolio)
+	}
+	if a := s.availability["premium.com"]; a.Domain != "premium.com" || a.Price != 2499 || a.Currency != "USD" {
+		t.Fatalf("expected availability keyed and defaulted from the map, got %+v", a)
+	}
+	if len(s.orders) != 0 {
+		t.Fatalf("expected an empty orders section to clear the defaults, got %d", len(s.orders))
+	}
+	if len(s.subs) != len(newState().subs) {
+		t.Fatalf("expected missing sections to keep the defaults")
+	}
+
+	dir := t.TempDir()
+	bad := dir + "/bad.json"
+	if err := os.WriteFile(bad, []byte(`{"portfolo": []}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := loadState(bad); err == nil || !strings.Contains(err.Error(), "portfolo") {
+		t.Fatalf("expected an unknown key to be rejected, got %v", err)
+	}
+	if s, err := loadState(""); err != nil || len(s.portfolio) != 2 {
+		t.Fatalf("expected the built-in state without a file, got %v", err)
+	}
+}
+
+func TestStateFileSurvivesRestart(t *testing.T) {
+	path := t.TempDir() + "/state.json"
+	first, err := openState("", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	srv := httptest.NewServer(first.persistWrites(first.routes()))
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/v1/domains/alpha.com/records", strings.NewReader(`[{"type":"TXT","name":"@","data":"kept"}]`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("put records: %v", err)
+	}
+	resp.Body.Close()
+	resp, err = http.Post(srv.URL+"/v1/domains/purchase", "application/json", strings.NewReader(`{"domain":"example.com"}`))
+	if err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+	resp.Body.Close()
+	srv.Close()
+
+	second, err := openState("testdata/fixtures.json", path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if recs := second.records["alpha.com"]; len(recs) != 1 || recs[0].Data != "kept" {
+		t.Fatalf("expected the saved records over the fixtures, got %+v", second.records)
+	}
+	if second.orderCounter != 1 {
+		t.Fatalf("expected the order counter to carry over, got %d", second.orderCounter)
+	}
+
+	if s, err := openState("", ""); err != nil || s.stateFile != "" {
+		t.Fatalf("expected in-memory state by default, got %q %v", s.stateFile, err)
+	}
+}
+
+func TestRecordScrubsSecretsAndReplayMatchesQuery(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "sso-key k:s" {
+			t.Errorf("expected the credentials to reach upstream, got %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Set-Cookie", "session=secret")
+		writeJSON(w, http.StatusOK, map[string]any{"domain": r.URL.Query().Get("domain"), "price": 11990000})
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	if _, err := newRecorder("http://api.godaddy.com", dir); err == nil {
+		t.Fatalf("expected a plain-http upstream to be refused")
+	}
+	rec, err := newRecorder(upstream.URL, dir)
+	if err != nil {
+		t.Fatalf("recorder: %v", err)
+	}
+	proxy := httptest.NewServer(rec)
+	get := func(base, query string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, base+"/v1/domains/available?"+query, nil)
+		req.Header.Set("Authorization", "sso-key k:s")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer resp.Body.Close()
+		var out struct {
+			Domain string `json:"domain"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out.Domain
+	}
+	get(proxy.URL, "domain=a.com&checkType=FULL")
+	get(proxy.URL, "domain=b.com&checkType=FULL")
+	proxy.Close()
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Fatalf("expected two recordings, got %d", len(files))
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(dir + "/" + f.Name())
+		if strings.Contains(string(data), "k:s") || strings.Contains(string(data), "session=secret") {
+			t.Fatalf("%s leaks a secret:\n%s", f.Name(), data)
+		}
+	}
+
+	rp, err := newReplayer(dir)
+	if err != nil {
+		t.Fatalf("replayer: %v", err)
+	}
+	replay := httptest.NewServer(rp)
+	defer replay.Close()
+	if status, domain := get(replay.URL, "checkType=FULL&domain=b.com"); status != http.StatusOK || domain != "b.com" {
+		t.Fatalf("expected the b.com recording regardless of parameter order, got %d %q", status, domain)
+	}
+	if status, _ := get(replay.URL, "domain=c.com&checkType=FULL"); status != http.StatusNotFound {
+		t.Fatalf("expected an unrecorded query to miss, got %d", status)
+	}
+}
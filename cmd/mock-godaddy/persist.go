@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// openState builds the starting state. An existing --state-file wins over --fixtures, which
+// only seeds the first run; with neither, the built-in demo data is used and nothing is saved.
+func openState(fixturesPath, stateFile string) (*state, error) {
+	path := fixturesPath
+	if strings.TrimSpace(stateFile) != "" {
+		if _, err := os.Stat(stateFile); err == nil {
+			path = stateFile
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	s, err := loadState(path)
+	if err != nil {
+		return nil, err
+	}
+	s.stateFile = stateFile
+	return s, nil
+}
+
+// save writes the whole state to s.stateFile through a temporary file and a rename, so a
+// crash mid-write leaves the previous copy intact.
+func (s *state) save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.snapshot(), "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.stateFile), ".mock-state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.stateFile)
+}
+
+// persistWrites saves the state after every request that can change it. Saves are
+// serialized so an older snapshot never overwrites a newer one.
+func (s *state) persistWrites(next http.Handler) http.Handler {
+	if s.stateFile == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return
+		}
+		s.saveMu.Lock()
+		defer s.saveMu.Unlock()
+		if err := s.save(); err != nil {
+			log.Printf("saving %s: %v", s.stateFile, err)
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedHeaders never reach a recording: credentials, session cookies, and the shopper ID
+// that identifies the account.
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Shopper-Id"}
+
+const redacted = "REDACTED"
+
+// interaction is one recorded request/response pair, stored as its own JSON file.
+type interaction struct {
+	Key        string          `json:"key"`
+	RecordedAt string          `json:"recorded_at"`
+	Request    recordedMessage `json:"request"`
+	Response   recordedMessage `json:"response"`
+}
+
+type recordedMessage struct {
+	Method string              `json:"method,omitempty"`
+	Path   string              `json:"path,omitempty"`
+	Query  string              `json:"query,omitempty"`
+	Status int                 `json:"status,omitempty"`
+	Header map[string][]string `json:"header,omitempty"`
+	// Body is kept as JSON when it parses, so recordings stay readable and diffable.
+	Body json.RawMessage `json:"body,omitempty"`
+	Text string          `json:"text,omitempty"`
+}
+
+// matchKey is what replay matches on: the method, the path, and the query parameters in a
+// canonical order.
+func matchKey(method string, u *url.URL) string {
+	key := method + " " + u.Path
+	if q := u.Query(); len(q) > 0 {
+		key += "?" + q.Encode()
+	}
+	return key
+}
+
+func scrubHeader(h http.Header) map[string][]string {
+	out := map[string][]string{}
+	for k, v := range h {
+		out[k] = append([]string(nil), v...)
+	}
+	for _, k := range redactedHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(k)]; ok {
+			out[http.CanonicalHeaderKey(k)] = []string{redacted}
+		}
+	}
+	return out
+}
+
+func setBody(m *recordedMessage, body []byte) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return
+	}
+	if json.Valid(body) {
+		m.Body = json.RawMessage(body)
+		return
+	}
+	m.Text = string(body)
+}
+
+func (m recordedMessage) body() []byte {
+	if len(m.Body) > 0 {
+		return m.Body
+	}
+	return []byte(m.Text)
+}
+
+// recorder proxies every request to upstream and writes each exchange to dir.
+type recorder struct {
+	upstream *url.URL
+	dir      string
+	client   *http.Client
+
+	mu  sync.Mutex
+	seq int
+}
+
+// newRecorder checks upstream and numbers new recordings after any already in dir.
+func newRecorder(upstream, dir string) (*recorder, error) {
+	u, err := url.Parse(upstream)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid --upstream %q", upstream)
+	}
+	if u.Scheme != "https" && !isLoopback(u.Hostname()) {
+		return nil, fmt.Errorf("--upstream must use https unless it is a loopback address, got %q", upstream)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &recorder{upstream: u, dir: dir, client: &http.Client{Timeout: 60 * time.Second}, seq: len(existing)}, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	if err != nil {
+		writeDecodeErr(w, err)
+		return
+	}
+	target := *rec.upstream
+	target.Path = strings.TrimSuffix(rec.upstream.Path, "/") + r.URL.Path
+	target.RawQuery = r.URL.RawQuery
+	out, err := http.NewRequestWithContext(r.Context(), r.Method, target.String(), bytes.NewReader(reqBody))
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"message": err.Error()})
+		return
+	}
+	out.Header = r.Header.Clone()
+	// Let the transport negotiate compression so recordings hold the decoded body.
+	out.Header.Del("Accept-Encoding")
+	// #nosec G704 -- the upstream is fixed by --upstream at startup, not taken from the request.
+	resp, err := rec.client.Do(out)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"message": "upstream: " + err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"message": "upstream: " + err.Error()})
+		return
+	}
+
+	it := interaction{
+		Key:        matchKey(r.Method, r.URL),
+		RecordedAt: time.Now().UTC().Format(time.RFC3339),
+		Request:    recordedMessage{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Header: scrubHeader(r.Header)},
+		Response:   recordedMessage{Status: resp.StatusCode, Header: scrubHeader(resp.Header)},
+	}
+	setBody(&it.Request, reqBody)
+	setBody(&it.Response, respBody)
+	if err := rec.write(it); err != nil {
+		log.Printf("recording %s: %v", it.Key, err)
+	}
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+}
+
+func (rec *recorder) write(it interaction) error {
+	data, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return err
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.seq++
+	name := fmt.Sprintf("%04d-%s%s.json", rec.seq, strings.ToLower(it.Request.Method), slug(it.Request.Path))
+	return os.WriteFile(filepath.Join(rec.dir, name), append(data, '\n'), 0o600)
+}
+
+// slug turns a path into a file-name fragment: /v1/domains/available -> -v1-domains-available.
+func slug(path string) string {
+	var b strings.Builder
+	for _, c := range path {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.':
+			b.WriteRune(c)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// replayer answers requests from a directory of recordings. Recordings that share a key are
+// served in the order they were made, and the last one repeats once they run out.
+type replayer struct {
+	mu     sync.Mutex
+	byKey  map[string][]interaction
+	served map[string]int
+}
+
+func newReplayer(dir string) (*replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recordings in %s", dir)
+	}
+	sort.Strings(files)
+	rp := &replayer{byKey: map[string][]interaction{}, served: map[string]int{}}
+	for _, f := range files {
+		data, err := os.ReadFile(f) // #nosec G304 -- files come from the --replay directory.
+		if err != nil {
+			return nil, err
+		}
+		var it interaction
+		if err := json.Unmarshal(data, &it); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		u := &url.URL{Path: it.Request.Path, RawQuery: it.Request.Query}
+		key := matchKey(it.Request.Method, u)
+		rp.byKey[key] = append(rp.byKey[key], it)
+	}
+	return rp, nil
+}
+
+func (rp *replayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := matchKey(r.Method, r.URL)
+	rp.mu.Lock()
+	list := rp.byKey[key]
+	n := rp.served[key]
+	rp.served[key] = n + 1
+	rp.mu.Unlock()
+	if len(list) == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_RECORDED", "message": "no recording for " + key})
+		return
+	}
+	it := list[min(n, len(list)-1)]
+	for k, v := range it.Response.Header {
+		if k == "Content-Length" || k == "Set-Cookie" {
+			continue
+		}
+		w.Header()[k] = v
+	}
+	w.WriteHeader(it.Response.Status)
+	_, _ = w.Write(it.Response.body())
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mockCustomerID is the customer every shopper resolves to, and the only one the v2 routes
+// accept.
+const mockCustomerID = "5a0f7c1e-3b1d-4c8e-9f2a-6d4b8e1c2a70"
+
+// handleShopper answers GET /v1/shoppers/{shopperId}?includes=customerId for any shopper ID.
+func (s *state) handleShopper(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+		return
+	}
+	shopperID := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/v1/shoppers/"))
+	if shopperID == "" || strings.Contains(shopperID, "/") {
+		writeJSON(w, http.StatusNotFound, map[string]any{"message": "not found"})
+		return
+	}
+	out := map[string]any{
+		"shopperId": shopperID,
+		"email":     "demo@example.com",
+		"nameFirst": "Demo",
+		"nameLast":  "Shopper",
+		"marketId":  "en-US",
+	}
+	if strings.Contains(r.URL.Query().Get("includes"), "customerId") {
+		out["customerId"] = mockCustomerID
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleV2 serves /v2/customers/{customerId}/domains/... against the same state as the v1
+// routes, so a change made through one API version shows up in the other.
+func (s *state) handleV2(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v2/customers/"), "/"), "/")
+	if len(parts) < 3 || parts[1] != "domains" {
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_FOUND", "message": "not found"})
+		return
+	}
+	if parts[0] != mockCustomerID {
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "UNKNOWN_CUSTOMER", "message": "customer not found"})
+		return
+	}
+	rest := parts[2:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch rest[0] {
+	case "notifications":
+		s.handleV2Notifications(w, r, rest[1:])
+		return
+	case "forwards":
+		s.handleV2Forwards(w, r, rest[1:])
+		return
+	}
+
+	domain := strings.ToLower(rest[0])
+	if _, ok := s.findDomain(domain); !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_FOUND", "message": "domain not found in this account"})
+		return
+	}
+	sub := strings.Join(rest[1:], "/")
+	switch {
+	case sub == "" && r.Method == http.MethodGet:
+		out := s.domainDetail(domain)
+		if !strings.Contains(strings.Join(r.URL.Query()["includes"], ","), "contacts") {
+			for _, c := range contactKeys {
+				delete(out, c)
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
+	case sub == "" && r.Method == http.MethodPatch:
+		var req struct {
+			Locked    *bool `json:"locked"`
+			RenewAuto *bool `json:"renewAuto"`
+		}
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeDecodeErr(w, err)
+			return
+		}
+		if req.Locked != nil {
+			s.locked[domain] = *req.Locked
+		}
+		if req.RenewAuto != nil {
+			s.renewAuto[domain] = *req.RenewAuto
+		}
+		s.recordAction(domain, "DOMAIN_UPDATE")
+		w.WriteHeader(http.StatusNoContent)
+	case sub == "nameServers" && r.Method == http.MethodPut:
+		var req struct {
+			NameServers []string `json:"nameServers"`
+		}
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeDecodeErr(w, err)
+			return
+		}
+		s.nameservers[domain] = req.NameServers
+		s.recordAction(domain, "DOMAIN_UPDATE_NAME_SERVERS")
+		w.WriteHeader(http.StatusAccepted)
+	case sub == "renew" && r.Method == http.MethodPost:
+		var req struct {
+			Expires string `json:"expires"`
+			Period  int    `json:"period"`
+		}
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeDecodeErr(w, err)
+			return
+		}
+		if req.Period <= 0 {
+			req.Period = 1
+		}
+		key := idempotencyScope(r, "renew-v2:"+domain)
+		echoIdempotencyKey(w, r)
+		if prev, ok := s.idempotent[key]; ok && key != "" {
+			w.Header().Set("X-Idempotent-Replay", "true")
+			writeJSON(w, http.StatusOK, prev)
+			return
+		}
+		s.orderCounter++
+		res := map[string]any{"orderId": "mock-renew-" + strconv.Itoa(s.orderCounter), "price": 12.99 * float64(req.Period), "currency": "USD"}
+		if key != "" {
+			s.idempotent[key] = res
+		}
+		s.recordAction(domain, "RENEW")
+		writeJSON(w, http.StatusOK, res)
+	case sub == "contacts" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.contacts[domain])
+	case sub == "contacts" && r.Method == http.MethodPatch:
+		var req map[string]any
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeDecodeErr(w, err)
+			return
+		}
+		if s.contacts[domain] == nil {
+			s.contacts[domain] = map[string]any{}
+		}
+		for _, c := range contactKeys {
+			if block, ok := req[c]; ok {
+				s.contacts[domain][c] = block
+			}
+		}
+		s.recordAction(domain, "DOMAIN_UPDATE_CONTACTS")
+		w.WriteHeader(http.StatusAccepted)
+	case sub == "actions" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.actions[domain])
+	case strings.HasPrefix(sub, "actions/") && r.Method == http.MethodGet:
+		actionType := strings.TrimPrefix(sub, "actions/")
+		for i := len(s.actions[domain]) - 1; i >= 0; i-- {
+			if a := s.actions[domain][i]; a["type"] == actionType {
+				writeJSON(w, http.StatusOK, a)
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_FOUND", "message": "no action of that type"})
+	case sub == "transfer" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"domain": domain, "status": "COMPLETED"})
+	case sub == "regenerateAuthCode" && r.Method == http.MethodPost:
+		s.recordAction(domain, "AUTH_CODE_REGENERATE")
+		w.WriteHeader(http.StatusAccepted)
+	case sub == "privacy/forwarding" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.privacyForwarding[domain])
+	case sub == "privacy/forwarding" && r.Method == http.MethodPatch:
+		var req map[string]any
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeDecodeErr(w, err)
+			return
+		}
+		s.privacyForwarding[domain] = req
+		s.recordAction(domain, "PRIVACY_FORWARDING_UPDATE")
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_FOUND", "message": "not found"})
+	}
+}
+
+// handleV2Notifications serves domains/notifications: nothing is ever pending, and the
+// opt-in list is kept in memory.
+func (s *state) handleV2Notifications(w http.ResponseWriter, r *http.Request, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusNoContent)
+	case len(rest) == 1 && rest[0] == "optIn" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.optIn)
+	case len(rest) == 1 && rest[0] == "optIn" && r.Method == http.MethodPut:
+		types := r.URL.Query()["types"]
+		if len(types) == 0 {
+			var req struct {
+				NotificationTypes []string `json:"notificationTypes"`
+			}
+			if err := decodeJSONBody(w, r, &req); err != nil {
+				writeDecodeErr(w, err)
+				return
+			}
+			types = req.NotificationTypes
+		}
+		s.optIn = types
+		w.WriteHeader(http.StatusNoContent)
+	case len(rest) == 2 && rest[1] == "acknowledge" && r.Method == http.MethodPost:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_FOUND", "message": "not found"})
+	}
+}
+
+// handleV2Forwards serves domains/forwards/{fqdn}.
+func (s *state) handleV2Forwards(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) != 1 {
+		writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_FOUND", "message": "not found"})
+		return
+	}
+	fqdn := strings.ToLower(rest[0])
+	switch r.Method {
+	case http.MethodGet:
+		fwd, ok := s.forwards[fqdn]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{"code": "NOT_FOUND", "message": "no forwarding for " + fqdn})
+			return
+		}
+		writeJSON(w, http.StatusOK, fwd)
+	case http.MethodPost, http.MethodPut:
+		var req map[string]any
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeDecodeErr(w, err)
+			return
+		}
+		if _, exists := s.forwards[fqdn]; exists == (r.Method == http.MethodPost) {
+			writeJSON(w, http.StatusConflict, map[string]any{"code": "CONFLICT", "message": "use POST to create and PUT to update forwarding"})
+			return
+		}
+		s.forwards[fqdn] = req
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+	}
+}
+
+// contactKeys are the contact blocks shared by v1 detail, v2 detail, and v2 contacts.
+var contactKeys = []string{"contactRegistrant", "contactAdmin", "contactTech", "contactBilling"}
+
+func (s *state) findDomain(domain string) (portfolioDomain, bool) {
+	for _, d := range s.portfolio {
+		if d.Domain == domain {
+			return d, true
+		}
+	}
+	return portfolioDomain{}, false
+}
+
+// domainDetail is the detail both API versions return for a portfolio domain, contacts
+// included. The caller holds s.mu.
+func (s *state) domainDetail(domain string) map[string]any {
+	d, _ := s.findDomain(domain)
+	ns := s.nameservers[domain]
+	if len(ns) == 0 {
+		ns = []string{"ns1.notafternic.com", "ns2.notafternic.com"}
+	}
+	out := map[string]any{
+		"domain":      domain,
+		"status":      "ACTIVE",
+		"expires":     d.Expires,
+		"locked":      s.locked[domain],
+		"renewAuto":   s.renewAuto[domain],
+		"nameServers": ns,
+	}
+	for _, c := range contactKeys {
+		if block, ok := s.contacts[domain][c]; ok {
+			out[c] = block
+		}
+	}
+	return out
+}
+
+// recordAction appends a completed action for the domain's actions list. The caller holds
+// s.mu.
+func (s *state) recordAction(domain, actionType string) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	s.actions[domain] = append(s.actions[domain], map[string]any{
+		"type":        actionType,
+		"origination": "USER",
+		"status":      "SUCCESS",
+		"createdAt":   now,
+		"modifiedAt":  now,
+	})
+}
+
+// demoContact is the seeded contact used for every role of the demo domains.
+func demoContact() map[string]any {
+	return map[string]any{
+		"nameFirst": "Demo",
+		"nameLast":  "Owner",
+		"email":     "owner@example.com",
+		"phone":     "+1.4805550100",
+		"addressMailing": map[string]any{
+			"address1":   "1 Example Way",
+			"city":       "Tempe",
+			"state":      "AZ",
+			"postalCode": "85281",
+			"country":    "US",
+		},
+	}
+}
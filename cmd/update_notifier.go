@@ -44,22 +44,55 @@ func shouldRunStartupUpdateCheck(rt *app.Runtime, rootCommand string) bool {
 	return true
 }
 
+// updateChannel is the configured release channel, falling back to stable.
+func updateChannel(rt *app.Runtime) string {
+	if rt == nil || rt.Cfg == nil {
+		return upd.ChannelStable
+	}
+	if ch, ok := upd.NormalizeChannel(rt.Cfg.UpdateChannel); ok {
+		return ch
+	}
+	return upd.ChannelStable
+}
+
+// cacheApplies reports whether a cached check was made for this version and channel.
+func cacheApplies(cache *upd.Cache, current, channel string) bool {
+	if cache == nil || cache.CurrentVersion != current {
+		return false
+	}
+	ch, _ := upd.NormalizeChannel(cache.Channel)
+	return ch == channel
+}
+
 func runStartupUpdateNotifier(rt *app.Runtime) {
 	current := upd.NormalizeVersion(Version)
+	channel := updateChannel(rt)
 	now := timeNow()
 
 	cache, err := loadUpdateCache()
-	if err == nil && cache != nil && cache.CurrentVersion == current && !upd.ShouldCheck(now, cache.LastCheckedAt, startupUpdateCheckInterval) {
+	if err == nil && cacheApplies(cache, current, channel) && !upd.ShouldCheckCache(now, cache, startupUpdateCheckInterval) {
 		if cache.UpdateAvailable != nil && *cache.UpdateAvailable {
 			emitUpdateNotice(rt, current, cache.LatestVersion, cache.ReleaseURL)
 		}
 		return
 	}
 
-	res := checkUpdate(context.Background(), Version, startupUpdateCheckTimeout)
+	res := checkUpdate(context.Background(), Version, channel, startupUpdateCheckTimeout)
+	if res.Skipped {
+		// Rate-limited checks are not errors: keep what we knew and back off quietly.
+		backoff := &upd.Cache{LastCheckedAt: now, CurrentVersion: current, Channel: channel, NextCheckAt: res.NextCheckAt}
+		if err == nil && cacheApplies(cache, current, channel) {
+			backoff.LatestVersion = cache.LatestVersion
+			backoff.UpdateAvailable = cache.UpdateAvailable
+			backoff.ReleaseURL = cache.ReleaseURL
+		}
+		_ = saveUpdateCache(backoff)
+		return
+	}
 	updateCache := &upd.Cache{
 		LastCheckedAt:   now,
 		CurrentVersion:  current,
+		Channel:         channel,
 		LatestVersion:   res.LatestVersion,
 		UpdateAvailable: res.UpdateAvailable,
 		ReleaseURL:      res.ReleaseURL,
@@ -75,13 +108,10 @@ func maybeEmitCachedUpdateNotice(rt *app.Runtime) bool {
 	current := upd.NormalizeVersion(Version)
 	now := timeNow()
 	cache, err := loadUpdateCache()
-	if err != nil || cache == nil {
+	if err != nil || !cacheApplies(cache, current, updateChannel(rt)) {
 		return false
 	}
-	if cache.CurrentVersion != current {
-		return false
-	}
-	if upd.ShouldCheck(now, cache.LastCheckedAt, startupUpdateCheckInterval) {
+	if upd.ShouldCheckCache(now, cache, startupUpdateCheckInterval) {
 		return false
 	}
 	if cache.UpdateAvailable != nil && *cache.UpdateAvailable {
@@ -91,6 +121,10 @@ func maybeEmitCachedUpdateNotice(rt *app.Runtime) bool {
 }
 
 func emitUpdateNotice(rt *app.Runtime, current, latest, releaseURL string) {
+	// "off" silences the notice only; the background check still refreshes the cache.
+	if rt.Cfg != nil && rt.Cfg.UpdateNoticeStream == "off" {
+		return
+	}
 	output.LogErr(rt.ErrOut, "update available: gdcli %s -> %s (run: gdcli self-update --json)", current, latest)
 	if releaseURL != "" {
 		output.LogErr(rt.ErrOut, "release: %s", releaseURL)
@@ -98,6 +132,15 @@ func emitUpdateNotice(rt *app.Runtime, current, latest, releaseURL string) {
 }
 
 func updateCheckMap(res upd.Result) map[string]any {
+	if res.Skipped {
+		return map[string]any{
+			"ok":            false,
+			"skipped":       true,
+			"reason":        res.SkipReason,
+			"current":       res.CurrentVersion,
+			"next_check_at": res.NextCheckAt.UTC().Format(time.RFC3339),
+		}
+	}
 	if !res.OK {
 		return map[string]any{
 			"ok":      false,
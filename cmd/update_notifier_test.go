@@ -53,7 +53,7 @@ func TestRunStartupUpdateNotifierUsesCacheAndWritesStderrOnly(t *testing.T) {
 		}, nil
 	}
 	saveUpdateCache = func(c *upd.Cache) error { return nil }
-	checkUpdate = func(ctx context.Context, current string, timeout time.Duration) upd.Result {
+	checkUpdate = func(ctx context.Context, current, channel string, timeout time.Duration) upd.Result {
 		t.Fatalf("network check should not run when cache is fresh")
 		return upd.Result{}
 	}
@@ -92,7 +92,7 @@ func TestRunStartupUpdateNotifierRefreshesStaleCache(t *testing.T) {
 		*saved = *c
 		return nil
 	}
-	checkUpdate = func(ctx context.Context, current string, timeout time.Duration) upd.Result {
+	checkUpdate = func(ctx context.Context, current, channel string, timeout time.Duration) upd.Result {
 		if timeout != startupUpdateCheckTimeout {
 			t.Fatalf("unexpected timeout: %v", timeout)
 		}
@@ -144,7 +144,9 @@ func TestUpdateNotifierDoesNotCorruptJSONOutput(t *testing.T) {
 		}, nil
 	}
 	saveUpdateCache = func(c *upd.Cache) error { return nil }
-	checkUpdate = func(ctx context.Context, current string, timeout time.Duration) upd.Result { return upd.Result{} }
+	checkUpdate = func(ctx context.Context, current, channel string, timeout time.Duration) upd.Result {
+		return upd.Result{}
+	}
 	timeNow = func() time.Time { return time.Now().UTC() }
 
 	emitErr := emitSuccess(rt, "help", map[string]any{"commands": []string{"init"}})
@@ -157,3 +159,71 @@ func TestUpdateNotifierDoesNotCorruptJSONOutput(t *testing.T) {
 		t.Fatalf("expected valid json envelope in stdout, got %q", stdout)
 	}
 }
+
+func TestUpdateNoticeStreamOffSuppressesNoticeButRefreshesCache(t *testing.T) {
+	rt := testNotifierRuntime(t, false)
+	rt.Cfg.UpdateNoticeStream = "off"
+
+	origLoad, origSave, origCheck, origNow := loadUpdateCache, saveUpdateCache, checkUpdate, timeNow
+	t.Cleanup(func() {
+		loadUpdateCache, saveUpdateCache, checkUpdate, timeNow = origLoad, origSave, origCheck, origNow
+	})
+
+	loadUpdateCache = func() (*upd.Cache, error) { return nil, nil }
+	saved := &upd.Cache{}
+	saveUpdateCache = func(c *upd.Cache) error {
+		*saved = *c
+		return nil
+	}
+	checkUpdate = func(ctx context.Context, current, channel string, timeout time.Duration) upd.Result {
+		available := true
+		return upd.Result{
+			OK:              true,
+			CurrentVersion:  upd.NormalizeVersion(current),
+			LatestVersion:   "9.9.9",
+			UpdateAvailable: &available,
+			ReleaseURL:      "https://example.com/release",
+		}
+	}
+	timeNow = func() time.Time { return time.Now().UTC() }
+
+	runStartupUpdateNotifier(rt)
+
+	if got := rt.ErrOut.(*bytes.Buffer).String(); got != "" {
+		t.Fatalf("expected no update notice when stream is off, got %q", got)
+	}
+	if saved.LatestVersion != "9.9.9" {
+		t.Fatalf("expected background check to refresh cache, got %+v", saved)
+	}
+}
+
+func TestRunStartupUpdateNotifierRechecksWhenChannelChanges(t *testing.T) {
+	rt := testNotifierRuntime(t, false)
+	rt.Cfg.UpdateChannel = "beta"
+
+	origLoad, origSave, origCheck, origNow := loadUpdateCache, saveUpdateCache, checkUpdate, timeNow
+	t.Cleanup(func() {
+		loadUpdateCache, saveUpdateCache, checkUpdate, timeNow = origLoad, origSave, origCheck, origNow
+	})
+
+	falsy := false
+	loadUpdateCache = func() (*upd.Cache, error) {
+		return &upd.Cache{LastCheckedAt: time.Now().UTC(), CurrentVersion: upd.NormalizeVersion(Version), UpdateAvailable: &falsy}, nil
+	}
+	var saved *upd.Cache
+	saveUpdateCache = func(c *upd.Cache) error { saved = c; return nil }
+	var gotChannel string
+	checkUpdate = func(ctx context.Context, current, channel string, timeout time.Duration) upd.Result {
+		gotChannel = channel
+		return upd.Result{OK: true, CurrentVersion: current, LatestVersion: "9.9.9-rc1"}
+	}
+	timeNow = func() time.Time { return time.Now().UTC() }
+
+	if maybeEmitCachedUpdateNotice(rt) {
+		t.Fatalf("a stable-channel cache should not satisfy a beta-channel check")
+	}
+	runStartupUpdateNotifier(rt)
+	if gotChannel != upd.ChannelBeta || saved == nil || saved.Channel != upd.ChannelBeta {
+		t.Fatalf("expected beta check and cache, got channel %q cache %+v", gotChannel, saved)
+	}
+}
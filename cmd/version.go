@@ -2,13 +2,24 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sportwhiz/gdcli/internal/app"
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
 	upd "github.com/sportwhiz/gdcli/internal/update"
 )
 
+// explicitUpdateCheckTimeout bounds `version --check` and `self-update` so a hung GitHub
+// cannot stall the command; override with --update-timeout.
+const explicitUpdateCheckTimeout = 3 * time.Second
+
 // Version metadata is populated at build time via ldflags.
 var (
 	Version   = "dev"
@@ -18,6 +29,11 @@ var (
 
 func runVersion(rt *app.Runtime, args []string) error {
 	check := hasBoolFlag(args, "check")
+	timeout, err := parseUpdateTimeout(parseKVFlags(args)["update-timeout"])
+	if err != nil {
+		emitError(rt, "version", err)
+		return err
+	}
 	result := map[string]any{
 		"version":    Version,
 		"commit":     Commit,
@@ -27,13 +43,21 @@ func runVersion(rt *app.Runtime, args []string) error {
 		"arch":       runtime.GOARCH,
 	}
 	if check {
-		result["update_check"] = checkForUpdate(rt.Ctx, Version, 8*time.Second)
+		result["update_check"] = checkForUpdate(rt.Ctx, Version, updateChannel(rt), timeout)
 	}
 	return emitSuccess(rt, "version", result)
 }
 
-func runSelfUpdate(rt *app.Runtime, _ []string) error {
-	check := checkForUpdate(rt.Ctx, Version, 8*time.Second)
+func runSelfUpdate(rt *app.Runtime, args []string) error {
+	timeout, err := parseUpdateTimeout(parseKVFlags(args)["update-timeout"])
+	if err != nil {
+		emitError(rt, "self-update", err)
+		return err
+	}
+	if hasBoolFlag(args, "apply") {
+		return applySelfUpdate(rt, timeout)
+	}
+	check := checkForUpdate(rt.Ctx, Version, updateChannel(rt), timeout)
 	result := map[string]any{
 		"current_version": Version,
 		"update_check":    check,
@@ -46,7 +70,117 @@ func runSelfUpdate(rt *app.Runtime, _ []string) error {
 	return emitSuccess(rt, "self-update", result)
 }
 
-func checkForUpdate(ctx context.Context, current string, timeout time.Duration) map[string]any {
-	res := upd.CheckWithTimeout(ctx, current, timeout)
+// executablePath locates the running binary, resolving symlinks so the real file is replaced.
+func executablePath() (string, error) {
+	p, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(p)
+}
+
+// applySelfUpdate replaces the running binary with the latest release asset for this
+// platform, after verifying it against the release checksums.
+func applySelfUpdate(rt *app.Runtime, timeout time.Duration) error {
+	res := upd.CheckWithTimeout(rt.Ctx, Version, updateChannel(rt), timeout)
+	if !res.OK {
+		err := &apperr.AppError{Code: apperr.CodeInternal, Message: "update check failed", Details: updateCheckMap(res)}
+		emitError(rt, "self-update", err)
+		return err
+	}
+	if res.UpdateAvailable == nil || !*res.UpdateAvailable {
+		return emitSuccess(rt, "self-update", map[string]any{
+			"current_version": Version,
+			"latest_version":  res.LatestVersion,
+			"updated":         false,
+			"message":         "no newer release to install",
+		})
+	}
+	path, err := executablePath()
+	if err != nil {
+		appErr := &apperr.AppError{Code: apperr.CodeInternal, Message: "cannot locate the running binary", Cause: err}
+		emitError(rt, "self-update", appErr)
+		return appErr
+	}
+	if err := upd.CheckManaged(path); err != nil {
+		var managed *upd.ManagedError
+		errors.As(err, &managed)
+		appErr := &apperr.AppError{
+			Code:    apperr.CodeValidation,
+			Message: fmt.Sprintf("gdcli was installed with %s; upgrade with `%s` instead", managed.Manager, managed.Upgrade),
+			Details: map[string]any{"path": path, "manager": managed.Manager, "upgrade_command": managed.Upgrade},
+			Cause:   err,
+		}
+		emitError(rt, "self-update", appErr)
+		return appErr
+	}
+	if err := upd.CheckWritable(path); err != nil {
+		appErr := &apperr.AppError{
+			Code:    apperr.CodeInternal,
+			Message: "binary is not writable; rerun with permission to replace it or upgrade with your package manager",
+			Details: map[string]any{"path": path, "error": err.Error()},
+			Cause:   err,
+		}
+		emitError(rt, "self-update", appErr)
+		return appErr
+	}
+	applied, err := upd.Apply(rt.Ctx, res, path, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		appErr := &apperr.AppError{Code: apperr.CodeInternal, Message: "self-update failed", Details: map[string]any{"path": path, "error": err.Error()}, Cause: err}
+		emitError(rt, "self-update", appErr)
+		return appErr
+	}
+	return emitSuccess(rt, "self-update", map[string]any{
+		"current_version": Version,
+		"latest_version":  applied.Version,
+		"updated":         true,
+		"path":            applied.Path,
+		"asset":           applied.Asset,
+		"sha256":          applied.SHA256,
+		"verify_command":  "gdcli version --json",
+	})
+}
+
+func parseUpdateTimeout(v string) (time.Duration, error) {
+	return parseDurationFlag("update-timeout", v, explicitUpdateCheckTimeout)
+}
+
+// parseDurationFlag accepts a Go duration ("2s", "500ms") or a whole number of seconds.
+// Empty input yields def; values <= 0 are rejected.
+func parseDurationFlag(name, v string, def time.Duration) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		secs, convErr := strconv.Atoi(v)
+		if convErr != nil {
+			return 0, &apperr.AppError{Code: apperr.CodeValidation, Message: name + " must be a duration like 2s or a number of seconds", Details: map[string]any{"value": v}}
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d <= 0 {
+		return 0, &apperr.AppError{Code: apperr.CodeValidation, Message: name + " must be > 0", Details: map[string]any{"value": v}}
+	}
+	return d, nil
+}
+
+// parseAgeFlag accepts a whole number of days ("90d") or anything parseDurationFlag takes.
+// Empty input yields def.
+func parseAgeFlag(name, v string, def time.Duration) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, &apperr.AppError{Code: apperr.CodeValidation, Message: name + " must be a positive number of days like 90d or a duration like 72h", Details: map[string]any{"value": v}}
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return parseDurationFlag(name, v, def)
+}
+
+func checkForUpdate(ctx context.Context, current, channel string, timeout time.Duration) map[string]any {
+	res := upd.CheckWithTimeout(ctx, current, channel, timeout)
 	return updateCheckMap(res)
 }
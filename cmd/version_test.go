@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
 	upd "github.com/sportwhiz/gdcli/internal/update"
 )
@@ -43,3 +44,20 @@ func TestIsVersionNewer(t *testing.T) {
 func boolPtr(v bool) *bool {
 	return &v
 }
+
+func TestParseUpdateTimeout(t *testing.T) {
+	if d, err := parseUpdateTimeout(""); err != nil || d != explicitUpdateCheckTimeout {
+		t.Fatalf("expected default timeout, got %v %v", d, err)
+	}
+	if d, err := parseUpdateTimeout("500ms"); err != nil || d != 500*time.Millisecond {
+		t.Fatalf("expected 500ms, got %v %v", d, err)
+	}
+	if d, err := parseUpdateTimeout("5"); err != nil || d != 5*time.Second {
+		t.Fatalf("expected 5s, got %v %v", d, err)
+	}
+	for _, bad := range []string{"0", "-1s", "soon"} {
+		if _, err := parseUpdateTimeout(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
@@ -2,12 +2,14 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/sportwhiz/gdcli/internal/config"
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
@@ -24,13 +26,27 @@ func (c Credentials) APIKey() string    { return c.apiKey }
 func (c Credentials) APISecret() string { return c.apiSecret }
 
 type Runtime struct {
-	Ctx       context.Context
-	Cfg       *config.Config
-	Out       *output.Writer
-	ErrOut    io.Writer
-	Limiter   *rate.Limiter
-	JSON      bool
-	NDJSON    bool
+	Ctx     context.Context
+	Cfg     *config.Config
+	Out     *output.Writer
+	ErrOut  io.Writer
+	Limiter *rate.Limiter
+	JSON    bool
+	NDJSON  bool
+	CSV     bool
+	// HTTPTimeout overrides http_timeout_seconds for this run (--timeout); zero means unset.
+	HTTPTimeout time.Duration
+	// APIEnvOverride overrides api_environment for this run (--api-environment); empty means unset.
+	APIEnvOverride string
+	// AllowProd lets purchases and renewals through require_ote_first for this run (--allow-prod).
+	AllowProd bool
+	// NoRetry makes every API call a single attempt for this run (--no-retry).
+	NoRetry bool
+	// DryRun stops every write for this run (global --dry-run): V2Apply echoes the request it
+	// would send and the client refuses any other write.
+	DryRun bool
+	// Log receives --verbose debug lines on stderr; a nil Logger or level 0 logs nothing.
+	Log       *output.Logger
 	Quiet     bool
 	RequestID string
 }
@@ -38,15 +54,27 @@ type Runtime struct {
 func NewRuntime(ctx context.Context, stdOut, stdErr io.Writer, jsonMode, ndjsonMode, quiet bool, requestID string) (*Runtime, error) {
 	cfg, err := config.Load()
 	if err != nil {
+		var unknown *config.UnknownProfileError
+		if errors.As(err, &unknown) {
+			return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: err.Error(), Details: map[string]any{"profile": unknown.Name}, Cause: err}
+		}
 		return nil, apperr.Wrap(apperr.CodeInternal, "failed loading config", err)
 	}
+	if cfg.HTTPTimeoutSeconds <= 0 {
+		// Refusing here would also block init and settings reset, the commands that fix it.
+		def := config.Default().HTTPTimeoutSeconds
+		if !quiet {
+			output.LogErr(stdErr, "warning: http_timeout_seconds %d in config.json must be > 0; using %d", cfg.HTTPTimeoutSeconds, def)
+		}
+		cfg.HTTPTimeoutSeconds = def
+	}
 	applyIdentityEnvOverrides(cfg)
 	return &Runtime{
 		Ctx:       ctx,
 		Cfg:       cfg,
 		Out:       output.NewWriter(stdOut),
 		ErrOut:    stdErr,
-		Limiter:   rate.NewLimiter(55),
+		Limiter:   rate.NewLimiter(55, rate.DefaultBurst),
 		JSON:      jsonMode,
 		NDJSON:    ndjsonMode,
 		Quiet:     quiet,
@@ -69,33 +97,125 @@ func applyIdentityEnvOverrides(cfg *config.Config) {
 	}
 }
 
-func LoadCredentials() (Credentials, error) {
+// NoKeychainEnvVar disables every macOS keychain lookup and write when set to a true value.
+const NoKeychainEnvVar = "GDCLI_NO_KEYCHAIN"
+
+// noKeychain is set by the --no-keychain global flag.
+var noKeychain bool
+
+// SetNoKeychain disables keychain access for this invocation, in addition to GDCLI_NO_KEYCHAIN.
+func SetNoKeychain(disabled bool) {
+	noKeychain = disabled
+}
+
+// KeychainDisabled reports whether --no-keychain or GDCLI_NO_KEYCHAIN is in effect.
+func KeychainDisabled() bool {
+	if noKeychain {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(NoKeychainEnvVar))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// ProxyEnvVar names an explicit proxy for API requests, used when --proxy is not passed.
+const ProxyEnvVar = "GDCLI_PROXY"
+
+// proxyOverride is set by the --proxy global flag.
+var proxyOverride string
+
+// SetProxy sets the proxy for this invocation, taking precedence over GDCLI_PROXY.
+func SetProxy(raw string) {
+	proxyOverride = strings.TrimSpace(raw)
+}
+
+// Proxy returns the proxy from --proxy or GDCLI_PROXY. An empty result means the standard
+// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment applies.
+func Proxy() string {
+	if proxyOverride != "" {
+		return proxyOverride
+	}
+	return strings.TrimSpace(os.Getenv(ProxyEnvVar))
+}
+
+// keychainAccounts returns the keychain account names for a profile's key and secret.
+// The default profile keeps the original un-suffixed names.
+func keychainAccounts(profile string) (string, string) {
+	if profile == "" || profile == config.DefaultProfile {
+		return "godaddy_api_key", "godaddy_api_secret"
+	}
+	return "godaddy_api_key_" + profile, "godaddy_api_secret_" + profile
+}
+
+func LoadCredentials(profile string) (Credentials, error) {
 	key := strings.TrimSpace(os.Getenv("GODADDY_API_KEY"))
 	secret := strings.TrimSpace(os.Getenv("GODADDY_API_SECRET"))
 	if key != "" && secret != "" {
 		return Credentials{apiKey: key, apiSecret: secret}, nil
 	}
 
-	if runtime.GOOS == "darwin" {
-		k := keychainRead("godaddy_api_key")
-		s := keychainRead("godaddy_api_secret")
+	if keychainSupported() && !KeychainDisabled() {
+		keyAccount, secretAccount := keychainAccounts(profile)
+		k := keychainRead(keyAccount)
+		s := keychainRead(secretAccount)
 		if k != "" && s != "" {
 			return Credentials{apiKey: k, apiSecret: s}, nil
 		}
 	}
 
+	if creds, ok, err := loadCredentialsFile(profile); err != nil {
+		return Credentials{}, err
+	} else if ok {
+		return creds, nil
+	}
+
 	return Credentials{}, &apperr.AppError{
 		Code:    apperr.CodeAuth,
-		Message: "missing GoDaddy credentials; set GODADDY_API_KEY and GODADDY_API_SECRET or store in OS keychain",
+		Message: "missing GoDaddy credentials; set GODADDY_API_KEY and GODADDY_API_SECRET, store in OS keychain, or run init --store-file",
 		Details: map[string]any{"env_vars": []string{"GODADDY_API_KEY", "GODADDY_API_SECRET"}},
 	}
 }
 
+func validKeychainAccount(account string) bool {
+	for _, prefix := range []string{"godaddy_api_key", "godaddy_api_secret"} {
+		if account == prefix {
+			return true
+		}
+		if profile, ok := strings.CutPrefix(account, prefix+"_"); ok && config.ValidProfileName(profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// keychainSupported reports whether an OS secret store is available: the macOS keychain via
+// `security`, or on Linux the Secret Service (GNOME Keyring, KWallet) via libsecret's `secret-tool`.
+func keychainSupported() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		return true
+	case "linux":
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	}
+	return false
+}
+
 func keychainRead(account string) string {
-	if account != "godaddy_api_key" && account != "godaddy_api_secret" {
+	if !validKeychainAccount(account) {
 		return ""
 	}
-	// #nosec G204 -- exec.Command is called with a fixed binary/flags and a strict account allowlist.
+	if runtime.GOOS == "linux" {
+		// #nosec G204 -- exec.Command is called with a fixed binary/attributes and a strict account allowlist/pattern.
+		out, err := exec.Command("secret-tool", "lookup", "service", "gdcli", "account", account).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	// #nosec G204 -- exec.Command is called with a fixed binary/flags and a strict account allowlist/pattern.
 	out, err := exec.Command("security", "find-generic-password", "-s", "gdcli", "-a", account, "-w").Output()
 	if err != nil {
 		return ""
@@ -103,24 +223,90 @@ func keychainRead(account string) string {
 	return strings.TrimSpace(string(out))
 }
 
-func StoreCredentialsInKeychain(key, secret string) error {
-	if runtime.GOOS != "darwin" {
-		return &apperr.AppError{Code: apperr.CodeValidation, Message: "keychain storage is only supported on macOS"}
+func StoreCredentialsInKeychain(profile, key, secret string) error {
+	if KeychainDisabled() {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "keychain access is disabled by --no-keychain or " + NoKeychainEnvVar, Details: map[string]any{"env_var": NoKeychainEnvVar}}
+	}
+	if !keychainSupported() {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "keychain storage requires macOS, or Linux with secret-tool installed; use --store-file instead"}
 	}
 	if strings.TrimSpace(key) == "" || strings.TrimSpace(secret) == "" {
 		return &apperr.AppError{Code: apperr.CodeValidation, Message: "api key and secret are required"}
 	}
+	keyAccount, secretAccount := keychainAccounts(profile)
+	if !validKeychainAccount(keyAccount) || !validKeychainAccount(secretAccount) {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "invalid profile name for keychain storage", Details: map[string]any{"profile": profile}}
+	}
+	if runtime.GOOS == "linux" {
+		if out, err := secretToolStore(keyAccount, key); err != nil {
+			return &apperr.AppError{Code: apperr.CodeInternal, Message: "failed storing keyring api key", Details: map[string]any{"stderr": strings.TrimSpace(string(out))}, Cause: err}
+		}
+		if out, err := secretToolStore(secretAccount, secret); err != nil {
+			return &apperr.AppError{Code: apperr.CodeInternal, Message: "failed storing keyring api secret", Details: map[string]any{"stderr": strings.TrimSpace(string(out))}, Cause: err}
+		}
+		return nil
+	}
 	// #nosec G204 -- exec.Command is called with a fixed binary/flags; key is passed as an argument without shell interpolation.
-	if out, err := exec.Command("security", "add-generic-password", "-U", "-s", "gdcli", "-a", "godaddy_api_key", "-w", key).CombinedOutput(); err != nil {
+	if out, err := exec.Command("security", "add-generic-password", "-U", "-s", "gdcli", "-a", keyAccount, "-w", key).CombinedOutput(); err != nil {
 		return &apperr.AppError{Code: apperr.CodeInternal, Message: "failed storing keychain api key", Details: map[string]any{"stderr": strings.TrimSpace(string(out))}, Cause: err}
 	}
 	// #nosec G204 -- exec.Command is called with a fixed binary/flags; secret is passed as an argument without shell interpolation.
-	if out, err := exec.Command("security", "add-generic-password", "-U", "-s", "gdcli", "-a", "godaddy_api_secret", "-w", secret).CombinedOutput(); err != nil {
+	if out, err := exec.Command("security", "add-generic-password", "-U", "-s", "gdcli", "-a", secretAccount, "-w", secret).CombinedOutput(); err != nil {
 		return &apperr.AppError{Code: apperr.CodeInternal, Message: "failed storing keychain api secret", Details: map[string]any{"stderr": strings.TrimSpace(string(out))}, Cause: err}
 	}
 	return nil
 }
 
+// secretToolStore saves value in the Secret Service under the same service/account attributes
+// that keychainRead looks up. The value goes over stdin so it never appears in the process list.
+func secretToolStore(account, value string) ([]byte, error) {
+	// #nosec G204 -- exec.Command is called with a fixed binary/attributes; account is allowlisted by the caller.
+	cmd := exec.Command("secret-tool", "store", "--label=gdcli "+account, "service", "gdcli", "account", account)
+	cmd.Stdin = strings.NewReader(value)
+	return cmd.CombinedOutput()
+}
+
+const (
+	// progressMinItems is the smallest batch that gets a progress line.
+	progressMinItems = 20
+	// progressInterval bounds how often the progress line is redrawn.
+	progressInterval = 500 * time.Millisecond
+)
+
+// NewProgress returns a stderr progress counter for a batch of total items, or nil (which
+// does nothing) under --quiet, when stderr is not a terminal, or for small batches.
+func (rt *Runtime) NewProgress(label string, total int) *output.Progress {
+	if rt.Quiet || total < progressMinItems || !output.IsTerminal(rt.ErrOut) {
+		return nil
+	}
+	return output.NewProgress(rt.ErrOut, label, total, progressInterval)
+}
+
+// APIEnvironment is the environment this run talks to: the --api-environment override, else
+// the config's api_environment.
+func (rt *Runtime) APIEnvironment() string {
+	if rt.APIEnvOverride != "" {
+		return rt.APIEnvOverride
+	}
+	return rt.Cfg.APIEnvironment
+}
+
+// RetryPolicy is how API calls retry this run: retry_attempts and retry_base_ms from config
+// (defaults when unset), or a single attempt under --no-retry.
+func (rt *Runtime) RetryPolicy() rate.Policy {
+	p := rate.Policy{Attempts: rt.Cfg.RetryAttempts, Base: time.Duration(rt.Cfg.RetryBaseMs) * time.Millisecond}
+	if p.Attempts <= 0 {
+		p.Attempts = rate.DefaultRetryAttempts
+	}
+	if p.Base <= 0 {
+		p.Base = rate.DefaultRetryBase
+	}
+	if rt.NoRetry {
+		p.Attempts = 1
+	}
+	return p
+}
+
 func BaseURL(env string) string {
 	if override := strings.TrimSpace(os.Getenv("GDCLI_BASE_URL")); override != "" {
 		return strings.TrimSuffix(override, "/")
@@ -132,10 +318,35 @@ func BaseURL(env string) string {
 }
 
 func MaybeWarnProdFinancial(rt *Runtime, command string) {
-	if rt.Quiet {
+	if !(strings.Contains(command, "purchase") || strings.Contains(command, "renew")) {
 		return
 	}
-	if rt.Cfg.APIEnvironment == "prod" && (strings.Contains(command, "purchase") || strings.Contains(command, "renew")) {
-		fmt.Fprintf(rt.ErrOut, "warning: running financial action against production API environment\n")
+	WarnProdSpend(rt, command)
+}
+
+// WarnProdSpend warns on stderr that command, which can charge the account, targets prod.
+func WarnProdSpend(rt *Runtime, command string) {
+	env := rt.APIEnvironment()
+	if rt.Quiet || env != "prod" {
+		return
+	}
+	msg := "warning: %s is running against the %s API environment; rerun with --api-environment ote to test first"
+	if rt.Cfg.RequireOTEFirst && !rt.AllowProd {
+		msg += "; require_ote_first is set, so purchases and renewals need --allow-prod"
+	}
+	output.LogErr(rt.ErrOut, msg, command, env)
+}
+
+// GuardProdFinancial refuses a purchase, renewal, or other spending call against prod when
+// require_ote_first is set and the run did not pass --allow-prod.
+func GuardProdFinancial(rt *Runtime, opType string) error {
+	env := rt.APIEnvironment()
+	if !rt.Cfg.RequireOTEFirst || rt.AllowProd || env != "prod" {
+		return nil
+	}
+	return &apperr.AppError{
+		Code:    apperr.CodeSafety,
+		Message: fmt.Sprintf("%s refused: require_ote_first is set and this run targets the %s API environment; test with --api-environment ote, or pass --allow-prod to spend for real", opType, env),
+		Details: map[string]any{"api_environment": env, "operation": opType, "override": "--allow-prod"},
 	}
 }
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeSecretTool puts a secret-tool on PATH that keeps one file per account under dir and
+// logs its arguments, exiting 1 for a lookup miss like the real tool.
+func fakeSecretTool(t *testing.T) (dir, argLog string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("secret-tool is only used on linux")
+	}
+	dir = t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	argLog = filepath.Join(dir, "args.log")
+	script := `#!/bin/sh
+echo "$@" >> "` + argLog + `"
+cmd=$1; shift
+while [ $# -gt 1 ]; do
+	if [ "$1" = account ]; then account=$2; fi
+	shift
+done
+case $cmd in
+store) cat > "` + dir + `/$account" ;;
+lookup) [ -f "` + dir + `/$account" ] || exit 1; cat "` + dir + `/$account" ;;
+*) exit 2 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "secret-tool"), []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(NoKeychainEnvVar, "")
+	return dir, argLog
+}
+
+func TestSecretToolStoreAndLookup(t *testing.T) {
+	_, argLog := fakeSecretTool(t)
+	if !keychainSupported() {
+		t.Fatalf("expected secret-tool on PATH to enable keychain storage")
+	}
+	if err := StoreCredentialsInKeychain("ote", "the-key", "the-secret"); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	keyAccount, secretAccount := keychainAccounts("ote")
+	if got := keychainRead(keyAccount); got != "the-key" {
+		t.Fatalf("expected stored key back, got %q", got)
+	}
+	if got := keychainRead(secretAccount); got != "the-secret" {
+		t.Fatalf("expected stored secret back, got %q", got)
+	}
+	args, err := os.ReadFile(argLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(args), "the-secret") {
+		t.Fatalf("secret must go over stdin, not argv: %s", args)
+	}
+	if !strings.Contains(string(args), "store --label=gdcli godaddy_api_key_ote service gdcli account godaddy_api_key_ote") {
+		t.Fatalf("unexpected secret-tool arguments: %s", args)
+	}
+
+	// A miss exits 1 and reads as no credentials.
+	missKey, _ := keychainAccounts("prod")
+	if got := keychainRead(missKey); got != "" {
+		t.Fatalf("expected an empty lookup for a missing account, got %q", got)
+	}
+}
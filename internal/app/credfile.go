@@ -0,0 +1,278 @@
+package app
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sportwhiz/gdcli/internal/config"
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
+)
+
+// CredentialsFile holds API credentials encrypted with a passphrase, for platforms (or setups)
+// without keychain storage. It sits next to config.json.
+const CredentialsFile = "credentials.enc"
+
+// PassphraseEnvVar supplies the passphrase that encrypts and decrypts CredentialsFile.
+const PassphraseEnvVar = "GDCLI_PASSPHRASE"
+
+const (
+	credFileVersion    = 1
+	credFileKDF        = "pbkdf2-sha256"
+	credFileIterations = 600_000
+	// credFileMinIterations and credFileMaxIterations bound the work factor read from the
+	// file, so a tampered file can neither weaken the KDF nor stall the command.
+	credFileMinIterations = 100_000
+	credFileMaxIterations = 10_000_000
+	// credFileAAD binds the ciphertext to this file format.
+	credFileAAD = "gdcli-credentials-v1"
+)
+
+type credFileEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type credFileEntry struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+}
+
+type credFilePayload struct {
+	Profiles map[string]credFileEntry `json:"profiles"`
+}
+
+func credentialsFilePath() (string, error) {
+	dir, err := config.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, CredentialsFile), nil
+}
+
+// promptedPassphrase caches a passphrase typed at the terminal for the rest of the run.
+var promptedPassphrase string
+
+// readPassphrase asks for a passphrase on the terminal without echo. It returns "" when stdin
+// is not a terminal or echo cannot be turned off; tests replace it.
+var readPassphrase = func(prompt string) string {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return ""
+	}
+	if err := stty("-echo"); err != nil {
+		return ""
+	}
+	defer func() {
+		_ = stty("echo")
+		fmt.Fprintln(os.Stderr)
+	}()
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+func stty(arg string) error {
+	// #nosec G204 -- fixed binary; arg is "echo" or "-echo".
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// passphrase returns GDCLI_PASSPHRASE, or asks for it on the terminal when it is unset. A new
+// passphrase (confirm) must be typed twice. It returns "" when neither source is available.
+func passphrase(confirm bool) string {
+	if v := os.Getenv(PassphraseEnvVar); v != "" {
+		return v
+	}
+	if promptedPassphrase != "" {
+		return promptedPassphrase
+	}
+	pass := readPassphrase("gdcli passphrase: ")
+	if pass == "" {
+		return ""
+	}
+	if confirm && readPassphrase("repeat passphrase: ") != pass {
+		return ""
+	}
+	promptedPassphrase = pass
+	return pass
+}
+
+// StoreCredentialsInFile encrypts the profile's key and secret into CredentialsFile with the
+// GDCLI_PASSPHRASE passphrase. Other profiles already in the file are kept, which requires the
+// same passphrase.
+func StoreCredentialsInFile(profile, key, secret string) (string, error) {
+	if strings.TrimSpace(key) == "" || strings.TrimSpace(secret) == "" {
+		return "", &apperr.AppError{Code: apperr.CodeValidation, Message: "api key and secret are required"}
+	}
+	path, err := credentialsFilePath()
+	if err != nil {
+		return "", &apperr.AppError{Code: apperr.CodeInternal, Message: "failed resolving credentials file path", Cause: err}
+	}
+	_, statErr := os.Stat(path)
+	pass := passphrase(statErr != nil)
+	if pass == "" {
+		return "", &apperr.AppError{Code: apperr.CodeValidation, Message: "encrypted credential storage requires a passphrase in " + PassphraseEnvVar + " or typed (twice) at a terminal", Details: map[string]any{"env_var": PassphraseEnvVar}}
+	}
+	if _, err := config.EnsureDir(); err != nil {
+		return "", &apperr.AppError{Code: apperr.CodeInternal, Message: "failed creating config directory", Cause: err}
+	}
+	payload, err := readCredentialsFile(path, pass)
+	if err != nil {
+		return "", err
+	}
+	if payload.Profiles == nil {
+		payload.Profiles = map[string]credFileEntry{}
+	}
+	payload.Profiles[profileKey(profile)] = credFileEntry{APIKey: key, APISecret: secret}
+	b, err := sealCredentials(payload, pass)
+	if err != nil {
+		return "", &apperr.AppError{Code: apperr.CodeInternal, Message: "failed encrypting credentials", Cause: err}
+	}
+	if err := config.WriteFileAtomic(path, b, 0o600, false); err != nil {
+		return "", &apperr.AppError{Code: apperr.CodeInternal, Message: "failed writing credentials file", Cause: err}
+	}
+	return path, nil
+}
+
+// loadCredentialsFile returns the profile's credentials from CredentialsFile. ok is false when
+// the file does not exist or has no entry for the profile.
+func loadCredentialsFile(profile string) (Credentials, bool, error) {
+	path, err := credentialsFilePath()
+	if err != nil {
+		return Credentials{}, false, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return Credentials{}, false, nil
+	}
+	pass := passphrase(false)
+	if pass == "" {
+		return Credentials{}, false, &apperr.AppError{
+			Code:    apperr.CodeAuth,
+			Message: "encrypted credentials found but " + PassphraseEnvVar + " is not set and no terminal is available to ask for it",
+			Details: map[string]any{"path": path, "env_var": PassphraseEnvVar},
+		}
+	}
+	payload, err := readCredentialsFile(path, pass)
+	if err != nil {
+		return Credentials{}, false, err
+	}
+	entry, ok := payload.Profiles[profileKey(profile)]
+	if !ok || entry.APIKey == "" || entry.APISecret == "" {
+		return Credentials{}, false, nil
+	}
+	return Credentials{apiKey: entry.APIKey, apiSecret: entry.APISecret}, true, nil
+}
+
+// readCredentialsFile decrypts path with pass. A missing file is an empty payload.
+func readCredentialsFile(path, pass string) (credFilePayload, error) {
+	// #nosec G304 -- path is CredentialsFile inside the gdcli config directory.
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return credFilePayload{}, nil
+		}
+		return credFilePayload{}, &apperr.AppError{Code: apperr.CodeInternal, Message: "failed reading credentials file", Cause: err}
+	}
+	unsupported := &apperr.AppError{Code: apperr.CodeAuth, Message: "credentials file is not in a supported format", Details: map[string]any{"path": path}}
+	var env credFileEnvelope
+	if err := json.Unmarshal(b, &env); err != nil || env.Version != credFileVersion || env.KDF != credFileKDF {
+		return credFilePayload{}, unsupported
+	}
+	if env.Iterations < credFileMinIterations || env.Iterations > credFileMaxIterations {
+		unsupported.Details["iterations"] = env.Iterations
+		return credFilePayload{}, unsupported
+	}
+	gcm, err := credFileCipher(pass, env.Salt, env.Iterations)
+	if err != nil {
+		unsupported.Cause = err
+		return credFilePayload{}, unsupported
+	}
+	// gcm.Open panics on a nonce of the wrong length.
+	if len(env.Nonce) != gcm.NonceSize() {
+		return credFilePayload{}, unsupported
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Ciphertext, []byte(credFileAAD))
+	if err != nil {
+		return credFilePayload{}, &apperr.AppError{
+			Code:    apperr.CodeAuth,
+			Message: "failed decrypting credentials file; check " + PassphraseEnvVar,
+			Details: map[string]any{"path": path},
+		}
+	}
+	var payload credFilePayload
+	if err := json.Unmarshal(plain, &payload); err != nil {
+		unsupported.Cause = err
+		return credFilePayload{}, unsupported
+	}
+	return payload, nil
+}
+
+// sealCredentials encrypts payload under a fresh salt and nonce.
+func sealCredentials(payload credFilePayload, pass string) ([]byte, error) {
+	plain, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := credFileCipher(pass, salt, credFileIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	env := credFileEnvelope{
+		Version:    credFileVersion,
+		KDF:        credFileKDF,
+		Iterations: credFileIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plain, []byte(credFileAAD)),
+	}
+	b, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func credFileCipher(pass string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, pass, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func profileKey(profile string) string {
+	if profile == "" {
+		return config.DefaultProfile
+	}
+	return profile
+}
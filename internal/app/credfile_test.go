@@ -0,0 +1,91 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/sportwhiz/gdcli/internal/config"
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
+)
+
+func withCredHome(t *testing.T) string {
+	t.Helper()
+	t.Setenv(config.HomeEnvVar, t.TempDir())
+	path, err := credentialsFilePath()
+	if err != nil {
+		t.Fatalf("credentials path: %v", err)
+	}
+	return path
+}
+
+func TestReadCredentialsFileRejectsTamperedEnvelope(t *testing.T) {
+	path := withCredHome(t)
+	t.Setenv(PassphraseEnvVar, "correct horse")
+	if _, err := StoreCredentialsInFile("", "k", "s"); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var env credFileEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+
+	for name, tamper := range map[string]func(e *credFileEnvelope){
+		"short nonce":     func(e *credFileEnvelope) { e.Nonce = e.Nonce[:4] },
+		"huge iterations": func(e *credFileEnvelope) { e.Iterations = 1 << 40 },
+		"weak iterations": func(e *credFileEnvelope) { e.Iterations = 1 },
+	} {
+		bad := env
+		tamper(&bad)
+		b, _ := json.Marshal(bad)
+		if err := os.WriteFile(path, b, 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_, err := readCredentialsFile(path, "correct horse")
+		var ae *apperr.AppError
+		if !apperr.As(err, &ae) || ae.Code != apperr.CodeAuth {
+			t.Fatalf("%s: expected auth_error, got %v", name, err)
+		}
+	}
+}
+
+func TestPassphrasePromptsWhenEnvUnset(t *testing.T) {
+	withCredHome(t)
+	t.Setenv(PassphraseEnvVar, "")
+	prompts := 0
+	orig := readPassphrase
+	t.Cleanup(func() { readPassphrase, promptedPassphrase = orig, "" })
+	readPassphrase = func(string) string {
+		prompts++
+		return "typed secret"
+	}
+
+	if _, err := StoreCredentialsInFile("", "k", "s"); err != nil {
+		t.Fatalf("store with a typed passphrase: %v", err)
+	}
+	if prompts != 2 {
+		t.Fatalf("expected a new passphrase to be asked for twice, got %d prompts", prompts)
+	}
+	creds, ok, err := loadCredentialsFile(config.DefaultProfile)
+	if err != nil || !ok || creds.APIKey() != "k" {
+		t.Fatalf("expected credentials back with the cached passphrase, got %v %v", ok, err)
+	}
+	if prompts != 2 {
+		t.Fatalf("expected the typed passphrase to be reused within the run, got %d prompts", prompts)
+	}
+
+	promptedPassphrase = ""
+	readPassphrase = func(string) string { return "" }
+	_, _, err = loadCredentialsFile(config.DefaultProfile)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeAuth {
+		t.Fatalf("expected auth_error without a passphrase or terminal, got %v", err)
+	}
+	if want, _ := credentialsFilePath(); ae.Details["path"] != want {
+		t.Fatalf("expected the error to name the credentials file, got %+v", ae.Details)
+	}
+}
@@ -1,6 +1,9 @@
 package budget
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/sportwhiz/gdcli/internal/config"
@@ -8,37 +11,147 @@ import (
 	"github.com/sportwhiz/gdcli/internal/store"
 )
 
-func CheckPrice(cfg *config.Config, price float64, currency string) error {
-	if currency != "USD" {
-		return &apperr.AppError{Code: apperr.CodeValidation, Message: "only USD prices are supported in v1", Details: map[string]any{"currency": currency}}
+// CheckPrice rejects quotes in a currency the caps cannot be compared against and prices
+// above the domain's cap: its max_price_per_tld entry when one exists, otherwise
+// max_price_per_domain. Caps are denominated in the base currency.
+func CheckPrice(cfg *config.Config, domain string, price float64, currency string) error {
+	base, err := ToBase(cfg, price, currency)
+	if err != nil {
+		return err
+	}
+	details := map[string]any{"domain": domain, "price": price, "currency": currency}
+	if !strings.EqualFold(currency, BaseCurrency(cfg)) {
+		details["base_currency"] = BaseCurrency(cfg)
+		details["base_price"] = base
+	}
+	if tld, limit, ok := TLDPriceCap(cfg, domain); ok {
+		if base > limit {
+			details["cap"], details["tld"], details["max_price_per_tld"] = "tld", tld, limit
+			return &apperr.AppError{Code: apperr.CodeBudget, Message: "price exceeds max_price_per_tld for ." + tld, Details: details}
+		}
+		return nil
 	}
-	if price > cfg.MaxPricePerDomain {
-		return &apperr.AppError{Code: apperr.CodeBudget, Message: "price exceeds max_price_per_domain", Details: map[string]any{"price": price, "max_price_per_domain": cfg.MaxPricePerDomain}}
+	if base > cfg.MaxPricePerDomain {
+		details["cap"], details["max_price_per_domain"] = "global", cfg.MaxPricePerDomain
+		return &apperr.AppError{Code: apperr.CodeBudget, Message: "price exceeds max_price_per_domain", Details: details}
 	}
 	return nil
 }
 
-func CheckDailyCaps(cfg *config.Config, now time.Time, candidatePrice float64) error {
+// BaseCurrency is the currency caps are expressed in, USD unless base_currency says otherwise.
+func BaseCurrency(cfg *config.Config) string {
+	if c := strings.ToUpper(strings.TrimSpace(cfg.BaseCurrency)); c != "" {
+		return c
+	}
+	return "USD"
+}
+
+// ToBase converts amount to the base currency. An empty currency is taken to be the base;
+// any other currency needs a currency_rates entry, so caps are never compared across
+// currencies by accident.
+func ToBase(cfg *config.Config, amount float64, currency string) (float64, error) {
+	cur := strings.ToUpper(strings.TrimSpace(currency))
+	base := BaseCurrency(cfg)
+	if cur == "" || cur == base {
+		return amount, nil
+	}
+	if rate, ok := cfg.CurrencyRates[cur]; ok && rate > 0 {
+		return amount * rate, nil
+	}
+	return 0, &apperr.AppError{
+		Code:    apperr.CodeValidation,
+		Message: fmt.Sprintf("%s prices are not allowed; set base_currency to %s or add a currency_rates entry", cur, cur),
+		Details: map[string]any{"currency": cur, "base_currency": base},
+	}
+}
+
+// OperationSpend is op's amount in the base currency. Logged operations passed ToBase when
+// they were reserved, so a rate removed since then falls back to the raw amount.
+func OperationSpend(cfg *config.Config, op store.Operation) float64 {
+	v, err := ToBase(cfg, op.Amount, op.Currency)
+	if err != nil {
+		return op.Amount
+	}
+	return v
+}
+
+// TLDPriceCap finds the most specific max_price_per_tld entry for domain, so "co.uk" wins
+// over "uk" for example.co.uk.
+func TLDPriceCap(cfg *config.Config, domain string) (string, float64, bool) {
+	if len(cfg.MaxPricePerTLD) == 0 {
+		return "", 0, false
+	}
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(domain, ".")), ".")
+	for i := 1; i < len(labels); i++ {
+		tld := strings.Join(labels[i:], ".")
+		if limit, ok := cfg.MaxPricePerTLD[tld]; ok {
+			return tld, limit, true
+		}
+	}
+	return "", 0, false
+}
+
+// NormalizeTLD lowercases a TLD and drops its leading dot, the form used as a
+// max_price_per_tld key.
+func NormalizeTLD(tld string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tld), "."))
+}
+
+// CheckPriceFloor rejects quotes below floor, which usually indicate misreported (e.g.
+// premium) pricing. floor is min_plausible_price in the base currency unless the caller
+// overrides it for one run; zero disables the check. price is converted with ToBase first.
+func CheckPriceFloor(cfg *config.Config, floor, price float64, currency string) error {
+	if floor <= 0 {
+		return nil
+	}
+	base, err := ToBase(cfg, price, currency)
+	if err != nil {
+		return err
+	}
+	if base >= floor {
+		return nil
+	}
+	details := map[string]any{"price": price, "currency": currency, "min_plausible_price": floor}
+	if !strings.EqualFold(currency, BaseCurrency(cfg)) {
+		details["base_currency"] = BaseCurrency(cfg)
+		details["base_price"] = base
+	}
+	return &apperr.AppError{
+		Code:    apperr.CodeSafety,
+		Message: "quoted price is implausibly low; refusing to act on suspicious pricing (pass --allow-below-floor to override)",
+		Details: details,
+	}
+}
+
+// CheckCaps checks candidatePrice (in currency) against the daily spend and domain caps and,
+// when configured, the calendar-month spend cap in now's time zone.
+func CheckCaps(cfg *config.Config, now time.Time, candidatePrice float64, currency string) error {
+	candidatePrice, err := ToBase(cfg, candidatePrice, currency)
+	if err != nil {
+		return err
+	}
 	ops, err := store.ReadOperations()
 	if err != nil {
 		return err
 	}
 	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	dayEnd := dayStart.Add(24 * time.Hour)
+	monthStart, monthEnd := MonthBounds(now)
 
 	totalSpend := 0.0
 	totalDomains := 0
+	monthSpend := 0.0
 	for _, op := range ops {
-		if op.CreatedAt.Before(dayStart) || !op.CreatedAt.Before(dayEnd) {
+		if !countsTowardCaps(op) {
 			continue
 		}
-		if op.Status != "succeeded" {
-			continue
+		if !op.CreatedAt.Before(monthStart) && op.CreatedAt.Before(monthEnd) {
+			monthSpend += OperationSpend(cfg, op)
 		}
-		if op.Type != "purchase" && op.Type != "renew" {
+		if op.CreatedAt.Before(dayStart) || !op.CreatedAt.Before(dayEnd) {
 			continue
 		}
-		totalSpend += op.Amount
+		totalSpend += OperationSpend(cfg, op)
 		totalDomains++
 	}
 
@@ -48,5 +161,130 @@ func CheckDailyCaps(cfg *config.Config, now time.Time, candidatePrice float64) e
 	if totalDomains+1 > cfg.MaxDomainsPerDay {
 		return &apperr.AppError{Code: apperr.CodeBudget, Message: "daily domain count cap exceeded", Details: map[string]any{"attempted_total": totalDomains + 1, "max_domains_per_day": cfg.MaxDomainsPerDay}}
 	}
-	return nil
+	return CheckMonthlySpend(cfg, monthSpend+candidatePrice)
+}
+
+// IsCapExceeded reports whether err is a daily or monthly cap violation, as opposed to a
+// per-domain price limit. Cap violations stay true for every later operation that day.
+func IsCapExceeded(err error) bool {
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeBudget {
+		return false
+	}
+	for _, k := range []string{"max_daily_spend", "max_domains_per_day", "max_monthly_spend"} {
+		if _, ok := ae.Details[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MonthBounds returns the calendar month containing t, in t's time zone.
+func MonthBounds(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// CheckMonthlySpend rejects a month total above max_monthly_spend. A zero cap disables it.
+func CheckMonthlySpend(cfg *config.Config, attemptedTotal float64) error {
+	if cfg.MaxMonthlySpend <= 0 || attemptedTotal <= cfg.MaxMonthlySpend {
+		return nil
+	}
+	return &apperr.AppError{Code: apperr.CodeBudget, Message: "monthly spend cap exceeded", Details: map[string]any{"attempted_total": attemptedTotal, "max_monthly_spend": cfg.MaxMonthlySpend}}
+}
+
+// countsTowardCaps reports whether op is spend that the caps limit.
+func countsTowardCaps(op store.Operation) bool {
+	return op.Status == "succeeded" && (op.Type == "purchase" || op.Type == "renew")
+}
+
+// DomainSpend is the spend attributed to one domain within a report window.
+type DomainSpend struct {
+	Domain     string  `json:"domain"`
+	Amount     float64 `json:"amount"`
+	Operations int     `json:"operations"`
+}
+
+// Window aggregates succeeded purchases and renewals between Start and End.
+type Window struct {
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	Spend      float64       `json:"spend"`
+	Operations int           `json:"operations"`
+	ByDomain   []DomainSpend `json:"by_domain"`
+}
+
+// Report is the spend so far today and this month together with the headroom left
+// under the daily caps.
+type Report struct {
+	Currency              string             `json:"currency"`
+	Today                 Window             `json:"today"`
+	Month                 Window             `json:"month"`
+	MaxDailySpend         float64            `json:"max_daily_spend"`
+	MaxDomainsPerDay      int                `json:"max_domains_per_day"`
+	RemainingDailySpend   float64            `json:"remaining_daily_spend"`
+	RemainingDomainsToday int                `json:"remaining_domains_today"`
+	MaxPricePerDomain     float64            `json:"max_price_per_domain"`
+	MaxPricePerTLD        map[string]float64 `json:"max_price_per_tld,omitempty"`
+	MaxMonthlySpend       float64            `json:"max_monthly_spend,omitempty"`
+	// RemainingMonthlySpend is nil when no monthly cap is configured.
+	RemainingMonthlySpend *float64 `json:"remaining_monthly_spend,omitempty"`
+}
+
+// BuildReport reads the operations log and summarizes spend for the day and month
+// containing now, using the same rules as CheckCaps.
+func BuildReport(cfg *config.Config, now time.Time) (*Report, error) {
+	ops, err := store.ReadOperations()
+	if err != nil {
+		return nil, err
+	}
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart, monthEnd := MonthBounds(now)
+	r := &Report{
+		Currency:          BaseCurrency(cfg),
+		Today:             aggregate(cfg, ops, dayStart, dayStart.Add(24*time.Hour)),
+		Month:             aggregate(cfg, ops, monthStart, monthEnd),
+		MaxDailySpend:     cfg.MaxDailySpend,
+		MaxDomainsPerDay:  cfg.MaxDomainsPerDay,
+		MaxPricePerDomain: cfg.MaxPricePerDomain,
+		MaxPricePerTLD:    cfg.MaxPricePerTLD,
+	}
+	r.RemainingDailySpend = max(cfg.MaxDailySpend-r.Today.Spend, 0)
+	r.RemainingDomainsToday = max(cfg.MaxDomainsPerDay-r.Today.Operations, 0)
+	if cfg.MaxMonthlySpend > 0 {
+		r.MaxMonthlySpend = cfg.MaxMonthlySpend
+		remaining := max(cfg.MaxMonthlySpend-r.Month.Spend, 0)
+		r.RemainingMonthlySpend = &remaining
+	}
+	return r, nil
+}
+
+func aggregate(cfg *config.Config, ops []store.Operation, start, end time.Time) Window {
+	w := Window{Start: start, End: end, ByDomain: []DomainSpend{}}
+	byDomain := map[string]*DomainSpend{}
+	for _, op := range ops {
+		if op.CreatedAt.Before(start) || !op.CreatedAt.Before(end) || !countsTowardCaps(op) {
+			continue
+		}
+		spend := OperationSpend(cfg, op)
+		w.Spend += spend
+		w.Operations++
+		d, ok := byDomain[op.Domain]
+		if !ok {
+			d = &DomainSpend{Domain: op.Domain}
+			byDomain[op.Domain] = d
+		}
+		d.Amount += spend
+		d.Operations++
+	}
+	for _, d := range byDomain {
+		w.ByDomain = append(w.ByDomain, *d)
+	}
+	sort.Slice(w.ByDomain, func(i, j int) bool {
+		if w.ByDomain[i].Amount != w.ByDomain[j].Amount {
+			return w.ByDomain[i].Amount > w.ByDomain[j].Amount
+		}
+		return w.ByDomain[i].Domain < w.ByDomain[j].Domain
+	})
+	return w
 }
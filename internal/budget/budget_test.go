@@ -5,10 +5,11 @@ import (
 	"time"
 
 	"github.com/sportwhiz/gdcli/internal/config"
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
 	"github.com/sportwhiz/gdcli/internal/store"
 )
 
-func TestCheckDailyCaps(t *testing.T) {
+func TestCheckCaps(t *testing.T) {
 	t.Setenv("HOME", t.TempDir())
 	cfg := config.Default()
 	cfg.MaxDailySpend = 100
@@ -18,18 +19,127 @@ func TestCheckDailyCaps(t *testing.T) {
 	_ = store.AppendOperation(store.Operation{OperationID: "1", Type: "purchase", Domain: "a.com", Amount: 40, Currency: "USD", CreatedAt: now, Status: "succeeded"})
 	_ = store.AppendOperation(store.Operation{OperationID: "2", Type: "renew", Domain: "b.com", Amount: 40, Currency: "USD", CreatedAt: now, Status: "succeeded"})
 
-	if err := CheckDailyCaps(cfg, now, 10); err == nil {
+	if err := CheckCaps(cfg, now, 10, "USD"); err == nil {
 		t.Fatalf("expected domains/day cap to fail")
 	}
 }
 
+func TestIsCapExceeded(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.MaxDomainsPerDay = 0
+	if err := CheckCaps(cfg, time.Now(), 1, "USD"); !IsCapExceeded(err) {
+		t.Fatalf("expected cap error, got %v", err)
+	}
+	cfg.MaxPricePerDomain = 5
+	if err := CheckPrice(cfg, "example.com", 10, "USD"); err == nil || IsCapExceeded(err) {
+		t.Fatalf("per-domain price limit is not a cap: %v", err)
+	}
+}
+
 func TestCheckPrice(t *testing.T) {
 	cfg := config.Default()
 	cfg.MaxPricePerDomain = 20
-	if err := CheckPrice(cfg, 25, "USD"); err == nil {
+	if err := CheckPrice(cfg, "example.com", 25, "USD"); err == nil {
 		t.Fatalf("expected max price failure")
 	}
-	if err := CheckPrice(cfg, 10, "EUR"); err == nil {
+	if err := CheckPrice(cfg, "example.com", 10, "EUR"); err == nil {
 		t.Fatalf("expected currency validation failure")
 	}
 }
+
+func TestCheckPricePerTLD(t *testing.T) {
+	cfg := config.Default()
+	cfg.MaxPricePerDomain = 20
+	cfg.MaxPricePerTLD = map[string]float64{"ai": 80, "co.uk": 5, "uk": 50}
+	if err := CheckPrice(cfg, "example.ai", 75, "USD"); err != nil {
+		t.Fatalf("expected .ai cap to allow 75: %v", err)
+	}
+	err := CheckPrice(cfg, "example.ai", 90, "USD")
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Details["cap"] != "tld" || ae.Details["tld"] != "ai" || ae.Details["max_price_per_tld"] != 80.0 {
+		t.Fatalf("expected tld cap violation, got %v", err)
+	}
+	if err := CheckPrice(cfg, "example.co.uk", 10, "USD"); !apperr.As(err, &ae) || ae.Details["tld"] != "co.uk" {
+		t.Fatalf("expected the most specific suffix to apply, got %v", err)
+	}
+	if err := CheckPrice(cfg, "example.com", 21, "USD"); !apperr.As(err, &ae) || ae.Details["cap"] != "global" {
+		t.Fatalf("expected global cap violation, got %v", err)
+	}
+}
+
+func TestCheckPriceFloor(t *testing.T) {
+	cfg := config.Default()
+	if err := CheckPriceFloor(cfg, cfg.MinPlausiblePrice, 0.01, "USD"); err != nil {
+		t.Fatalf("expected floor disabled by default: %v", err)
+	}
+	if err := CheckPriceFloor(cfg, 5, 0.99, "USD"); err == nil {
+		t.Fatalf("expected suspiciously cheap price to fail")
+	}
+	if err := CheckPriceFloor(cfg, 5, 12.99, "USD"); err != nil {
+		t.Fatalf("expected normal price to pass: %v", err)
+	}
+
+	// 600 JPY is about 4 USD: below a 5 USD floor even though 600 > 5.
+	cfg.CurrencyRates = map[string]float64{"JPY": 0.0067}
+	var ae *apperr.AppError
+	if err := CheckPriceFloor(cfg, 5, 600, "JPY"); !apperr.As(err, &ae) || ae.Details["base_currency"] != "USD" {
+		t.Fatalf("expected the JPY quote to be converted before the floor check, got %v", err)
+	}
+	if err := CheckPriceFloor(cfg, 5, 1200, "JPY"); err != nil {
+		t.Fatalf("expected 1200 JPY to clear a 5 USD floor: %v", err)
+	}
+	if err := CheckPriceFloor(cfg, 5, 10, "EUR"); err == nil {
+		t.Fatalf("expected an unconvertible currency to be refused")
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.MaxDailySpend = 100
+	cfg.MaxDomainsPerDay = 5
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	_ = store.AppendOperation(store.Operation{OperationID: "1", Type: "purchase", Domain: "a.com", Amount: 20, Currency: "USD", CreatedAt: now, Status: "succeeded"})
+	_ = store.AppendOperation(store.Operation{OperationID: "2", Type: "renew", Domain: "a.com", Amount: 15, Currency: "USD", CreatedAt: now, Status: "succeeded"})
+	_ = store.AppendOperation(store.Operation{OperationID: "3", Type: "purchase", Domain: "b.com", Amount: 30, Currency: "USD", CreatedAt: now.AddDate(0, 0, -3), Status: "succeeded"})
+	_ = store.AppendOperation(store.Operation{OperationID: "4", Type: "purchase", Domain: "c.com", Amount: 50, Currency: "USD", CreatedAt: now, Status: "failed"})
+	_ = store.AppendOperation(store.Operation{OperationID: "5", Type: "purchase", Domain: "d.com", Amount: 70, Currency: "USD", CreatedAt: now.AddDate(0, -1, 0), Status: "succeeded"})
+
+	r, err := BuildReport(cfg, now)
+	if err != nil {
+		t.Fatalf("BuildReport: %v", err)
+	}
+	if r.Today.Spend != 35 || r.Today.Operations != 2 || r.RemainingDailySpend != 65 || r.RemainingDomainsToday != 3 {
+		t.Fatalf("unexpected today totals: %+v", r)
+	}
+	if len(r.Today.ByDomain) != 1 || r.Today.ByDomain[0].Domain != "a.com" || r.Today.ByDomain[0].Operations != 2 {
+		t.Fatalf("unexpected today breakdown: %+v", r.Today.ByDomain)
+	}
+	if r.Month.Spend != 65 || len(r.Month.ByDomain) != 2 || r.Month.ByDomain[0].Domain != "a.com" {
+		t.Fatalf("unexpected month totals: %+v", r.Month)
+	}
+}
+
+func TestCheckCapsMonthly(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.MaxDailySpend = 100
+	cfg.MaxMonthlySpend = 60
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	_ = store.AppendOperation(store.Operation{OperationID: "1", Type: "purchase", Domain: "a.com", Amount: 50, Currency: "USD", CreatedAt: now.AddDate(0, 0, -10), Status: "succeeded"})
+	_ = store.AppendOperation(store.Operation{OperationID: "2", Type: "purchase", Domain: "b.com", Amount: 50, Currency: "USD", CreatedAt: now.AddDate(0, -1, 0), Status: "succeeded"})
+
+	if err := CheckCaps(cfg, now, 5, "USD"); err != nil {
+		t.Fatalf("expected purchase within monthly cap to pass: %v", err)
+	}
+	if err := CheckCaps(cfg, now, 15, "USD"); err == nil {
+		t.Fatalf("expected monthly cap to fail although the daily cap passes")
+	}
+	cfg.MaxMonthlySpend = 0
+	if err := CheckCaps(cfg, now, 15, "USD"); err != nil {
+		t.Fatalf("expected zero monthly cap to disable the check: %v", err)
+	}
+}
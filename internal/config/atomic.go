@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// BackupSuffix names the copy of the previous file kept by WriteFileAtomic.
+const BackupSuffix = ".bak"
+
+// WriteFileAtomic writes data to a temp file next to path, syncs it, and renames it over path,
+// so a crash leaves either the old or the new contents. With backup set, the previous
+// contents are first saved as path+BackupSuffix.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode, backup bool) error {
+	path = filepath.Clean(path)
+	if backup {
+		// #nosec G304 -- path is a gdcli state file inside the config directory.
+		prev, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := WriteFileAtomic(path+BackupSuffix, prev, perm, false); err != nil {
+				return err
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpName)
+		}
+	}()
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	committed = true
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the rename to disk where the platform allows syncing a directory.
+func syncDir(dir string) {
+	// #nosec G304 -- dir is the gdcli config directory.
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 )
 
 const (
@@ -13,35 +16,104 @@ const (
 )
 
 type Config struct {
-	APIEnvironment      string  `json:"api_environment"`
-	ShopperID           string  `json:"shopper_id,omitempty"`
-	CustomerID          string  `json:"customer_id,omitempty"`
-	CustomerIDResolved  string  `json:"customer_id_resolved_at,omitempty"`
-	CustomerIDSource    string  `json:"customer_id_source,omitempty"`
-	AutoPurchaseEnabled bool    `json:"auto_purchase_enabled"`
-	AcknowledgmentHash  string  `json:"acknowledgment_hash,omitempty"`
-	MaxPricePerDomain   float64 `json:"max_price_per_domain"`
-	MaxDailySpend       float64 `json:"max_daily_spend"`
-	MaxDomainsPerDay    int     `json:"max_domains_per_day"`
-	DefaultYears        int     `json:"default_years"`
-	DefaultDNSTemplate  string  `json:"default_dns_template"`
-	OutputDefault       string  `json:"output_default"`
+	APIEnvironment             string             `json:"api_environment"`
+	ShopperID                  string             `json:"shopper_id,omitempty"`
+	CustomerID                 string             `json:"customer_id,omitempty"`
+	CustomerIDResolved         string             `json:"customer_id_resolved_at,omitempty"`
+	CustomerIDSource           string             `json:"customer_id_source,omitempty"`
+	AutoPurchaseEnabled        bool               `json:"auto_purchase_enabled"`
+	AcknowledgmentHash         string             `json:"acknowledgment_hash,omitempty"`
+	AutoRequireDefinitive      bool               `json:"auto_require_definitive"`
+	RequireOTEFirst            bool               `json:"require_ote_first,omitempty"`
+	MaxPricePerDomain          float64            `json:"max_price_per_domain"`
+	MaxPricePerTLD             map[string]float64 `json:"max_price_per_tld,omitempty"`
+	MaxDailySpend              float64            `json:"max_daily_spend"`
+	MaxDomainsPerDay           int                `json:"max_domains_per_day"`
+	MaxMonthlySpend            float64            `json:"max_monthly_spend,omitempty"`
+	BaseCurrency               string             `json:"base_currency,omitempty"`
+	CurrencyRates              map[string]float64 `json:"currency_rates,omitempty"`
+	ConfirmTokenTTLMinutes     int                `json:"confirm_token_ttl_minutes,omitempty"`
+	MinPlausiblePrice          float64            `json:"min_plausible_price,omitempty"`
+	DefaultYears               int                `json:"default_years"`
+	DefaultDNSTemplate         string             `json:"default_dns_template"`
+	OutputDefault              string             `json:"output_default"`
+	UpdateNoticeStream         string             `json:"update_notice_stream,omitempty"`
+	UpdateChannel              string             `json:"update_channel,omitempty"`
+	HTTPMaxIdleConnsPerHost    int                `json:"http_max_idle_conns_per_host,omitempty"`
+	HTTPIdleConnTimeoutSeconds int                `json:"http_idle_conn_timeout_seconds,omitempty"`
+	HTTPTimeoutSeconds         int                `json:"http_timeout_seconds,omitempty"`
+	MaxBulkItems               int                `json:"max_bulk_items"`
+	MaxConcurrency             int                `json:"max_concurrency,omitempty"`
+	RetryAttempts              int                `json:"retry_attempts,omitempty"`
+	RetryBaseMs                int                `json:"retry_base_ms,omitempty"`
+	ActiveProfile              string             `json:"active_profile,omitempty"`
+	Profiles                   map[string]Config  `json:"profiles,omitempty"`
+
+	// profile is the name this Config was resolved for; root is the file it belongs to.
+	profile string
+	root    *Config
 }
 
 func Default() *Config {
 	return &Config{
-		APIEnvironment:      "prod",
-		AutoPurchaseEnabled: false,
-		MaxPricePerDomain:   25,
-		MaxDailySpend:       100,
-		MaxDomainsPerDay:    5,
-		DefaultYears:        1,
-		DefaultDNSTemplate:  "afternic-nameservers",
-		OutputDefault:       "json",
+		APIEnvironment:         "prod",
+		AutoPurchaseEnabled:    false,
+		AutoRequireDefinitive:  true,
+		MaxPricePerDomain:      25,
+		MaxDailySpend:          100,
+		MaxDomainsPerDay:       5,
+		BaseCurrency:           "USD",
+		DefaultYears:           1,
+		DefaultDNSTemplate:     "afternic-nameservers",
+		OutputDefault:          "json",
+		UpdateNoticeStream:     "stderr",
+		UpdateChannel:          "stable",
+		MaxBulkItems:           10000,
+		MaxConcurrency:         20,
+		RetryAttempts:          3,
+		RetryBaseMs:            250,
+		ConfirmTokenTTLMinutes: 10,
+		HTTPTimeoutSeconds:     20,
 	}
 }
 
+// HomeEnvVar overrides the directory holding config.json and the state files.
+const HomeEnvVar = "GDCLI_CONFIG_HOME"
+
+// pathOverride is the config file chosen with the --config global flag.
+var pathOverride string
+
+// SetPath points Load/Save at an explicit config file; state files live next to it.
+// An empty path restores the default resolution.
+func SetPath(path string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		pathOverride = ""
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(home, rest)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	pathOverride = abs
+	return nil
+}
+
+// HomeDir resolves the state directory: --config's directory, then $GDCLI_CONFIG_HOME, then ~/.gdcli.
 func HomeDir() (string, error) {
+	if pathOverride != "" {
+		return filepath.Dir(pathOverride), nil
+	}
+	if dir := strings.TrimSpace(os.Getenv(HomeEnvVar)); dir != "" {
+		return filepath.Abs(dir)
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -50,6 +122,9 @@ func HomeDir() (string, error) {
 }
 
 func Path() (string, error) {
+	if pathOverride != "" {
+		return pathOverride, nil
+	}
 	home, err := HomeDir()
 	if err != nil {
 		return "", err
@@ -68,13 +143,104 @@ func EnsureDir() (string, error) {
 	return dir, nil
 }
 
+// DefaultProfile names the top-level settings in config.json.
+const DefaultProfile = "default"
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// profileOverride is the profile chosen with the --profile global flag.
+var profileOverride string
+
+// UnknownProfileError reports a profile name that is not defined in config.json.
+type UnknownProfileError struct {
+	Name string
+}
+
+func (e *UnknownProfileError) Error() string {
+	return fmt.Sprintf("unknown profile %q", e.Name)
+}
+
+func ValidProfileName(name string) bool {
+	return profileNamePattern.MatchString(name)
+}
+
+// SetProfile selects a profile for this invocation, overriding active_profile.
+func SetProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name != "" && !ValidProfileName(name) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	profileOverride = name
+	return nil
+}
+
+// Reset restores default values in place. Profile bookkeeping is always kept; the shopper and
+// customer identity is kept when keepIdentity is true.
+func (c *Config) Reset(keepIdentity bool) {
+	fresh := Default()
+	fresh.ActiveProfile = c.ActiveProfile
+	fresh.Profiles = c.Profiles
+	fresh.profile = c.profile
+	fresh.root = c.root
+	if keepIdentity {
+		fresh.ShopperID = c.ShopperID
+		fresh.CustomerID = c.CustomerID
+		fresh.CustomerIDResolved = c.CustomerIDResolved
+		fresh.CustomerIDSource = c.CustomerIDSource
+	}
+	*c = *fresh
+}
+
+// Profile returns the profile name this config was resolved for.
+func (c *Config) Profile() string {
+	if c.profile == "" {
+		return DefaultProfile
+	}
+	return c.profile
+}
+
+// ProfileNames lists the default profile followed by named profiles in sorted order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// Load returns the effective config for the selected profile (--profile, then active_profile).
 func Load() (*Config, error) {
+	root, err := LoadRoot()
+	if err != nil {
+		return nil, err
+	}
+	name := profileOverride
+	if name == "" {
+		name = root.ActiveProfile
+	}
+	if name == "" || name == DefaultProfile {
+		return root, nil
+	}
+	p, ok := root.Profiles[name]
+	if !ok {
+		return nil, &UnknownProfileError{Name: name}
+	}
+	p.Profiles = nil
+	p.ActiveProfile = ""
+	p.profile = name
+	p.root = root
+	return &p, nil
+}
+
+// LoadRoot reads config.json itself, without resolving profiles.
+func LoadRoot() (*Config, error) {
 	path, err := Path()
 	if err != nil {
 		return nil, err
 	}
 	path = filepath.Clean(path)
-	// #nosec G304 -- path is derived from user home + fixed filename.
+	// #nosec G304 -- path is derived from user home + fixed filename, or the user's --config choice.
 	b, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -90,10 +256,41 @@ func Load() (*Config, error) {
 	if err := json.Unmarshal(b, cfg); err != nil {
 		return nil, err
 	}
+	// Decode profiles on top of defaults so keys missing from a profile keep default values.
+	var raw struct {
+		Profiles map[string]json.RawMessage `json:"profiles"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	for name, msg := range raw.Profiles {
+		p := Default()
+		if err := json.Unmarshal(msg, p); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		cfg.Profiles[name] = *p
+	}
 	return cfg, nil
 }
 
+// Save writes cfg back to config.json; a profile config is stored under its profile entry.
 func Save(cfg *Config) error {
+	if cfg.root != nil && cfg.profile != "" && cfg.profile != DefaultProfile {
+		p := *cfg
+		p.profile = ""
+		p.root = nil
+		p.Profiles = nil
+		p.ActiveProfile = ""
+		if cfg.root.Profiles == nil {
+			cfg.root.Profiles = map[string]Config{}
+		}
+		cfg.root.Profiles[cfg.profile] = p
+		return saveFile(cfg.root)
+	}
+	return saveFile(cfg)
+}
+
+func saveFile(cfg *Config) error {
 	if _, err := EnsureDir(); err != nil {
 		return err
 	}
@@ -106,5 +303,5 @@ func Save(cfg *Config) error {
 		return err
 	}
 	b = append(b, '\n')
-	return os.WriteFile(path, b, 0o600)
+	return WriteFileAtomic(path, b, 0o600, false)
 }
@@ -3,6 +3,7 @@ package errors
 import (
 	stderrors "errors"
 	"fmt"
+	"time"
 )
 
 type Code string
@@ -12,6 +13,7 @@ const (
 	CodeAuth         Code = "auth_error"
 	CodeRateLimited  Code = "rate_limited"
 	CodeProvider     Code = "provider_error"
+	CodeNetwork      Code = "network_error"
 	CodeBudget       Code = "budget_violation"
 	CodeConfirmation Code = "confirmation_error"
 	CodeSafety       Code = "safety_policy_violation"
@@ -19,6 +21,31 @@ const (
 	CodeInternal     Code = "internal_error"
 )
 
+// DetailRetryAfter is the Details key holding the provider's Retry-After delay in seconds.
+const DetailRetryAfter = "retry_after_seconds"
+
+// DetailRequestID is the Details key holding the X-Request-Id sent with a failed provider call.
+const DetailRequestID = "request_id"
+
+// TroubleshootingURL is the published troubleshooting guide that DocURL links point into.
+const TroubleshootingURL = "https://github.com/sportwhiz/gdcli/blob/main/docs/troubleshooting.md"
+
+// DocInvalidPaymentInfo is the DocURL for renewals GoDaddy refused with INVALID_PAYMENT_INFO.
+const DocInvalidPaymentInfo = TroubleshootingURL + "#invalid_payment_info"
+
+// docURLs holds the troubleshooting section for each code that has a clear next step.
+// Internal, provider, and partial errors are left out on purpose.
+var docURLs = map[Code]string{
+	CodeAuth:   TroubleshootingURL + "#auth_error",
+	CodeBudget: TroubleshootingURL + "#budget_violation",
+	CodeSafety: TroubleshootingURL + "#safety_policy_violation",
+}
+
+// DocURLFor returns the troubleshooting link for code, or "" when it has none.
+func DocURLFor(code Code) string {
+	return docURLs[code]
+}
+
 type AppError struct {
 	Code      Code           `json:"code"`
 	Message   string         `json:"message"`
@@ -53,6 +80,28 @@ func WithDetails(err *AppError, details map[string]any) *AppError {
 	return err
 }
 
+// RetryAfter returns the provider-requested delay recorded on err, if any.
+func RetryAfter(err error) (time.Duration, bool) {
+	var appErr *AppError
+	if !As(err, &appErr) || appErr.Details == nil {
+		return 0, false
+	}
+	secs, ok := appErr.Details[DetailRetryAfter].(float64)
+	if !ok || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs * float64(time.Second)), true
+}
+
+// CodeOf returns err's code, or CodeInternal for errors that are not an AppError.
+func CodeOf(err error) Code {
+	var appErr *AppError
+	if !As(err, &appErr) {
+		return CodeInternal
+	}
+	return appErr.Code
+}
+
 func ExitCode(err error) int {
 	if err == nil {
 		return 0
@@ -78,6 +127,8 @@ func ExitCode(err error) int {
 		return 8
 	case CodePartial:
 		return 9
+	case CodeNetwork:
+		return 10
 	default:
 		return 5
 	}
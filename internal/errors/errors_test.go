@@ -1,6 +1,10 @@
 package errors
 
-import "testing"
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+)
 
 func TestExitCodes(t *testing.T) {
 	cases := []struct {
@@ -15,6 +19,7 @@ func TestExitCodes(t *testing.T) {
 		{&AppError{Code: CodeConfirmation}, 7},
 		{&AppError{Code: CodeSafety}, 8},
 		{&AppError{Code: CodePartial}, 9},
+		{&AppError{Code: CodeNetwork}, 10},
 	}
 	for _, c := range cases {
 		if got := ExitCode(c.err); got != c.code {
@@ -22,3 +27,22 @@ func TestExitCodes(t *testing.T) {
 		}
 	}
 }
+
+func TestCodeOf(t *testing.T) {
+	wrapped := fmt.Errorf("lookup: %w", &AppError{Code: CodeRateLimited})
+	if got := CodeOf(wrapped); got != CodeRateLimited {
+		t.Fatalf("expected rate_limited, got %s", got)
+	}
+	if got := CodeOf(stderrors.New("boom")); got != CodeInternal {
+		t.Fatalf("expected internal_error for plain errors, got %s", got)
+	}
+}
+
+func TestDocURLFor(t *testing.T) {
+	if got := DocURLFor(CodeBudget); got != TroubleshootingURL+"#budget_violation" {
+		t.Fatalf("unexpected budget doc url %q", got)
+	}
+	if got := DocURLFor(CodeInternal); got != "" {
+		t.Fatalf("expected no doc url for internal errors, got %q", got)
+	}
+}
@@ -4,17 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
+	"github.com/sportwhiz/gdcli/internal/output"
 )
 
 type Client interface {
@@ -37,8 +40,23 @@ type HTTPClient struct {
 	apiKey     string
 	apiSecret  string
 	httpClient *http.Client
+	timeout    time.Duration
+	log        *output.Logger
+	requestID  string
+	dryRun     bool
 }
 
+// Connection pool defaults. Go's default of 2 idle connections per host forces fresh TLS
+// handshakes once bulk commands run more than a couple of requests concurrently.
+const (
+	DefaultMaxIdleConnsPerHost = 20
+	DefaultIdleConnTimeout     = 90 * time.Second
+	// DefaultTimeout bounds a single request, including reading the response body.
+	DefaultTimeout = 20 * time.Second
+	// bulkTimeoutFactor stretches the timeout for endpoints that return bulk responses.
+	bulkTimeoutFactor = 3
+)
+
 const (
 	smallResponseLimitBytes = int64(2 << 20)
 	bulkResponseLimitBytes  = int64(50 << 20)
@@ -63,21 +81,33 @@ type Suggestion struct {
 }
 
 type Availability struct {
-	Domain     string  `json:"domain"`
-	Available  bool    `json:"available"`
-	Definitive bool    `json:"definitive,omitempty"`
-	Price      float64 `json:"price,omitempty"`
-	Currency   string  `json:"currency,omitempty"`
-	PriceRaw   float64 `json:"price_raw,omitempty"`
-	PriceUnit  string  `json:"price_unit,omitempty"`
+	Domain string `json:"domain"`
+	// DomainUnicode is the readable form of an internationalized (xn--) Domain.
+	DomainUnicode string  `json:"domain_unicode,omitempty"`
+	Available     bool    `json:"available"`
+	Definitive    bool    `json:"definitive,omitempty"`
+	Price         float64 `json:"price,omitempty"`
+	Currency      string  `json:"currency,omitempty"`
+	PriceRaw      float64 `json:"price_raw,omitempty"`
+	PriceUnit     string  `json:"price_unit,omitempty"`
+	// Period is the registration term in years that Price covers, when the provider reports it.
+	Period int `json:"period,omitempty"`
+	// RenewalPrice is the yearly renewal price in major units, when the provider reports it.
+	RenewalPrice float64 `json:"renewal_price,omitempty"`
 }
 
 type PurchaseResult struct {
 	Domain        string  `json:"domain"`
+	DomainUnicode string  `json:"domain_unicode,omitempty"`
 	Price         float64 `json:"price"`
 	Currency      string  `json:"currency"`
 	OrderID       string  `json:"order_id,omitempty"`
 	AlreadyBought bool    `json:"already_bought,omitempty"`
+	// OperationKey is the X-Idempotency-Key the order was placed under; retries reuse it.
+	OperationKey string `json:"operation_key,omitempty"`
+	// DryRun marks a purchase the global --dry-run stopped before anything was reserved or sent.
+	DryRun bool `json:"dry_run,omitempty"`
+	Years  int  `json:"years,omitempty"`
 }
 
 type RenewResult struct {
@@ -101,16 +131,63 @@ type RenewV2Request struct {
 	Period  int            `json:"period,omitempty"`
 }
 
+type Shopper struct {
+	ShopperID  string `json:"shopper_id"`
+	CustomerID string `json:"customer_id,omitempty"`
+	Email      string `json:"email,omitempty"`
+	NameFirst  string `json:"name_first,omitempty"`
+	NameLast   string `json:"name_last,omitempty"`
+	MarketID   string `json:"market_id,omitempty"`
+}
+
 type PortfolioDomain struct {
 	Domain  string `json:"domain"`
 	Expires string `json:"expires"`
 }
 
-type DNSRecord struct {
-	Type string `json:"type"`
+type TLD struct {
 	Name string `json:"name"`
-	Data string `json:"data"`
-	TTL  int    `json:"ttl,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// Agreement is a legal agreement required to register or transfer a domain.
+type Agreement struct {
+	Key     string `json:"key"`
+	Title   string `json:"title"`
+	URL     string `json:"url,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type DNSRecord struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+	TTL      int    `json:"ttl,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+	Weight   int    `json:"weight,omitempty"`
+	Port     int    `json:"port,omitempty"`
+}
+
+// MarshalJSON always sends priority on MX and SRV records, and weight and port on SRV, where 0
+// is a real value (Microsoft 365 publishes its MX at priority 0) that omitempty would otherwise
+// drop.
+func (r DNSRecord) MarshalJSON() ([]byte, error) {
+	type plain DNSRecord
+	switch strings.ToUpper(strings.TrimSpace(r.Type)) {
+	case "MX":
+		return json.Marshal(struct {
+			plain
+			Priority int `json:"priority"`
+		}{plain(r), r.Priority})
+	case "SRV":
+		return json.Marshal(struct {
+			plain
+			Priority int `json:"priority"`
+			Weight   int `json:"weight"`
+			Port     int `json:"port"`
+		}{plain(r), r.Priority, r.Weight, r.Port})
+	}
+	return json.Marshal(plain(r))
 }
 
 type Pagination struct {
@@ -177,13 +254,175 @@ func NewHTTPClient(baseURL, key, secret string) (*HTTPClient, error) {
 		return nil, err
 	}
 	return &HTTPClient{
-		baseURL:    strings.TrimSuffix(baseURL, "/"),
-		apiKey:     key,
-		apiSecret:  secret,
-		httpClient: &http.Client{Timeout: 20 * time.Second},
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		apiKey:    key,
+		apiSecret: secret,
+		httpClient: &http.Client{
+			Transport: newPooledTransport(DefaultMaxIdleConnsPerHost, DefaultIdleConnTimeout),
+		},
+		timeout: DefaultTimeout,
 	}, nil
 }
 
+// SetTimeout sets the per-request timeout. Bulk endpoints get bulkTimeoutFactor times as
+// long. A non-positive value restores DefaultTimeout.
+func (c *HTTPClient) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	c.timeout = timeout
+}
+
+// SetProxy sends every request through the proxy at raw instead of the one chosen by
+// HTTP_PROXY/HTTPS_PROXY/NO_PROXY. The base URL allowlist still applies to the target.
+func (c *HTTPClient) SetProxy(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "invalid proxy URL", Details: map[string]any{"proxy": raw}}
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5":
+	default:
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "proxy URL scheme must be http, https, or socks5", Details: map[string]any{"proxy": u.Redacted()}}
+	}
+	t, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t = newPooledTransport(DefaultMaxIdleConnsPerHost, DefaultIdleConnTimeout)
+		c.httpClient.Transport = t
+	}
+	t.Proxy = http.ProxyURL(u)
+	return nil
+}
+
+// SetLogger enables request logging at the logger's verbosity.
+func (c *HTTPClient) SetLogger(l *output.Logger) {
+	c.log = l
+}
+
+// SetRequestID sends id as X-Request-Id on every provider call so failures can be matched
+// against the CLI envelope and quoted in GoDaddy support tickets.
+func (c *HTTPClient) SetRequestID(id string) {
+	c.requestID = id
+}
+
+// SetDryRun makes every write return a safety error describing the request instead of
+// sending it. Reads, including the POST behind bulk availability, still go out.
+func (c *HTTPClient) SetDryRun(on bool) {
+	c.dryRun = on
+}
+
+// isWrite reports whether a request changes account state.
+func isWrite(method, path string) bool {
+	if method == http.MethodGet {
+		return false
+	}
+	clean, _, _ := strings.Cut(path, "?")
+	return method != http.MethodPost || clean != "/v1/domains/available"
+}
+
+// debugBodyLimit bounds how much of a response body -vv logs.
+const debugBodyLimit = 4 << 10
+
+// prefixBuffer keeps the first max bytes written to it and discards the rest.
+type prefixBuffer struct {
+	buf []byte
+	max int
+	cut bool
+}
+
+func (p *prefixBuffer) Write(b []byte) (int, error) {
+	n := len(b)
+	if room := p.max - len(p.buf); n > room {
+		p.cut = true
+		b = b[:max(room, 0)]
+	}
+	p.buf = append(p.buf, b...)
+	return n, nil
+}
+
+// logRequest writes the -v summary line and, at -vv, redacted request headers and the
+// start of the response body.
+func (c *HTTPClient) logRequest(req *http.Request, status int, elapsed time.Duration, callErr error, body *prefixBuffer) {
+	if !c.log.Enabled(1) {
+		return
+	}
+	target := req.URL.RequestURI()
+	if callErr != nil {
+		c.log.Logf(1, "http %s %s error=%q duration=%s request_id=%s", req.Method, target, callErr.Error(), elapsed.Round(time.Millisecond), c.requestID)
+	} else {
+		c.log.Logf(1, "http %s %s status=%d duration=%s request_id=%s", req.Method, target, status, elapsed.Round(time.Millisecond), c.requestID)
+	}
+	if !c.log.Enabled(2) {
+		return
+	}
+	names := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	headers := make([]string, 0, len(names))
+	for _, k := range names {
+		v := req.Header.Get(k)
+		if strings.EqualFold(k, "Authorization") {
+			v = redactAuthorization(v)
+		}
+		headers = append(headers, k+"="+v)
+	}
+	c.log.Logf(2, "http request headers: %s", strings.Join(headers, " "))
+	if body != nil {
+		suffix := ""
+		if body.cut {
+			suffix = " [truncated]"
+		}
+		c.log.Logf(2, "http response body: %s%s", body.buf, suffix)
+	}
+}
+
+// redactAuthorization keeps the scheme of an Authorization value and hides the credentials.
+func redactAuthorization(v string) string {
+	if scheme, _, ok := strings.Cut(v, " "); ok {
+		return scheme + " [REDACTED]"
+	}
+	return "[REDACTED]"
+}
+
+func (c *HTTPClient) timeoutFor(method, path string) time.Duration {
+	if responseLimitFor(method, path) == bulkResponseLimitBytes {
+		return c.timeout * bulkTimeoutFactor
+	}
+	return c.timeout
+}
+
+// ConfigurePool tunes keep-alive connection reuse. Non-positive values keep the defaults.
+func (c *HTTPClient) ConfigurePool(maxIdleConnsPerHost int, idleConnTimeout time.Duration) {
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if idleConnTimeout <= 0 {
+		idleConnTimeout = DefaultIdleConnTimeout
+	}
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		t.IdleConnTimeout = idleConnTimeout
+		if t.MaxIdleConns < maxIdleConnsPerHost {
+			t.MaxIdleConns = maxIdleConnsPerHost
+		}
+		return
+	}
+	c.httpClient.Transport = newPooledTransport(maxIdleConnsPerHost, idleConnTimeout)
+}
+
+func newPooledTransport(maxIdleConnsPerHost int, idleConnTimeout time.Duration) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	t.IdleConnTimeout = idleConnTimeout
+	if t.MaxIdleConns < maxIdleConnsPerHost {
+		t.MaxIdleConns = maxIdleConnsPerHost
+	}
+	return t
+}
+
 func validateBaseURL(raw string) error {
 	u, err := url.Parse(raw)
 	if err != nil || u.Host == "" {
@@ -228,6 +467,10 @@ func (c *HTTPClient) Suggest(ctx context.Context, query string, tlds []string, l
 }
 
 func (c *HTTPClient) Available(ctx context.Context, domain string) (Availability, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return Availability{}, err
+	}
 	q := url.Values{}
 	q.Set("domain", domain)
 	// FULL provides a definitive answer for single lookups and avoids FAST-mode ambiguity.
@@ -240,7 +483,15 @@ func (c *HTTPClient) Available(ctx context.Context, domain string) (Availability
 }
 
 func (c *HTTPClient) AvailableBulk(ctx context.Context, domains []string) ([]Availability, error) {
-	body := map[string]any{"domains": domains, "checkType": "FAST"}
+	ascii := make([]string, len(domains))
+	for i, d := range domains {
+		a, err := ToASCII(d)
+		if err != nil {
+			return nil, err
+		}
+		ascii[i] = a
+	}
+	body := map[string]any{"domains": ascii, "checkType": "FAST"}
 	var raw []availabilityAPI
 	if err := c.do(ctx, http.MethodPost, "/v1/domains/available", body, &raw, ""); err != nil {
 		return nil, err
@@ -258,26 +509,37 @@ type availabilityAPI struct {
 	Definitive bool        `json:"definitive,omitempty"`
 	Price      interface{} `json:"price,omitempty"`
 	Currency   string      `json:"currency,omitempty"`
+	Period     int         `json:"period,omitempty"`
+	// RenewalPrice uses the same units as Price.
+	RenewalPrice interface{} `json:"renewalPrice,omitempty"`
 }
 
 func normalizeAvailability(in availabilityAPI) Availability {
 	out := Availability{
-		Domain:     in.Domain,
-		Available:  in.Available,
-		Definitive: in.Definitive,
-		Currency:   in.Currency,
+		Domain:        in.Domain,
+		DomainUnicode: unicodeForm(in.Domain),
+		Available:     in.Available,
+		Definitive:    in.Definitive,
+		Currency:      in.Currency,
+		Period:        in.Period,
 	}
-	price, raw, unit := normalizeProviderPrice(in.Price)
+	price, raw, unit := normalizeProviderPrice(in.Price, in.Currency)
 	out.Price = price
 	out.PriceRaw = raw
 	out.PriceUnit = unit
+	out.RenewalPrice, _, _ = normalizeProviderPrice(in.RenewalPrice, in.Currency)
 	return out
 }
 
 // GoDaddy availability pricing is commonly reported in micro-units.
-// We normalize to USD in `Price` and preserve provider value/unit for auditing.
-func normalizeProviderPrice(v interface{}) (price float64, raw float64, unit string) {
+// We normalize to major units of the provider currency in `Price` and preserve the provider
+// value/unit for auditing; non-micro prices report the lowercased currency as their unit.
+func normalizeProviderPrice(v interface{}, currency string) (price float64, raw float64, unit string) {
 	const micros = 1_000_000.0
+	major := strings.ToLower(strings.TrimSpace(currency))
+	if major == "" {
+		major = "usd"
+	}
 	switch x := v.(type) {
 	case nil:
 		return 0, 0, ""
@@ -286,39 +548,39 @@ func normalizeProviderPrice(v interface{}) (price float64, raw float64, unit str
 		if isWholeNumber(x) && x >= micros {
 			return x / micros, x, "micros"
 		}
-		return x, x, "usd"
+		return x, x, major
 	case float32:
 		f := float64(x)
 		raw = f
 		if isWholeNumber(f) && f >= micros {
 			return f / micros, f, "micros"
 		}
-		return f, f, "usd"
+		return f, f, major
 	case int:
 		f := float64(x)
 		if f >= micros {
 			return f / micros, f, "micros"
 		}
-		return f, f, "usd"
+		return f, f, major
 	case int64:
 		f := float64(x)
 		if f >= micros {
 			return f / micros, f, "micros"
 		}
-		return f, f, "usd"
+		return f, f, major
 	case json.Number:
 		if i, err := x.Int64(); err == nil {
 			f := float64(i)
 			if f >= micros {
 				return f / micros, f, "micros"
 			}
-			return f, f, "usd"
+			return f, f, major
 		}
 		if f, err := x.Float64(); err == nil {
 			if isWholeNumber(f) && f >= micros {
 				return f / micros, f, "micros"
 			}
-			return f, f, "usd"
+			return f, f, major
 		}
 	case string:
 		if s := strings.TrimSpace(x); s != "" {
@@ -327,13 +589,13 @@ func normalizeProviderPrice(v interface{}) (price float64, raw float64, unit str
 				if f >= micros {
 					return f / micros, f, "micros"
 				}
-				return f, f, "usd"
+				return f, f, major
 			}
 			if f, err := strconv.ParseFloat(s, 64); err == nil {
 				if isWholeNumber(f) && f >= micros {
 					return f / micros, f, "micros"
 				}
-				return f, f, "usd"
+				return f, f, major
 			}
 		}
 	}
@@ -345,11 +607,19 @@ func isWholeNumber(v float64) bool {
 }
 
 func (c *HTTPClient) Purchase(ctx context.Context, domain string, years int, idempotencyKey string) (PurchaseResult, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return PurchaseResult{}, err
+	}
 	body := map[string]any{"domain": domain, "period": years}
 	var out PurchaseResult
 	if err := c.do(ctx, http.MethodPost, "/v1/domains/purchase", body, &out, idempotencyKey); err != nil {
 		return PurchaseResult{}, err
 	}
+	if out.Domain == "" {
+		out.Domain = domain
+	}
+	out.DomainUnicode = unicodeForm(out.Domain)
 	return out, nil
 }
 
@@ -418,7 +688,7 @@ func (c *HTTPClient) ListOrders(ctx context.Context, limit, offset int) (OrdersP
 		},
 	}
 	for _, o := range raw.Orders {
-		price, rawPrice, unit := normalizeProviderPrice(o.Pricing.Total)
+		price, rawPrice, unit := normalizeProviderPrice(o.Pricing.Total, o.Currency)
 		items := make([]OrderItem, 0, len(o.Items))
 		for _, item := range o.Items {
 			items = append(items, OrderItem{Label: item.Label})
@@ -438,29 +708,52 @@ func (c *HTTPClient) ListOrders(ctx context.Context, limit, offset int) (OrdersP
 	return out, nil
 }
 
+// subscriptionAPI is a subscription as the v1 API returns it.
+type subscriptionAPI struct {
+	SubscriptionID string `json:"subscriptionId"`
+	Status         string `json:"status"`
+	Label          string `json:"label"`
+	CreatedAt      string `json:"createdAt"`
+	ExpiresAt      string `json:"expiresAt"`
+	Renewable      bool   `json:"renewable"`
+	RenewAuto      bool   `json:"renewAuto"`
+	Product        struct {
+		Namespace       string `json:"namespace"`
+		ProductGroupKey string `json:"productGroupKey"`
+	} `json:"product"`
+	Billing struct {
+		Status  string `json:"status"`
+		RenewAt string `json:"renewAt"`
+	} `json:"billing"`
+}
+
+func (s subscriptionAPI) normalize() Subscription {
+	return Subscription{
+		SubscriptionID: s.SubscriptionID,
+		Status:         s.Status,
+		Label:          s.Label,
+		CreatedAt:      s.CreatedAt,
+		ExpiresAt:      s.ExpiresAt,
+		Renewable:      s.Renewable,
+		RenewAuto:      s.RenewAuto,
+		Product: SubscriptionProduct{
+			Namespace:       s.Product.Namespace,
+			ProductGroupKey: s.Product.ProductGroupKey,
+		},
+		Billing: SubscriptionBilling{
+			Status:  s.Billing.Status,
+			RenewAt: s.Billing.RenewAt,
+		},
+	}
+}
+
 func (c *HTTPClient) ListSubscriptions(ctx context.Context, limit, offset int) (SubscriptionsPage, error) {
 	q := url.Values{}
 	q.Set("limit", strconv.Itoa(limit))
 	q.Set("offset", strconv.Itoa(offset))
 	var raw struct {
-		Subscriptions []struct {
-			SubscriptionID string `json:"subscriptionId"`
-			Status         string `json:"status"`
-			Label          string `json:"label"`
-			CreatedAt      string `json:"createdAt"`
-			ExpiresAt      string `json:"expiresAt"`
-			Renewable      bool   `json:"renewable"`
-			RenewAuto      bool   `json:"renewAuto"`
-			Product        struct {
-				Namespace       string `json:"namespace"`
-				ProductGroupKey string `json:"productGroupKey"`
-			} `json:"product"`
-			Billing struct {
-				Status  string `json:"status"`
-				RenewAt string `json:"renewAt"`
-			} `json:"billing"`
-		} `json:"subscriptions"`
-		Pagination struct {
+		Subscriptions []subscriptionAPI `json:"subscriptions"`
+		Pagination    struct {
 			First string `json:"first"`
 			Last  string `json:"last"`
 			Next  string `json:"next"`
@@ -482,27 +775,23 @@ func (c *HTTPClient) ListSubscriptions(ctx context.Context, limit, offset int) (
 		},
 	}
 	for _, s := range raw.Subscriptions {
-		out.Subscriptions = append(out.Subscriptions, Subscription{
-			SubscriptionID: s.SubscriptionID,
-			Status:         s.Status,
-			Label:          s.Label,
-			CreatedAt:      s.CreatedAt,
-			ExpiresAt:      s.ExpiresAt,
-			Renewable:      s.Renewable,
-			RenewAuto:      s.RenewAuto,
-			Product: SubscriptionProduct{
-				Namespace:       s.Product.Namespace,
-				ProductGroupKey: s.Product.ProductGroupKey,
-			},
-			Billing: SubscriptionBilling{
-				Status:  s.Billing.Status,
-				RenewAt: s.Billing.RenewAt,
-			},
-		})
+		out.Subscriptions = append(out.Subscriptions, s.normalize())
 	}
 	return out, nil
 }
 
+func (c *HTTPClient) GetSubscription(ctx context.Context, subscriptionID string) (Subscription, error) {
+	var raw subscriptionAPI
+	if err := c.do(ctx, http.MethodGet, "/v1/subscriptions/"+url.PathEscape(subscriptionID), nil, &raw, ""); err != nil {
+		return Subscription{}, err
+	}
+	return raw.normalize(), nil
+}
+
+func (c *HTTPClient) SetSubscriptionAutoRenew(ctx context.Context, subscriptionID string, enabled bool) error {
+	return c.do(ctx, http.MethodPatch, "/v1/subscriptions/"+url.PathEscape(subscriptionID), map[string]any{"renewAuto": enabled}, nil, "")
+}
+
 func (c *HTTPClient) GetNameservers(ctx context.Context, domain string) ([]string, error) {
 	var out struct {
 		NameServers []string `json:"nameServers"`
@@ -513,6 +802,35 @@ func (c *HTTPClient) GetNameservers(ctx context.Context, domain string) ([]strin
 	return out.NameServers, nil
 }
 
+func (c *HTTPClient) ListTLDs(ctx context.Context) ([]TLD, error) {
+	var out []TLD
+	if err := c.do(ctx, http.MethodGet, "/v1/domains/tlds", nil, &out, ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *HTTPClient) Agreements(ctx context.Context, tlds []string, privacy, forTransfer bool) ([]Agreement, error) {
+	q := url.Values{}
+	q.Set("tlds", strings.Join(tlds, ","))
+	q.Set("privacy", strconv.FormatBool(privacy))
+	q.Set("forTransfer", strconv.FormatBool(forTransfer))
+	var raw []struct {
+		AgreementKey string `json:"agreementKey"`
+		Title        string `json:"title"`
+		URL          string `json:"url"`
+		Content      string `json:"content"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/domains/agreements?"+q.Encode(), nil, &raw, ""); err != nil {
+		return nil, err
+	}
+	out := make([]Agreement, 0, len(raw))
+	for _, a := range raw {
+		out = append(out, Agreement{Key: a.AgreementKey, Title: a.Title, URL: a.URL, Content: a.Content})
+	}
+	return out, nil
+}
+
 func (c *HTTPClient) GetRecords(ctx context.Context, domain string) ([]DNSRecord, error) {
 	var out []DNSRecord
 	if err := c.do(ctx, http.MethodGet, "/v1/domains/"+url.PathEscape(domain)+"/records", nil, &out, ""); err != nil {
@@ -548,6 +866,37 @@ func (c *HTTPClient) ResolveCustomerID(ctx context.Context, shopperID string) (s
 	return out.CustomerID, nil
 }
 
+func (c *HTTPClient) GetShopper(ctx context.Context, shopperID string) (Shopper, error) {
+	if strings.TrimSpace(shopperID) == "" {
+		return Shopper{}, &apperr.AppError{Code: apperr.CodeValidation, Message: "shopper_id is required"}
+	}
+	var raw struct {
+		ShopperID  string `json:"shopperId"`
+		CustomerID string `json:"customerId"`
+		Email      string `json:"email"`
+		NameFirst  string `json:"nameFirst"`
+		NameLast   string `json:"nameLast"`
+		MarketID   string `json:"marketId"`
+	}
+	q := url.Values{}
+	q.Set("includes", "customerId")
+	if err := c.do(ctx, http.MethodGet, "/v1/shoppers/"+url.PathEscape(shopperID)+"?"+q.Encode(), nil, &raw, ""); err != nil {
+		return Shopper{}, err
+	}
+	out := Shopper{
+		ShopperID:  raw.ShopperID,
+		CustomerID: raw.CustomerID,
+		Email:      raw.Email,
+		NameFirst:  raw.NameFirst,
+		NameLast:   raw.NameLast,
+		MarketID:   raw.MarketID,
+	}
+	if out.ShopperID == "" {
+		out.ShopperID = shopperID
+	}
+	return out, nil
+}
+
 func (c *HTTPClient) V2Get(ctx context.Context, path string, query url.Values, out any) error {
 	p := path
 	if query != nil && len(query) > 0 {
@@ -612,7 +961,7 @@ func (c *HTTPClient) RenewV2(ctx context.Context, customerID, domain string, req
 	if err := c.V2Post(ctx, path, body, &out, idempotencyKey); err != nil {
 		return RenewResult{}, err
 	}
-	price, _, _ := normalizeProviderPrice(out.Price)
+	price, _, _ := normalizeProviderPrice(out.Price, out.Currency)
 	return RenewResult{
 		Domain:   domain,
 		Price:    price,
@@ -627,11 +976,27 @@ func (c *HTTPClient) SetNameserversV2(ctx context.Context, customerID, domain st
 	return c.V2Put(ctx, path, body, nil)
 }
 
+func (c *HTTPClient) SetLockV2(ctx context.Context, customerID, domain string, locked bool) error {
+	path := "/v2/customers/" + url.PathEscape(customerID) + "/domains/" + url.PathEscape(domain)
+	return c.V2Patch(ctx, path, map[string]any{"locked": locked}, nil)
+}
+
+func (c *HTTPClient) SetLockV1(ctx context.Context, domain string, locked bool) error {
+	return c.do(ctx, http.MethodPatch, "/v1/domains/"+url.PathEscape(domain), map[string]any{"locked": locked}, nil, "")
+}
+
 func (c *HTTPClient) do(ctx context.Context, method, path string, body any, out any, idempotencyKey string) error {
 	return c.doWithHeaders(ctx, method, path, body, out, idempotencyKey, nil)
 }
 
 func (c *HTTPClient) doWithHeaders(ctx context.Context, method, path string, body any, out any, idempotencyKey string, extraHeaders map[string]string) error {
+	if c.dryRun && isWrite(method, path) {
+		return &apperr.AppError{
+			Code:    apperr.CodeSafety,
+			Message: fmt.Sprintf("dry run: not sending %s %s", method, path),
+			Details: map[string]any{"dry_run": true, "method": method, "path": path, "body": body},
+		}
+	}
 	var r io.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
@@ -640,6 +1005,8 @@ func (c *HTTPClient) doWithHeaders(ctx context.Context, method, path string, bod
 		}
 		r = bytes.NewReader(b)
 	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeoutFor(method, path))
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, r)
 	if err != nil {
 		return err
@@ -652,6 +1019,9 @@ func (c *HTTPClient) doWithHeaders(ctx context.Context, method, path string, bod
 	if idempotencyKey != "" {
 		req.Header.Set("X-Idempotency-Key", idempotencyKey)
 	}
+	if c.requestID != "" {
+		req.Header.Set("X-Request-Id", c.requestID)
+	}
 	for k, v := range extraHeaders {
 		if strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
 			continue
@@ -659,18 +1029,27 @@ func (c *HTTPClient) doWithHeaders(ctx context.Context, method, path string, bod
 		req.Header.Set(k, v)
 	}
 
+	start := time.Now()
 	// #nosec G704 -- base URL is validated to approved GoDaddy/loopback hosts in validateBaseURL.
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return &apperr.AppError{Code: apperr.CodeProvider, Message: "provider request failed", Retryable: true, Cause: err}
+		c.logRequest(req, 0, time.Since(start), err, nil)
+		return c.transportError(err)
 	}
 	defer resp.Body.Close()
+	var respBody io.Reader = resp.Body
+	var captured *prefixBuffer
+	if c.log.Enabled(2) {
+		captured = &prefixBuffer{max: debugBodyLimit}
+		respBody = io.TeeReader(resp.Body, captured)
+	}
+	defer func() { c.logRequest(req, resp.StatusCode, time.Since(start), nil, captured) }()
 
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		if out == nil {
 			return nil
 		}
-		limited := io.LimitReader(resp.Body, responseLimitFor(method, path))
+		limited := io.LimitReader(respBody, responseLimitFor(method, path))
 		if err := json.NewDecoder(limited).Decode(out); err != nil && err != io.EOF {
 			return &apperr.AppError{Code: apperr.CodeProvider, Message: "failed decoding provider response", Cause: err}
 		}
@@ -678,14 +1057,61 @@ func (c *HTTPClient) doWithHeaders(ctx context.Context, method, path string, bod
 	}
 
 	var raw map[string]any
-	_ = json.NewDecoder(io.LimitReader(resp.Body, errorResponseLimitBytes)).Decode(&raw)
+	_ = json.NewDecoder(io.LimitReader(respBody, errorResponseLimitBytes)).Decode(&raw)
 	if resp.StatusCode == 429 {
-		return &apperr.AppError{Code: apperr.CodeRateLimited, Message: "provider rate limited", Retryable: true, Details: raw}
+		details := c.errorDetails(map[string]any{"status": resp.StatusCode, "provider": raw})
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			details[apperr.DetailRetryAfter] = wait.Seconds()
+		}
+		return &apperr.AppError{Code: apperr.CodeRateLimited, Message: "provider rate limited", Retryable: true, Details: details}
 	}
 	if resp.StatusCode == 401 || resp.StatusCode == 403 {
-		return &apperr.AppError{Code: apperr.CodeAuth, Message: "provider authentication failed", Details: raw}
+		return &apperr.AppError{Code: apperr.CodeAuth, Message: "provider authentication failed", Details: c.errorDetails(map[string]any{"status": resp.StatusCode, "provider": raw})}
+	}
+	return &apperr.AppError{Code: apperr.CodeProvider, Message: "provider returned non-success status", Details: c.errorDetails(map[string]any{"status": resp.StatusCode, "provider": raw})}
+}
+
+// transportError classifies a request that got no HTTP response as network_error, with
+// details.timeout set when it ran out of time rather than failing to connect.
+func (c *HTTPClient) transportError(err error) error {
+	var netErr net.Error
+	timeout := errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+	msg := "network request to provider failed"
+	if timeout {
+		msg = "provider request timed out"
+	}
+	return &apperr.AppError{Code: apperr.CodeNetwork, Message: msg, Retryable: true, Details: c.errorDetails(map[string]any{"timeout": timeout}), Cause: err}
+}
+
+// errorDetails tags provider error details with the request ID sent to GoDaddy, if any.
+func (c *HTTPClient) errorDetails(details map[string]any) map[string]any {
+	if c.requestID == "" {
+		return details
+	}
+	if details == nil {
+		details = map[string]any{}
+	}
+	details[apperr.DetailRequestID] = c.requestID
+	return details
+}
+
+// parseRetryAfter reads a Retry-After header given as delay seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
 	}
-	return &apperr.AppError{Code: apperr.CodeProvider, Message: "provider returned non-success status", Details: map[string]any{"status": resp.StatusCode, "provider": raw}}
+	return max(at.Sub(now), 0), true
 }
 
 func responseLimitFor(method, path string) int64 {
@@ -2,17 +2,25 @@ package godaddy
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
+	"github.com/sportwhiz/gdcli/internal/output"
 )
 
 func TestNormalizeProviderPriceMicros(t *testing.T) {
-	price, raw, unit := normalizeProviderPrice(float64(9_990_000))
+	price, raw, unit := normalizeProviderPrice(float64(9_990_000), "USD")
 	if price != 9.99 {
 		t.Fatalf("expected 9.99, got %v", price)
 	}
@@ -25,7 +33,7 @@ func TestNormalizeProviderPriceMicros(t *testing.T) {
 }
 
 func TestNormalizeProviderPriceUSD(t *testing.T) {
-	price, raw, unit := normalizeProviderPrice(float64(12.99))
+	price, raw, unit := normalizeProviderPrice(float64(12.99), "USD")
 	if price != 12.99 {
 		t.Fatalf("expected 12.99, got %v", price)
 	}
@@ -37,6 +45,15 @@ func TestNormalizeProviderPriceUSD(t *testing.T) {
 	}
 }
 
+func TestNormalizeProviderPriceKeepsCurrency(t *testing.T) {
+	if price, _, unit := normalizeProviderPrice(float64(11.5), "EUR"); price != 11.5 || unit != "eur" {
+		t.Fatalf("expected 11.5 eur, got %v %q", price, unit)
+	}
+	if price, _, unit := normalizeProviderPrice(float64(8_000_000), "GBP"); price != 8 || unit != "micros" {
+		t.Fatalf("expected 8 from micros, got %v %q", price, unit)
+	}
+}
+
 func TestNormalizeAvailabilityIncludesPriceMetadata(t *testing.T) {
 	in := availabilityAPI{
 		Domain:     "example.org",
@@ -60,6 +77,67 @@ func TestNormalizeAvailabilityIncludesPriceMetadata(t *testing.T) {
 	}
 }
 
+func TestNormalizeAvailabilityIncludesRenewalPricing(t *testing.T) {
+	var in availabilityAPI
+	if err := json.Unmarshal([]byte(`{"domain":"example.ai","available":true,"price":69990000,"currency":"USD","period":2,"renewalPrice":79990000}`), &in); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	out := normalizeAvailability(in)
+	if out.Period != 2 || out.RenewalPrice != 79.99 || out.Price != 69.99 {
+		t.Fatalf("expected period 2 and renewal 79.99, got %+v", out)
+	}
+
+	b, err := json.Marshal(normalizeAvailability(availabilityAPI{Domain: "example.com", Price: float64(12_990_000), Currency: "USD"}))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if strings.Contains(string(b), "renewal_price") || strings.Contains(string(b), "period") {
+		t.Fatalf("expected renewal fields omitted when absent, got %s", b)
+	}
+}
+
+func TestSetRecordsSendsZeroPriorityForMX(t *testing.T) {
+	var body []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v1/domains/a.com/records" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	records := []DNSRecord{
+		{Type: "MX", Name: "@", Data: "a-com.mail.protection.outlook.com", TTL: 3600, Priority: 0},
+		{Type: "TXT", Name: "@", Data: "v=spf1 include:spf.protection.outlook.com -all", TTL: 3600},
+		{Type: "SRV", Name: "_sip._tls", Data: "sipdir.online.lync.com", TTL: 3600, Priority: 100, Weight: 0, Port: 443},
+	}
+	if err := c.SetRecords(context.Background(), "a.com", records); err != nil {
+		t.Fatalf("set records: %v", err)
+	}
+	if len(body) != 3 {
+		t.Fatalf("expected three records in the PUT body, got %+v", body)
+	}
+	if body[2]["priority"] != float64(100) || body[2]["weight"] != float64(0) || body[2]["port"] != float64(443) {
+		t.Fatalf("expected SRV priority, weight 0, and port to be sent, got %+v", body[2])
+	}
+	if _, ok := body[0]["weight"]; ok {
+		t.Fatalf("expected no weight on MX, got %+v", body[0])
+	}
+	if p, ok := body[0]["priority"]; !ok || p != float64(0) {
+		t.Fatalf("expected MX priority 0 to be sent, got %+v", body[0])
+	}
+	if _, ok := body[1]["priority"]; ok {
+		t.Fatalf("expected no priority on TXT, got %+v", body[1])
+	}
+}
+
 func TestListOrdersNormalizesPricingAndPagination(t *testing.T) {
 	var gotQuery string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -206,3 +284,280 @@ func TestDoHandlesOversizedErrorBody(t *testing.T) {
 		t.Fatalf("expected rate-limited code, got %s", ae.Code)
 	}
 }
+
+func TestConfigurePoolTunesTransport(t *testing.T) {
+	c, err := NewHTTPClient("https://api.godaddy.com", "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	tr, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok || tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || tr.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Fatalf("expected pooled default transport, got %+v", c.httpClient.Transport)
+	}
+	c.ConfigurePool(64, 30*time.Second)
+	if tr.MaxIdleConnsPerHost != 64 || tr.IdleConnTimeout != 30*time.Second || tr.MaxIdleConns < 64 {
+		t.Fatalf("expected tuned transport, got idle/host=%d idle=%v max=%d", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout, tr.MaxIdleConns)
+	}
+	c.ConfigurePool(0, 0)
+	if tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || tr.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Fatalf("expected zero values to restore defaults")
+	}
+}
+
+// BenchmarkAvailabilityPool compares 1000 concurrent availability lookups over TLS with Go's
+// default of 2 idle connections per host against the pooled default.
+func BenchmarkAvailabilityPool(b *testing.B) {
+	var handshakes atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"example.com","available":true,"definitive":true,"price":12990000,"currency":"USD"}`))
+	}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			handshakes.Add(1)
+		}
+	}
+	srv.StartTLS()
+	defer srv.Close()
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+
+	for _, idle := range []int{2, DefaultMaxIdleConnsPerHost} {
+		b.Run(fmt.Sprintf("idle_per_host=%d", idle), func(b *testing.B) {
+			handshakes.Store(0)
+			for i := 0; i < b.N; i++ {
+				c, err := NewHTTPClient(srv.URL, "k", "s")
+				if err != nil {
+					b.Fatalf("new client: %v", err)
+				}
+				c.ConfigurePool(idle, DefaultIdleConnTimeout)
+				c.httpClient.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+				jobs := make(chan int)
+				var wg sync.WaitGroup
+				for w := 0; w < 20; w++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for n := range jobs {
+							if _, err := c.Available(context.Background(), fmt.Sprintf("d%d.com", n)); err != nil {
+								b.Errorf("available: %v", err)
+							}
+						}
+					}()
+				}
+				for n := 0; n < 1000; n++ {
+					jobs <- n
+				}
+				close(jobs)
+				wg.Wait()
+				c.httpClient.CloseIdleConnections()
+			}
+			b.ReportMetric(float64(handshakes.Load())/float64(b.N), "conns/op")
+		})
+	}
+}
+
+func TestAgreementsNormalizesKeys(t *testing.T) {
+	var gotPath, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"agreementKey":"DNRA","title":"Domain Name Registration Agreement","url":"https://example.com/dnra","content":"..."}]`))
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	out, err := c.Agreements(context.Background(), []string{"com", "ai"}, true, false)
+	if err != nil {
+		t.Fatalf("agreements: %v", err)
+	}
+	if gotPath != "/v1/domains/agreements" || gotQuery != "forTransfer=false&privacy=true&tlds=com%2Cai" {
+		t.Fatalf("unexpected request %s?%s", gotPath, gotQuery)
+	}
+	if len(out) != 1 || out[0].Key != "DNRA" || out[0].URL != "https://example.com/dnra" {
+		t.Fatalf("unexpected agreements: %+v", out)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if d, ok := parseRetryAfter("7", now); !ok || d != 7*time.Second {
+		t.Fatalf("expected 7s, got %s %v", d, ok)
+	}
+	if d, ok := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now); !ok || d != 30*time.Second {
+		t.Fatalf("expected 30s from HTTP date, got %s %v", d, ok)
+	}
+	if d, ok := parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now); !ok || d != 0 {
+		t.Fatalf("expected past date to mean no wait, got %s %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Fatalf("expected invalid header to be ignored")
+	}
+}
+
+func TestDoRecordsRetryAfterOn429(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	_, err = c.Available(context.Background(), "example.com")
+	if d, ok := apperr.RetryAfter(err); !ok || d != 3*time.Second {
+		t.Fatalf("expected 3s Retry-After on the error, got %s %v (%v)", d, ok, err)
+	}
+}
+
+func TestDoSendsRequestIDAndReportsItOnErrors(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Request-Id")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	c.SetRequestID("req-abc")
+	_, err = c.Available(context.Background(), "example.com")
+	if got != "req-abc" {
+		t.Fatalf("expected X-Request-Id header, got %q", got)
+	}
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Details[apperr.DetailRequestID] != "req-abc" {
+		t.Fatalf("expected request id in error details, got %v", err)
+	}
+}
+
+func TestSetTimeoutBoundsRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	c.SetTimeout(50 * time.Millisecond)
+	if _, err := c.Available(context.Background(), "example.com"); err == nil {
+		t.Fatalf("expected single request to time out")
+	}
+	// The bulk availability endpoint gets bulkTimeoutFactor times the timeout.
+	if _, err := c.AvailableBulk(context.Background(), []string{"example.com"}); err != nil {
+		t.Fatalf("expected bulk request within its longer timeout: %v", err)
+	}
+}
+
+func TestDoClassifiesTransportFailuresAsNetworkErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	c.SetTimeout(20 * time.Millisecond)
+	_, err = c.Available(context.Background(), "example.com")
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeNetwork || !ae.Retryable || ae.Details["timeout"] != true {
+		t.Fatalf("expected retryable network_error with timeout, got %v", err)
+	}
+
+	c, err = NewHTTPClient("http://127.0.0.1:1", "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	_, err = c.Available(context.Background(), "example.com")
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeNetwork || ae.Details["timeout"] != false {
+		t.Fatalf("expected network_error without timeout for a refused connection, got %v", err)
+	}
+	if apperr.ExitCode(err) != 10 {
+		t.Fatalf("expected exit code 10, got %d", apperr.ExitCode(err))
+	}
+}
+
+func TestSetProxyRoutesRequestsThroughProxy(t *testing.T) {
+	var proxiedHost, proxiedPath string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL.
+		proxiedHost, proxiedPath = r.URL.Host, r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"example.com","available":true,"price":12990000,"currency":"USD"}`))
+	}))
+	defer proxy.Close()
+
+	c, err := NewHTTPClient("http://127.0.0.1:1", "k", "s")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := c.SetProxy(proxy.URL); err != nil {
+		t.Fatalf("set proxy: %v", err)
+	}
+	got, err := c.Available(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("available through proxy: %v", err)
+	}
+	if !got.Available || proxiedHost != "127.0.0.1:1" || proxiedPath != "/v1/domains/available" {
+		t.Fatalf("expected request for the API target via the proxy, got host=%q path=%q", proxiedHost, proxiedPath)
+	}
+
+	if err := c.SetProxy("ftp://proxy.example:21"); err == nil {
+		t.Fatalf("expected unsupported proxy scheme to be rejected")
+	}
+	if _, err := NewHTTPClient("https://evil.example", "k", "s"); err == nil {
+		t.Fatalf("expected the target allowlist to still apply")
+	}
+}
+
+func TestVerboseLoggingRedactsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"example.com","available":false}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "the-key", "the-secret")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	var logs strings.Builder
+	c.SetLogger(output.NewLogger(&logs, 1))
+	c.SetRequestID("req-1")
+	if _, err := c.Available(context.Background(), "example.com"); err != nil {
+		t.Fatalf("available: %v", err)
+	}
+	got := logs.String()
+	if !strings.Contains(got, "http GET /v1/domains/available?checkType=FULL&domain=example.com status=200") || !strings.Contains(got, "request_id=req-1") {
+		t.Fatalf("expected request summary, got %q", got)
+	}
+	if strings.Contains(got, "response body") {
+		t.Fatalf("expected no body at -v, got %q", got)
+	}
+
+	logs.Reset()
+	c.SetLogger(output.NewLogger(&logs, 2))
+	if _, err := c.Available(context.Background(), "example.com"); err != nil {
+		t.Fatalf("available: %v", err)
+	}
+	got = logs.String()
+	if strings.Contains(got, "the-key") || strings.Contains(got, "the-secret") || !strings.Contains(got, "Authorization=sso-key [REDACTED]") {
+		t.Fatalf("expected redacted credentials, got %q", got)
+	}
+	if !strings.Contains(got, `http response body: {"domain":"example.com","available":false}`) {
+		t.Fatalf("expected response body at -vv, got %q", got)
+	}
+}
@@ -0,0 +1,301 @@
+package godaddy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
+)
+
+// The module keeps no dependencies outside the standard library, so IDN handling is a small
+// RFC 3492 punycode codec rather than golang.org/x/net/idna. Labels are lowercased but not
+// NFC-normalized or UTS #46-mapped. Without the normalization tables, input that is plainly not
+// NFC (see checkComposed) is refused rather than encoded to the wrong name.
+
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+	acePrefix       = "xn--"
+)
+
+var errPunycode = errors.New("invalid punycode")
+
+// NormalizeDomain is the check every domain argument passes before it reaches the API. It
+// applies ToASCII and also refuses URLs and names without a TLD, with a validation error that
+// says what to fix.
+func NormalizeDomain(domain string) (string, error) {
+	trimmed := strings.TrimSpace(domain)
+	if strings.Contains(trimmed, "://") || strings.ContainsAny(trimmed, "/?#@:") {
+		return "", &apperr.AppError{Code: apperr.CodeValidation, Message: fmt.Sprintf("invalid domain %q: pass the bare domain name, not a URL", domain), Details: map[string]any{"domain": domain}}
+	}
+	out, err := ToASCII(trimmed)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(out, ".") {
+		return "", &apperr.AppError{Code: apperr.CodeValidation, Message: fmt.Sprintf("invalid domain %q: missing a TLD", domain), Details: map[string]any{"domain": domain}}
+	}
+	return out, nil
+}
+
+// ToASCII converts a domain to the ASCII form the API expects: trimmed, lowercased, without a
+// trailing dot, and with every non-ASCII label punycode-encoded behind "xn--". Labels must be
+// 1-63 letters, digits, or inner hyphens once encoded.
+func ToASCII(domain string) (string, error) {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	invalid := func(reason string) error {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: fmt.Sprintf("invalid domain %q: %s", domain, reason), Details: map[string]any{"domain": domain}}
+	}
+	if name == "" {
+		return "", invalid("empty")
+	}
+	labels := strings.Split(name, ".")
+	for i, l := range labels {
+		if l == "" {
+			return "", invalid("empty label")
+		}
+		if !isASCII(l) {
+			for _, r := range l {
+				if !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r) && r != '-' {
+					return "", invalid(fmt.Sprintf("character %q is not allowed", r))
+				}
+			}
+			if err := checkComposed(l); err != nil {
+				return "", invalid(err.Error())
+			}
+			enc, err := punyEncode(l)
+			if err != nil {
+				return "", invalid(err.Error())
+			}
+			l = acePrefix + enc
+			labels[i] = l
+		}
+		if len(l) > 63 {
+			return "", invalid("label longer than 63 characters")
+		}
+		if l[0] == '-' || l[len(l)-1] == '-' {
+			return "", invalid("label starts or ends with a hyphen")
+		}
+		for j := 0; j < len(l); j++ {
+			c := l[j]
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return "", invalid(fmt.Sprintf("character %q is not allowed", c))
+			}
+		}
+	}
+	out := strings.Join(labels, ".")
+	if len(out) > 253 {
+		return "", invalid("longer than 253 characters")
+	}
+	return out, nil
+}
+
+// ToUnicode decodes every "xn--" label of an ASCII domain for display. Labels that do not
+// decode are left as they are.
+func ToUnicode(domain string) string {
+	labels := strings.Split(domain, ".")
+	for i, l := range labels {
+		rest, ok := strings.CutPrefix(strings.ToLower(l), acePrefix)
+		if !ok {
+			continue
+		}
+		if dec, err := punyDecode(rest); err == nil {
+			labels[i] = dec
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// unicodeForm returns the display form of an ASCII domain, or "" when it has no IDN labels.
+func unicodeForm(domain string) string {
+	if u := ToUnicode(domain); u != domain {
+		return u
+	}
+	return ""
+}
+
+// combiningDiacritics are the generic combining accents. NFC folds them into the preceding
+// Latin, Greek, or Cyrillic letter wherever a precomposed letter exists, which covers every
+// such letter a registry accepts.
+var combiningDiacritics = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0300, Hi: 0x036f, Stride: 1},
+		{Lo: 0x1ab0, Hi: 0x1aff, Stride: 1},
+		{Lo: 0x1dc0, Hi: 0x1dff, Stride: 1},
+		{Lo: 0x20d0, Hi: 0x20ff, Stride: 1},
+		{Lo: 0xfe20, Hi: 0xfe2f, Stride: 1},
+	},
+}
+
+// checkComposed rejects a label that cannot be NFC: one that starts with a combining mark
+// (RFC 5891 section 4.2.3.2), a combining accent after a Latin, Greek, or Cyrillic letter, as in
+// a decomposed "cafe\u0301", or a conjoining Hangul lead and vowel jamo pair that NFC
+// composes into a syllable.
+func checkComposed(label string) error {
+	var prev rune
+	for i, r := range label {
+		if i == 0 && unicode.IsMark(r) {
+			return fmt.Errorf("label starts with combining mark %U", r)
+		}
+		if unicode.Is(combiningDiacritics, r) && unicode.In(prev, unicode.Latin, unicode.Greek, unicode.Cyrillic) {
+			return fmt.Errorf("decomposed character %q + %U; use the composed (NFC) form", prev, r)
+		}
+		if prev >= 0x1100 && prev <= 0x1112 && r >= 0x1161 && r <= 0x1175 {
+			return fmt.Errorf("decomposed Hangul jamo %U %U; use the composed (NFC) syllable", prev, r)
+		}
+		prev = r
+	}
+	return nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punyThreshold(k, bias int) int {
+	return min(max(k-bias, punyTMin), punyTMax)
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+// punyEncode encodes one label (RFC 3492 section 6.3).
+func punyEncode(label string) (string, error) {
+	runes := []rune(label)
+	var out []byte
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+	b := len(out)
+	h := b
+	if b > 0 {
+		out = append(out, '-')
+	}
+	n, delta, bias := rune(punyInitialN), 0, punyInitialBias
+	for h < len(runes) {
+		m := rune(unicode.MaxRune + 1)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		if int(m-n) > (1<<30)/(h+1) {
+			return "", errPunycode
+		}
+		delta += int(m-n) * (h + 1)
+		n = m
+		for _, r := range runes {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punyThreshold(k, bias)
+				if q < t {
+					break
+				}
+				out = append(out, punyDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out = append(out, punyDigit(q))
+			bias = punyAdapt(delta, h+1, h == b)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return string(out), nil
+}
+
+// punyDecode decodes one label without its "xn--" prefix (RFC 3492 section 6.2).
+func punyDecode(s string) (string, error) {
+	var out []rune
+	pos := 0
+	if b := strings.LastIndexByte(s, '-'); b >= 0 {
+		for i := 0; i < b; i++ {
+			if s[i] >= utf8.RuneSelf {
+				return "", errPunycode
+			}
+			out = append(out, rune(s[i]))
+		}
+		pos = b + 1
+	}
+	n, i, bias := rune(punyInitialN), 0, punyInitialBias
+	for pos < len(s) {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if pos >= len(s) {
+				return "", errPunycode
+			}
+			c := s[pos]
+			pos++
+			var digit int
+			switch {
+			case c >= 'a' && c <= 'z':
+				digit = int(c - 'a')
+			case c >= 'A' && c <= 'Z':
+				digit = int(c - 'A')
+			case c >= '0' && c <= '9':
+				digit = int(c-'0') + 26
+			default:
+				return "", errPunycode
+			}
+			if digit > ((1<<30)-i)/w {
+				return "", errPunycode
+			}
+			i += digit * w
+			t := punyThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punyBase - t
+		}
+		bias = punyAdapt(i-oldi, len(out)+1, oldi == 0)
+		n += rune(i / (len(out) + 1))
+		if n > unicode.MaxRune || (n >= 0xD800 && n <= 0xDFFF) {
+			return "", errPunycode
+		}
+		i %= len(out) + 1
+		out = append(out[:i], append([]rune{n}, out[i:]...)...)
+		i++
+	}
+	return string(out), nil
+}
@@ -0,0 +1,111 @@
+package godaddy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
+)
+
+func TestToASCIIEncodesIDNLabels(t *testing.T) {
+	cases := map[string]string{
+		"café.com":          "xn--caf-dma.com",
+		" Bücher.DE. ":      "xn--bcher-kva.de",
+		"münchen.example":   "xn--mnchen-3ya.example",
+		"例え.テスト":            "xn--r8jz45g.xn--zckzah",
+		"plain-name.io":     "plain-name.io",
+		"xn--caf-dma.com":   "xn--caf-dma.com",
+		"ÉCOLE.fr":          "xn--cole-9oa.fr",
+		"sub.straße.com":    "sub.xn--strae-oqa.com",
+		"123.example.co.uk": "123.example.co.uk",
+	}
+	for in, want := range cases {
+		got, err := ToASCII(in)
+		if err != nil || got != want {
+			t.Fatalf("ToASCII(%q) = %q, %v; want %q", in, got, err, want)
+		}
+		if u := ToUnicode(got); u != ToUnicode(want) {
+			t.Fatalf("ToUnicode(%q) = %q", got, u)
+		}
+	}
+	if u := ToUnicode("xn--caf-dma.com"); u != "café.com" {
+		t.Fatalf("expected café.com, got %q", u)
+	}
+	if u := ToUnicode("xn--r8jz45g.xn--zckzah"); u != "例え.テスト" {
+		t.Fatalf("expected 例え.テスト, got %q", u)
+	}
+}
+
+func TestToASCIIRejectsMalformedDomains(t *testing.T) {
+	for _, in := range []string{"", "exa mple.com", "a..com", "-bad.com", "bad-.com", "under_score.com", "smile☺.com", "https://x.com"} {
+		_, err := ToASCII(in)
+		var ae *apperr.AppError
+		if !apperr.As(err, &ae) || ae.Code != apperr.CodeValidation {
+			t.Fatalf("ToASCII(%q): expected validation error, got %v", in, err)
+		}
+	}
+}
+
+func TestToASCIIRejectsNonNFCLabels(t *testing.T) {
+	for _, in := range []string{"cafe\u0301.com", "\u0301cafe.com", "e\u0308.de", "\u1112\u1161n.kr"} {
+		_, err := ToASCII(in)
+		var ae *apperr.AppError
+		if !apperr.As(err, &ae) || ae.Code != apperr.CodeValidation {
+			t.Fatalf("ToASCII(%q): expected validation error, got %v", in, err)
+		}
+	}
+	// Marks that belong to their script, such as Devanagari vowel signs, are NFC as written.
+	for _, in := range []string{"भारत.com", "\ud55c\uae00.kr"} {
+		got, err := ToASCII(in)
+		if err != nil {
+			t.Fatalf("ToASCII(%q): %v", in, err)
+		}
+		if u := ToUnicode(got); u != in {
+			t.Fatalf("ToUnicode(%q) = %q, want %q", got, u, in)
+		}
+	}
+}
+
+func TestAvailableSendsPunycodeAndEchoesUnicode(t *testing.T) {
+	var gotDomain string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotDomain = r.URL.Query().Get("domain")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"domain":"xn--caf-dma.com","available":true,"definitive":true,"price":12990000,"currency":"USD"}`))
+	}))
+	defer srv.Close()
+	c, err := NewHTTPClient(srv.URL, "k", "s")
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	a, err := c.Available(context.Background(), "Café.com")
+	if err != nil {
+		t.Fatalf("available: %v", err)
+	}
+	if gotDomain != "xn--caf-dma.com" || a.Domain != "xn--caf-dma.com" || a.DomainUnicode != "café.com" {
+		t.Fatalf("expected punycode request and both forms back, got %q %+v", gotDomain, a)
+	}
+}
+
+func TestNormalizeDomainEdgeCases(t *testing.T) {
+	ok := map[string]string{
+		"Example.COM":    "example.com",
+		"example.com.":   "example.com",
+		"  example.com ": "example.com",
+		"Café.com.":      "xn--caf-dma.com",
+	}
+	for in, want := range ok {
+		if got, err := NormalizeDomain(in); err != nil || got != want {
+			t.Fatalf("NormalizeDomain(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"https://example.com", "example.com/path", "user@example.com", "example.com:443", "example", "exa mple.com", "example..com", ""} {
+		_, err := NormalizeDomain(in)
+		var ae *apperr.AppError
+		if !apperr.As(err, &ae) || ae.Code != apperr.CodeValidation {
+			t.Fatalf("NormalizeDomain(%q): expected validation error, got %v", in, err)
+		}
+	}
+}
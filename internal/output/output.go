@@ -1,10 +1,16 @@
 package output
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
@@ -20,6 +26,10 @@ type Envelope struct {
 
 type Writer struct {
 	Out io.Writer
+	// Fields, when set by --fields, projects each result down to these dot paths.
+	Fields []string
+	// Pretty indents single-envelope JSON (--pretty). NDJSON stays one object per line.
+	Pretty bool
 }
 
 func NewWriter(out io.Writer) *Writer {
@@ -31,11 +41,14 @@ func (w *Writer) EmitJSON(command, reqID string, result any, err *apperr.AppErro
 		Command:      command,
 		TimestampUTC: time.Now().UTC().Format(time.RFC3339),
 		RequestID:    reqID,
-		Result:       normalize(result),
+		Result:       w.project(result),
 		Error:        err,
 	}
 	enc := json.NewEncoder(w.Out)
 	enc.SetEscapeHTML(false)
+	if w.Pretty {
+		enc.SetIndent("", "  ")
+	}
 	return enc.Encode(env)
 }
 
@@ -47,7 +60,7 @@ func (w *Writer) EmitNDJSON(command, reqID string, records []any) error {
 			Command:      command,
 			TimestampUTC: time.Now().UTC().Format(time.RFC3339),
 			RequestID:    reqID,
-			Result:       normalize(r),
+			Result:       w.project(r),
 		}
 		if err := enc.Encode(env); err != nil {
 			return err
@@ -80,6 +93,247 @@ func normalize(v any) any {
 	}
 }
 
+func (w *Writer) project(v any) any {
+	if len(w.Fields) == 0 || v == nil {
+		return normalize(v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return normalize(v)
+	}
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return normalize(v)
+	}
+	return Project(generic, w.Fields)
+}
+
+// ParseFields splits a --fields value into dot paths, dropping blanks. An empty selection is an error.
+func ParseFields(v string) ([]string, error) {
+	var fields []string
+	for _, f := range strings.Split(v, ",") {
+		if f = strings.Trim(strings.TrimSpace(f), "."); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("--fields requires at least one field name")
+	}
+	return fields, nil
+}
+
+// Project keeps only the dot-path fields of decoded JSON. Lists are projected element by
+// element, at the top level and wherever a path crosses one, so "result.price" selects the
+// price of every bulk row. Paths that do not exist are omitted.
+func Project(v any, fields []string) any {
+	paths := make([][]string, 0, len(fields))
+	for _, f := range fields {
+		paths = append(paths, strings.Split(f, "."))
+	}
+	out, _ := project(v, paths)
+	return out
+}
+
+func project(v any, paths [][]string) (any, bool) {
+	switch t := v.(type) {
+	case []any:
+		out := make([]any, 0, len(t))
+		for _, item := range t {
+			if p, ok := project(item, paths); ok {
+				out = append(out, p)
+			}
+		}
+		return out, true
+	case map[string]any:
+		out := map[string]any{}
+		tails := map[string][][]string{}
+		whole := map[string]bool{}
+		for _, p := range paths {
+			if _, ok := t[p[0]]; !ok {
+				continue
+			}
+			if len(p) == 1 {
+				whole[p[0]] = true
+				continue
+			}
+			tails[p[0]] = append(tails[p[0]], p[1:])
+		}
+		for k := range whole {
+			out[k] = t[k]
+		}
+		for k, rest := range tails {
+			if whole[k] {
+				continue
+			}
+			if p, ok := project(t[k], rest); ok {
+				out[k] = p
+			}
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// EmitCSV writes a header row followed by rows.
+func (w *Writer) EmitCSV(columns []string, rows [][]string) error {
+	cw := csv.NewWriter(w.Out)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// LogErr writes one line to errOut. On a ColorWriter, "error:" lines are red and "warning:" and
+// update notices yellow.
 func LogErr(errOut io.Writer, format string, args ...any) {
-	fmt.Fprintf(errOut, format+"\n", args...)
+	line := fmt.Sprintf(format, args...)
+	if _, ok := errOut.(*ColorWriter); ok {
+		if c := lineColor(line); c != "" {
+			line = c + line + ansiReset
+		}
+	}
+	fmt.Fprintln(errOut, line)
+}
+
+const (
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+func lineColor(line string) string {
+	switch {
+	case strings.HasPrefix(line, "error:"):
+		return ansiRed
+	case strings.HasPrefix(line, "warning:"), strings.HasPrefix(line, "update available:"):
+		return ansiYellow
+	default:
+		return ""
+	}
+}
+
+// ColorWriter marks a stderr stream that accepts ANSI colors. stdout is never wrapped, so
+// JSON, NDJSON and CSV output stay uncolored.
+type ColorWriter struct {
+	io.Writer
+}
+
+// Color modes for --color.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ValidColorMode reports whether mode is auto, always or never.
+func ValidColorMode(mode string) bool {
+	return mode == ColorAuto || mode == ColorAlways || mode == ColorNever
+}
+
+// UseColor decides whether w gets colors: always and never are absolute; auto requires a
+// terminal, an unset NO_COLOR and a TERM other than "dumb".
+func UseColor(mode string, w io.Writer, getenv func(string) string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if getenv("NO_COLOR") != "" || getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(w)
+}
+
+// IsTerminal reports whether w (or the stream a ColorWriter wraps) is a terminal.
+func IsTerminal(w io.Writer) bool {
+	if cw, ok := w.(*ColorWriter); ok {
+		w = cw.Writer
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Progress keeps a "processed N/total" counter on one stderr line for a long batch, redrawn
+// at most once per interval and when the batch finishes. A nil Progress does nothing, so
+// callers can use it without checking whether progress is enabled.
+type Progress struct {
+	mu       sync.Mutex
+	w        io.Writer
+	label    string
+	total    int
+	done     int
+	interval time.Duration
+	last     time.Time
+	drawn    bool
+}
+
+func NewProgress(w io.Writer, label string, total int, interval time.Duration) *Progress {
+	return &Progress{w: w, label: label, total: total, interval: interval}
+}
+
+// Add counts n more finished items and redraws the line when the interval has passed.
+func (p *Progress) Add(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	now := time.Now()
+	if p.done < p.total && p.drawn && now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	p.drawn = true
+	fmt.Fprintf(p.w, "\r%s: processed %d/%d", p.label, p.done, p.total)
+}
+
+// Finish ends the progress line so later stderr output starts on a fresh line.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		fmt.Fprintln(p.w)
+		p.drawn = false
+	}
+}
+
+// Logger writes leveled debug lines to stderr for --verbose. A nil Logger logs nothing.
+type Logger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level int
+}
+
+func NewLogger(w io.Writer, level int) *Logger {
+	return &Logger{w: w, level: level}
+}
+
+// Enabled reports whether lines at level are written.
+func (l *Logger) Enabled(level int) bool {
+	return l != nil && l.w != nil && level > 0 && l.level >= level
+}
+
+// Logf writes one "debug:" line when level is enabled. Lines from concurrent workers do not interleave.
+func (l *Logger) Logf(level int, format string, args ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, "debug: "+format+"\n", args...)
 }
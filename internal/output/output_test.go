@@ -0,0 +1,133 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmitJSONProjectsFields(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Fields = []string{"domain", "price", "missing", "result.available"}
+	rows := []any{
+		map[string]any{"domain": "a.com", "price": 12.99, "currency": "USD", "result": map[string]any{"available": true, "definitive": true}},
+		map[string]any{"domain": "b.com", "currency": "USD"},
+	}
+	if err := w.EmitJSON("domains avail-bulk", "req-1", rows, nil); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	var env struct {
+		Command string           `json:"command"`
+		Result  []map[string]any `json:"result"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &env); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if env.Command != "domains avail-bulk" || len(env.Result) != 2 {
+		t.Fatalf("unexpected envelope: %s", buf.String())
+	}
+	first := env.Result[0]
+	if len(first) != 3 || first["domain"] != "a.com" || first["price"] != 12.99 {
+		t.Fatalf("unexpected projection: %+v", first)
+	}
+	if nested, _ := first["result"].(map[string]any); len(nested) != 1 || nested["available"] != true {
+		t.Fatalf("expected only result.available: %+v", first["result"])
+	}
+	if len(env.Result[1]) != 1 || env.Result[1]["domain"] != "b.com" {
+		t.Fatalf("expected missing fields omitted: %+v", env.Result[1])
+	}
+}
+
+func TestEmitJSONPrettyIndentsButNDJSONStaysCompact(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Pretty = true
+	if err := w.EmitJSON("version", "req-1", map[string]any{"version": "1.0.0"}, nil); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\n  \"command\": \"version\",\n") || !strings.Contains(buf.String(), "\n    \"version\": \"1.0.0\"\n") {
+		t.Fatalf("expected indented JSON, got %s", buf.String())
+	}
+	buf.Reset()
+	if err := w.EmitNDJSON("domains list", "req-1", []any{1, 2}); err != nil {
+		t.Fatalf("emit ndjson: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 {
+		t.Fatalf("expected one line per record, got %q", buf.String())
+	}
+}
+
+func TestProjectCrossesNestedLists(t *testing.T) {
+	in := map[string]any{"domains": []any{map[string]any{"domain": "a.com", "expires": "2027"}}, "source": "portfolio"}
+	b, _ := json.Marshal(Project(in, []string{"domains.domain"}))
+	if got := string(b); got != `{"domains":[{"domain":"a.com"}]}` {
+		t.Fatalf("unexpected projection: %s", got)
+	}
+}
+
+func TestLogErrColorsOnlyColorWriters(t *testing.T) {
+	var plain bytes.Buffer
+	LogErr(&plain, "error: %s", "boom")
+	if plain.String() != "error: boom\n" {
+		t.Fatalf("expected plain line, got %q", plain.String())
+	}
+	var buf bytes.Buffer
+	cw := &ColorWriter{Writer: &buf}
+	LogErr(cw, "error: boom")
+	LogErr(cw, "warning: careful")
+	LogErr(cw, "release: https://example.com")
+	want := "\x1b[31merror: boom\x1b[0m\n\x1b[33mwarning: careful\x1b[0m\nrelease: https://example.com\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected colored output %q", buf.String())
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	env := func(vals map[string]string) func(string) string {
+		return func(k string) string { return vals[k] }
+	}
+	var buf bytes.Buffer
+	if !UseColor(ColorAlways, &buf, env(nil)) || UseColor(ColorNever, &buf, env(nil)) {
+		t.Fatalf("always/never must ignore the stream")
+	}
+	if UseColor(ColorAuto, &buf, env(nil)) {
+		t.Fatalf("auto must not color a non-terminal")
+	}
+	if UseColor(ColorAuto, &buf, env(map[string]string{"NO_COLOR": "1"})) {
+		t.Fatalf("auto must honor NO_COLOR")
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields(" domain, ,result.price ,")
+	if err != nil || strings.Join(fields, "|") != "domain|result.price" {
+		t.Fatalf("unexpected fields %v %v", fields, err)
+	}
+	for _, empty := range []string{"", " , ", "."} {
+		if _, err := ParseFields(empty); err == nil {
+			t.Fatalf("expected error for %q", empty)
+		}
+	}
+}
+
+func TestProgressThrottlesRedraws(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgress(&buf, "availability", 3, time.Hour)
+	p.Add(1)
+	p.Add(1)
+	p.Add(1)
+	p.Finish()
+	if got := buf.String(); got != "\ravailability: processed 1/3\ravailability: processed 3/3\n" {
+		t.Fatalf("expected first and final counts only, got %q", got)
+	}
+
+	var nilProgress *Progress
+	nilProgress.Add(1)
+	nilProgress.Finish()
+	if IsTerminal(&buf) || IsTerminal(&ColorWriter{Writer: &buf}) {
+		t.Fatalf("a buffer is not a terminal")
+	}
+}
@@ -10,30 +10,79 @@ import (
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
 )
 
+// DefaultBurst is the burst used by the runtime limiter. With 55 rpm it keeps the first
+// minute of a bulk run within the provider's 60 requests per minute.
+const DefaultBurst = 5
+
+// Limiter is a token bucket: it holds up to burst tokens and earns one back every
+// interval, so idle time lets concurrent workers start together.
 type Limiter struct {
+	base     time.Duration
 	interval time.Duration
+	burst    int
+	tokens   float64
 	last     time.Time
 	mu       sync.Mutex
 }
 
-func NewLimiter(rpm int) *Limiter {
+const (
+	// maxSlowdown bounds how far Observe429 stretches the interval over the configured rate.
+	maxSlowdown = 16
+	// recoveryFactor shrinks a widened interval on each success until it is back at base.
+	recoveryFactor = 0.9
+)
+
+func NewLimiter(rpm, burst int) *Limiter {
 	if rpm <= 0 {
 		rpm = 55
 	}
-	return &Limiter{interval: time.Minute / time.Duration(rpm)}
+	if burst < 1 {
+		burst = 1
+	}
+	interval := time.Minute / time.Duration(rpm)
+	return &Limiter{base: interval, interval: interval, burst: burst, tokens: float64(burst), last: time.Now()}
+}
+
+// Interval is the current time to earn one token, including any 429 slowdown.
+func (l *Limiter) Interval() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.interval
+}
+
+// Observe429 doubles the interval, up to maxSlowdown times the configured rate, and empties
+// the bucket so no burst follows a rate-limit response.
+func (l *Limiter) Observe429() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.interval = min(l.interval*2, l.base*maxSlowdown)
+	l.tokens = min(l.tokens, 0)
+}
+
+// ObserveSuccess moves a widened interval a step back toward the configured rate.
+func (l *Limiter) ObserveSuccess() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.interval == l.base {
+		return
+	}
+	l.refill(time.Now())
+	l.interval = max(time.Duration(float64(l.interval)*recoveryFactor), l.base)
 }
 
+// Wait takes a token, blocking until one is available or ctx is done.
 func (l *Limiter) Wait(ctx context.Context) error {
 	l.mu.Lock()
 	now := time.Now()
-	next := l.last.Add(l.interval)
-	if next.Before(now) {
-		next = now
+	l.refill(now)
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens * float64(l.interval))
 	}
-	l.last = next
 	l.mu.Unlock()
 
-	wait := time.Until(next)
 	if wait <= 0 {
 		return nil
 	}
@@ -41,30 +90,114 @@ func (l *Limiter) Wait(ctx context.Context) error {
 	defer t.Stop()
 	select {
 	case <-ctx.Done():
+		// Give the reserved token back so later callers are not delayed for it.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
 		return ctx.Err()
 	case <-t.C:
 		return nil
 	}
 }
 
+// refill credits the tokens earned since the last call. Callers hold l.mu.
+func (l *Limiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(float64(l.burst), l.tokens+float64(elapsed)/float64(l.interval))
+	}
+	l.last = now
+}
+
+// Stats accumulates every Retry call made with a context from WithStats.
+type Stats struct {
+	mu       sync.Mutex
+	attempts int
+	lastErr  error
+}
+
+type statsKey struct{}
+
+// WithStats returns a context that makes Retry count attempts and keep the last failure.
+func WithStats(ctx context.Context) (context.Context, *Stats) {
+	st := &Stats{}
+	return context.WithValue(ctx, statsKey{}, st), st
+}
+
+// Attempts is the number of calls made across all Retry loops sharing the context.
+func (s *Stats) Attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+// LastErr is the most recent failed attempt, or nil when no attempt failed.
+func (s *Stats) LastErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+func (s *Stats) record(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if err != nil {
+		s.lastErr = err
+	}
+}
+
+// MaxRetryAfter caps how long Retry honors a provider Retry-After delay.
+const MaxRetryAfter = 60 * time.Second
+
+// Defaults for Policy when retry_attempts and retry_base_ms are unset.
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBase     = 250 * time.Millisecond
+)
+
+// Policy is how many times a call is attempted and the backoff before the first retry, which
+// doubles on each further retry.
+type Policy struct {
+	Attempts int
+	Base     time.Duration
+}
+
+// Retry calls fn with the default backoff; see RetryWith.
 func Retry(ctx context.Context, attempts int, fn func() (bool, error)) error {
-	if attempts < 1 {
-		attempts = 1
+	return RetryWith(ctx, Policy{Attempts: attempts, Base: DefaultRetryBase}, fn)
+}
+
+// RetryWith calls fn until it succeeds, returns a non-retryable error, or p.Attempts run out.
+// Between attempts it waits for the provider's Retry-After when the error carries one
+// (capped at MaxRetryAfter), otherwise for an exponential backoff from p.Base with jitter.
+// A single-attempt policy returns fn's error as is, since nothing was retried.
+func RetryWith(ctx context.Context, p Policy, fn func() (bool, error)) error {
+	attempts := max(p.Attempts, 1)
+	base := p.Base
+	if base <= 0 {
+		base = DefaultRetryBase
 	}
-	base := 250 * time.Millisecond
+	stats, _ := ctx.Value(statsKey{}).(*Stats)
 	for i := 0; i < attempts; i++ {
 		retryable, err := fn()
+		stats.record(err)
 		if err == nil {
 			return nil
 		}
-		if !retryable {
+		if !retryable || attempts == 1 {
 			return err
 		}
 		if i == attempts-1 {
-			return &apperr.AppError{Code: apperr.CodeRateLimited, Message: "request exhausted retries", Retryable: true, Cause: err}
+			return exhausted(err, attempts)
 		}
-		jitter := time.Duration(randomIntn(250)) * time.Millisecond
+		jitter := time.Duration(randomIntn(int(base/time.Millisecond))) * time.Millisecond
 		wait := base*(1<<i) + jitter
+		if after, ok := apperr.RetryAfter(err); ok {
+			wait = min(after, MaxRetryAfter)
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -74,6 +207,37 @@ func Retry(ctx context.Context, attempts int, fn func() (bool, error)) error {
 	return nil
 }
 
+// exhausted reports the last error after every attempt failed. It keeps that error's code and
+// details, so a dead network stays network_error and only a final 429 is rate_limited.
+func exhausted(err error, attempts int) error {
+	out := &apperr.AppError{Code: apperr.CodeNetwork, Message: "request exhausted retries", Retryable: true, Details: map[string]any{"attempts": attempts}, Cause: err}
+	var ae *apperr.AppError
+	if apperr.As(err, &ae) {
+		out.Code = ae.Code
+		for k, v := range ae.Details {
+			out.Details[k] = v
+		}
+		out.Details["attempts"] = attempts
+	}
+	return out
+}
+
+// Stagger waits n steps plus up to half a step of jitter, so the nth of a group of workers
+// started together sends its first request a little after the others instead of in the same
+// instant. It returns early with the context's error when ctx ends.
+func Stagger(ctx context.Context, n int, step time.Duration) error {
+	if n <= 0 || step <= 0 {
+		return ctx.Err()
+	}
+	wait := time.Duration(n)*step + time.Duration(randomIntn(int(step/2)))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
 func randomIntn(max int) int {
 	if max <= 1 {
 		return 0
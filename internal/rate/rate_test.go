@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
+
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
 )
 
 func TestRetryEventuallySucceeds(t *testing.T) {
@@ -19,3 +22,112 @@ func TestRetryEventuallySucceeds(t *testing.T) {
 		t.Fatalf("retry should succeed: %v", err)
 	}
 }
+
+func TestRetryWithReportsAttemptsWhenExhausted(t *testing.T) {
+	count := 0
+	temp := &apperr.AppError{Code: apperr.CodeProvider, Message: "unavailable", Retryable: true}
+	err := RetryWith(context.Background(), Policy{Attempts: 2, Base: time.Millisecond}, func() (bool, error) {
+		count++
+		return true, temp
+	})
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeProvider || ae.Details["attempts"] != 2 || count != 2 {
+		t.Fatalf("expected exhausted provider error after 2 attempts, got %v (calls %d)", err, count)
+	}
+
+	limited := &apperr.AppError{Code: apperr.CodeRateLimited, Message: "too many requests", Details: map[string]any{"status": 429}}
+	err = RetryWith(context.Background(), Policy{Attempts: 2, Base: time.Millisecond}, func() (bool, error) {
+		return true, limited
+	})
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeRateLimited || ae.Details["status"] != 429 || ae.Details["attempts"] != 2 {
+		t.Fatalf("expected a final 429 to stay rate_limited, got %v", err)
+	}
+
+	count = 0
+	err = RetryWith(context.Background(), Policy{Attempts: 1}, func() (bool, error) {
+		count++
+		return true, temp
+	})
+	if err != temp || count != 1 {
+		t.Fatalf("expected a single attempt to return the original error, got %v (calls %d)", err, count)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	count := 0
+	start := time.Now()
+	err := Retry(context.Background(), 2, func() (bool, error) {
+		count++
+		if count == 1 {
+			return true, &apperr.AppError{Code: apperr.CodeRateLimited, Details: map[string]any{apperr.DetailRetryAfter: 0.02}}
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("retry should succeed: %v", err)
+	}
+	// The computed backoff would be at least 250ms; Retry-After asked for 20ms.
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed >= 250*time.Millisecond {
+		t.Fatalf("expected the Retry-After delay to be used, waited %s", elapsed)
+	}
+}
+
+func TestLimiterAllowsBurstThenThrottles(t *testing.T) {
+	l := NewLimiter(600, 3) // one token per 100ms
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("wait %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected burst of 3 to pass immediately, took %s", elapsed)
+	}
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("wait after burst: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("expected the call after the burst to be throttled, took %s", elapsed)
+	}
+}
+
+func TestLimiterWaitHonorsContext(t *testing.T) {
+	l := NewLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
+
+func TestLimiterAdaptsToRepeated429s(t *testing.T) {
+	l := NewLimiter(60, 1)
+	base := l.Interval()
+	for i := 0; i < 3; i++ {
+		l.Observe429()
+	}
+	if got := l.Interval(); got != 8*base {
+		t.Fatalf("expected interval to grow to %s after three 429s, got %s", 8*base, got)
+	}
+	for i := 0; i < 10; i++ {
+		l.Observe429()
+	}
+	if got := l.Interval(); got != maxSlowdown*base {
+		t.Fatalf("expected interval capped at %s, got %s", maxSlowdown*base, got)
+	}
+	prev := l.Interval()
+	l.ObserveSuccess()
+	if got := l.Interval(); got >= prev || got < base {
+		t.Fatalf("expected success to shrink the interval toward %s, got %s (was %s)", base, got, prev)
+	}
+	for i := 0; i < 100; i++ {
+		l.ObserveSuccess()
+	}
+	if got := l.Interval(); got != base {
+		t.Fatalf("expected interval to recover to %s, got %s", base, got)
+	}
+}
@@ -3,6 +3,7 @@ package safety
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
 	"time"
 
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
@@ -11,7 +12,14 @@ import (
 
 const (
 	AckPhrase = "I UNDERSTAND PURCHASES ARE FINAL"
-	TokenTTL  = 10 * time.Minute
+	// TokenTTL is the confirmation token lifetime used when none is configured.
+	TokenTTL = 10 * time.Minute
+	// ActionPurchase and ActionRenew name the action a confirmation token authorizes.
+	ActionPurchase = "purchase"
+	ActionRenew    = "renew"
+	// MinTokenTTLMinutes and MaxTokenTTLMinutes bound confirm_token_ttl_minutes.
+	MinTokenTTLMinutes = 1
+	MaxTokenTTLMinutes = 1440
 )
 
 func HashAcknowledgment(input string) string {
@@ -30,7 +38,12 @@ func EnableAutoPurchase(ack string) (string, error) {
 	return HashAcknowledgment(ack), nil
 }
 
-func IssueToken(domain string, price float64, currency, operationKey string, now time.Time) (store.ConfirmToken, error) {
+// IssueToken stores a confirmation token for action on domain for the given years, valid for
+// ttl, or TokenTTL when ttl is not positive.
+func IssueToken(action, domain string, years int, price float64, currency, operationKey string, now time.Time, ttl time.Duration) (store.ConfirmToken, error) {
+	if ttl <= 0 {
+		ttl = TokenTTL
+	}
 	raw := sha256.Sum256([]byte(domain + "|" + operationKey + "|" + now.UTC().Format(time.RFC3339Nano)))
 	tokenID := hex.EncodeToString(raw[:16])
 	var issued store.ConfirmToken
@@ -38,11 +51,13 @@ func IssueToken(domain string, price float64, currency, operationKey string, now
 		pruneTokens(ts, now)
 		t := store.ConfirmToken{
 			TokenID:      tokenID,
+			Action:       action,
 			Domain:       domain,
+			Years:        years,
 			QuotedPrice:  price,
 			Currency:     currency,
 			IssuedAt:     now.UTC(),
-			ExpiresAt:    now.UTC().Add(TokenTTL),
+			ExpiresAt:    now.UTC().Add(ttl),
 			Used:         false,
 			OperationKey: operationKey,
 		}
@@ -106,7 +121,17 @@ func ValidateAndUseToken(tokenID, domain string, now time.Time) (store.ConfirmTo
 	return used, nil
 }
 
-func ValidateToken(tokenID, domain string, now time.Time) (store.ConfirmToken, error) {
+// tokenAction returns the action t authorizes; tokens issued before actions were recorded
+// were always for purchases.
+func tokenAction(t store.ConfirmToken) string {
+	if t.Action == "" {
+		return ActionPurchase
+	}
+	return t.Action
+}
+
+// ValidateToken checks that tokenID is an active token issued for action on domain.
+func ValidateToken(action, tokenID, domain string, now time.Time) (store.ConfirmToken, error) {
 	ts, err := store.LoadTokens()
 	if err != nil {
 		return store.ConfirmToken{}, err
@@ -119,6 +144,9 @@ func ValidateToken(tokenID, domain string, now time.Time) (store.ConfirmToken, e
 		if t.Domain != domain {
 			return store.ConfirmToken{}, &apperr.AppError{Code: apperr.CodeConfirmation, Message: "token domain mismatch"}
 		}
+		if tokenAction(t) != action {
+			return store.ConfirmToken{}, &apperr.AppError{Code: apperr.CodeConfirmation, Message: "confirmation token was issued for a different action", Details: map[string]any{"token_action": tokenAction(t), "action": action}}
+		}
 		if t.Used {
 			return store.ConfirmToken{}, &apperr.AppError{Code: apperr.CodeConfirmation, Message: "confirmation token already used"}
 		}
@@ -160,6 +188,65 @@ func MarkTokenUsed(tokenID, domain string, now time.Time) error {
 	return nil
 }
 
+// ActiveTokens returns the unused, unexpired confirmation tokens.
+func ActiveTokens(now time.Time) ([]store.ConfirmToken, error) {
+	ts, err := store.LoadTokens()
+	if err != nil {
+		return nil, err
+	}
+	pruneTokens(ts, now)
+	return ts.Tokens, nil
+}
+
+// RevokeTokens marks active tokens as used so they can no longer confirm a purchase.
+// tokenID may be a unique prefix of a token ID; with all set every active token is revoked.
+func RevokeTokens(tokenID string, all bool, now time.Time) ([]store.ConfirmToken, error) {
+	var revoked []store.ConfirmToken
+	err := store.LoadAndSaveTokens(func(ts *store.TokenStore) error {
+		pruneTokens(ts, now)
+		var matches []int
+		for i, t := range ts.Tokens {
+			if all || t.TokenID == tokenID {
+				matches = append(matches, i)
+			}
+		}
+		if !all && len(matches) == 0 && tokenID != "" {
+			for i, t := range ts.Tokens {
+				if strings.HasPrefix(t.TokenID, tokenID) {
+					matches = append(matches, i)
+				}
+			}
+			if len(matches) > 1 {
+				return &apperr.AppError{Code: apperr.CodeValidation, Message: "token prefix matches more than one token", Details: map[string]any{"token_id": tokenID, "matches": len(matches)}}
+			}
+		}
+		if !all && len(matches) == 0 {
+			return &apperr.AppError{Code: apperr.CodeConfirmation, Message: "confirmation token not found"}
+		}
+		for _, i := range matches {
+			ts.Tokens[i].Used = true
+			revoked = append(revoked, ts.Tokens[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// CheckTokenTTLMinutes validates a configured confirmation token lifetime.
+func CheckTokenTTLMinutes(minutes int) error {
+	if minutes < MinTokenTTLMinutes || minutes > MaxTokenTTLMinutes {
+		return &apperr.AppError{
+			Code:    apperr.CodeValidation,
+			Message: "confirm-token-ttl-minutes must be between 1 and 1440",
+			Details: map[string]any{"confirm_token_ttl_minutes": minutes},
+		}
+	}
+	return nil
+}
+
 func RequireAutoEnabled(autoEnabled bool, ackHash string) error {
 	if !autoEnabled || ackHash == "" {
 		return &apperr.AppError{Code: apperr.CodeSafety, Message: "auto-purchase is not enabled"}
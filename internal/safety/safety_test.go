@@ -15,7 +15,7 @@ func TestTokenLifecycle(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
 	now := time.Now().UTC()
-	tok, err := IssueToken("example.com", 12.99, "USD", "op-key", now)
+	tok, err := IssueToken(ActionPurchase, "example.com", 1, 12.99, "USD", "op-key", now, 0)
 	if err != nil {
 		t.Fatalf("issue token: %v", err)
 	}
@@ -54,10 +54,10 @@ func TestTokenPruneRemovesExpired(t *testing.T) {
 	t.Setenv("HOME", home)
 	now := time.Now().UTC()
 
-	if _, err := IssueToken("expired.com", 10, "USD", "op-expired", now.Add(-2*TokenTTL)); err != nil {
+	if _, err := IssueToken(ActionPurchase, "expired.com", 1, 10, "USD", "op-expired", now.Add(-2*TokenTTL), 0); err != nil {
 		t.Fatalf("issue expired token: %v", err)
 	}
-	fresh, err := IssueToken("fresh.com", 11, "USD", "op-fresh", now)
+	fresh, err := IssueToken(ActionPurchase, "fresh.com", 1, 11, "USD", "op-fresh", now, 0)
 	if err != nil {
 		t.Fatalf("issue fresh token: %v", err)
 	}
@@ -79,7 +79,7 @@ func TestValidateAndUseTokenSingleSuccessUnderConcurrency(t *testing.T) {
 	t.Setenv("HOME", home)
 	now := time.Now().UTC()
 
-	tok, err := IssueToken("example.com", 12.99, "USD", "op-concurrent", now)
+	tok, err := IssueToken(ActionPurchase, "example.com", 1, 12.99, "USD", "op-concurrent", now, 0)
 	if err != nil {
 		t.Fatalf("issue token: %v", err)
 	}
@@ -104,3 +104,34 @@ func TestValidateAndUseTokenSingleSuccessUnderConcurrency(t *testing.T) {
 		t.Fatalf("expected exactly one successful token use, got %d", successCount)
 	}
 }
+
+func TestRevokeTokens(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Now().UTC()
+	a, err := IssueToken(ActionPurchase, "a.com", 1, 10, "USD", "op-a", now, 0)
+	if err != nil {
+		t.Fatalf("issue a: %v", err)
+	}
+	if _, err := IssueToken(ActionPurchase, "b.com", 1, 11, "USD", "op-b", now, 0); err != nil {
+		t.Fatalf("issue b: %v", err)
+	}
+
+	revoked, err := RevokeTokens(a.TokenID[:8], false, now)
+	if err != nil || len(revoked) != 1 || revoked[0].TokenID != a.TokenID {
+		t.Fatalf("expected prefix revoke of token a, got %+v (%v)", revoked, err)
+	}
+	if _, err := ValidateToken(ActionPurchase, a.TokenID, "a.com", now); err == nil {
+		t.Fatalf("expected revoked token to be rejected")
+	}
+	active, err := ActiveTokens(now)
+	if err != nil || len(active) != 1 || active[0].Domain != "b.com" {
+		t.Fatalf("expected only token b active, got %+v (%v)", active, err)
+	}
+	if _, err := RevokeTokens("missing", false, now); err == nil {
+		t.Fatalf("expected unknown token to fail")
+	}
+	revoked, err = RevokeTokens("", true, now)
+	if err != nil || len(revoked) != 1 {
+		t.Fatalf("expected --all to revoke remaining token, got %+v (%v)", revoked, err)
+	}
+}
@@ -2,13 +2,21 @@ package services
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"math"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,12 +36,38 @@ import (
 type Service struct {
 	RT     *app.Runtime
 	Client godaddy.Client
+
+	// detailCache holds domain detail for the lifetime of one invocation, keyed by domain and includes.
+	detailMu    sync.Mutex
+	detailCache map[string]map[string]any
 }
 
 type renewAsShopperClient interface {
 	RenewAsShopper(ctx context.Context, shopperID, domain string, years int, idempotencyKey string) (godaddy.RenewResult, error)
 }
 
+type shopperLookupClient interface {
+	GetShopper(ctx context.Context, shopperID string) (godaddy.Shopper, error)
+}
+
+type agreementsClient interface {
+	Agreements(ctx context.Context, tlds []string, privacy, forTransfer bool) ([]godaddy.Agreement, error)
+}
+
+type tldClient interface {
+	ListTLDs(ctx context.Context) ([]godaddy.TLD, error)
+}
+
+type subscriptionClient interface {
+	GetSubscription(ctx context.Context, subscriptionID string) (godaddy.Subscription, error)
+	SetSubscriptionAutoRenew(ctx context.Context, subscriptionID string, enabled bool) error
+}
+
+type domainLockClient interface {
+	SetLockV2(ctx context.Context, customerID, domain string, locked bool) error
+	SetLockV1(ctx context.Context, domain string, locked bool) error
+}
+
 type v2RouterClient interface {
 	ResolveCustomerID(ctx context.Context, shopperID string) (string, error)
 	DomainDetailV2(ctx context.Context, customerID, domain string, includes []string) (map[string]any, error)
@@ -102,6 +136,7 @@ func enrichRenewError(err error) error {
 		Message:   "renewal failed: invalid payment info. Fund Good As Gold or update payment profile in GoDaddy.",
 		Details:   details,
 		Retryable: false,
+		DocURL:    apperr.DocInvalidPaymentInfo,
 		Cause:     err,
 	}
 }
@@ -168,7 +203,7 @@ func (s *Service) buildRenewV2Request(ctx context.Context, v2c v2RouterClient, c
 	}
 	currency, _ := renewal["currency"].(string)
 	if strings.TrimSpace(currency) == "" {
-		currency = "USD"
+		currency = budget.BaseCurrency(s.RT.Cfg)
 	}
 	agreedBy := strings.TrimSpace(os.Getenv("GDCLI_AGREED_BY_IP"))
 	if agreedBy == "" {
@@ -186,6 +221,27 @@ func (s *Service) buildRenewV2Request(ctx context.Context, v2c v2RouterClient, c
 	}, nil
 }
 
+// renewalQuote reads the provider renewal price from v2 domain detail when a customer ID is
+// configured. It falls back to the given estimate, with source "estimate", when the detail
+// or its renewal price is unavailable.
+func (s *Service) renewalQuote(ctx context.Context, domain string, years int, estimate float64, currency string) (float64, string, string) {
+	v2c, ok := s.v2Client()
+	if !ok || !canUseV2(s.RT.Cfg.CustomerID) {
+		return estimate, currency, "estimate"
+	}
+	for _, customerID := range s.renewV2CustomerCandidates() {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			break
+		}
+		req, err := s.buildRenewV2Request(ctx, v2c, customerID, domain, years)
+		if err != nil {
+			continue
+		}
+		return float64(req.Consent.Price) / 1_000_000, req.Consent.Currency, "provider"
+	}
+	return estimate, currency, "estimate"
+}
+
 func (s *Service) renewV2CustomerCandidates() []string {
 	out := make([]string, 0, 2)
 	add := func(v string) {
@@ -212,12 +268,34 @@ func (s *Service) v2Client() (v2RouterClient, bool) {
 }
 
 type BulkAvailabilityItem struct {
-	Index    int                  `json:"index"`
-	Input    string               `json:"input"`
-	Success  bool                 `json:"success"`
-	Result   godaddy.Availability `json:"result,omitempty"`
-	Error    string               `json:"error,omitempty"`
-	Duration int64                `json:"duration_ms"`
+	Index      int                  `json:"index"`
+	Input      string               `json:"input"`
+	Success    bool                 `json:"success"`
+	Result     godaddy.Availability `json:"result,omitempty"`
+	Definitive bool                 `json:"definitive"`
+	Error      string               `json:"error,omitempty"`
+	Duration   int64                `json:"duration_ms"`
+	Attempts   int                  `json:"attempts"`
+	LastStatus int                  `json:"last_status,omitempty"`
+}
+
+// AttemptStatus returns the HTTP status carried by the last failed provider attempt, or 0.
+func AttemptStatus(stats *rate.Stats) int {
+	var ae *apperr.AppError
+	if !apperr.As(stats.LastErr(), &ae) {
+		return 0
+	}
+	for ae != nil {
+		if status, ok := ae.Details["status"].(int); ok {
+			return status
+		}
+		var inner *apperr.AppError
+		if !apperr.As(ae.Cause, &inner) {
+			return 0
+		}
+		ae = inner
+	}
+	return 0
 }
 
 type PortfolioDetailItem struct {
@@ -234,6 +312,33 @@ func New(rt *app.Runtime, client godaddy.Client) *Service {
 	return &Service{RT: rt, Client: client}
 }
 
+// retryOutcome is the result of a rate.Retry attempt. It reports the outcome to the limiter,
+// so repeated 429s slow every worker down, and retries retryable and rate-limited errors.
+func (s *Service) retryOutcome(err error) (bool, error) {
+	if err == nil {
+		s.RT.Limiter.ObserveSuccess()
+		return false, nil
+	}
+	var ae *apperr.AppError
+	if apperr.As(err, &ae) {
+		if ae.Code == apperr.CodeRateLimited {
+			s.RT.Limiter.Observe429()
+		}
+		return ae.Retryable || ae.Code == apperr.CodeRateLimited || ae.Code == apperr.CodeNetwork, err
+	}
+	return true, err
+}
+
+// paced runs one provider call under the shared limiter, retrying it per the retry policy.
+func (s *Service) paced(ctx context.Context, call func() error) error {
+	return rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		return s.retryOutcome(call())
+	})
+}
+
 func (s *Service) appendOperationWithWarning(op store.Operation) {
 	if err := store.AppendOperation(op); err != nil {
 		output.LogErr(s.RT.ErrOut, "warning: failed writing operation log for operation_id=%s: %v", op.OperationID, err)
@@ -241,13 +346,22 @@ func (s *Service) appendOperationWithWarning(op store.Operation) {
 }
 
 func (s *Service) reserveOperation(opType, domain string, amount float64, currency, operationID string, now time.Time) (bool, error) {
+	if err := app.GuardProdFinancial(s.RT, opType); err != nil {
+		return false, err
+	}
+	spend, err := budget.ToBase(s.RT.Cfg, amount, currency)
+	if err != nil {
+		return false, err
+	}
 	alreadySucceeded := false
-	err := store.LoadAndSaveOperations(func(ops *[]store.Operation) error {
+	err = store.LoadAndSaveOperations(func(ops *[]store.Operation) error {
 		dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 		dayEnd := dayStart.Add(24 * time.Hour)
+		monthStart, monthEnd := budget.MonthBounds(now)
 
 		totalSpend := 0.0
 		totalDomains := 0
+		monthSpend := 0.0
 		for _, op := range *ops {
 			if op.OperationID == operationID {
 				switch op.Status {
@@ -262,24 +376,27 @@ func (s *Service) reserveOperation(opType, domain string, amount float64, curren
 					}
 				}
 			}
-			if op.CreatedAt.Before(dayStart) || !op.CreatedAt.Before(dayEnd) {
-				continue
-			}
 			if op.Type != "purchase" && op.Type != "renew" {
 				continue
 			}
 			if op.Status != "succeeded" && op.Status != "pending" {
 				continue
 			}
-			totalSpend += op.Amount
+			if !op.CreatedAt.Before(monthStart) && op.CreatedAt.Before(monthEnd) {
+				monthSpend += budget.OperationSpend(s.RT.Cfg, op)
+			}
+			if op.CreatedAt.Before(dayStart) || !op.CreatedAt.Before(dayEnd) {
+				continue
+			}
+			totalSpend += budget.OperationSpend(s.RT.Cfg, op)
 			totalDomains++
 		}
 
-		if totalSpend+amount > s.RT.Cfg.MaxDailySpend {
+		if totalSpend+spend > s.RT.Cfg.MaxDailySpend {
 			return &apperr.AppError{
 				Code:    apperr.CodeBudget,
 				Message: "daily spend cap exceeded",
-				Details: map[string]any{"attempted_total": totalSpend + amount, "max_daily_spend": s.RT.Cfg.MaxDailySpend},
+				Details: map[string]any{"attempted_total": totalSpend + spend, "max_daily_spend": s.RT.Cfg.MaxDailySpend},
 			}
 		}
 		if totalDomains+1 > s.RT.Cfg.MaxDomainsPerDay {
@@ -289,6 +406,9 @@ func (s *Service) reserveOperation(opType, domain string, amount float64, curren
 				Details: map[string]any{"attempted_total": totalDomains + 1, "max_domains_per_day": s.RT.Cfg.MaxDomainsPerDay},
 			}
 		}
+		if err := budget.CheckMonthlySpend(s.RT.Cfg, monthSpend+spend); err != nil {
+			return err
+		}
 
 		*ops = append(*ops, store.Operation{
 			OperationID: operationID,
@@ -307,7 +427,73 @@ func (s *Service) reserveOperation(opType, domain string, amount float64, curren
 	return alreadySucceeded, nil
 }
 
-func (s *Service) finalizeOperation(operationID string, amount float64, currency, status string) error {
+// alreadyPurchasedResult describes a purchase that the operations log shows already succeeded.
+func alreadyPurchasedResult(operationID, domain string, price float64, currency string) godaddy.PurchaseResult {
+	res := godaddy.PurchaseResult{Domain: domain, Price: price, Currency: currency, AlreadyBought: true, OperationKey: operationID}
+	ops, err := store.ReadOperations()
+	if err != nil {
+		return res
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].OperationID == operationID && ops[i].Status == "succeeded" {
+			res.OrderID = ops[i].OrderID
+			res.Price = ops[i].Amount
+			if ops[i].Currency != "" {
+				res.Currency = ops[i].Currency
+			}
+			break
+		}
+	}
+	return res
+}
+
+// opResult is what a finished purchase or renewal adds to its operation. It is written in the
+// same log rewrite as the final status, so a succeeded operation never lacks its order ID.
+type opResult struct {
+	OrderID string
+	// Definitive is whether the availability result behind an auto-purchase was definitive.
+	Definitive *bool
+}
+
+// ResolveOperation settles a pending operation by hand, for one left pending when a run stopped
+// mid-order (see failSpend). status is "succeeded" when account orders shows the order went
+// through, or "failed" to release its reservation against the caps.
+func (s *Service) ResolveOperation(operationID, status, orderID string) (store.Operation, error) {
+	if status != "succeeded" && status != "failed" {
+		return store.Operation{}, &apperr.AppError{Code: apperr.CodeValidation, Message: "status must be succeeded or failed", Details: map[string]any{"status": status}}
+	}
+	op, ok, err := findOperation(operationID)
+	if err != nil {
+		return store.Operation{}, err
+	}
+	if !ok {
+		return store.Operation{}, &apperr.AppError{Code: apperr.CodeValidation, Message: "operation not found", Details: map[string]any{"operation_id": operationID}}
+	}
+	if op.Status != "pending" {
+		return store.Operation{}, &apperr.AppError{Code: apperr.CodeValidation, Message: "only pending operations can be resolved", Details: map[string]any{"operation_id": operationID, "status": op.Status}}
+	}
+	if err := s.finalizeOperation(operationID, op.Amount, op.Currency, status, opResult{OrderID: orderID}); err != nil {
+		return store.Operation{}, err
+	}
+	op, _, err = findOperation(operationID)
+	return op, err
+}
+
+// findOperation returns the latest log entry for operationID.
+func findOperation(operationID string) (store.Operation, bool, error) {
+	ops, err := store.ReadOperations()
+	if err != nil {
+		return store.Operation{}, false, err
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].OperationID == operationID {
+			return ops[i], true, nil
+		}
+	}
+	return store.Operation{}, false, nil
+}
+
+func (s *Service) finalizeOperation(operationID string, amount float64, currency, status string, res opResult) error {
 	now := time.Now()
 	var policyErr error
 	err := store.LoadAndSaveOperations(func(ops *[]store.Operation) error {
@@ -325,45 +511,54 @@ func (s *Service) finalizeOperation(operationID string, amount float64, currency
 				Currency:    currency,
 				CreatedAt:   now,
 				Status:      status,
+				OrderID:     strings.TrimSpace(res.OrderID),
+				Definitive:  res.Definitive,
 			})
 			return nil
 		}
 
 		op := (*ops)[index]
-		if status == "succeeded" {
+		// reserveOperation already counted this operation (as pending) against the daily
+		// and monthly caps, including its domain slot. Re-checking the full amount against other
+		// pending reservations would double-count them, so only a provider amount above
+		// what was reserved needs to fit in the remaining headroom.
+		spend := budget.OperationSpend(s.RT.Cfg, store.Operation{Amount: amount, Currency: currency})
+		if status == "succeeded" && op.Status == "pending" && spend > budget.OperationSpend(s.RT.Cfg, op) {
 			dayStart := time.Date(op.CreatedAt.Year(), op.CreatedAt.Month(), op.CreatedAt.Day(), 0, 0, 0, 0, op.CreatedAt.Location())
 			dayEnd := dayStart.Add(24 * time.Hour)
+			monthStart, monthEnd := budget.MonthBounds(op.CreatedAt)
 			totalSpend := 0.0
-			totalDomains := 0
+			monthSpend := 0.0
 			for i, existing := range *ops {
 				if i == index {
 					continue
 				}
-				if existing.CreatedAt.Before(dayStart) || !existing.CreatedAt.Before(dayEnd) {
-					continue
-				}
 				if existing.Type != "purchase" && existing.Type != "renew" {
 					continue
 				}
 				if existing.Status != "succeeded" && existing.Status != "pending" {
 					continue
 				}
-				totalSpend += existing.Amount
-				totalDomains++
+				if !existing.CreatedAt.Before(monthStart) && existing.CreatedAt.Before(monthEnd) {
+					monthSpend += budget.OperationSpend(s.RT.Cfg, existing)
+				}
+				if existing.CreatedAt.Before(dayStart) || !existing.CreatedAt.Before(dayEnd) {
+					continue
+				}
+				totalSpend += budget.OperationSpend(s.RT.Cfg, existing)
 			}
-			if totalSpend+amount > s.RT.Cfg.MaxDailySpend {
+			if totalSpend+spend > s.RT.Cfg.MaxDailySpend {
 				policyErr = &apperr.AppError{
 					Code:    apperr.CodeBudget,
 					Message: "daily spend cap exceeded by finalized provider amount",
-					Details: map[string]any{"attempted_total": totalSpend + amount, "max_daily_spend": s.RT.Cfg.MaxDailySpend},
+					Details: map[string]any{"attempted_total": totalSpend + spend, "reserved_amount": op.Amount, "max_daily_spend": s.RT.Cfg.MaxDailySpend},
 				}
 				status = "failed"
-			}
-			if totalDomains+1 > s.RT.Cfg.MaxDomainsPerDay {
+			} else if s.RT.Cfg.MaxMonthlySpend > 0 && monthSpend+spend > s.RT.Cfg.MaxMonthlySpend {
 				policyErr = &apperr.AppError{
 					Code:    apperr.CodeBudget,
-					Message: "daily domain count cap exceeded by finalized provider amount",
-					Details: map[string]any{"attempted_total": totalDomains + 1, "max_domains_per_day": s.RT.Cfg.MaxDomainsPerDay},
+					Message: "monthly spend cap exceeded by finalized provider amount",
+					Details: map[string]any{"attempted_total": monthSpend + spend, "reserved_amount": op.Amount, "max_monthly_spend": s.RT.Cfg.MaxMonthlySpend},
 				}
 				status = "failed"
 			}
@@ -374,6 +569,12 @@ func (s *Service) finalizeOperation(operationID string, amount float64, currency
 			op.Currency = currency
 		}
 		op.Status = status
+		if orderID := strings.TrimSpace(res.OrderID); orderID != "" {
+			op.OrderID = orderID
+		}
+		if res.Definitive != nil {
+			op.Definitive = res.Definitive
+		}
 		(*ops)[index] = op
 		return nil
 	})
@@ -383,45 +584,86 @@ func (s *Service) finalizeOperation(operationID string, amount float64, currency
 	return policyErr
 }
 
-func (s *Service) Suggest(ctx context.Context, query string, tlds []string, limit int) (map[string]any, error) {
+// SuggestSortFields are the accepted --sort values for suggestions: score sorts highest first,
+// domain alphabetically.
+var SuggestSortFields = []string{"score", "domain"}
+
+// SuggestFilter trims provider suggestions. Zero values keep every suggestion in provider order.
+type SuggestFilter struct {
+	MinScore      float64
+	SortBy        string
+	AvailableOnly bool
+	// Concurrency bounds the availability checks made for AvailableOnly.
+	Concurrency int
+}
+
+// Suggest returns the provider's suggestions for query narrowed by filter, with their count.
+// AvailableOnly checks every remaining suggestion and drops the taken ones; suggestions whose
+// check failed are dropped too and counted in "unchecked".
+func (s *Service) Suggest(ctx context.Context, query string, tlds []string, limit int, filter SuggestFilter) (map[string]any, error) {
 	var out []godaddy.Suggestion
-	err := rate.Retry(ctx, 3, func() (bool, error) {
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
 		if err := s.RT.Limiter.Wait(ctx); err != nil {
 			return false, err
 		}
 		r, err := s.Client.Suggest(ctx, query, tlds, limit)
 		out = r
-		if err == nil {
-			return false, nil
-		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
-		}
-		return true, err
+		return s.retryOutcome(err)
 	})
 	if err != nil {
 		return nil, enrichRenewError(err)
 	}
-	return map[string]any{"query": query, "suggestions": out}, nil
+	if filter.MinScore > 0 {
+		out = slices.DeleteFunc(out, func(sug godaddy.Suggestion) bool { return sug.Score < filter.MinScore })
+	}
+	res := map[string]any{"query": query}
+	if filter.AvailableOnly && len(out) > 0 {
+		domains := make([]string, len(out))
+		for i, sug := range out {
+			domains[i] = sug.Domain
+		}
+		items, err := s.AvailabilityBulkConcurrent(ctx, domains, filter.Concurrency)
+		kept := out[:0]
+		unchecked := 0
+		for i, item := range items {
+			switch {
+			case !item.Success:
+				unchecked++
+			case item.Result.Available:
+				kept = append(kept, out[i])
+			}
+		}
+		// Some failed checks only shrink the list; when none succeeded the filter means nothing.
+		if err != nil && unchecked == len(items) {
+			return nil, err
+		}
+		out = kept
+		res["unchecked"] = unchecked
+	}
+	switch filter.SortBy {
+	case "score":
+		slices.SortStableFunc(out, func(a, b godaddy.Suggestion) int { return cmp.Compare(b.Score, a.Score) })
+	case "domain":
+		slices.SortStableFunc(out, func(a, b godaddy.Suggestion) int { return strings.Compare(a.Domain, b.Domain) })
+	}
+	res["suggestions"] = out
+	res["count"] = len(out)
+	return res, nil
 }
 
 func (s *Service) Availability(ctx context.Context, domain string) (godaddy.Availability, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return godaddy.Availability{}, err
+	}
 	var out godaddy.Availability
-	err := rate.Retry(ctx, 3, func() (bool, error) {
+	err = rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
 		if err := s.RT.Limiter.Wait(ctx); err != nil {
 			return false, err
 		}
 		r, err := s.Client.Available(ctx, domain)
 		out = r
-		if err == nil {
-			return false, nil
-		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
-		}
-		return true, err
+		return s.retryOutcome(err)
 	})
 	return out, err
 }
@@ -436,6 +678,60 @@ func (s *Service) IdentityShow() map[string]any {
 	}
 }
 
+// Whoami combines the locally configured identity with a credential check (a one-order
+// listing) and a best-effort live shopper lookup. A rejected key is returned as the provider's
+// auth error; other check and lookup failures are reported in the result rather than returned.
+func (s *Service) Whoami(ctx context.Context) (map[string]any, error) {
+	shopperID := strings.TrimSpace(s.RT.Cfg.ShopperID)
+	customerID := strings.TrimSpace(s.RT.Cfg.CustomerID)
+	credentials := map[string]any{"valid": true, "checked_with": "GET /v1/orders"}
+	if _, err := s.ordersPage(ctx, 1, 0); err != nil {
+		var ae *apperr.AppError
+		switch {
+		case apperr.As(err, &ae) && ae.Code == apperr.CodeAuth && ae.Details["status"] == 403:
+			// A 403 means the key authenticated but may not list orders, e.g. a scoped key.
+			credentials = map[string]any{"valid": true, "scope": "limited", "checked_with": "GET /v1/orders", "error": err.Error()}
+		case apperr.As(err, &ae) && ae.Code == apperr.CodeAuth:
+			return nil, err
+		default:
+			// Anything but an auth failure says nothing about the key, so "valid" is left out.
+			credentials = map[string]any{"checked_with": "GET /v1/orders", "error": err.Error()}
+		}
+	}
+	out := map[string]any{
+		"credentials":     credentials,
+		"identity":        s.IdentityShow(),
+		"api_environment": s.RT.APIEnvironment(),
+		"v2_ready":        canUseV2(customerID),
+		"id_usage": map[string]any{
+			"v1": "shopper_id: sent as X-Shopper-Id for v1 calls that act on behalf of a shopper (e.g. renew)",
+			"v2": "customer_id: used in /v2/customers/{customerId}/... paths for customer-scoped operations",
+		},
+	}
+	guidance := make([]string, 0, 2)
+	if shopperID == "" {
+		out["shopper_lookup"] = map[string]any{"ok": false, "skipped": true, "reason": "shopper_id not configured"}
+		guidance = append(guidance, "run: gdcli account identity set --shopper-id <id> (or set GDCLI_SHOPPER_ID)")
+	} else if sc, ok := s.Client.(shopperLookupClient); ok {
+		shopper, err := sc.GetShopper(ctx, shopperID)
+		if err != nil {
+			out["shopper_lookup"] = map[string]any{"ok": false, "error": err.Error()}
+		} else {
+			out["shopper_lookup"] = map[string]any{"ok": true, "shopper": shopper}
+			if customerID != "" && shopper.CustomerID != "" && shopper.CustomerID != customerID {
+				guidance = append(guidance, "configured customer_id differs from the shopper's customerId; run: gdcli account identity resolve")
+			}
+		}
+	} else {
+		out["shopper_lookup"] = map[string]any{"ok": false, "skipped": true, "reason": "client does not support shopper lookup"}
+	}
+	if customerID == "" {
+		guidance = append(guidance, "run: gdcli account identity resolve to enable v2 customer-scoped commands")
+	}
+	out["guidance"] = guidance
+	return out, nil
+}
+
 func (s *Service) ResolveAndStoreCustomerID(ctx context.Context, shopperID string) (string, error) {
 	v2c, ok := s.v2Client()
 	if !ok {
@@ -452,7 +748,88 @@ func (s *Service) ResolveAndStoreCustomerID(ctx context.Context, shopperID strin
 	return customerID, nil
 }
 
+// VerifySetup runs the init --verify checks and reports each one as {check, ok, ...}. The
+// availability lookup always runs; deep adds an orders call and, when a shopper is configured, a
+// customer ID resolution, which catch keys that work for availability but lack access to those
+// endpoints. Every check runs even after a failure; the returned error is the first failure.
+func (s *Service) VerifySetup(ctx context.Context, deep bool) ([]map[string]any, error) {
+	var checks []map[string]any
+	var firstErr error
+	record := func(name string, err error, fields map[string]any) {
+		c := map[string]any{"check": name, "ok": err == nil}
+		for k, v := range fields {
+			c[k] = v
+		}
+		if err != nil {
+			c["error"] = err.Error()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		checks = append(checks, c)
+	}
+
+	avail, err := s.Availability(ctx, "example.com")
+	record("availability", err, map[string]any{"sample_domain": avail.Domain})
+	if !deep {
+		return checks, firstErr
+	}
+
+	_, err = s.ordersPage(ctx, 1, 0)
+	record("orders", err, nil)
+
+	shopperID := strings.TrimSpace(s.RT.Cfg.ShopperID)
+	switch v2c, ok := s.v2Client(); {
+	case shopperID == "":
+		checks = append(checks, map[string]any{"check": "customer_id", "ok": true, "skipped": true, "reason": "shopper_id not configured"})
+	case !ok:
+		checks = append(checks, map[string]any{"check": "customer_id", "ok": true, "skipped": true, "reason": "client does not support v2 identity resolution"})
+	default:
+		customerID, err := v2c.ResolveCustomerID(ctx, shopperID)
+		fields := map[string]any{"shopper_id": shopperID}
+		if err == nil {
+			fields["customer_id"] = customerID
+		}
+		record("customer_id", err, fields)
+	}
+	return checks, firstErr
+}
+
+// DomainDetail returns domain detail, fetching each domain/includes pair at most once per
+// Service. Use DomainDetailFresh when the answer must reflect a change made in this run.
 func (s *Service) DomainDetail(ctx context.Context, domain string, includes []string) (map[string]any, error) {
+	key := detailCacheKey(domain, includes)
+	s.detailMu.Lock()
+	cached, ok := s.detailCache[key]
+	s.detailMu.Unlock()
+	if ok {
+		return maps.Clone(cached), nil
+	}
+	return s.DomainDetailFresh(ctx, domain, includes)
+}
+
+// DomainDetailFresh always calls the API and refreshes the cached detail.
+func (s *Service) DomainDetailFresh(ctx context.Context, domain string, includes []string) (map[string]any, error) {
+	out, err := s.fetchDomainDetail(ctx, domain, includes)
+	if err != nil {
+		return nil, err
+	}
+	s.detailMu.Lock()
+	if s.detailCache == nil {
+		s.detailCache = map[string]map[string]any{}
+	}
+	s.detailCache[detailCacheKey(domain, includes)] = out
+	s.detailMu.Unlock()
+	return maps.Clone(out), nil
+}
+
+func detailCacheKey(domain string, includes []string) string {
+	inc := append([]string(nil), includes...)
+	sort.Strings(inc)
+	return strings.ToLower(strings.TrimSpace(domain)) + "|" + strings.Join(inc, ",")
+}
+
+func (s *Service) fetchDomainDetail(ctx context.Context, domain string, includes []string) (map[string]any, error) {
 	v2c, ok := s.v2Client()
 	if !ok {
 		return nil, &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support domain detail"}
@@ -469,7 +846,165 @@ func (s *Service) DomainDetail(ctx context.Context, domain string, includes []st
 	return out, nil
 }
 
+// DomainLock reports the transfer lock state from domain detail.
+func (s *Service) DomainLock(ctx context.Context, domain string) (map[string]any, error) {
+	detail, err := s.DomainDetailFresh(ctx, domain, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{"domain": domain, "api_version": detail["_api_version"]}
+	if locked, ok := detail["locked"].(bool); ok {
+		out["locked"] = locked
+	} else {
+		out["locked"] = nil
+	}
+	return out, nil
+}
+
+// contactRoles maps provider contact keys (shared by v1 detail and v2 contacts) to output names.
+var contactRoles = []struct{ key, role string }{
+	{"contactRegistrant", "registrant"},
+	{"contactAdmin", "admin"},
+	{"contactTech", "tech"},
+	{"contactBilling", "billing"},
+}
+
+// DomainContacts reads a domain's contacts from the v2 contacts endpoint, falling back to v1
+// domain detail, and returns them keyed by role. Roles the provider omits are left out.
+func (s *Service) DomainContacts(ctx context.Context, domain string) (map[string]any, error) {
+	v2c, ok := s.v2Client()
+	if !ok {
+		return nil, &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support domain contacts"}
+	}
+	customerID := s.RT.Cfg.CustomerID
+	raw, usedV2, err := doV2ThenV1(
+		canUseV2(customerID),
+		func() (map[string]any, error) {
+			var out map[string]any
+			path := "/v2/customers/" + url.PathEscape(customerID) + "/domains/" + url.PathEscape(domain) + "/contacts"
+			err := v2c.V2Get(ctx, path, nil, &out)
+			return out, err
+		},
+		func() (map[string]any, error) { return v2c.DomainDetailV1(ctx, domain) },
+	)
+	if err != nil {
+		return nil, err
+	}
+	contacts := map[string]any{}
+	for _, c := range contactRoles {
+		if block, ok := raw[c.key].(map[string]any); ok {
+			contacts[c.role] = block
+		}
+	}
+	return map[string]any{
+		"domain":      domain,
+		"contacts":    contacts,
+		"api_version": map[bool]string{true: "v2", false: "v1"}[usedV2],
+	}, nil
+}
+
+// SetDomainLock toggles the transfer lock (v2 first, v1 fallback) and re-reads detail to
+// report the resulting state.
+func (s *Service) SetDomainLock(ctx context.Context, domain string, locked bool) (map[string]any, error) {
+	lc, ok := s.Client.(domainLockClient)
+	if !ok {
+		return nil, &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support domain lock"}
+	}
+	_, usedV2, err := doV2ThenV1(
+		canUseV2(s.RT.Cfg.CustomerID),
+		func() (struct{}, error) {
+			return struct{}{}, lc.SetLockV2(ctx, s.RT.Cfg.CustomerID, domain, locked)
+		},
+		func() (struct{}, error) { return struct{}{}, lc.SetLockV1(ctx, domain, locked) },
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{
+		"domain":      domain,
+		"requested":   locked,
+		"locked":      locked,
+		"applied":     true,
+		"verified":    false,
+		"api_version": map[bool]string{true: "v2", false: "v1"}[usedV2],
+	}
+	if _, ok := s.v2Client(); ok {
+		if detail, err := s.DomainDetailFresh(ctx, domain, nil); err == nil {
+			if actual, ok := detail["locked"].(bool); ok {
+				out["locked"] = actual
+				out["verified"] = actual == locked
+			}
+		}
+	}
+	return out, nil
+}
+
+// NormalizeNameservers trims and lowercases nameserver hostnames (dropping a trailing dot) and
+// requires at least two distinct, syntactically valid FQDNs. Bad entries are listed in the
+// error's details.
+func NormalizeNameservers(nameservers []string) ([]string, error) {
+	out := make([]string, 0, len(nameservers))
+	var invalid []string
+	for _, ns := range nameservers {
+		host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(ns)), ".")
+		if !validHostname(host) {
+			invalid = append(invalid, ns)
+			continue
+		}
+		if !slices.Contains(out, host) {
+			out = append(out, host)
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, &apperr.AppError{
+			Code:    apperr.CodeValidation,
+			Message: fmt.Sprintf("invalid nameserver hostname: %s", strings.Join(invalid, ", ")),
+			Details: map[string]any{"invalid": invalid},
+		}
+	}
+	if len(out) < 2 {
+		return nil, &apperr.AppError{
+			Code:    apperr.CodeValidation,
+			Message: "at least two distinct nameservers are required",
+			Details: map[string]any{"nameservers": out},
+		}
+	}
+	return out, nil
+}
+
+// validHostname reports whether host is a lowercase FQDN of at least two labels, each 1-63
+// letters, digits, or inner hyphens, with a TLD that is not all digits.
+func validHostname(host string) bool {
+	if len(host) > 253 {
+		return false
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, l := range labels {
+		if len(l) == 0 || len(l) > 63 || l[0] == '-' || l[len(l)-1] == '-' {
+			return false
+		}
+		for _, c := range l {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+	tld := labels[len(labels)-1]
+	return strings.Trim(tld, "0123456789") != ""
+}
+
 func (s *Service) SetNameserversSmart(ctx context.Context, domain string, nameservers []string) (string, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return "", err
+	}
+	nameservers, err = NormalizeNameservers(nameservers)
+	if err != nil {
+		return "", err
+	}
 	if v2c, ok := s.v2Client(); ok && canUseV2(s.RT.Cfg.CustomerID) {
 		_, usedV2, err := doV2ThenV1(
 			true,
@@ -496,28 +1031,38 @@ func (s *Service) SetNameserversSmart(ctx context.Context, domain string, namese
 
 func (s *Service) AvailabilityBulk(ctx context.Context, domains []string) ([]godaddy.Availability, error) {
 	var out []godaddy.Availability
-	err := rate.Retry(ctx, 3, func() (bool, error) {
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
 		if err := s.RT.Limiter.Wait(ctx); err != nil {
 			return false, err
 		}
 		r, err := s.Client.AvailableBulk(ctx, domains)
 		out = r
-		if err == nil {
-			return false, nil
-		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
-		}
-		return true, err
+		return s.retryOutcome(err)
 	})
 	return out, err
 }
 
+// FailedItem names one input of a bulk run that failed, listed under "failed_items" in the
+// partial_failure details so a caller can retry just those. Runs driven by seeds (bulk-suggest,
+// discover) name the seed when no domain was reached.
+type FailedItem struct {
+	Domain string      `json:"domain,omitempty"`
+	Seed   string      `json:"seed,omitempty"`
+	Code   apperr.Code `json:"code"`
+}
+
+// NewFailedItem records domain as failed with err's code.
+func NewFailedItem(domain string, err error) FailedItem {
+	return FailedItem{Domain: domain, Code: apperr.CodeOf(err)}
+}
+
+// workerRampStep spaces out the first request of each AvailabilityBulkConcurrent worker, so a
+// large batch ramps up over a few hundred milliseconds instead of spending the limiter's whole
+// burst in one instant, which the provider tends to answer with 429s.
+var workerRampStep = 25 * time.Millisecond
+
 func (s *Service) AvailabilityBulkConcurrent(ctx context.Context, domains []string, concurrency int) ([]BulkAvailabilityItem, error) {
-	if concurrency < 1 {
-		concurrency = 1
-	}
+	concurrency = s.workers(concurrency)
 	type job struct {
 		idx    int
 		domain string
@@ -529,31 +1074,40 @@ func (s *Service) AvailabilityBulkConcurrent(ctx context.Context, domains []stri
 	jobs := make(chan job)
 	results := make(chan result, len(domains))
 	var wg sync.WaitGroup
+	progress := s.RT.NewProgress("availability", len(domains))
+	defer progress.Finish()
 
-	worker := func() {
+	worker := func(n int) {
 		defer wg.Done()
+		// A failed stagger only means ctx ended; the first request reports that.
+		_ = rate.Stagger(ctx, n, workerRampStep)
 		for j := range jobs {
 			start := time.Now()
-			r, err := s.Availability(ctx, j.domain)
+			itemCtx, stats := rate.WithStats(ctx)
+			r, err := s.Availability(itemCtx, j.domain)
 			item := BulkAvailabilityItem{
-				Index:    j.idx,
-				Input:    j.domain,
-				Success:  err == nil,
-				Duration: time.Since(start).Milliseconds(),
+				Index:      j.idx,
+				Input:      j.domain,
+				Success:    err == nil,
+				Duration:   time.Since(start).Milliseconds(),
+				Attempts:   stats.Attempts(),
+				LastStatus: AttemptStatus(stats),
 			}
+			progress.Add(1)
 			if err != nil {
 				item.Error = err.Error()
 				results <- result{item: item, err: err}
 				continue
 			}
 			item.Result = r
+			item.Definitive = r.Definitive
 			results <- result{item: item}
 		}
 	}
 
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go worker()
+		go worker(i)
 	}
 	for i, d := range domains {
 		jobs <- job{idx: i, domain: d}
@@ -563,89 +1117,593 @@ func (s *Service) AvailabilityBulkConcurrent(ctx context.Context, domains []stri
 	close(results)
 
 	out := make([]BulkAvailabilityItem, len(domains))
-	failures := 0
+	errs := make([]error, len(domains))
 	for r := range results {
 		out[r.item.Index] = r.item
-		if r.err != nil {
-			failures++
+		errs[r.item.Index] = r.err
+	}
+	var failed []FailedItem
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, NewFailedItem(domains[i], err))
 		}
 	}
-	if failures > 0 {
+	if len(failed) > 0 {
 		return out, &apperr.AppError{
 			Code:    apperr.CodePartial,
-			Message: fmt.Sprintf("%d availability checks failed", failures),
-			Details: map[string]any{"failed": failures, "total": len(domains)},
+			Message: fmt.Sprintf("%d availability checks failed", len(failed)),
+			Details: map[string]any{"failed": len(failed), "total": len(domains), "failed_items": failed},
 		}
 	}
 	return out, nil
 }
 
-func (s *Service) PurchaseDryRun(ctx context.Context, domain string, years int) (map[string]any, error) {
-	avail, err := s.Availability(ctx, domain)
+// TLDInfo is one supported TLD, with a first-year price when pricing was requested.
+type TLDInfo struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+// tldPriceBatch is how many probe domains go into one bulk availability call.
+const tldPriceBatch = 50
+
+// TLDs lists supported TLDs, optionally narrowed to filter. With withPrices, each TLD is priced
+// by checking availability of a random probe name; prices are normalized like avail output.
+func (s *Service) TLDs(ctx context.Context, filter []string, withPrices bool) ([]TLDInfo, error) {
+	tc, ok := s.Client.(tldClient)
+	if !ok {
+		return nil, &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support listing TLDs"}
+	}
+	var all []godaddy.TLD
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		r, err := tc.ListTLDs(ctx)
+		all = r
+		return s.retryOutcome(err)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if !avail.Available {
-		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "domain is not available", Details: map[string]any{"domain": domain}}
+	want := map[string]bool{}
+	for _, t := range filter {
+		if t = normalizeTLD(t); t != "" {
+			want[t] = true
+		}
 	}
-	if err := budget.CheckPrice(s.RT.Cfg, avail.Price, avail.Currency); err != nil {
-		return nil, err
+	out := make([]TLDInfo, 0, len(all))
+	for _, t := range all {
+		name := normalizeTLD(t.Name)
+		if len(want) > 0 && !want[name] {
+			continue
+		}
+		out = append(out, TLDInfo{Name: name, Type: t.Type})
 	}
-	if err := budget.CheckDailyCaps(s.RT.Cfg, time.Now(), avail.Price); err != nil {
-		return nil, err
+	if len(want) > 0 && len(out) == 0 {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "none of the requested TLDs are supported", Details: map[string]any{"tlds": filter}}
 	}
-	opKey := idempotency.OperationKey("purchase", domain, avail.Price, time.Now())
-	token, err := safety.IssueToken(domain, avail.Price, avail.Currency, opKey, time.Now())
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	if !withPrices {
+		return out, nil
+	}
+
+	label, err := probeLabel()
 	if err != nil {
 		return nil, err
 	}
-	return map[string]any{
-		"domain":                domain,
-		"years":                 years,
-		"price":                 avail.Price,
-		"currency":              avail.Currency,
-		"requires_confirmation": true,
-		"confirmation_token":    token.TokenID,
+	index := make(map[string]int, len(out))
+	probes := make([]string, 0, len(out))
+	for i, t := range out {
+		d := label + "." + t.Name
+		index[d] = i
+		probes = append(probes, d)
+	}
+	for start := 0; start < len(probes); start += tldPriceBatch {
+		end := min(start+tldPriceBatch, len(probes))
+		res, err := s.AvailabilityBulk(ctx, probes[start:end])
+		if err != nil {
+			return out, &apperr.AppError{Code: apperr.CodePartial, Message: "failed pricing some TLDs", Details: map[string]any{"priced_before_failure": start}, Cause: err}
+		}
+		for _, a := range res {
+			if i, ok := index[strings.ToLower(a.Domain)]; ok {
+				out[i].Price = a.Price
+				out[i].Currency = a.Currency
+			}
+		}
+	}
+	return out, nil
+}
+
+// Agreements fetches the legal agreements needed to register (or transfer) the given TLDs.
+func (s *Service) Agreements(ctx context.Context, tlds []string, privacy, forTransfer bool) ([]godaddy.Agreement, error) {
+	ac, ok := s.Client.(agreementsClient)
+	if !ok {
+		return nil, &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support agreements"}
+	}
+	norm := make([]string, 0, len(tlds))
+	for _, t := range tlds {
+		if t = normalizeTLD(t); t != "" {
+			norm = append(norm, t)
+		}
+	}
+	if len(norm) == 0 {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "at least one TLD is required"}
+	}
+	var out []godaddy.Agreement
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		r, err := ac.Agreements(ctx, norm, privacy, forTransfer)
+		out = r
+		return s.retryOutcome(err)
+	})
+	return out, err
+}
+
+func normalizeTLD(t string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t)), ".")
+}
+
+// probeLabel returns a random second-level label unlikely to be registered under any TLD.
+func probeLabel() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "gdcli-price-" + hex.EncodeToString(b), nil
+}
+
+// WatchPoll is one availability check made by Watch.
+type WatchPoll struct {
+	Poll       int     `json:"poll"`
+	Domain     string  `json:"domain"`
+	Available  bool    `json:"available"`
+	Price      float64 `json:"price,omitempty"`
+	Currency   string  `json:"currency,omitempty"`
+	CheckedAt  string  `json:"checked_at"`
+	Error      string  `json:"error,omitempty"`
+	NextPollMs int64   `json:"next_poll_ms,omitempty"`
+}
+
+// maxWatchBackoff bounds how far Watch stretches its interval after rate-limited polls.
+const maxWatchBackoff = 10 * time.Minute
+
+// Watch polls availability every interval until the domain is available or ctx ends. onPoll
+// receives every unavailable poll. Rate-limited polls double the wait up to maxWatchBackoff;
+// the next clean poll restores the interval. Auth and validation errors stop the watch.
+func (s *Service) Watch(ctx context.Context, domain string, interval time.Duration, onPoll func(WatchPoll) error) (WatchPoll, error) {
+	wait := interval
+	var last WatchPoll
+	for poll := 1; ; poll++ {
+		avail, err := s.Availability(ctx, domain)
+		if ctx.Err() != nil {
+			return last, ctx.Err()
+		}
+		last = WatchPoll{Poll: poll, Domain: domain, CheckedAt: time.Now().UTC().Format(time.RFC3339)}
+		if err != nil {
+			var ae *apperr.AppError
+			if apperr.As(err, &ae) && (ae.Code == apperr.CodeAuth || ae.Code == apperr.CodeValidation) {
+				return last, err
+			}
+			last.Error = err.Error()
+			if apperr.As(err, &ae) && ae.Code == apperr.CodeRateLimited {
+				wait = min(wait*2, max(interval, maxWatchBackoff))
+			}
+		} else {
+			wait = interval
+			last.Available = avail.Available
+			last.Price = avail.Price
+			last.Currency = avail.Currency
+			if avail.Available {
+				return last, nil
+			}
+		}
+		last.NextPollMs = wait.Milliseconds()
+		if err := onPoll(last); err != nil {
+			return last, err
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return last, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+type seedSuggestions struct {
+	suggestions []godaddy.Suggestion
+	err         error
+}
+
+// suggestPerSeed runs Suggest for every seed on a pool of concurrency workers and returns the
+// results in seed order.
+func (s *Service) suggestPerSeed(ctx context.Context, seeds, tlds []string, limit, concurrency int) []seedSuggestions {
+	concurrency = s.workers(concurrency)
+	results := make([]seedSuggestions, len(seeds))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				res, err := s.Suggest(ctx, seeds[idx], tlds, limit, SuggestFilter{})
+				if err != nil {
+					results[idx] = seedSuggestions{err: err}
+					continue
+				}
+				list, _ := res["suggestions"].([]godaddy.Suggestion)
+				results[idx] = seedSuggestions{suggestions: list}
+			}
+		}()
+	}
+	for i := range seeds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// SeedSuggestion is one merged bulk-suggest result with the seed that produced it.
+type SeedSuggestion struct {
+	Seed   string  `json:"seed"`
+	Domain string  `json:"domain"`
+	Score  float64 `json:"score"`
+}
+
+// SeedFailure is a seed whose suggest call failed.
+type SeedFailure struct {
+	Seed  string `json:"seed"`
+	Error string `json:"error"`
+}
+
+// BulkSuggest runs Suggest for each seed concurrently, merges the results, and ranks them by
+// score, highest first. A name suggested by several seeds appears once, under the seed that
+// scored it highest (the earliest seed on ties). Failed seeds are listed alongside and make the
+// error a partial failure.
+func (s *Service) BulkSuggest(ctx context.Context, seeds, tlds []string, limit, concurrency int) ([]SeedSuggestion, []SeedFailure, error) {
+	byDomain := map[string]int{}
+	var merged []SeedSuggestion
+	var failures []SeedFailure
+	var failed []FailedItem
+	for i, sr := range s.suggestPerSeed(ctx, seeds, tlds, limit, concurrency) {
+		if sr.err != nil {
+			failures = append(failures, SeedFailure{Seed: seeds[i], Error: sr.err.Error()})
+			failed = append(failed, FailedItem{Seed: seeds[i], Code: apperr.CodeOf(sr.err)})
+			continue
+		}
+		for _, sug := range sr.suggestions {
+			domain := strings.ToLower(strings.TrimSpace(sug.Domain))
+			if domain == "" {
+				continue
+			}
+			if at, ok := byDomain[domain]; ok {
+				if sug.Score > merged[at].Score {
+					merged[at] = SeedSuggestion{Seed: seeds[i], Domain: domain, Score: sug.Score}
+				}
+				continue
+			}
+			byDomain[domain] = len(merged)
+			merged = append(merged, SeedSuggestion{Seed: seeds[i], Domain: domain, Score: sug.Score})
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b SeedSuggestion) int { return cmp.Compare(b.Score, a.Score) })
+	if len(failures) > 0 {
+		return merged, failures, &apperr.AppError{
+			Code:    apperr.CodePartial,
+			Message: fmt.Sprintf("%d seed suggestions failed", len(failures)),
+			Details: map[string]any{"failed": len(failures), "total": len(seeds), "failed_items": failed},
+		}
+	}
+	return merged, nil, nil
+}
+
+type DiscoverCandidate struct {
+	Seed       string  `json:"seed"`
+	Domain     string  `json:"domain"`
+	TLD        string  `json:"tld"`
+	Score      float64 `json:"score"`
+	Available  bool    `json:"available"`
+	Definitive bool    `json:"definitive"`
+	Rechecked  bool    `json:"rechecked,omitempty"`
+	Price      float64 `json:"price,omitempty"`
+	Currency   string  `json:"currency,omitempty"`
+	Buyable    bool    `json:"buyable"`
+	Reason     string  `json:"reason,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type DiscoverOptions struct {
+	TLDs               []string
+	Limit              int
+	MaxPrice           float64
+	SuggestConcurrency int
+	CheckConcurrency   int
+	BatchSize          int
+	// Definitive re-checks every non-definitive FAST result with a FULL single lookup.
+	Definitive bool
+}
+
+// Discover suggests names for each seed, checks them in availability batches, and marks which
+// candidates are buyable under the TLD filter and price ceiling.
+func (s *Service) Discover(ctx context.Context, seeds []string, opts DiscoverOptions) ([]DiscoverCandidate, error) {
+	opts.SuggestConcurrency = s.workers(opts.SuggestConcurrency)
+	opts.CheckConcurrency = s.workers(opts.CheckConcurrency)
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 50
+	}
+	allowedTLDs := map[string]bool{}
+	for _, t := range opts.TLDs {
+		if t = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(t), ".")); t != "" {
+			allowedTLDs[t] = true
+		}
+	}
+
+	// Stage 1: suggestions per seed.
+	seedResults := s.suggestPerSeed(ctx, seeds, opts.TLDs, opts.Limit, opts.SuggestConcurrency)
+	var wg sync.WaitGroup
+
+	// Stage 2: dedupe and apply the TLD filter; the first seed to suggest a name owns it.
+	// codes holds the error code of each failed candidate, by index into out.
+	codes := map[int]apperr.Code{}
+	var out []DiscoverCandidate
+	seen := map[string]bool{}
+	for i, sr := range seedResults {
+		if sr.err != nil {
+			codes[len(out)] = apperr.CodeOf(sr.err)
+			out = append(out, DiscoverCandidate{Seed: seeds[i], Error: sr.err.Error()})
+			continue
+		}
+		for _, sug := range sr.suggestions {
+			domain := strings.ToLower(strings.TrimSpace(sug.Domain))
+			if domain == "" || seen[domain] {
+				continue
+			}
+			tld := domain[strings.LastIndex(domain, ".")+1:]
+			if len(allowedTLDs) > 0 && !allowedTLDs[tld] {
+				continue
+			}
+			seen[domain] = true
+			out = append(out, DiscoverCandidate{Seed: seeds[i], Domain: domain, TLD: tld, Score: sug.Score})
+		}
+	}
+
+	// Stage 3: batch availability checks.
+	var pending []int
+	for i, c := range out {
+		if c.Error == "" {
+			pending = append(pending, i)
+		}
+	}
+	batches := make(chan []int)
+	var mu sync.Mutex
+	for i := 0; i < opts.CheckConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				domains := make([]string, 0, len(batch))
+				for _, idx := range batch {
+					domains = append(domains, out[idx].Domain)
+				}
+				avail, err := s.AvailabilityBulk(ctx, domains)
+				byDomain := map[string]godaddy.Availability{}
+				for _, a := range avail {
+					byDomain[strings.ToLower(a.Domain)] = a
+				}
+				mu.Lock()
+				for _, idx := range batch {
+					c := &out[idx]
+					a, ok := byDomain[c.Domain]
+					switch {
+					case err != nil:
+						c.Error = err.Error()
+						codes[idx] = apperr.CodeOf(err)
+					case !ok:
+						c.Error = "missing from availability response"
+						codes[idx] = apperr.CodeProvider
+					default:
+						c.Available = a.Available
+						c.Definitive = a.Definitive
+						c.Price = a.Price
+						c.Currency = a.Currency
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for start := 0; start < len(pending); start += opts.BatchSize {
+		end := min(start+opts.BatchSize, len(pending))
+		batches <- pending[start:end]
+	}
+	close(batches)
+	wg.Wait()
+
+	// Stage 3b: FAST answers can be unsure; settle them with FULL lookups when asked.
+	if opts.Definitive {
+		var unsure []int
+		for i, c := range out {
+			if c.Error == "" && !c.Definitive {
+				unsure = append(unsure, i)
+			}
+		}
+		domains := make([]string, len(unsure))
+		for i, idx := range unsure {
+			domains[i] = out[idx].Domain
+		}
+		avail, errs := s.recheckAvailability(ctx, domains, opts.CheckConcurrency)
+		for i, idx := range unsure {
+			c := &out[idx]
+			c.Rechecked = true
+			if errs[i] != nil {
+				c.Error = errs[i].Error()
+				codes[idx] = apperr.CodeOf(errs[i])
+				continue
+			}
+			c.Available = avail[i].Available
+			c.Definitive = avail[i].Definitive
+			c.Price = avail[i].Price
+			c.Currency = avail[i].Currency
+		}
+	}
+
+	// Stage 4: decide what is buyable.
+	for i := range out {
+		c := &out[i]
+		switch {
+		case c.Error != "":
+			c.Reason = "error"
+		case !c.Available:
+			c.Reason = "unavailable"
+		case opts.MaxPrice > 0 && c.Price > opts.MaxPrice:
+			c.Reason = "over_max_price"
+		default:
+			c.Buyable = true
+		}
+	}
+	var failed []FailedItem
+	for i, c := range out {
+		if code, ok := codes[i]; ok {
+			item := FailedItem{Domain: c.Domain, Code: code}
+			if c.Domain == "" {
+				item.Seed = c.Seed
+			}
+			failed = append(failed, item)
+		}
+	}
+	if len(failed) > 0 {
+		return out, &apperr.AppError{
+			Code:    apperr.CodePartial,
+			Message: fmt.Sprintf("%d discovery lookups failed", len(failed)),
+			Details: map[string]any{"failed": len(failed), "seeds": len(seeds), "candidates": len(out), "failed_items": failed},
+		}
+	}
+	return out, nil
+}
+
+// recheckAvailability runs a single FULL lookup for each domain, at most concurrency at a time.
+// Results and errors line up with domains.
+func (s *Service) recheckAvailability(ctx context.Context, domains []string, concurrency int) ([]godaddy.Availability, []error) {
+	out := make([]godaddy.Availability, len(domains))
+	errs := make([]error, len(domains))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	concurrency = s.workers(concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				out[idx], errs[idx] = s.Availability(ctx, domains[idx])
+			}
+		}()
+	}
+	for i := range domains {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return out, errs
+}
+
+// PurchaseDryRun quotes domain and issues a confirmation token. Quotes below floor (normally
+// min_plausible_price) are refused.
+func (s *Service) PurchaseDryRun(ctx context.Context, domain string, years int, floor float64) (map[string]any, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	avail, err := s.Availability(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if !avail.Available {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "domain is not available", Details: map[string]any{"domain": domain}}
+	}
+	if err := budget.CheckPrice(s.RT.Cfg, domain, avail.Price, avail.Currency); err != nil {
+		return nil, err
+	}
+	if err := budget.CheckPriceFloor(s.RT.Cfg, floor, avail.Price, avail.Currency); err != nil {
+		return nil, err
+	}
+	if err := budget.CheckCaps(s.RT.Cfg, time.Now(), avail.Price, avail.Currency); err != nil {
+		return nil, err
+	}
+	opKey := idempotency.OperationKey("purchase", domain, avail.Price, time.Now())
+	token, err := safety.IssueToken(safety.ActionPurchase, domain, years, avail.Price, avail.Currency, opKey, time.Now(), time.Duration(s.RT.Cfg.ConfirmTokenTTLMinutes)*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{
+		"domain":                domain,
+		"years":                 years,
+		"price":                 avail.Price,
+		"currency":              avail.Currency,
+		"requires_confirmation": true,
+		"confirmation_token":    token.TokenID,
 		"token_expires_at":      token.ExpiresAt.UTC().Format(time.RFC3339),
-	}, nil
+	}
+	if avail.Period > 0 {
+		out["period"] = avail.Period
+	}
+	if avail.RenewalPrice > 0 {
+		out["renewal_price"] = avail.RenewalPrice
+	}
+	return out, nil
 }
 
 func (s *Service) PurchaseConfirm(ctx context.Context, domain, token string, years int) (godaddy.PurchaseResult, error) {
-	tok, err := safety.ValidateToken(token, domain, time.Now())
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return godaddy.PurchaseResult{}, err
+	}
+	tok, err := safety.ValidateToken(safety.ActionPurchase, token, domain, time.Now())
+	if err != nil {
+		return godaddy.PurchaseResult{}, err
+	}
+	years, err = tokenYears(tok, years)
 	if err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
-	if err := budget.CheckPrice(s.RT.Cfg, tok.QuotedPrice, tok.Currency); err != nil {
+	if err := budget.CheckPrice(s.RT.Cfg, domain, tok.QuotedPrice, tok.Currency); err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
+	if s.RT.DryRun {
+		if err := budget.CheckCaps(s.RT.Cfg, time.Now(), tok.QuotedPrice, tok.Currency); err != nil {
+			return godaddy.PurchaseResult{}, err
+		}
+		// ValidateToken does not consume the token, so the same command can be run again
+		// without --dry-run.
+		return godaddy.PurchaseResult{Domain: domain, Price: tok.QuotedPrice, Currency: tok.Currency, Years: years, DryRun: true}, nil
+	}
 	already, err := s.reserveOperation("purchase", domain, tok.QuotedPrice, tok.Currency, tok.OperationKey, time.Now())
 	if err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
 	if already {
 		_ = safety.MarkTokenUsed(token, domain, time.Now())
-		return godaddy.PurchaseResult{Domain: domain, Price: tok.QuotedPrice, Currency: tok.Currency, AlreadyBought: true}, nil
+		return alreadyPurchasedResult(tok.OperationKey, domain, tok.QuotedPrice, tok.Currency), nil
 	}
 
 	var result godaddy.PurchaseResult
-	err = rate.Retry(ctx, 3, func() (bool, error) {
+	err = rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
 		if err := s.RT.Limiter.Wait(ctx); err != nil {
 			return false, err
 		}
-		r, err := s.Client.Purchase(ctx, domain, years, tok.OperationKey)
+		r, err := s.Client.Purchase(context.WithoutCancel(ctx), domain, years, tok.OperationKey)
 		result = r
-		if err == nil {
-			return false, nil
-		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
-		}
-		return true, err
+		return s.retryOutcome(err)
 	})
 	if err != nil {
-		_ = s.finalizeOperation(tok.OperationKey, tok.QuotedPrice, tok.Currency, "failed")
-		return godaddy.PurchaseResult{}, err
+		return godaddy.PurchaseResult{}, s.failSpend(ctx, tok.OperationKey, domain, tok.QuotedPrice, tok.Currency, opResult{}, err)
 	}
 
 	if result.Price == 0 {
@@ -654,18 +1712,59 @@ func (s *Service) PurchaseConfirm(ctx context.Context, domain, token string, yea
 	if result.Currency == "" {
 		result.Currency = tok.Currency
 	}
-	if err := budget.CheckPrice(s.RT.Cfg, result.Price, result.Currency); err != nil {
-		_ = s.finalizeOperation(tok.OperationKey, result.Price, result.Currency, "failed")
+	if err := budget.CheckPrice(s.RT.Cfg, domain, result.Price, result.Currency); err != nil {
+		_ = s.finalizeOperation(tok.OperationKey, result.Price, result.Currency, "failed", opResult{OrderID: result.OrderID})
 		return godaddy.PurchaseResult{}, err
 	}
-	if err := s.finalizeOperation(tok.OperationKey, result.Price, result.Currency, "succeeded"); err != nil {
+	if err := s.finalizeOperation(tok.OperationKey, result.Price, result.Currency, "succeeded", opResult{OrderID: result.OrderID}); err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
 	_ = safety.MarkTokenUsed(token, domain, time.Now())
+	result.OperationKey = tok.OperationKey
 	return result, nil
 }
 
-func (s *Service) PurchaseAuto(ctx context.Context, domain string, years int) (godaddy.PurchaseResult, error) {
+// tokenYears returns the term tok was quoted for. A non-positive years means the caller
+// did not pass --years and takes the token's; any other value must match it, since the
+// quoted price only covers that term.
+func tokenYears(tok store.ConfirmToken, years int) (int, error) {
+	if years <= 0 {
+		return tok.Years, nil
+	}
+	if years != tok.Years {
+		return 0, &apperr.AppError{
+			Code:    apperr.CodeConfirmation,
+			Message: "confirmation token was issued for a different number of years; run the dry run again for a new token",
+			Details: map[string]any{"token_years": tok.Years, "years": years},
+		}
+	}
+	return years, nil
+}
+
+// failSpend settles a purchase or renewal whose provider call returned err. The request itself
+// is sent with context.WithoutCancel, so Ctrl-C or --deadline never abandons a response; but
+// if the run stopped between attempts an earlier one may still have placed the order, so the
+// operation is left pending instead of failed.
+func (s *Service) failSpend(ctx context.Context, opKey, domain string, amount float64, currency string, res opResult, err error) error {
+	if ctx.Err() == nil {
+		_ = s.finalizeOperation(opKey, amount, currency, "failed", res)
+		return err
+	}
+	return &apperr.AppError{
+		Code:    apperr.CodeInternal,
+		Message: "stopped while placing the order; it may have gone through, so the operation is left pending. Check account orders, then settle it with account operations resolve",
+		Details: map[string]any{"domain": domain, "operation_key": opKey, "status": "pending", "hint": "gdcli account operations resolve " + opKey + " --status succeeded|failed [--order-id ID]"},
+		Cause:   err,
+	}
+}
+
+// PurchaseAuto buys domain without a token under the auto-purchase rules. Quotes below floor
+// (normally min_plausible_price) are refused.
+func (s *Service) PurchaseAuto(ctx context.Context, domain string, years int, floor float64) (godaddy.PurchaseResult, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return godaddy.PurchaseResult{}, err
+	}
 	if err := safety.RequireAutoEnabled(s.RT.Cfg.AutoPurchaseEnabled, s.RT.Cfg.AcknowledgmentHash); err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
@@ -673,39 +1772,53 @@ func (s *Service) PurchaseAuto(ctx context.Context, domain string, years int) (g
 	if err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
+	if !avail.Definitive && s.RT.Cfg.AutoRequireDefinitive {
+		// Unattended buys hold a higher bar: re-check once (single lookups use FULL) before refusing.
+		avail, err = s.Availability(ctx, domain)
+		if err != nil {
+			return godaddy.PurchaseResult{}, err
+		}
+		if !avail.Definitive {
+			return godaddy.PurchaseResult{}, &apperr.AppError{
+				Code:    apperr.CodeSafety,
+				Message: "auto-purchase requires a definitive availability result",
+				Details: map[string]any{"domain": domain, "available": avail.Available, "definitive": false, "auto_require_definitive": true},
+			}
+		}
+	}
 	if !avail.Available {
 		return godaddy.PurchaseResult{}, &apperr.AppError{Code: apperr.CodeValidation, Message: "domain is not available", Details: map[string]any{"domain": domain}}
 	}
-	if err := budget.CheckPrice(s.RT.Cfg, avail.Price, avail.Currency); err != nil {
+	if err := budget.CheckPrice(s.RT.Cfg, domain, avail.Price, avail.Currency); err != nil {
+		return godaddy.PurchaseResult{}, err
+	}
+	if err := budget.CheckPriceFloor(s.RT.Cfg, floor, avail.Price, avail.Currency); err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
+	if s.RT.DryRun {
+		return godaddy.PurchaseResult{Domain: domain, Price: avail.Price, Currency: avail.Currency, Years: years, DryRun: true}, nil
+	}
 	opKey := idempotency.OperationKey("purchase", domain, avail.Price, time.Now())
 	already, err := s.reserveOperation("purchase", domain, avail.Price, avail.Currency, opKey, time.Now())
 	if err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
 	if already {
-		return godaddy.PurchaseResult{Domain: domain, Price: avail.Price, Currency: avail.Currency, AlreadyBought: true}, nil
+		return alreadyPurchasedResult(opKey, domain, avail.Price, avail.Currency), nil
 	}
+	definitive := avail.Definitive
+	outcome := opResult{Definitive: &definitive}
 	var result godaddy.PurchaseResult
-	err = rate.Retry(ctx, 3, func() (bool, error) {
+	err = rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
 		if err := s.RT.Limiter.Wait(ctx); err != nil {
 			return false, err
 		}
-		r, err := s.Client.Purchase(ctx, domain, years, opKey)
+		r, err := s.Client.Purchase(context.WithoutCancel(ctx), domain, years, opKey)
 		result = r
-		if err == nil {
-			return false, nil
-		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
-		}
-		return true, err
+		return s.retryOutcome(err)
 	})
 	if err != nil {
-		_ = s.finalizeOperation(opKey, avail.Price, avail.Currency, "failed")
-		return godaddy.PurchaseResult{}, err
+		return godaddy.PurchaseResult{}, s.failSpend(ctx, opKey, domain, avail.Price, avail.Currency, outcome, err)
 	}
 	if result.Price == 0 {
 		result.Price = avail.Price
@@ -713,52 +1826,149 @@ func (s *Service) PurchaseAuto(ctx context.Context, domain string, years int) (g
 	if result.Currency == "" {
 		result.Currency = avail.Currency
 	}
-	if err := budget.CheckPrice(s.RT.Cfg, result.Price, result.Currency); err != nil {
-		_ = s.finalizeOperation(opKey, result.Price, result.Currency, "failed")
+	outcome.OrderID = result.OrderID
+	if err := budget.CheckPrice(s.RT.Cfg, domain, result.Price, result.Currency); err != nil {
+		_ = s.finalizeOperation(opKey, result.Price, result.Currency, "failed", outcome)
 		return godaddy.PurchaseResult{}, err
 	}
-	if err := s.finalizeOperation(opKey, result.Price, result.Currency, "succeeded"); err != nil {
+	if err := s.finalizeOperation(opKey, result.Price, result.Currency, "succeeded", outcome); err != nil {
 		return godaddy.PurchaseResult{}, err
 	}
+	result.OperationKey = opKey
 	return result, nil
 }
 
 func (s *Service) Renew(ctx context.Context, domain string, years int, dryRun bool, autoApprove bool) (map[string]any, error) {
-	if !dryRun && !autoApprove {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	if s.RT.DryRun || !autoApprove {
 		dryRun = true
 	}
-	priceEstimate := 12.99
-	currency := "USD"
-	if err := budget.CheckPrice(s.RT.Cfg, priceEstimate, currency); err != nil {
+	price, currency, source := s.renewalQuote(ctx, domain, years, 12.99, budget.BaseCurrency(s.RT.Cfg))
+	if err := budget.CheckPrice(s.RT.Cfg, domain, price, currency); err != nil {
 		return nil, err
 	}
 	if dryRun {
-		return map[string]any{"domain": domain, "years": years, "dry_run": true, "price": priceEstimate, "currency": currency}, nil
+		return map[string]any{"domain": domain, "years": years, "dry_run": true, "price": price, "currency": currency, "price_source": source}, nil
+	}
+	// The caps and the ledger must see what the renewal will really cost, never the estimate.
+	if source != "provider" {
+		return nil, &apperr.AppError{
+			Code:    apperr.CodeSafety,
+			Message: "the provider did not quote this renewal; refusing to renew at an estimated price",
+			Details: map[string]any{"domain": domain, "years": years, "price_source": source, "hint": "set customer_id (gdcli init) so renewals can be quoted through the v2 API"},
+		}
+	}
+	if err := budget.CheckCaps(s.RT.Cfg, time.Now(), price, currency); err != nil {
+		return nil, err
 	}
-	opKey := idempotency.OperationKey("renew", domain, priceEstimate, time.Now())
-	already, err := s.reserveOperation("renew", domain, priceEstimate, currency, opKey, time.Now())
+	opKey := idempotency.OperationKey("renew", domain, price, time.Now())
+	return s.renew(ctx, domain, years, price, currency, opKey)
+}
+
+// RenewDryRun quotes a renewal and issues a confirmation token bound to the domain and
+// quoted price, mirroring PurchaseDryRun.
+func (s *Service) RenewDryRun(ctx context.Context, domain string, years int) (map[string]any, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
 	if err != nil {
 		return nil, err
 	}
-	if already {
-		return map[string]any{"domain": domain, "already_renewed": true, "price": priceEstimate, "currency": currency}, nil
+	price, currency, source := s.renewalQuote(ctx, domain, years, 12.99, budget.BaseCurrency(s.RT.Cfg))
+	if err := budget.CheckPrice(s.RT.Cfg, domain, price, currency); err != nil {
+		return nil, err
 	}
-	var rr godaddy.RenewResult
-	usedV2 := false
-	err = rate.Retry(ctx, 3, func() (bool, error) {
-		if err := s.RT.Limiter.Wait(ctx); err != nil {
-			return false, err
+	if err := budget.CheckCaps(s.RT.Cfg, time.Now(), price, currency); err != nil {
+		return nil, err
+	}
+	opKey := idempotency.OperationKey("renew", domain, price, time.Now())
+	token, err := safety.IssueToken(safety.ActionRenew, domain, years, price, currency, opKey, time.Now(), time.Duration(s.RT.Cfg.ConfirmTokenTTLMinutes)*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"domain":                domain,
+		"years":                 years,
+		"dry_run":               true,
+		"price":                 price,
+		"currency":              currency,
+		"price_source":          source,
+		"requires_confirmation": true,
+		"confirmation_token":    token.TokenID,
+		"token_expires_at":      token.ExpiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// RenewConfirm renews with a token from RenewDryRun. The provider price is quoted again
+// and the renewal is refused if it no longer matches the price the token was issued for.
+func (s *Service) RenewConfirm(ctx context.Context, domain, token string, years int) (map[string]any, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := safety.ValidateToken(safety.ActionRenew, token, domain, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	years, err = tokenYears(tok, years)
+	if err != nil {
+		return nil, err
+	}
+	price, currency, source := s.renewalQuote(ctx, domain, years, tok.QuotedPrice, tok.Currency)
+	if source == "provider" && (math.Abs(price-tok.QuotedPrice) >= 0.005 || !strings.EqualFold(currency, tok.Currency)) {
+		return nil, &apperr.AppError{
+			Code:    apperr.CodeConfirmation,
+			Message: "renewal price changed since the quote; run the dry run again for a new token",
+			Details: map[string]any{"quoted_price": tok.QuotedPrice, "quoted_currency": tok.Currency, "current_price": price, "current_currency": currency},
 		}
-		useV2 := canUseV2(s.RT.Cfg.CustomerID) || strings.TrimSpace(s.RT.Cfg.ShopperID) != ""
-		var r godaddy.RenewResult
-		if v2c, ok := s.v2Client(); ok && useV2 {
-			out, used, callErr := doV2ThenV1(
-				true,
-				func() (godaddy.RenewResult, error) {
-					var lastErr error
-					for _, customerID := range s.renewV2CustomerCandidates() {
-						req, reqErr := s.buildRenewV2Request(ctx, v2c, customerID, domain, years)
-						if reqErr != nil {
+	}
+	if err := budget.CheckPrice(s.RT.Cfg, domain, tok.QuotedPrice, tok.Currency); err != nil {
+		return nil, err
+	}
+	if s.RT.DryRun {
+		if err := budget.CheckCaps(s.RT.Cfg, time.Now(), tok.QuotedPrice, tok.Currency); err != nil {
+			return nil, err
+		}
+		return map[string]any{"domain": domain, "years": years, "dry_run": true, "price": tok.QuotedPrice, "currency": tok.Currency}, nil
+	}
+	out, err := s.renew(ctx, domain, years, tok.QuotedPrice, tok.Currency, tok.OperationKey)
+	if err != nil {
+		return nil, err
+	}
+	_ = safety.MarkTokenUsed(token, domain, time.Now())
+	return out, nil
+}
+
+// renew reserves price against the caps under opKey and places the renewal order.
+func (s *Service) renew(ctx context.Context, domain string, years int, price float64, currency, opKey string) (map[string]any, error) {
+	already, err := s.reserveOperation("renew", domain, price, currency, opKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if already {
+		return map[string]any{"domain": domain, "already_renewed": true, "price": price, "currency": currency, "operation_key": opKey}, nil
+	}
+	expiresBefore := s.domainExpiresAt(ctx, domain)
+	var rr godaddy.RenewResult
+	usedV2 := false
+	spendCtx := context.WithoutCancel(ctx)
+	err = rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		// Past the limiter the renewal is on its way; see failSpend.
+		ctx := spendCtx
+		useV2 := canUseV2(s.RT.Cfg.CustomerID) || strings.TrimSpace(s.RT.Cfg.ShopperID) != ""
+		var r godaddy.RenewResult
+		if v2c, ok := s.v2Client(); ok && useV2 {
+			out, used, callErr := doV2ThenV1(
+				true,
+				func() (godaddy.RenewResult, error) {
+					var lastErr error
+					for _, customerID := range s.renewV2CustomerCandidates() {
+						req, reqErr := s.buildRenewV2Request(ctx, v2c, customerID, domain, years)
+						if reqErr != nil {
 							lastErr = reqErr
 							continue
 						}
@@ -799,55 +2009,79 @@ func (s *Service) Renew(ctx context.Context, domain string, years int, dryRun bo
 			usedV2 = false
 		}
 		rr = r
-		if err == nil {
-			return false, nil
-		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
-		}
-		return true, err
+		return s.retryOutcome(err)
 	})
 	if err != nil {
-		_ = s.finalizeOperation(opKey, priceEstimate, currency, "failed")
-		return nil, enrichRenewError(err)
+		return nil, enrichRenewError(s.failSpend(ctx, opKey, domain, price, currency, opResult{}, err))
 	}
 	if rr.Price == 0 {
-		rr.Price = priceEstimate
+		rr.Price = price
 	}
 	if rr.Currency == "" {
 		rr.Currency = currency
 	}
-	if err := budget.CheckPrice(s.RT.Cfg, rr.Price, rr.Currency); err != nil {
-		_ = s.finalizeOperation(opKey, rr.Price, rr.Currency, "failed")
+	if err := budget.CheckPrice(s.RT.Cfg, domain, rr.Price, rr.Currency); err != nil {
+		_ = s.finalizeOperation(opKey, rr.Price, rr.Currency, "failed", opResult{OrderID: rr.OrderID})
 		return nil, err
 	}
-	if err := s.finalizeOperation(opKey, rr.Price, rr.Currency, "succeeded"); err != nil {
+	if err := s.finalizeOperation(opKey, rr.Price, rr.Currency, "succeeded", opResult{OrderID: rr.OrderID}); err != nil {
 		return nil, err
 	}
 	apiVersion := "v1"
 	if usedV2 {
 		apiVersion = "v2"
 	}
-	return map[string]any{"domain": domain, "years": years, "dry_run": false, "price": rr.Price, "currency": rr.Currency, "order_id": rr.OrderID, "api_version": apiVersion}, nil
+	out := map[string]any{"domain": domain, "years": years, "dry_run": false, "price": rr.Price, "currency": rr.Currency, "order_id": rr.OrderID, "operation_key": opKey, "api_version": apiVersion}
+	expiresAfter := s.domainExpiresAt(ctx, domain)
+	out["expires_before"] = expiresBefore
+	out["expires_after"] = expiresAfter
+	advanced, comparable := expirationAdvanced(expiresBefore, expiresAfter)
+	out["expiration_verified"] = comparable && advanced
+	switch {
+	case !comparable:
+		out["warning"] = "could not confirm the new expiration date; check domains detail"
+	case !advanced:
+		out["warning"] = "renewal order succeeded but the expiration date did not advance; the renewal may not have fully applied"
+		output.LogErr(s.RT.ErrOut, "warning: %s renewal order %s succeeded but expiration stayed at %s", domain, rr.OrderID, expiresAfter)
+	}
+	return out, nil
+}
+
+// domainExpiresAt returns the domain's current expiration as reported by domain detail, or "" if unknown.
+func (s *Service) domainExpiresAt(ctx context.Context, domain string) string {
+	if _, ok := s.v2Client(); !ok {
+		return ""
+	}
+	detail, err := s.DomainDetailFresh(ctx, domain, nil)
+	if err != nil {
+		return ""
+	}
+	for _, key := range []string{"expiresAt", "expires"} {
+		if v, ok := detail[key].(string); ok && strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func expirationAdvanced(before, after string) (advanced bool, comparable bool) {
+	b, errB := time.Parse(time.RFC3339, strings.TrimSpace(before))
+	a, errA := time.Parse(time.RFC3339, strings.TrimSpace(after))
+	if errB != nil || errA != nil {
+		return false, false
+	}
+	return a.After(b), true
 }
 
 func (s *Service) ListPortfolio(ctx context.Context, expiringIn int, tld, contains string) ([]godaddy.PortfolioDomain, error) {
 	var all []godaddy.PortfolioDomain
-	err := rate.Retry(ctx, 3, func() (bool, error) {
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
 		if err := s.RT.Limiter.Wait(ctx); err != nil {
 			return false, err
 		}
 		r, err := s.Client.ListDomains(ctx)
 		all = r
-		if err == nil {
-			return false, nil
-		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
-		}
-		return true, err
+		return s.retryOutcome(err)
 	})
 	if err != nil {
 		return nil, err
@@ -879,12 +2113,7 @@ func (s *Service) PortfolioWithNameservers(ctx context.Context, expiringIn int,
 	if err != nil {
 		return nil, err
 	}
-	if concurrency < 1 {
-		concurrency = 1
-	}
-	if concurrency > 20 {
-		concurrency = 20
-	}
+	concurrency = s.workers(concurrency)
 
 	type job struct {
 		index int
@@ -898,6 +2127,8 @@ func (s *Service) PortfolioWithNameservers(ctx context.Context, expiringIn int,
 	jobs := make(chan job)
 	results := make(chan result, len(domains))
 	var wg sync.WaitGroup
+	progress := s.RT.NewProgress("portfolio", len(domains))
+	defer progress.Finish()
 
 	worker := func() {
 		defer wg.Done()
@@ -908,287 +2139,1352 @@ func (s *Service) PortfolioWithNameservers(ctx context.Context, expiringIn int,
 				Expires: j.item.Expires,
 				Success: true,
 			}
-			detail, err := s.DomainDetail(ctx, j.item.Domain, nil)
+			detail, err := s.DomainDetail(ctx, j.item.Domain, nil)
+			progress.Add(1)
+			if err != nil {
+				out.Success = false
+				out.Error = err.Error()
+				results <- result{item: out, err: err}
+				continue
+			}
+			if ns, ok := detail["nameServers"].([]any); ok {
+				for _, n := range ns {
+					if s, ok := n.(string); ok && strings.TrimSpace(s) != "" {
+						out.NameServers = append(out.NameServers, s)
+					}
+				}
+			}
+			if v, ok := detail["_api_version"].(string); ok {
+				out.APIVersion = v
+			}
+			results <- result{item: out}
+		}
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go worker()
+	}
+	for i, d := range domains {
+		jobs <- job{index: i, item: d}
+	}
+	close(jobs)
+	wg.Wait()
+	close(results)
+
+	out := make([]PortfolioDetailItem, len(domains))
+	errs := make([]error, len(domains))
+	for r := range results {
+		out[r.item.Index] = r.item
+		errs[r.item.Index] = r.err
+	}
+	var failed []FailedItem
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, NewFailedItem(domains[i].Domain, err))
+		}
+	}
+	if len(failed) > 0 {
+		return out, &apperr.AppError{
+			Code:    apperr.CodePartial,
+			Message: fmt.Sprintf("%d domain detail lookups failed", len(failed)),
+			Details: map[string]any{"failed": len(failed), "total": len(domains), "failed_items": failed},
+		}
+	}
+	return out, nil
+}
+
+// OrderSortFields are the accepted --sort values for orders; both sort newest/largest first.
+var OrderSortFields = []string{"created_at", "total"}
+
+// OrderFilter narrows fetched orders client-side. Zero values disable each part.
+type OrderFilter struct {
+	Since    time.Time
+	MinTotal float64
+	SortBy   string
+}
+
+// ParseSince parses a --since value: a date (2006-01-02, local time) or an RFC 3339
+// timestamp. An empty value yields the zero time.
+func ParseSince(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, v)
+	}
+	if err != nil {
+		return time.Time{}, &apperr.AppError{Code: apperr.CodeValidation, Message: "--since must be a date (YYYY-MM-DD) or RFC 3339 timestamp", Details: map[string]any{"since": v}, Cause: err}
+	}
+	return t, nil
+}
+
+// ParseOrderFilter validates the --since, --min-total and --sort flag values.
+func ParseOrderFilter(since, minTotal, sortBy string) (OrderFilter, error) {
+	var f OrderFilter
+	t, err := ParseSince(since)
+	if err != nil {
+		return f, err
+	}
+	f.Since = t
+	if v := strings.TrimSpace(minTotal); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 {
+			return f, &apperr.AppError{Code: apperr.CodeValidation, Message: "--min-total must be a non-negative number", Details: map[string]any{"min_total": v}}
+		}
+		f.MinTotal = n
+	}
+	if v := strings.TrimSpace(sortBy); v != "" {
+		if !slices.Contains(OrderSortFields, v) {
+			return f, &apperr.AppError{Code: apperr.CodeValidation, Message: "--sort must be one of " + strings.Join(OrderSortFields, ", "), Details: map[string]any{"sort": v}}
+		}
+		f.SortBy = v
+	}
+	return f, nil
+}
+
+// FilterOrders returns the orders matching f, sorted when f.SortBy is set. Orders whose
+// created_at cannot be parsed never match --since.
+func FilterOrders(orders []godaddy.Order, f OrderFilter) []godaddy.Order {
+	out := make([]godaddy.Order, 0, len(orders))
+	for _, o := range orders {
+		if !f.Since.IsZero() {
+			created, err := time.Parse(time.RFC3339, o.CreatedAt)
+			if err != nil || created.Before(f.Since) {
+				continue
+			}
+		}
+		if o.Pricing.Total < f.MinTotal {
+			continue
+		}
+		out = append(out, o)
+	}
+	switch f.SortBy {
+	case "created_at":
+		sort.SliceStable(out, func(i, j int) bool {
+			ti, _ := time.Parse(time.RFC3339, out[i].CreatedAt)
+			tj, _ := time.Parse(time.RFC3339, out[j].CreatedAt)
+			return ti.After(tj)
+		})
+	case "total":
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Pricing.Total > out[j].Pricing.Total })
+	}
+	return out
+}
+
+// OperationFilter selects entries of the local operations log. Empty fields match everything.
+type OperationFilter struct {
+	Status string
+	Type   string
+	Domain string
+	Since  time.Time
+}
+
+// FilterOperations returns the operations matching f, newest first.
+func FilterOperations(ops []store.Operation, f OperationFilter) []store.Operation {
+	out := make([]store.Operation, 0, len(ops))
+	for _, op := range ops {
+		if f.Status != "" && !strings.EqualFold(op.Status, f.Status) {
+			continue
+		}
+		if f.Type != "" && !strings.EqualFold(op.Type, f.Type) {
+			continue
+		}
+		if f.Domain != "" && !strings.EqualFold(op.Domain, f.Domain) {
+			continue
+		}
+		if !f.Since.IsZero() && op.CreatedAt.Before(f.Since) {
+			continue
+		}
+		out = append(out, op)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+func (s *Service) OrdersList(ctx context.Context, limit, offset int, filter OrderFilter) (map[string]any, error) {
+	out, err := s.ordersPage(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	orders := FilterOrders(out.Orders, filter)
+	// pagination describes the provider page, so offset+limit still finds the next one;
+	// matched counts what the filter kept of it.
+	return map[string]any{
+		"orders":     orders,
+		"matched":    len(orders),
+		"pagination": out.Pagination,
+	}, nil
+}
+
+// OrdersListAll walks order pages from offset, applying filter. With onPage set, each page is
+// handed over as it arrives and not accumulated, so a sort only orders rows within a page;
+// otherwise all matching orders are returned as one combined, fully sorted page.
+func (s *Service) OrdersListAll(ctx context.Context, limit, offset, maxPages int, filter OrderFilter, onPage func([]godaddy.Order, godaddy.Pagination) error) (map[string]any, error) {
+	var all []godaddy.Order
+	walk, err := walkPages(limit, offset, maxPages, func(limit, offset int) (int, godaddy.Pagination, error) {
+		page, err := s.ordersPage(ctx, limit, offset)
+		if err != nil {
+			return 0, godaddy.Pagination{}, err
+		}
+		if onPage != nil {
+			return len(page.Orders), page.Pagination, onPage(FilterOrders(page.Orders, filter), page.Pagination)
+		}
+		all = append(all, page.Orders...)
+		return len(page.Orders), page.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := walk.summary(limit, offset)
+	if onPage == nil {
+		orders := FilterOrders(all, filter)
+		out["orders"] = orders
+		out["matched"] = len(orders)
+	}
+	return out, nil
+}
+
+func (s *Service) ordersPage(ctx context.Context, limit, offset int) (godaddy.OrdersPage, error) {
+	var out godaddy.OrdersPage
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		r, err := s.Client.ListOrders(ctx, limit, offset)
+		out = r
+		return s.retryOutcome(err)
+	})
+	return out, err
+}
+
+func (s *Service) SubscriptionsList(ctx context.Context, limit, offset int) (map[string]any, error) {
+	out, err := s.subscriptionsPage(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"subscriptions": out.Subscriptions,
+		"pagination":    out.Pagination,
+	}, nil
+}
+
+// Subscription fetches a single subscription by ID.
+func (s *Service) Subscription(ctx context.Context, subscriptionID string) (godaddy.Subscription, error) {
+	sc, ok := s.Client.(subscriptionClient)
+	if !ok {
+		return godaddy.Subscription{}, &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support subscription lookup"}
+	}
+	var out godaddy.Subscription
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		r, err := sc.GetSubscription(ctx, subscriptionID)
+		out = r
+		return s.retryOutcome(err)
+	})
+	return out, err
+}
+
+// SetSubscriptionAutoRenew toggles auto-renew on a subscription and re-reads it to report the
+// resulting state, like SetDomainLock.
+func (s *Service) SetSubscriptionAutoRenew(ctx context.Context, subscriptionID string, enabled bool) (map[string]any, error) {
+	sc, ok := s.Client.(subscriptionClient)
+	if !ok {
+		return nil, &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support subscription updates"}
+	}
+	if err := s.RT.Limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := sc.SetSubscriptionAutoRenew(ctx, subscriptionID, enabled); err != nil {
+		return nil, err
+	}
+	out := map[string]any{
+		"subscription_id": subscriptionID,
+		"requested":       enabled,
+		"renew_auto":      enabled,
+		"applied":         true,
+		"verified":        false,
+	}
+	if sub, err := s.Subscription(ctx, subscriptionID); err == nil {
+		out["renew_auto"] = sub.RenewAuto
+		out["verified"] = sub.RenewAuto == enabled
+	}
+	return out, nil
+}
+
+// SubscriptionsListAll is OrdersListAll for subscriptions.
+func (s *Service) SubscriptionsListAll(ctx context.Context, limit, offset, maxPages int, onPage func([]godaddy.Subscription, godaddy.Pagination) error) (map[string]any, error) {
+	var all []godaddy.Subscription
+	walk, err := walkPages(limit, offset, maxPages, func(limit, offset int) (int, godaddy.Pagination, error) {
+		page, err := s.subscriptionsPage(ctx, limit, offset)
+		if err != nil {
+			return 0, godaddy.Pagination{}, err
+		}
+		if onPage != nil {
+			return len(page.Subscriptions), page.Pagination, onPage(page.Subscriptions, page.Pagination)
+		}
+		all = append(all, page.Subscriptions...)
+		return len(page.Subscriptions), page.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := walk.summary(limit, offset)
+	if onPage == nil {
+		if all == nil {
+			all = []godaddy.Subscription{}
+		}
+		out["subscriptions"] = all
+	}
+	return out, nil
+}
+
+// pageWalk records how far walkPages got.
+type pageWalk struct {
+	pages     int
+	fetched   int
+	total     int
+	truncated bool
+}
+
+func (w pageWalk) summary(limit, offset int) map[string]any {
+	return map[string]any{
+		"pagination": map[string]any{"limit": limit, "offset": offset, "total": w.total},
+		"pages":      w.pages,
+		"fetched":    w.fetched,
+		"truncated":  w.truncated,
+	}
+}
+
+// walkPages calls fetch page by page from offset. It stops on an empty page, once offset
+// reaches the reported total (or, without a total, when there is no next link), or after
+// maxPages pages (reported as truncated).
+func walkPages(limit, offset, maxPages int, fetch func(limit, offset int) (int, godaddy.Pagination, error)) (pageWalk, error) {
+	var w pageWalk
+	for {
+		if maxPages > 0 && w.pages >= maxPages {
+			w.truncated = true
+			return w, nil
+		}
+		n, pg, err := fetch(limit, offset)
+		if err != nil {
+			return w, err
+		}
+		w.pages++
+		w.fetched += n
+		w.total = pg.Total
+		offset += n
+		if n == 0 || (pg.Total > 0 && offset >= pg.Total) || (pg.Total == 0 && strings.TrimSpace(pg.Next) == "") {
+			return w, nil
+		}
+	}
+}
+
+func (s *Service) subscriptionsPage(ctx context.Context, limit, offset int) (godaddy.SubscriptionsPage, error) {
+	var out godaddy.SubscriptionsPage
+	err := rate.RetryWith(ctx, s.RT.RetryPolicy(), func() (bool, error) {
+		if err := s.RT.Limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		r, err := s.Client.ListSubscriptions(ctx, limit, offset)
+		out = r
+		return s.retryOutcome(err)
+	})
+	return out, err
+}
+
+func (s *Service) requireV2() (v2RouterClient, string, error) {
+	v2c, ok := s.v2Client()
+	if !ok {
+		return nil, "", &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support v2 operations"}
+	}
+	if !canUseV2(s.RT.Cfg.CustomerID) {
+		return nil, "", &apperr.AppError{Code: apperr.CodeValidation, Message: "customer_id is not configured; run account identity set/resolve first"}
+	}
+	return v2c, s.RT.Cfg.CustomerID, nil
+}
+
+func (s *Service) V2Get(ctx context.Context, path string, q url.Values) (map[string]any, error) {
+	v2c, _, err := s.requireV2()
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := v2c.V2Get(ctx, path, q, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// V2Apply sends a v2 write. Under the global --dry-run it sends nothing and returns the
+// method, path, and body instead, so no command depends on its own --apply gate alone.
+func (s *Service) V2Apply(ctx context.Context, method, path string, body any, idempotencyKey string) (map[string]any, error) {
+	method = strings.ToUpper(method)
+	if method != "POST" && method != "PUT" && method != "PATCH" {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "unsupported method", Details: map[string]any{"method": method}}
+	}
+	if s.RT.DryRun {
+		return map[string]any{"dry_run": true, "method": method, "path": path, "body": body}, nil
+	}
+	v2c, _, err := s.requireV2()
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	switch method {
+	case "POST":
+		err = v2c.V2Post(ctx, path, body, &out, idempotencyKey)
+	case "PUT":
+		err = v2c.V2Put(ctx, path, body, &out)
+	case "PATCH":
+		err = v2c.V2Patch(ctx, path, body, &out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Plan is the reviewable form of a v2 write: the exact method, path template, and body that
+// --apply would send. Paths keep the {customerId} placeholder so plans stay portable.
+type Plan struct {
+	Command string `json:"command"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Body    any    `json:"body"`
+}
+
+func NewPlan(command, method, pathTemplate string, body any) Plan {
+	if body == nil {
+		body = map[string]any{}
+	}
+	return Plan{Command: command, Method: strings.ToUpper(method), Path: pathTemplate, Body: body}
+}
+
+// LoadPlanFile reads a saved plan. It accepts a bare plan, a dry-run result containing "plan",
+// or the full JSON envelope a dry-run printed.
+func LoadPlanFile(path string) (Plan, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Plan{}, err
+	}
+	// #nosec G304 -- plan path is intentionally user-provided local file input.
+	b, err := os.ReadFile(filepath.Clean(abs))
+	if err != nil {
+		return Plan{}, err
+	}
+	var doc struct {
+		Plan
+		Nested *Plan `json:"plan"`
+		Result *struct {
+			Plan *Plan `json:"plan"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return Plan{}, err
+	}
+	p := doc.Plan
+	switch {
+	case doc.Result != nil && doc.Result.Plan != nil:
+		p = *doc.Result.Plan
+	case doc.Nested != nil:
+		p = *doc.Nested
+	}
+	if err := validatePlan(p); err != nil {
+		return Plan{}, err
+	}
+	return p, nil
+}
+
+func validatePlan(p Plan) error {
+	method := strings.ToUpper(strings.TrimSpace(p.Method))
+	if method != "POST" && method != "PUT" && method != "PATCH" {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "plan method must be POST, PUT, or PATCH", Details: map[string]any{"method": p.Method}}
+	}
+	if !strings.HasPrefix(p.Path, "/v2/") || strings.Contains(p.Path, "..") {
+		return &apperr.AppError{Code: apperr.CodeValidation, Message: "plan path must be a /v2/ API path", Details: map[string]any{"path": p.Path}}
+	}
+	return nil
+}
+
+// Spends reports whether applying p can charge the account: a POST that registers, renews,
+// redeems, or drives a transfer. It looks only at Method and Path, since Command is free text
+// that anyone can edit in a saved plan.
+func (p Plan) Spends() bool {
+	if !strings.EqualFold(strings.TrimSpace(p.Method), "POST") {
+		return false
+	}
+	path := strings.TrimSuffix(strings.TrimSpace(p.Path), "/")
+	last := path[strings.LastIndex(path, "/")+1:]
+	switch last {
+	case "register", "renew", "redeem":
+		return true
+	}
+	return strings.HasPrefix(last, "transfer")
+}
+
+// ApplyPlan executes a previously reviewed plan.
+func (s *Service) ApplyPlan(ctx context.Context, p Plan) (map[string]any, error) {
+	if err := validatePlan(p); err != nil {
+		return nil, err
+	}
+	path, err := s.V2PathCustomer(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	return s.V2Apply(ctx, p.Method, path, p.Body, "")
+}
+
+// transferGates lists the transfer statuses in which a transfer sub-action can succeed.
+var transferGates = map[string]struct {
+	verb    string
+	allowed []string
+}{
+	"in-retry":   {verb: "retry", allowed: []string{"FAILED"}},
+	"in-restart": {verb: "restart", allowed: []string{"FAILED", "CANCELLED"}},
+}
+
+// CheckTransferAction fetches the current transfer status and refuses sub-actions that
+// cannot succeed from it. Actions without a gate always pass.
+func (s *Service) CheckTransferAction(ctx context.Context, domain, action string) error {
+	gate, ok := transferGates[action]
+	if !ok {
+		return nil
+	}
+	path, err := s.V2PathCustomer("/v2/customers/{customerId}/domains/" + url.PathEscape(domain) + "/transfer")
+	if err != nil {
+		return err
+	}
+	res, err := s.V2Get(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	status, _ := res["status"].(string)
+	status = strings.ToUpper(strings.TrimSpace(status))
+	for _, allowed := range gate.allowed {
+		if status == allowed {
+			return nil
+		}
+	}
+	shown := status
+	if shown == "" {
+		shown = "unknown"
+	}
+	return &apperr.AppError{
+		Code:    apperr.CodeValidation,
+		Message: fmt.Sprintf("cannot %s: transfer status is %s, not %s", gate.verb, shown, strings.Join(gate.allowed, " or ")),
+		Details: map[string]any{"domain": domain, "action": action, "status": status, "allowed_statuses": gate.allowed, "override": "--force"},
+	}
+}
+
+// TransferTerminalStatuses are the transfer statuses after which nothing more will happen.
+var TransferTerminalStatuses = []string{"COMPLETED", "FAILED", "CANCELLED"}
+
+// TransferPoll is one transfer status check made by WatchTransfer.
+type TransferPoll struct {
+	Poll       int    `json:"poll"`
+	Domain     string `json:"domain"`
+	Status     string `json:"status,omitempty"`
+	Previous   string `json:"previous_status,omitempty"`
+	CheckedAt  string `json:"checked_at"`
+	Error      string `json:"error,omitempty"`
+	NextPollMs int64  `json:"next_poll_ms,omitempty"`
+}
+
+// WatchTransfer polls the transfer status every interval until it reaches one of
+// TransferTerminalStatuses or ctx ends. onChange receives the first poll, every poll whose
+// status differs from the last one seen, and failed polls; the terminal poll is returned
+// instead. Rate-limited polls back off like Watch; auth and validation errors stop the watch.
+func (s *Service) WatchTransfer(ctx context.Context, domain string, interval time.Duration, onChange func(TransferPoll) error) (TransferPoll, error) {
+	path, err := s.V2PathCustomer("/v2/customers/{customerId}/domains/" + url.PathEscape(domain) + "/transfer")
+	if err != nil {
+		return TransferPoll{}, err
+	}
+	wait := interval
+	seen := ""
+	var last TransferPoll
+	for poll := 1; ; poll++ {
+		var res map[string]any
+		err := s.RT.Limiter.Wait(ctx)
+		if err == nil {
+			res, err = s.V2Get(ctx, path, nil)
+		}
+		if ctx.Err() != nil {
+			return last, ctx.Err()
+		}
+		last = TransferPoll{Poll: poll, Domain: domain, Previous: seen, CheckedAt: time.Now().UTC().Format(time.RFC3339)}
+		changed := false
+		if err != nil {
+			var ae *apperr.AppError
+			if apperr.As(err, &ae) && (ae.Code == apperr.CodeAuth || ae.Code == apperr.CodeValidation) {
+				return last, err
+			}
+			last.Error = err.Error()
+			changed = true
+			if apperr.As(err, &ae) && ae.Code == apperr.CodeRateLimited {
+				wait = min(wait*2, max(interval, maxWatchBackoff))
+			}
+		} else {
+			wait = interval
+			status, _ := res["status"].(string)
+			last.Status = strings.ToUpper(strings.TrimSpace(status))
+			if slices.Contains(TransferTerminalStatuses, last.Status) {
+				return last, nil
+			}
+			changed = poll == 1 || last.Status != seen
+			seen = last.Status
+		}
+		last.NextPollMs = wait.Milliseconds()
+		if changed {
+			if err := onChange(last); err != nil {
+				return last, err
+			}
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return last, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Service) V2PathCustomer(pathTemplate string) (string, error) {
+	_, customerID, err := s.requireV2()
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(pathTemplate, "{customerId}", url.PathEscape(customerID)), nil
+}
+
+// DNSAuditChecks names the rules DNSAudit can run, in the order their issues are reported.
+var DNSAuditChecks = []string{"afternic", "txt", "a", "spf", "dmarc", "caa", "mx"}
+
+// ParseDNSAuditChecks validates a --checks selection; an empty selection runs every check.
+func ParseDNSAuditChecks(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return DNSAuditChecks, nil
+	}
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		if !slices.Contains(DNSAuditChecks, n) {
+			return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "unknown dns audit check", Details: map[string]any{"check": n, "allowed": DNSAuditChecks}}
+		}
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// DNSAudit reports, per domain, the issues found by the selected checks (see DNSAuditChecks).
+func (s *Service) DNSAudit(ctx context.Context, domains []string, checks []string, concurrency int) ([]map[string]any, error) {
+	if len(checks) == 0 {
+		checks = DNSAuditChecks
+	}
+	return domainRows(ctx, domains, s.workers(concurrency), "dns audits", s.RT.NewProgress("dns audit", len(domains)), func(ctx context.Context, d string) map[string]any {
+		var ns []string
+		if err := s.paced(ctx, func() (err error) {
+			ns, err = s.Client.GetNameservers(ctx, d)
+			return err
+		}); err != nil {
+			return map[string]any{"domain": d, "issues": []string{"nameserver_fetch_failed"}, "error": err}
+		}
+		var recs []godaddy.DNSRecord
+		if err := s.paced(ctx, func() (err error) {
+			recs, err = s.Client.GetRecords(ctx, d)
+			return err
+		}); err != nil {
+			return map[string]any{"domain": d, "issues": []string{"records_fetch_failed"}, "error": err}
+		}
+		afternic := len(ns) >= 2 && strings.EqualFold(ns[0], "ns1.afternic.com") && strings.EqualFold(ns[1], "ns2.afternic.com")
+		var has struct{ txt, a, spf, dmarc, caa, mx bool }
+		for _, r := range recs {
+			apex := strings.TrimSpace(r.Name) == "@"
+			data := strings.ToLower(strings.TrimSpace(r.Data))
+			switch strings.ToUpper(strings.TrimSpace(r.Type)) {
+			case "TXT":
+				has.txt = true
+				if apex && strings.HasPrefix(data, "v=spf1") {
+					has.spf = true
+				}
+				if strings.EqualFold(strings.TrimSpace(r.Name), "_dmarc") && strings.HasPrefix(data, "v=dmarc1") {
+					has.dmarc = true
+				}
+			case "A":
+				has.a = true
+			case "CAA":
+				has.caa = has.caa || apex
+			case "MX":
+				has.mx = has.mx || apex
+			}
+		}
+		issues := make([]string, 0)
+		for _, c := range DNSAuditChecks {
+			if !slices.Contains(checks, c) {
+				continue
+			}
+			switch {
+			case c == "afternic" && !afternic:
+				issues = append(issues, "nameservers_not_afternic")
+			case c == "txt" && !has.txt:
+				issues = append(issues, "missing_txt_verification")
+			case c == "a" && !has.a:
+				issues = append(issues, "missing_a_record")
+			case c == "spf" && !has.spf:
+				issues = append(issues, "missing_spf")
+			case c == "dmarc" && !has.dmarc:
+				issues = append(issues, "missing_dmarc")
+			case c == "caa" && !has.caa:
+				issues = append(issues, "missing_caa")
+			case c == "mx" && !has.mx:
+				issues = append(issues, "missing_mx")
+			}
+		}
+		return map[string]any{"domain": d, "afternic_pointed": afternic, "issues": issues}
+	})
+}
+
+// DNSAuditRules is a user-defined audit read from dns audit --rules. Every listed
+// nameserver, record type, and TXT substring must be present for a domain to pass.
+type DNSAuditRules struct {
+	NameServers []string `json:"nameservers,omitempty"`
+	RecordTypes []string `json:"record_types,omitempty"`
+	TXTContains []string `json:"txt_contains,omitempty"`
+}
+
+// LoadDNSAuditRules reads and validates a rules file.
+func LoadDNSAuditRules(path string) (*DNSAuditRules, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	abs = filepath.Clean(abs)
+	// #nosec G304 -- rules path is intentionally user-provided local file input.
+	b, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "audit rules file not found", Details: map[string]any{"rules": abs}}
+	}
+	var rules DNSAuditRules
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rules); err != nil {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "invalid audit rules JSON", Cause: err}
+	}
+	if len(rules.NameServers) == 0 && len(rules.RecordTypes) == 0 && len(rules.TXTContains) == 0 {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "audit rules must include nameservers, record_types, or txt_contains"}
+	}
+	return &rules, nil
+}
+
+// DNSAuditWithRules evaluates each domain against rules, reporting pass/fail per rule. Failed
+// rule names are also listed under issues so rows line up with the built-in audit.
+func (s *Service) DNSAuditWithRules(ctx context.Context, domains []string, rules *DNSAuditRules, concurrency int) ([]map[string]any, error) {
+	return domainRows(ctx, domains, s.workers(concurrency), "dns audits", s.RT.NewProgress("dns audit", len(domains)), func(ctx context.Context, d string) map[string]any {
+		var ns []string
+		if len(rules.NameServers) > 0 {
+			if err := s.paced(ctx, func() (err error) {
+				ns, err = s.Client.GetNameservers(ctx, d)
+				return err
+			}); err != nil {
+				return map[string]any{"domain": d, "passed": false, "issues": []string{"nameserver_fetch_failed"}, "error": err}
+			}
+		}
+		var recs []godaddy.DNSRecord
+		if len(rules.RecordTypes) > 0 || len(rules.TXTContains) > 0 {
+			if err := s.paced(ctx, func() (err error) {
+				recs, err = s.Client.GetRecords(ctx, d)
+				return err
+			}); err != nil {
+				return map[string]any{"domain": d, "passed": false, "issues": []string{"records_fetch_failed"}, "error": err}
+			}
+		}
+		checks := make([]map[string]any, 0)
+		issues := make([]string, 0)
+		record := func(rule string, pass bool) {
+			checks = append(checks, map[string]any{"rule": rule, "pass": pass})
+			if !pass {
+				issues = append(issues, rule)
+			}
+		}
+		if len(rules.NameServers) > 0 {
+			record("nameservers", nameserversEqual(ns, rules.NameServers))
+		}
+		for _, t := range rules.RecordTypes {
+			found := false
+			for _, r := range recs {
+				if strings.EqualFold(strings.TrimSpace(r.Type), strings.TrimSpace(t)) {
+					found = true
+					break
+				}
+			}
+			record("record_type:"+strings.ToUpper(strings.TrimSpace(t)), found)
+		}
+		for _, sub := range rules.TXTContains {
+			found := false
+			for _, r := range recs {
+				if strings.EqualFold(strings.TrimSpace(r.Type), "TXT") && strings.Contains(r.Data, sub) {
+					found = true
+					break
+				}
+			}
+			record("txt_contains:"+sub, found)
+		}
+		return map[string]any{"domain": d, "passed": len(issues) == 0, "rules": checks, "issues": issues}
+	})
+}
+
+// DNSApplyTemplate writes a template to each domain. vars fills ${VAR:key} placeholders in
+// custom templates; ${DOMAIN} expands to the domain being written.
+func (s *Service) DNSApplyTemplate(ctx context.Context, tmpl string, domains []string, vars map[string]string, dryRun, onlyChanged bool, concurrency int) ([]map[string]any, error) {
+	var custom *DNSTemplate
+	if strings.HasSuffix(strings.ToLower(tmpl), ".json") {
+		c, err := loadCustomTemplate(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		// Expanding once up front reports a missing --var before any domain is touched.
+		if _, err := c.expand("", vars); err != nil {
+			return nil, err
+		}
+		custom = c
+	}
+	if _, ok := templateTarget(tmpl, custom, ""); !ok {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "unsupported template", Details: map[string]any{"template": tmpl}}
+	}
+	return domainRows(ctx, domains, s.workers(concurrency), "dns applies", s.RT.NewProgress("dns apply", len(domains)), func(ctx context.Context, d string) map[string]any {
+		domainTmpl := custom
+		if custom != nil {
+			domainTmpl, _ = custom.expand(d, vars)
+		}
+		target, _ := templateTarget(tmpl, domainTmpl, d)
+		ns, recs := target.NameServers, target.Records
+		if len(ns) > 0 {
+			var err error
+			if ns, err = NormalizeNameservers(ns); err != nil {
+				return map[string]any{"domain": d, "template": tmpl, "applied": false, "error": err}
+			}
+		}
+		if onlyChanged {
+			same, err := s.dnsStateMatches(ctx, d, target)
 			if err != nil {
-				out.Success = false
-				out.Error = err.Error()
-				results <- result{item: out, err: err}
-				continue
+				return map[string]any{"domain": d, "template": tmpl, "applied": false, "error": err}
 			}
-			if ns, ok := detail["nameServers"].([]any); ok {
-				for _, n := range ns {
-					if s, ok := n.(string); ok && strings.TrimSpace(s) != "" {
-						out.NameServers = append(out.NameServers, s)
-					}
+			if same {
+				return map[string]any{"domain": d, "template": tmpl, "applied": false, "unchanged": true}
+			}
+		}
+		if dryRun {
+			row := map[string]any{"domain": d, "template": tmpl, "dry_run": true, "changes": templateChanges(ns, recs), "merge_records": target.Merge}
+			if len(ns) > 0 {
+				row["nameservers"] = ns
+			}
+			if len(recs) > 0 {
+				row["records"] = recs
+			}
+			return row
+		}
+		if len(ns) > 0 {
+			if err := s.paced(ctx, func() error {
+				_, err := s.SetNameserversSmart(ctx, d, ns)
+				return err
+			}); err != nil {
+				return map[string]any{"domain": d, "applied": false, "error": err}
+			}
+		}
+		if len(recs) > 0 {
+			if target.Merge {
+				var current []godaddy.DNSRecord
+				if err := s.paced(ctx, func() (err error) {
+					current, err = s.Client.GetRecords(ctx, d)
+					return err
+				}); err != nil {
+					return map[string]any{"domain": d, "applied": false, "error": err}
 				}
+				recs = mergeRecordSlots(current, recs)
 			}
-			if v, ok := detail["_api_version"].(string); ok {
-				out.APIVersion = v
+			if err := s.paced(ctx, func() error { return s.Client.SetRecords(ctx, d, recs) }); err != nil {
+				return map[string]any{"domain": d, "applied": false, "error": err}
 			}
-			results <- result{item: out}
 		}
-	}
+		return map[string]any{"domain": d, "template": tmpl, "applied": true}
+	})
+}
 
+// domainRows runs fn for each domain on up to concurrency workers and returns the rows in
+// input order, each with duration_ms. Rows carrying an "error" are failures; fn sets it to the
+// error itself, which is rendered as its message and listed with its code in failed_items. Any
+// failure makes the returned error a partial_failure naming what (e.g. "dns audits").
+// progress, which may be nil, counts finished rows.
+func domainRows(ctx context.Context, domains []string, concurrency int, what string, progress *output.Progress, fn func(context.Context, string) map[string]any) ([]map[string]any, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	defer progress.Finish()
+	type job struct {
+		idx    int
+		domain string
+	}
+	out := make([]map[string]any, len(domains))
+	codes := make([]apperr.Code, len(domains))
+	jobs := make(chan job)
+	var wg sync.WaitGroup
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go worker()
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				start := time.Now()
+				var row map[string]any
+				if d, err := godaddy.NormalizeDomain(j.domain); err != nil {
+					row = map[string]any{"domain": j.domain, "error": err}
+				} else {
+					row = fn(ctx, d)
+				}
+				if err, ok := row["error"].(error); ok {
+					row["error"] = err.Error()
+					codes[j.idx] = apperr.CodeOf(err)
+				}
+				row["duration_ms"] = time.Since(start).Milliseconds()
+				out[j.idx] = row
+				progress.Add(1)
+			}
+		}()
 	}
 	for i, d := range domains {
-		jobs <- job{index: i, item: d}
+		jobs <- job{idx: i, domain: d}
 	}
 	close(jobs)
 	wg.Wait()
-	close(results)
 
-	out := make([]PortfolioDetailItem, len(domains))
-	failures := 0
-	for r := range results {
-		out[r.item.Index] = r.item
-		if r.err != nil {
-			failures++
+	var failed []FailedItem
+	for i, row := range out {
+		if row["error"] != nil {
+			code := codes[i]
+			if code == "" {
+				code = apperr.CodeInternal
+			}
+			failed = append(failed, FailedItem{Domain: domains[i], Code: code})
 		}
 	}
-	if failures > 0 {
+	if len(failed) > 0 {
 		return out, &apperr.AppError{
 			Code:    apperr.CodePartial,
-			Message: fmt.Sprintf("%d domain detail lookups failed", failures),
-			Details: map[string]any{"failed": failures, "total": len(domains)},
+			Message: fmt.Sprintf("%d %s failed", len(failed), what),
+			Details: map[string]any{"failed": len(failed), "total": len(domains), "failed_items": failed},
 		}
 	}
 	return out, nil
 }
 
-func (s *Service) OrdersList(ctx context.Context, limit, offset int) (map[string]any, error) {
-	var out godaddy.OrdersPage
-	err := rate.Retry(ctx, 3, func() (bool, error) {
-		if err := s.RT.Limiter.Wait(ctx); err != nil {
+// dnsTarget is the state a template writes to one domain.
+type dnsTarget struct {
+	NameServers []string
+	Records     []godaddy.DNSRecord
+	// Merge replaces only the record slots the template covers (see recordSlot) and keeps
+	// the rest of the zone; otherwise Records replaces the whole zone.
+	Merge bool
+}
+
+// templateTarget resolves a template name (or loaded custom template) into the
+// nameservers and records it would write to domain.
+func templateTarget(tmpl string, custom *DNSTemplate, domain string) (dnsTarget, bool) {
+	switch tmpl {
+	case "afternic", "afternic-nameservers":
+		return dnsTarget{NameServers: []string{"ns1.afternic.com", "ns2.afternic.com"}}, true
+	case "parking":
+		return dnsTarget{Records: []godaddy.DNSRecord{{Type: "A", Name: "@", Data: "52.71.57.184", TTL: 600}}}, true
+	case "google-workspace":
+		return dnsTarget{Merge: true, Records: []godaddy.DNSRecord{
+			{Type: "MX", Name: "@", Data: "aspmx.l.google.com", TTL: 3600, Priority: 1},
+			{Type: "MX", Name: "@", Data: "alt1.aspmx.l.google.com", TTL: 3600, Priority: 5},
+			{Type: "MX", Name: "@", Data: "alt2.aspmx.l.google.com", TTL: 3600, Priority: 5},
+			{Type: "MX", Name: "@", Data: "alt3.aspmx.l.google.com", TTL: 3600, Priority: 10},
+			{Type: "MX", Name: "@", Data: "alt4.aspmx.l.google.com", TTL: 3600, Priority: 10},
+		}}, true
+	case "microsoft365":
+		// Exchange Online routes mail for example.com through example-com.mail.protection.outlook.com.
+		mx := strings.ReplaceAll(strings.ToLower(domain), ".", "-") + ".mail.protection.outlook.com"
+		return dnsTarget{Merge: true, Records: []godaddy.DNSRecord{
+			{Type: "MX", Name: "@", Data: mx, TTL: 3600, Priority: 0},
+			{Type: "TXT", Name: "@", Data: "v=spf1 include:spf.protection.outlook.com -all", TTL: 3600},
+			{Type: "CNAME", Name: "autodiscover", Data: "autodiscover.outlook.com", TTL: 3600},
+		}}, true
+	case "email-hardening":
+		// For domains that send no mail: reject everything claiming to come from them. The
+		// DKIM record with an empty key marks any selector as revoked.
+		return dnsTarget{Merge: true, Records: []godaddy.DNSRecord{
+			{Type: "TXT", Name: "@", Data: "v=spf1 -all", TTL: 3600},
+			{Type: "TXT", Name: "_dmarc", Data: "v=DMARC1; p=reject; sp=reject; adkim=s; aspf=s", TTL: 3600},
+			{Type: "TXT", Name: "*._domainkey", Data: "v=DKIM1; p=", TTL: 3600},
+		}}, true
+	}
+	if custom != nil {
+		return dnsTarget{NameServers: custom.NameServers, Records: custom.Records}, true
+	}
+	return dnsTarget{}, false
+}
+
+// recordSlot keys the records a merge template replaces: type and name, plus the "v=" tag for
+// TXT records so an SPF record replaces the old SPF but leaves verification tokens alone.
+func recordSlot(rec godaddy.DNSRecord) string {
+	key := strings.ToUpper(strings.TrimSpace(rec.Type)) + " " + strings.ToLower(strings.TrimSpace(rec.Name))
+	if strings.EqualFold(strings.TrimSpace(rec.Type), "TXT") {
+		data := strings.ToLower(strings.TrimSpace(rec.Data))
+		if strings.HasPrefix(data, "v=") {
+			if i := strings.IndexAny(data, "; "); i >= 0 {
+				data = data[:i]
+			}
+			key += " " + data
+		}
+	}
+	return key
+}
+
+// mergeRecordSlots drops the current records in slots the template covers and appends the
+// template's records, keeping everything else in the zone.
+func mergeRecordSlots(current, recs []godaddy.DNSRecord) []godaddy.DNSRecord {
+	covered := make(map[string]bool, len(recs))
+	for _, r := range recs {
+		covered[recordSlot(r)] = true
+	}
+	next := make([]godaddy.DNSRecord, 0, len(current)+len(recs))
+	for _, r := range current {
+		if !covered[recordSlot(r)] {
+			next = append(next, r)
+		}
+	}
+	return append(next, recs...)
+}
+
+func templateChanges(ns []string, recs []godaddy.DNSRecord) []string {
+	changes := make([]string, 0, 2)
+	if len(ns) > 0 {
+		changes = append(changes, "set_nameservers")
+	}
+	if len(recs) > 0 {
+		changes = append(changes, "set_records")
+	}
+	return changes
+}
+
+// dnsStateMatches reads a domain's current nameservers/records and reports
+// whether they already equal the target state, so a write would be a no-op.
+func (s *Service) dnsStateMatches(ctx context.Context, domain string, target dnsTarget) (bool, error) {
+	ns, recs := target.NameServers, target.Records
+	if len(ns) > 0 {
+		var current []string
+		if err := s.paced(ctx, func() (err error) {
+			current, err = s.Client.GetNameservers(ctx, domain)
+			return err
+		}); err != nil {
 			return false, err
 		}
-		r, err := s.Client.ListOrders(ctx, limit, offset)
-		out = r
-		if err == nil {
+		if !nameserversEqual(current, ns) {
 			return false, nil
 		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
+	}
+	if len(recs) > 0 {
+		var current []godaddy.DNSRecord
+		if err := s.paced(ctx, func() (err error) {
+			current, err = s.Client.GetRecords(ctx, domain)
+			return err
+		}); err != nil {
+			return false, err
+		}
+		if target.Merge {
+			recs = mergeRecordSlots(current, recs)
+		}
+		if !recordsEqual(current, recs) {
+			return false, nil
 		}
-		return true, err
-	})
-	if err != nil {
-		return nil, err
 	}
-	return map[string]any{
-		"orders":     out.Orders,
-		"pagination": out.Pagination,
-	}, nil
+	return true, nil
 }
 
-func (s *Service) SubscriptionsList(ctx context.Context, limit, offset int) (map[string]any, error) {
-	var out godaddy.SubscriptionsPage
-	err := rate.Retry(ctx, 3, func() (bool, error) {
-		if err := s.RT.Limiter.Wait(ctx); err != nil {
-			return false, err
+func nameserversEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	norm := func(in []string) []string {
+		out := make([]string, 0, len(in))
+		for _, v := range in {
+			out = append(out, strings.TrimSuffix(strings.ToLower(strings.TrimSpace(v)), "."))
 		}
-		r, err := s.Client.ListSubscriptions(ctx, limit, offset)
-		out = r
-		if err == nil {
-			return false, nil
+		sort.Strings(out)
+		return out
+	}
+	na, nb := norm(a), norm(b)
+	for i := range na {
+		if na[i] != nb[i] {
+			return false
 		}
-		var ae *apperr.AppError
-		if apperr.As(err, &ae) {
-			return ae.Retryable || ae.Code == apperr.CodeRateLimited, err
+	}
+	return true
+}
+
+func recordsEqual(current, target []godaddy.DNSRecord) bool {
+	if len(current) != len(target) {
+		return false
+	}
+	used := make([]bool, len(current))
+	for _, want := range target {
+		found := false
+		for i, have := range current {
+			if used[i] || !sameRecord(have, want) {
+				continue
+			}
+			used[i] = true
+			found = true
+			break
+		}
+		if !found {
+			return false
 		}
-		return true, err
-	})
-	if err != nil {
-		return nil, err
 	}
-	return map[string]any{
-		"subscriptions": out.Subscriptions,
-		"pagination":    out.Pagination,
-	}, nil
+	return true
 }
 
-func (s *Service) requireV2() (v2RouterClient, string, error) {
-	v2c, ok := s.v2Client()
-	if !ok {
-		return nil, "", &apperr.AppError{Code: apperr.CodeInternal, Message: "client does not support v2 operations"}
+// sameRecord compares type/name/data (and priority for MX/SRV, weight and port for SRV); a zero
+// TTL on want means "provider default".
+func sameRecord(have, want godaddy.DNSRecord) bool {
+	if !strings.EqualFold(strings.TrimSpace(have.Type), strings.TrimSpace(want.Type)) {
+		return false
 	}
-	if !canUseV2(s.RT.Cfg.CustomerID) {
-		return nil, "", &apperr.AppError{Code: apperr.CodeValidation, Message: "customer_id is not configured; run account identity set/resolve first"}
+	if !strings.EqualFold(strings.TrimSpace(have.Name), strings.TrimSpace(want.Name)) {
+		return false
 	}
-	return v2c, s.RT.Cfg.CustomerID, nil
+	if strings.TrimSpace(have.Data) != strings.TrimSpace(want.Data) {
+		return false
+	}
+	if t := strings.ToUpper(strings.TrimSpace(want.Type)); (t == "MX" || t == "SRV") && have.Priority != want.Priority {
+		return false
+	}
+	if strings.EqualFold(strings.TrimSpace(want.Type), "SRV") && (have.Weight != want.Weight || have.Port != want.Port) {
+		return false
+	}
+	return want.TTL == 0 || have.TTL == want.TTL
 }
 
-func (s *Service) V2Get(ctx context.Context, path string, q url.Values) (map[string]any, error) {
-	v2c, _, err := s.requireV2()
-	if err != nil {
-		return nil, err
+// sameRRSet reports whether two records share type and name.
+func sameRRSet(a, b godaddy.DNSRecord) bool {
+	return strings.EqualFold(strings.TrimSpace(a.Type), strings.TrimSpace(b.Type)) &&
+		strings.EqualFold(strings.TrimSpace(a.Name), strings.TrimSpace(b.Name))
+}
+
+func validateRecord(rec godaddy.DNSRecord, requireData bool) (godaddy.DNSRecord, error) {
+	rec.Type = strings.ToUpper(strings.TrimSpace(rec.Type))
+	rec.Name = strings.TrimSpace(rec.Name)
+	rec.Data = strings.TrimSpace(rec.Data)
+	if rec.Type == "" || rec.Name == "" || (requireData && rec.Data == "") {
+		return rec, &apperr.AppError{Code: apperr.CodeValidation, Message: "record requires --type, --name and --data", Details: map[string]any{"type": rec.Type, "name": rec.Name, "data": rec.Data}}
 	}
-	var out map[string]any
-	if err := v2c.V2Get(ctx, path, q, &out); err != nil {
-		return nil, err
+	if rec.TTL < 0 {
+		return rec, &apperr.AppError{Code: apperr.CodeValidation, Message: "--ttl must be >= 0", Details: map[string]any{"ttl": rec.TTL}}
 	}
-	return out, nil
+	if rec.Priority < 0 || rec.Weight < 0 || rec.Port < 0 || rec.Port > 65535 {
+		return rec, &apperr.AppError{Code: apperr.CodeValidation, Message: "--priority and --weight must be >= 0 and --port 0-65535", Details: map[string]any{"priority": rec.Priority, "weight": rec.Weight, "port": rec.Port}}
+	}
+	return rec, nil
 }
 
-func (s *Service) V2Apply(ctx context.Context, method, path string, body any, idempotencyKey string) (map[string]any, error) {
-	v2c, _, err := s.requireV2()
+// DNSExport snapshots a domain's nameservers and records as a custom template, so the
+// result can be restored later with dns apply --template.
+func (s *Service) DNSExport(ctx context.Context, domain string) (*DNSTemplate, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
 	if err != nil {
 		return nil, err
 	}
-	var out map[string]any
-	switch strings.ToUpper(method) {
-	case "POST":
-		err = v2c.V2Post(ctx, path, body, &out, idempotencyKey)
-	case "PUT":
-		err = v2c.V2Put(ctx, path, body, &out)
-	case "PATCH":
-		err = v2c.V2Patch(ctx, path, body, &out)
-	default:
-		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "unsupported method", Details: map[string]any{"method": method}}
+	ns, err := s.Client.GetNameservers(ctx, domain)
+	if err != nil {
+		return nil, err
 	}
+	recs, err := s.Client.GetRecords(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	if len(ns) == 0 && len(recs) == 0 {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "domain has no nameservers or records to export", Details: map[string]any{"domain": domain}}
+	}
+	return &DNSTemplate{NameServers: ns, Records: recs}, nil
 }
 
-func (s *Service) V2PathCustomer(pathTemplate string) (string, error) {
-	_, customerID, err := s.requireV2()
+func (s *Service) RecordsList(ctx context.Context, domain string) ([]godaddy.DNSRecord, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return strings.ReplaceAll(pathTemplate, "{customerId}", url.PathEscape(customerID)), nil
+	return s.Client.GetRecords(ctx, domain)
 }
 
-func (s *Service) DNSAudit(ctx context.Context, domains []string) ([]map[string]any, error) {
-	results := make([]map[string]any, 0, len(domains))
-	for _, d := range domains {
-		ns, err := s.Client.GetNameservers(ctx, d)
-		if err != nil {
-			results = append(results, map[string]any{"domain": d, "issues": []string{"nameserver_fetch_failed"}, "error": err.Error()})
-			continue
-		}
-		recs, err := s.Client.GetRecords(ctx, d)
-		if err != nil {
-			results = append(results, map[string]any{"domain": d, "issues": []string{"records_fetch_failed"}, "error": err.Error()})
+// RecordsAdd merges one record into the domain's current record set. An identical
+// type/name/data record is left alone unless its TTL (or MX/SRV priority, weight and port)
+// differs. Without apply nothing is written.
+func (s *Service) RecordsAdd(ctx context.Context, domain string, rec godaddy.DNSRecord, apply bool) (map[string]any, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	rec, err = validateRecord(rec, true)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.Client.GetRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	next := append([]godaddy.DNSRecord(nil), current...)
+	changed := true
+	found := false
+	for i, have := range next {
+		if !sameRRSet(have, rec) || strings.TrimSpace(have.Data) != rec.Data {
 			continue
 		}
-		issues := make([]string, 0)
-		afternic := len(ns) >= 2 && strings.EqualFold(ns[0], "ns1.afternic.com") && strings.EqualFold(ns[1], "ns2.afternic.com")
-		if !afternic {
-			issues = append(issues, "nameservers_not_afternic")
-		}
-		hasTXT := false
-		hasA := false
-		for _, r := range recs {
-			if strings.EqualFold(r.Type, "TXT") {
-				hasTXT = true
-			}
-			if strings.EqualFold(r.Type, "A") {
-				hasA = true
-			}
-		}
-		if !hasTXT {
-			issues = append(issues, "missing_txt_verification")
+		found = true
+		want := have
+		if rec.TTL != 0 {
+			want.TTL = rec.TTL
 		}
-		if !hasA {
-			issues = append(issues, "missing_a_record")
+		if rec.Type == "MX" || rec.Type == "SRV" {
+			want.Priority, want.Weight, want.Port = rec.Priority, rec.Weight, rec.Port
 		}
-		results = append(results, map[string]any{"domain": d, "afternic_pointed": afternic, "issues": issues})
+		changed = want != have
+		next[i] = want
+		break
 	}
-	return results, nil
+	if !found {
+		next = append(next, rec)
+	}
+	out := map[string]any{"domain": domain, "record": rec, "changed": changed, "records_before": len(current), "records_after": len(next)}
+	if !changed && apply {
+		return out, nil
+	}
+	return s.writeRecords(ctx, domain, next, apply, out)
 }
 
-func (s *Service) DNSApplyTemplate(ctx context.Context, tmpl string, domains []string, dryRun bool) ([]map[string]any, error) {
-	out := make([]map[string]any, 0, len(domains))
-	ns := []string{"ns1.afternic.com", "ns2.afternic.com"}
-	var custom *dnsTemplateFile
-	if strings.HasSuffix(strings.ToLower(tmpl), ".json") {
-		c, err := loadCustomTemplate(tmpl)
-		if err != nil {
-			return nil, err
-		}
-		custom = c
+// RecordsDelete removes records matching type, name and data from the domain's record set.
+// Without apply nothing is written.
+func (s *Service) RecordsDelete(ctx context.Context, domain string, rec godaddy.DNSRecord, apply bool) (map[string]any, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
 	}
-	for _, d := range domains {
-		if dryRun {
-			out = append(out, map[string]any{"domain": d, "template": tmpl, "dry_run": true, "changes": []string{"set_nameservers"}})
+	rec, err = validateRecord(rec, true)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.Client.GetRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	next := make([]godaddy.DNSRecord, 0, len(current))
+	removed := 0
+	for _, have := range current {
+		if sameRRSet(have, rec) && strings.TrimSpace(have.Data) == rec.Data {
+			removed++
 			continue
 		}
-		switch tmpl {
-		case "afternic", "afternic-nameservers":
-			setNS := func() error {
-				if v2c, ok := s.v2Client(); ok && canUseV2(s.RT.Cfg.CustomerID) {
-					_, _, err := doV2ThenV1(
-						true,
-						func() (struct{}, error) {
-							return struct{}{}, v2c.SetNameserversV2(ctx, s.RT.Cfg.CustomerID, d, ns)
-						},
-						func() (struct{}, error) {
-							return struct{}{}, s.Client.SetNameservers(ctx, d, ns)
-						},
-					)
-					return err
-				}
-				return s.Client.SetNameservers(ctx, d, ns)
-			}
-			if err := setNS(); err != nil {
-				out = append(out, map[string]any{"domain": d, "applied": false, "error": err.Error()})
-				continue
-			}
-		case "parking":
-			recs := []godaddy.DNSRecord{{Type: "A", Name: "@", Data: "52.71.57.184", TTL: 600}}
-			if err := s.Client.SetRecords(ctx, d, recs); err != nil {
-				out = append(out, map[string]any{"domain": d, "applied": false, "error": err.Error()})
-				continue
-			}
-		default:
-			if custom != nil {
-				if len(custom.NameServers) > 0 {
-					setCustomNS := func() error {
-						if v2c, ok := s.v2Client(); ok && canUseV2(s.RT.Cfg.CustomerID) {
-							_, _, err := doV2ThenV1(
-								true,
-								func() (struct{}, error) {
-									return struct{}{}, v2c.SetNameserversV2(ctx, s.RT.Cfg.CustomerID, d, custom.NameServers)
-								},
-								func() (struct{}, error) {
-									return struct{}{}, s.Client.SetNameservers(ctx, d, custom.NameServers)
-								},
-							)
-							return err
-						}
-						return s.Client.SetNameservers(ctx, d, custom.NameServers)
-					}
-					if err := setCustomNS(); err != nil {
-						out = append(out, map[string]any{"domain": d, "applied": false, "error": err.Error()})
-						continue
-					}
-				}
-				if len(custom.Records) > 0 {
-					if err := s.Client.SetRecords(ctx, d, custom.Records); err != nil {
-						out = append(out, map[string]any{"domain": d, "applied": false, "error": err.Error()})
-						continue
-					}
-				}
-			} else {
-				return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "unsupported template", Details: map[string]any{"template": tmpl}}
-			}
+		next = append(next, have)
+	}
+	if removed == 0 {
+		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "no matching record to delete", Details: map[string]any{"domain": domain, "type": rec.Type, "name": rec.Name, "data": rec.Data}}
+	}
+	return s.writeRecords(ctx, domain, next, apply, map[string]any{"domain": domain, "record": rec, "removed": removed, "records_before": len(current), "records_after": len(next)})
+}
+
+// RecordsReplace swaps every record with the given type and name for the single record passed,
+// leaving the rest of the zone untouched. Without apply nothing is written.
+func (s *Service) RecordsReplace(ctx context.Context, domain string, rec godaddy.DNSRecord, apply bool) (map[string]any, error) {
+	domain, err := godaddy.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	rec, err = validateRecord(rec, true)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.Client.GetRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	next := make([]godaddy.DNSRecord, 0, len(current)+1)
+	replaced := 0
+	for _, have := range current {
+		if sameRRSet(have, rec) {
+			replaced++
+			continue
 		}
-		out = append(out, map[string]any{"domain": d, "template": tmpl, "applied": true})
+		next = append(next, have)
+	}
+	next = append(next, rec)
+	return s.writeRecords(ctx, domain, next, apply, map[string]any{"domain": domain, "record": rec, "replaced": replaced, "records_before": len(current), "records_after": len(next)})
+}
+
+// writeRecords replaces the zone with next when apply is set. Otherwise it leaves the zone
+// alone and returns out as a dry run carrying the full record set --apply would write.
+func (s *Service) writeRecords(ctx context.Context, domain string, next []godaddy.DNSRecord, apply bool, out map[string]any) (map[string]any, error) {
+	if !apply {
+		out["dry_run"] = true
+		out["records"] = next
+		return out, nil
+	}
+	if err := s.Client.SetRecords(ctx, domain, next); err != nil {
+		return nil, err
 	}
+	out["applied"] = true
 	return out, nil
 }
 
-type dnsTemplateFile struct {
+// DNSTemplate is the custom template file format read by dns apply and written by dns export.
+type DNSTemplate struct {
 	NameServers []string            `json:"nameservers"`
 	Records     []godaddy.DNSRecord `json:"records"`
 }
 
-func loadCustomTemplate(path string) (*dnsTemplateFile, error) {
+var templateVarPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// expand returns a copy of t with ${DOMAIN} and ${VAR:key} substituted in record names and
+// data. Unknown placeholders and keys missing from vars are validation errors.
+func (t *DNSTemplate) expand(domain string, vars map[string]string) (*DNSTemplate, error) {
+	var missing []string
+	sub := func(in string) string {
+		return templateVarPattern.ReplaceAllStringFunc(in, func(m string) string {
+			name := m[2 : len(m)-1]
+			if name == "DOMAIN" {
+				return domain
+			}
+			if key, ok := strings.CutPrefix(name, "VAR:"); ok {
+				if v, ok := vars[key]; ok {
+					return v
+				}
+			}
+			missing = append(missing, m)
+			return m
+		})
+	}
+	out := &DNSTemplate{NameServers: t.NameServers, Records: make([]godaddy.DNSRecord, len(t.Records))}
+	for i, rec := range t.Records {
+		rec.Name = sub(rec.Name)
+		rec.Data = sub(rec.Data)
+		out.Records[i] = rec
+	}
+	if len(missing) > 0 {
+		return nil, &apperr.AppError{
+			Code:    apperr.CodeValidation,
+			Message: "template placeholders have no value; pass --var key=value",
+			Details: map[string]any{"placeholders": missing},
+		}
+	}
+	return out, nil
+}
+
+func loadCustomTemplate(path string) (*DNSTemplate, error) {
 	abs, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
@@ -1199,7 +3495,7 @@ func loadCustomTemplate(path string) (*dnsTemplateFile, error) {
 	if err != nil {
 		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "custom template file not found", Details: map[string]any{"template": abs}}
 	}
-	var tmpl dnsTemplateFile
+	var tmpl DNSTemplate
 	if err := json.Unmarshal(b, &tmpl); err != nil {
 		return nil, &apperr.AppError{Code: apperr.CodeValidation, Message: "invalid custom template JSON", Cause: err}
 	}
@@ -1209,6 +3505,51 @@ func loadCustomTemplate(path string) (*dnsTemplateFile, error) {
 	return &tmpl, nil
 }
 
+// CheckBulkItems refuses bulk input larger than max; max <= 0 disables the cap.
+func CheckBulkItems(count, max int) error {
+	if max <= 0 || count <= max {
+		return nil
+	}
+	return &apperr.AppError{
+		Code:    apperr.CodeValidation,
+		Message: fmt.Sprintf("bulk input has %d items, above the cap of %d; pass --max-items N to override", count, max),
+		Details: map[string]any{"count": count, "max_items": max},
+	}
+}
+
+// DefaultMaxConcurrency is the worker cap when max_concurrency is unset.
+const DefaultMaxConcurrency = 20
+
+// CheckConcurrency refuses a --concurrency style flag below 1 or above max; max <= 0 means
+// DefaultMaxConcurrency.
+func CheckConcurrency(flag string, n, max int) error {
+	if max <= 0 {
+		max = DefaultMaxConcurrency
+	}
+	if n >= 1 && n <= max {
+		return nil
+	}
+	msg := fmt.Sprintf("--%s must be at least 1", flag)
+	if n > max {
+		msg = fmt.Sprintf("--%s %d is above max_concurrency %d", flag, n, max)
+	}
+	return &apperr.AppError{
+		Code:    apperr.CodeValidation,
+		Message: msg,
+		Details: map[string]any{"flag": "--" + flag, "concurrency": n, "max_concurrency": max},
+	}
+}
+
+// workers bounds a requested worker count to 1..max_concurrency, for callers that did not go
+// through CheckConcurrency.
+func (s *Service) workers(n int) int {
+	limit := s.RT.Cfg.MaxConcurrency
+	if limit <= 0 {
+		limit = DefaultMaxConcurrency
+	}
+	return min(max(n, 1), limit)
+}
+
 func LoadDomainFile(path string) ([]string, error) {
 	abs, err := filepath.Abs(path)
 	if err != nil {
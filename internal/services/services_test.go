@@ -3,15 +3,25 @@ package services
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/sportwhiz/gdcli/internal/app"
+	"github.com/sportwhiz/gdcli/internal/budget"
 	"github.com/sportwhiz/gdcli/internal/config"
+	apperr "github.com/sportwhiz/gdcli/internal/errors"
 	"github.com/sportwhiz/gdcli/internal/godaddy"
+	"github.com/sportwhiz/gdcli/internal/rate"
 	"github.com/sportwhiz/gdcli/internal/store"
 )
 
@@ -90,22 +100,32 @@ func (f *fakeClient) SetRecords(ctx context.Context, domain string, records []go
 type flakyPurchaseClient struct {
 	fakeClient
 	purchaseCalls int
+	keys          []string
 }
 
 func (f *flakyPurchaseClient) Purchase(ctx context.Context, domain string, years int, idempotencyKey string) (godaddy.PurchaseResult, error) {
 	f.purchaseCalls++
+	f.keys = append(f.keys, idempotencyKey)
 	if f.purchaseCalls <= 3 {
 		return godaddy.PurchaseResult{}, io.ErrUnexpectedEOF
 	}
 	return godaddy.PurchaseResult{Domain: domain, Price: 12.99 * float64(years), Currency: "USD", OrderID: "order-2"}, nil
 }
 
-type eurRenewClient struct {
-	fakeClient
-}
-
-func (f *eurRenewClient) Renew(ctx context.Context, domain string, years int, idempotencyKey string) (godaddy.RenewResult, error) {
-	return godaddy.RenewResult{Domain: domain, Price: 12.99, Currency: "EUR", OrderID: "renew-eur"}, nil
+// quotedRenewClient quotes and bills renewals at 12.99 in currency through the
+// v2 API, so rt.Cfg.CustomerID must be set for Renew to use it.
+func quotedRenewClient(currency string) *fakeV2Client {
+	return &fakeV2Client{
+		renewCurrency: currency,
+		v2Detail: map[string]any{
+			"domain":    "example.com",
+			"expiresAt": "2026-05-27T15:01:38.000Z",
+			"renewal": map[string]any{
+				"price":    float64(12990000),
+				"currency": currency,
+			},
+		},
+	}
 }
 
 func makeRuntime(t *testing.T) *app.Runtime {
@@ -119,11 +139,31 @@ func makeRuntime(t *testing.T) *app.Runtime {
 	return rt
 }
 
+func TestPurchaseDryRunTokenExpiryFollowsConfig(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.ConfirmTokenTTLMinutes = 120
+	svc := New(rt, &fakeClient{})
+
+	before := time.Now()
+	dry, err := svc.PurchaseDryRun(context.Background(), "example.com", 1, 0)
+	if err != nil {
+		t.Fatalf("purchase dry run: %v", err)
+	}
+	raw, _ := dry["token_expires_at"].(string)
+	expires, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		t.Fatalf("parse token_expires_at %q: %v", raw, err)
+	}
+	if ttl := expires.Sub(before); ttl < 119*time.Minute || ttl > 121*time.Minute {
+		t.Fatalf("expected token to expire in about 120 minutes, got %s", ttl)
+	}
+}
+
 func TestPurchaseDryRunAndConfirm(t *testing.T) {
 	rt := makeRuntime(t)
 	svc := New(rt, &fakeClient{})
 
-	dry, err := svc.PurchaseDryRun(context.Background(), "example.com", 1)
+	dry, err := svc.PurchaseDryRun(context.Background(), "example.com", 1, 0)
 	if err != nil {
 		t.Fatalf("purchase dry run: %v", err)
 	}
@@ -141,6 +181,40 @@ func TestPurchaseDryRunAndConfirm(t *testing.T) {
 	}
 }
 
+func TestConfirmHoldsTheQuotedYears(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.MaxPricePerDomain = 50
+	svc := New(rt, &fakeClient{})
+	ctx := context.Background()
+
+	dry, err := svc.PurchaseDryRun(ctx, "example.com", 2, 0)
+	if err != nil {
+		t.Fatalf("purchase dry run: %v", err)
+	}
+	tok, _ := dry["confirmation_token"].(string)
+	_, err = svc.PurchaseConfirm(ctx, "example.com", tok, 1)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeConfirmation || ae.Details["token_years"] != 2 {
+		t.Fatalf("expected a years mismatch to be rejected, got %v", err)
+	}
+	res, err := svc.PurchaseConfirm(ctx, "example.com", tok, 0)
+	if err != nil {
+		t.Fatalf("purchase confirm without years: %v", err)
+	}
+	if res.Price != 12.99*2 {
+		t.Fatalf("expected the token's 2 years to be ordered, got %+v", res)
+	}
+
+	dry, err = svc.RenewDryRun(ctx, "example.com", 2)
+	if err != nil {
+		t.Fatalf("renew dry run: %v", err)
+	}
+	tok, _ = dry["confirmation_token"].(string)
+	if _, err := svc.RenewConfirm(ctx, "example.com", tok, 3); apperr.CodeOf(err) != apperr.CodeConfirmation {
+		t.Fatalf("expected a renew years mismatch to be rejected, got %v", err)
+	}
+}
+
 func TestAvailabilityBulkConcurrent(t *testing.T) {
 	rt := makeRuntime(t)
 	svc := New(rt, &fakeClient{})
@@ -156,10 +230,206 @@ func TestAvailabilityBulkConcurrent(t *testing.T) {
 	}
 }
 
+type flakyAvailabilityClient struct {
+	fakeClient
+}
+
+func (f *flakyAvailabilityClient) Available(ctx context.Context, domain string) (godaddy.Availability, error) {
+	switch domain {
+	case "flaky.com":
+		return godaddy.Availability{}, &apperr.AppError{Code: apperr.CodeProvider, Message: "provider returned non-success status", Retryable: true, Details: map[string]any{"status": 503}}
+	case "bad.com":
+		return godaddy.Availability{}, &apperr.AppError{Code: apperr.CodeProvider, Message: "provider returned non-success status", Details: map[string]any{"status": 400}}
+	}
+	return f.fakeClient.Available(ctx, domain)
+}
+
+func TestAvailabilityBulkConcurrentReportsAttempts(t *testing.T) {
+	rt := makeRuntime(t)
+	svc := New(rt, &flakyAvailabilityClient{})
+	out, err := svc.AvailabilityBulkConcurrent(context.Background(), []string{"ok.com", "bad.com", "flaky.com"}, 3)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial {
+		t.Fatalf("expected partial failure, got %v", err)
+	}
+	if out[0].Attempts != 1 || out[0].LastStatus != 0 {
+		t.Fatalf("unexpected success row: %+v", out[0])
+	}
+	if out[1].Attempts != 1 || out[1].LastStatus != 400 {
+		t.Fatalf("expected one attempt with 400, got %+v", out[1])
+	}
+	if out[2].Attempts != 3 || out[2].LastStatus != 503 {
+		t.Fatalf("expected three attempts ending in 503, got %+v", out[2])
+	}
+	failed, _ := ae.Details["failed_items"].([]FailedItem)
+	want := []FailedItem{{Domain: "bad.com", Code: apperr.CodeProvider}, {Domain: "flaky.com", Code: apperr.CodeProvider}}
+	if !reflect.DeepEqual(failed, want) {
+		t.Fatalf("expected failed_items %+v, got %+v", want, ae.Details["failed_items"])
+	}
+}
+
+// burstClient answers 429 when a call arrives within burstWindow of two others, like a
+// provider that rate-limits bursts rather than sustained load. Successful calls take
+// burstLatency, long enough that each worker in the test handles one domain.
+type burstClient struct {
+	fakeClient
+	mu     sync.Mutex
+	recent []time.Time
+}
+
+const (
+	burstWindow  = 10 * time.Millisecond
+	burstLatency = 250 * time.Millisecond
+)
+
+func (f *burstClient) Available(ctx context.Context, domain string) (godaddy.Availability, error) {
+	f.mu.Lock()
+	now := time.Now()
+	kept := f.recent[:0]
+	for _, at := range f.recent {
+		if now.Sub(at) < burstWindow {
+			kept = append(kept, at)
+		}
+	}
+	f.recent = append(kept, now)
+	burst := len(f.recent) > 2
+	f.mu.Unlock()
+	if burst {
+		return godaddy.Availability{}, &apperr.AppError{Code: apperr.CodeRateLimited, Message: "too many requests", Details: map[string]any{"status": 429}}
+	}
+	time.Sleep(burstLatency)
+	return f.fakeClient.Available(ctx, domain)
+}
+
+func TestAvailabilityBulkConcurrentRampAvoidsBurst429s(t *testing.T) {
+	domains := []string{"a.com", "b.com", "c.com", "d.com", "e.com", "f.com", "g.com", "h.com"}
+	firstTry := func(step time.Duration) int {
+		orig := workerRampStep
+		workerRampStep = step
+		defer func() { workerRampStep = orig }()
+		rt := makeRuntime(t)
+		rt.Limiter = rate.NewLimiter(60000, len(domains))
+		out, _ := New(rt, &burstClient{}).AvailabilityBulkConcurrent(context.Background(), domains, len(domains))
+		n := 0
+		for _, item := range out {
+			if item.Success && item.Attempts == 1 {
+				n++
+			}
+		}
+		return n
+	}
+	unramped := firstTry(0)
+	ramped := firstTry(workerRampStep)
+	t.Logf("first-attempt success: %d/%d without ramp, %d/%d with ramp", unramped, len(domains), ramped, len(domains))
+	if ramped != len(domains) {
+		t.Fatalf("expected every ramped check to succeed first time, got %d/%d", ramped, len(domains))
+	}
+	if unramped > ramped {
+		t.Fatalf("ramp should not lower the success rate: %d without, %d with", unramped, ramped)
+	}
+}
+
+type watchClient struct {
+	fakeClient
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *watchClient) Available(ctx context.Context, domain string) (godaddy.Availability, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	switch {
+	case f.calls == 1:
+		return godaddy.Availability{Domain: domain, Available: false}, nil
+	case f.calls <= 4:
+		return godaddy.Availability{}, &apperr.AppError{Code: apperr.CodeRateLimited, Message: "provider rate limited", Retryable: true, Details: map[string]any{"status": 429}}
+	}
+	return godaddy.Availability{Domain: domain, Available: true, Price: 12.99, Currency: "USD"}, nil
+}
+
+func TestNormalizeNameserversValidatesHostnames(t *testing.T) {
+	got, err := NormalizeNameservers([]string{" NS1.Afternic.com. ", "ns2.afternic.com", "ns1.afternic.com"})
+	if err != nil || strings.Join(got, ",") != "ns1.afternic.com,ns2.afternic.com" {
+		t.Fatalf("expected trimmed, lowercased, deduped nameservers, got %v %v", got, err)
+	}
+	_, err = NormalizeNameservers([]string{"ns1.afternic.com", "ns2..afternic.com", "-ns3.example.com", "ns4_example.com", "localhost", "1.2.3.4"})
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if invalid, _ := ae.Details["invalid"].([]string); len(invalid) != 5 || invalid[0] != "ns2..afternic.com" {
+		t.Fatalf("expected every bad entry listed, got %+v", ae.Details)
+	}
+	if _, err := NormalizeNameservers([]string{"ns1.example.com", "NS1.example.com"}); err == nil {
+		t.Fatalf("expected a single distinct nameserver to be rejected")
+	}
+}
+
+func TestWatchBacksOffOnRateLimitAndStopsWhenAvailable(t *testing.T) {
+	rt := makeRuntime(t)
+	svc := New(rt, &watchClient{})
+	var polls []WatchPoll
+	last, err := svc.Watch(context.Background(), "drop.com", 5*time.Millisecond, func(p WatchPoll) error {
+		polls = append(polls, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if !last.Available || last.Poll != 3 || last.Price != 12.99 {
+		t.Fatalf("unexpected final poll: %+v", last)
+	}
+	if len(polls) != 2 || polls[0].NextPollMs != 5 || polls[1].Error == "" || polls[1].NextPollMs != 10 {
+		t.Fatalf("expected a plain poll then a backed-off rate-limited poll, got %+v", polls)
+	}
+}
+
+func TestWatchStopsOnContextEnd(t *testing.T) {
+	rt := makeRuntime(t)
+	svc := New(rt, &fakeClient{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	last, err := svc.Watch(ctx, "taken.com", 10*time.Millisecond, func(WatchPoll) error { return nil })
+	if err == nil || last.Available || last.Poll == 0 {
+		t.Fatalf("expected watch to end unavailable on timeout, got %+v %v", last, err)
+	}
+}
+
+type tldsClient struct {
+	fakeClient
+}
+
+func (f *tldsClient) ListTLDs(ctx context.Context) ([]godaddy.TLD, error) {
+	return []godaddy.TLD{{Name: "net", Type: "GENERIC"}, {Name: "com", Type: "GENERIC"}, {Name: "ai", Type: "COUNTRY_CODE"}}, nil
+}
+
+func TestTLDsFiltersAndPrices(t *testing.T) {
+	rt := makeRuntime(t)
+	svc := New(rt, &tldsClient{})
+	all, err := svc.TLDs(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("tlds: %v", err)
+	}
+	if len(all) != 3 || all[0].Name != "ai" || all[0].Price != 0 {
+		t.Fatalf("expected sorted unpriced list, got %+v", all)
+	}
+	priced, err := svc.TLDs(context.Background(), []string{".AI"}, true)
+	if err != nil {
+		t.Fatalf("tlds priced: %v", err)
+	}
+	if len(priced) != 1 || priced[0].Name != "ai" || priced[0].Price != 12.99 || priced[0].Currency != "USD" {
+		t.Fatalf("expected priced .ai, got %+v", priced)
+	}
+	if _, err := svc.TLDs(context.Background(), []string{"zz"}, false); err == nil {
+		t.Fatalf("expected unsupported TLD filter to fail")
+	}
+}
+
 func TestOrdersList(t *testing.T) {
 	rt := makeRuntime(t)
 	svc := New(rt, &fakeClient{})
-	out, err := svc.OrdersList(context.Background(), 5, 0)
+	out, err := svc.OrdersList(context.Background(), 5, 0, OrderFilter{})
 	if err != nil {
 		t.Fatalf("orders list: %v", err)
 	}
@@ -172,6 +442,39 @@ func TestOrdersList(t *testing.T) {
 	}
 }
 
+func TestFilterOrders(t *testing.T) {
+	orders := []godaddy.Order{
+		{OrderID: "old", CreatedAt: "2025-01-10T00:00:00.000Z", Pricing: godaddy.OrderPricing{Total: 50}},
+		{OrderID: "cheap", CreatedAt: "2025-06-01T00:00:00.000Z", Pricing: godaddy.OrderPricing{Total: 5}},
+		{OrderID: "mid", CreatedAt: "2025-05-01T00:00:00.000Z", Pricing: godaddy.OrderPricing{Total: 20}},
+		{OrderID: "new", CreatedAt: "2025-07-01T00:00:00.000Z", Pricing: godaddy.OrderPricing{Total: 12}},
+		{OrderID: "undated", Pricing: godaddy.OrderPricing{Total: 99}},
+	}
+	f, err := ParseOrderFilter("2025-03-01T00:00:00Z", "10", "created_at")
+	if err != nil {
+		t.Fatalf("parse filter: %v", err)
+	}
+	got := FilterOrders(orders, f)
+	if len(got) != 2 || got[0].OrderID != "new" || got[1].OrderID != "mid" {
+		t.Fatalf("unexpected created_at filter result: %+v", got)
+	}
+	f, _ = ParseOrderFilter("", "", "total")
+	got = FilterOrders(orders, f)
+	if len(got) != 5 || got[0].OrderID != "undated" || got[4].OrderID != "cheap" {
+		t.Fatalf("unexpected total sort: %+v", got)
+	}
+	if _, err := ParseOrderFilter("2025-03-01", "", ""); err != nil {
+		t.Fatalf("expected plain date to parse: %v", err)
+	}
+	for _, bad := range [][3]string{{"03/01/2025", "", ""}, {"", "-1", ""}, {"", "", "price"}} {
+		_, err := ParseOrderFilter(bad[0], bad[1], bad[2])
+		var ae *apperr.AppError
+		if !apperr.As(err, &ae) || ae.Code != apperr.CodeValidation {
+			t.Fatalf("expected validation error for %v, got %v", bad, err)
+		}
+	}
+}
+
 func TestSubscriptionsList(t *testing.T) {
 	rt := makeRuntime(t)
 	svc := New(rt, &fakeClient{})
@@ -227,9 +530,10 @@ func TestAppendOperationWarningOnFailure(t *testing.T) {
 
 func TestPurchaseConfirmTokenReusableAfterTransientFailure(t *testing.T) {
 	rt := makeRuntime(t)
-	svc := New(rt, &flakyPurchaseClient{})
+	client := &flakyPurchaseClient{}
+	svc := New(rt, client)
 
-	dry, err := svc.PurchaseDryRun(context.Background(), "example.com", 1)
+	dry, err := svc.PurchaseDryRun(context.Background(), "example.com", 1, 0)
 	if err != nil {
 		t.Fatalf("purchase dry run: %v", err)
 	}
@@ -249,14 +553,901 @@ func TestPurchaseConfirmTokenReusableAfterTransientFailure(t *testing.T) {
 	if res.OrderID == "" {
 		t.Fatalf("expected order id on retry")
 	}
+	for _, k := range client.keys {
+		if k == "" || k != res.OperationKey {
+			t.Fatalf("expected every attempt under the reported operation_key %q, got %v", res.OperationKey, client.keys)
+		}
+	}
+}
+
+func TestRenewReportsOperationKey(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CustomerID = "cust-123"
+	client := quotedRenewClient("USD")
+	res, err := New(rt, client).Renew(context.Background(), "example.com", 1, false, true)
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if client.lastRenewKey == "" || res["operation_key"] != client.lastRenewKey {
+		t.Fatalf("expected operation_key %q in result, got %+v", client.lastRenewKey, res)
+	}
 }
 
 func TestRenewRejectsNonUSDProviderPrice(t *testing.T) {
 	rt := makeRuntime(t)
-	svc := New(rt, &eurRenewClient{})
+	rt.Cfg.CustomerID = "cust-123"
+	svc := New(rt, quotedRenewClient("EUR"))
 
 	_, err := svc.Renew(context.Background(), "example.com", 1, false, true)
 	if err == nil {
 		t.Fatalf("expected non-USD renew to fail budget policy")
 	}
 }
+
+func TestRenewAllowsConfiguredBaseCurrency(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.BaseCurrency = "EUR"
+	rt.Cfg.CustomerID = "cust-123"
+	svc := New(rt, quotedRenewClient("EUR"))
+
+	res, err := svc.Renew(context.Background(), "example.com", 1, false, true)
+	if err != nil {
+		t.Fatalf("renew in base currency: %v", err)
+	}
+	if res["currency"] != "EUR" {
+		t.Fatalf("expected EUR result, got %+v", res)
+	}
+	report, err := budget.BuildReport(rt.Cfg, time.Now())
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.Currency != "EUR" || report.Today.Spend != 12.99 {
+		t.Fatalf("expected EUR spend recorded, got %+v", report)
+	}
+}
+
+func TestRenewConvertsWithCurrencyRates(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CurrencyRates = map[string]float64{"EUR": 2}
+	rt.Cfg.MaxPricePerDomain = 20
+	rt.Cfg.CustomerID = "cust-123"
+	svc := New(rt, quotedRenewClient("EUR"))
+
+	_, err := svc.Renew(context.Background(), "example.com", 1, false, true)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeBudget || ae.Details["base_price"] != 25.98 {
+		t.Fatalf("expected converted price over the USD cap, got %v", err)
+	}
+	rt.Cfg.MaxPricePerDomain = 30
+	if _, err := svc.Renew(context.Background(), "example.com", 1, false, true); err != nil {
+		t.Fatalf("renew with converted price under cap: %v", err)
+	}
+}
+
+type recordingDNSClient struct {
+	fakeClient
+	setNSCalls      int
+	setRecordsCalls int
+	lastRecords     []godaddy.DNSRecord
+}
+
+func (f *recordingDNSClient) SetNameservers(ctx context.Context, domain string, nameservers []string) error {
+	f.setNSCalls++
+	return nil
+}
+
+func (f *recordingDNSClient) SetRecords(ctx context.Context, domain string, records []godaddy.DNSRecord) error {
+	f.setRecordsCalls++
+	f.lastRecords = records
+	return nil
+}
+
+func TestDNSApplyTemplateOnlyChangedSkipsMatchingDomains(t *testing.T) {
+	rt := makeRuntime(t)
+	fc := &recordingDNSClient{}
+	svc := New(rt, fc)
+
+	out, err := svc.DNSApplyTemplate(context.Background(), "afternic-nameservers", []string{"a.com", "b.com"}, nil, false, true, 1)
+	if err != nil {
+		t.Fatalf("dns apply: %v", err)
+	}
+	if fc.setNSCalls != 0 {
+		t.Fatalf("expected no nameserver writes for matching domains, got %d", fc.setNSCalls)
+	}
+	for _, row := range out {
+		if row["unchanged"] != true {
+			t.Fatalf("expected unchanged row, got %+v", row)
+		}
+	}
+
+	if _, err := svc.DNSApplyTemplate(context.Background(), "parking", []string{"a.com"}, nil, false, true, 1); err != nil {
+		t.Fatalf("dns apply parking: %v", err)
+	}
+	if fc.setRecordsCalls != 1 {
+		t.Fatalf("expected differing records to be written once, got %d", fc.setRecordsCalls)
+	}
+}
+
+func TestDNSApplyMailTemplatesMergeIntoZone(t *testing.T) {
+	rt := makeRuntime(t)
+	fc := &recordingDNSClient{}
+	svc := New(rt, fc)
+	ctx := context.Background()
+
+	out, err := svc.DNSApplyTemplate(ctx, "microsoft365", []string{"shop.example.com"}, nil, true, false, 1)
+	if err != nil {
+		t.Fatalf("dns apply dry run: %v", err)
+	}
+	recs, _ := out[0]["records"].([]godaddy.DNSRecord)
+	if fc.setRecordsCalls != 0 || len(recs) != 3 || recs[0].Data != "shop-example-com.mail.protection.outlook.com" || out[0]["merge_records"] != true {
+		t.Fatalf("expected dry run to preview per-domain records, got %+v", out[0])
+	}
+
+	if _, err := svc.DNSApplyTemplate(ctx, "email-hardening", []string{"a.com"}, nil, false, false, 1); err != nil {
+		t.Fatalf("dns apply: %v", err)
+	}
+	if len(fc.lastRecords) != 5 || fc.lastRecords[0].Type != "A" || fc.lastRecords[1].Data != "verify=ok" {
+		t.Fatalf("expected existing A and verification TXT to be kept, got %+v", fc.lastRecords)
+	}
+	if fc.lastRecords[3].Name != "_dmarc" || !strings.HasPrefix(fc.lastRecords[3].Data, "v=DMARC1;") {
+		t.Fatalf("expected DMARC record, got %+v", fc.lastRecords)
+	}
+
+	if _, err := svc.DNSApplyTemplate(ctx, "google-workspace", []string{"a.com"}, nil, false, false, 1); err != nil {
+		t.Fatalf("dns apply: %v", err)
+	}
+	if len(fc.lastRecords) != 7 || fc.lastRecords[2].Type != "MX" || fc.lastRecords[2].Priority != 1 {
+		t.Fatalf("expected MX records appended to the zone, got %+v", fc.lastRecords)
+	}
+}
+
+func TestDNSApplyCustomTemplateExpandsVariables(t *testing.T) {
+	rt := makeRuntime(t)
+	fc := &recordingDNSClient{}
+	svc := New(rt, fc)
+	path := filepath.Join(t.TempDir(), "site.json")
+	tmpl := `{"records":[{"type":"A","name":"@","data":"${VAR:ip}"},{"type":"TXT","name":"@","data":"site=${DOMAIN} token=${VAR:token}"}]}`
+	if err := os.WriteFile(path, []byte(tmpl), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	_, err := svc.DNSApplyTemplate(context.Background(), path, []string{"a.com"}, map[string]string{"ip": "192.0.2.7"}, false, false, 1)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeValidation || fc.setRecordsCalls != 0 {
+		t.Fatalf("expected missing variable to fail before writing, got %v", err)
+	}
+
+	vars := map[string]string{"ip": "192.0.2.7", "token": "abc"}
+	if _, err := svc.DNSApplyTemplate(context.Background(), path, []string{"a.com", "b.com"}, vars, false, false, 1); err != nil {
+		t.Fatalf("dns apply: %v", err)
+	}
+	if fc.lastRecords[0].Data != "192.0.2.7" || fc.lastRecords[1].Data != "site=b.com token=abc" {
+		t.Fatalf("expected placeholders expanded per domain, got %+v", fc.lastRecords)
+	}
+}
+
+type mailDNSClient struct {
+	fakeClient
+}
+
+func (f *mailDNSClient) GetRecords(ctx context.Context, domain string) ([]godaddy.DNSRecord, error) {
+	return []godaddy.DNSRecord{
+		{Type: "A", Name: "@", Data: "1.2.3.4"},
+		{Type: "TXT", Name: "@", Data: "v=spf1 include:_spf.google.com ~all"},
+		{Type: "TXT", Name: "_dmarc", Data: "v=DMARC1; p=none"},
+		{Type: "MX", Name: "@", Data: "aspmx.l.google.com", Priority: 1},
+	}, nil
+}
+
+func TestDNSAuditEmailChecks(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Limiter = rate.NewLimiter(60000, 10)
+	ctx := context.Background()
+
+	out, err := New(rt, &fakeClient{}).DNSAudit(ctx, []string{"a.com"}, nil, 1)
+	if err != nil {
+		t.Fatalf("dns audit: %v", err)
+	}
+	if got := fmt.Sprint(out[0]["issues"]); got != "[missing_spf missing_dmarc missing_caa missing_mx]" {
+		t.Fatalf("unexpected issues for a bare zone: %s", got)
+	}
+
+	out, err = New(rt, &mailDNSClient{}).DNSAudit(ctx, []string{"a.com"}, nil, 1)
+	if err != nil {
+		t.Fatalf("dns audit: %v", err)
+	}
+	if got := fmt.Sprint(out[0]["issues"]); got != "[missing_caa]" {
+		t.Fatalf("unexpected issues for a mail zone: %s", got)
+	}
+
+	checks, err := ParseDNSAuditChecks([]string{"MX", "caa"})
+	if err != nil {
+		t.Fatalf("parse checks: %v", err)
+	}
+	out, _ = New(rt, &fakeClient{}).DNSAudit(ctx, []string{"a.com"}, checks, 1)
+	if got := fmt.Sprint(out[0]["issues"]); got != "[missing_caa missing_mx]" {
+		t.Fatalf("expected only selected checks in rule order, got %s", got)
+	}
+	if _, err := ParseDNSAuditChecks([]string{"dkim"}); err == nil {
+		t.Fatalf("expected unknown check to be rejected")
+	}
+}
+
+func TestDNSAuditWithRulesFile(t *testing.T) {
+	rt := makeRuntime(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.json")
+	rulesJSON := `{"nameservers":["ns2.afternic.com","ns1.afternic.com"],"record_types":["a","MX"],"txt_contains":["verify=ok","v=spf1"]}`
+	if err := os.WriteFile(path, []byte(rulesJSON), 0o600); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	rules, err := LoadDNSAuditRules(path)
+	if err != nil {
+		t.Fatalf("load rules: %v", err)
+	}
+	out, err := New(rt, &fakeClient{}).DNSAuditWithRules(context.Background(), []string{"a.com"}, rules, 1)
+	if err != nil {
+		t.Fatalf("dns audit: %v", err)
+	}
+	if out[0]["passed"] != false || fmt.Sprint(out[0]["issues"]) != "[record_type:MX txt_contains:v=spf1]" {
+		t.Fatalf("unexpected audit row: %+v", out[0])
+	}
+	if checks, _ := out[0]["rules"].([]map[string]any); len(checks) != 5 || checks[0]["rule"] != "nameservers" || checks[0]["pass"] != true {
+		t.Fatalf("expected pass/fail per rule, got %+v", out[0]["rules"])
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"required":["A"]}`), 0o600); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	if _, err := LoadDNSAuditRules(bad); err == nil {
+		t.Fatalf("expected unknown rule keys to be rejected")
+	}
+}
+
+type flakyRecordsClient struct {
+	fakeClient
+}
+
+func (f *flakyRecordsClient) GetRecords(ctx context.Context, domain string) ([]godaddy.DNSRecord, error) {
+	if domain == "bad.com" {
+		return nil, errors.New("boom")
+	}
+	return f.fakeClient.GetRecords(ctx, domain)
+}
+
+func TestDNSAuditConcurrentKeepsOrderAndReportsPartialFailure(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Limiter = rate.NewLimiter(60000, 10)
+	rt.Cfg.RetryBaseMs = 10
+	domains := []string{"a.com", "bad.com", "c.com", "d.com"}
+	out, err := New(rt, &flakyRecordsClient{}).DNSAudit(context.Background(), domains, nil, 3)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial || ae.Details["failed"] != 1 {
+		t.Fatalf("expected partial failure for one domain, got %v", err)
+	}
+	if want := []FailedItem{{Domain: "bad.com", Code: apperr.CodeNetwork}}; !reflect.DeepEqual(ae.Details["failed_items"], want) {
+		t.Fatalf("expected failed_items %+v, got %+v", want, ae.Details["failed_items"])
+	}
+	if msg, _ := out[1]["error"].(string); !strings.HasSuffix(msg, "boom") {
+		t.Fatalf("expected the row error rendered as its message, got %+v", out[1])
+	}
+	for i, row := range out {
+		if row["domain"] != domains[i] {
+			t.Fatalf("row %d out of order: %+v", i, row)
+		}
+		if _, ok := row["duration_ms"].(int64); !ok {
+			t.Fatalf("row %d missing duration_ms: %+v", i, row)
+		}
+	}
+	if out[1]["error"] == nil || out[2]["error"] != nil {
+		t.Fatalf("expected only bad.com to fail, got %+v", out)
+	}
+}
+
+type throttledRecordsClient struct {
+	fakeClient
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *throttledRecordsClient) GetRecords(ctx context.Context, domain string) ([]godaddy.DNSRecord, error) {
+	f.mu.Lock()
+	f.calls++
+	first := f.calls == 1
+	f.mu.Unlock()
+	if first {
+		return nil, &apperr.AppError{Code: apperr.CodeRateLimited, Message: "too many requests"}
+	}
+	return f.fakeClient.GetRecords(ctx, domain)
+}
+
+func TestDNSAuditRetriesRateLimitedCalls(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Limiter = rate.NewLimiter(60000, 10)
+	rt.Cfg.RetryBaseMs = 10
+	fc := &throttledRecordsClient{}
+	out, err := New(rt, fc).DNSAudit(context.Background(), []string{"a.com"}, nil, 1)
+	if err != nil || out[0]["error"] != nil {
+		t.Fatalf("expected the 429 to be retried, got %v %+v", err, out)
+	}
+	if fc.calls != 2 {
+		t.Fatalf("expected one retry of GetRecords, got %d calls", fc.calls)
+	}
+}
+
+func TestRecordsAddDeleteReplaceMergeWithZone(t *testing.T) {
+	rt := makeRuntime(t)
+	fc := &recordingDNSClient{}
+	svc := New(rt, fc)
+	ctx := context.Background()
+
+	res, err := svc.RecordsAdd(ctx, "a.com", godaddy.DNSRecord{Type: "cname", Name: "www", Data: "a.com", TTL: 600}, false)
+	if err != nil || res["dry_run"] != true || fc.setRecordsCalls != 0 {
+		t.Fatalf("expected a dry run without --apply, got %v %v calls=%d", res, err, fc.setRecordsCalls)
+	}
+	if recs, _ := res["records"].([]godaddy.DNSRecord); len(recs) != 3 {
+		t.Fatalf("expected the dry run to show the full record set, got %+v", res["records"])
+	}
+
+	res, err = svc.RecordsAdd(ctx, "a.com", godaddy.DNSRecord{Type: "cname", Name: "www", Data: "a.com", TTL: 600}, true)
+	if err != nil {
+		t.Fatalf("records add: %v", err)
+	}
+	if len(fc.lastRecords) != 3 || fc.lastRecords[2].Type != "CNAME" || res["changed"] != true {
+		t.Fatalf("expected CNAME appended to existing records, got %+v", fc.lastRecords)
+	}
+	res, err = svc.RecordsAdd(ctx, "a.com", godaddy.DNSRecord{Type: "A", Name: "@", Data: "1.2.3.4"}, true)
+	if err != nil || res["changed"] != false || fc.setRecordsCalls != 1 {
+		t.Fatalf("expected duplicate add to be a no-op, got %v %v calls=%d", res, err, fc.setRecordsCalls)
+	}
+
+	if _, err := svc.RecordsDelete(ctx, "a.com", godaddy.DNSRecord{Type: "A", Name: "@", Data: "9.9.9.9"}, true); err == nil {
+		t.Fatalf("expected delete with non-matching data to fail")
+	}
+	if _, err := svc.RecordsDelete(ctx, "a.com", godaddy.DNSRecord{Type: "TXT", Name: "@", Data: "verify=ok"}, true); err != nil {
+		t.Fatalf("records delete: %v", err)
+	}
+	if len(fc.lastRecords) != 1 || fc.lastRecords[0].Type != "A" {
+		t.Fatalf("expected only the A record to remain, got %+v", fc.lastRecords)
+	}
+
+	if _, err := svc.RecordsReplace(ctx, "a.com", godaddy.DNSRecord{Type: "A", Name: "@", Data: "5.6.7.8"}, true); err != nil {
+		t.Fatalf("records replace: %v", err)
+	}
+	if len(fc.lastRecords) != 2 || fc.lastRecords[0].Type != "TXT" || fc.lastRecords[1].Data != "5.6.7.8" {
+		t.Fatalf("expected A replaced and TXT kept, got %+v", fc.lastRecords)
+	}
+
+	mx := godaddy.DNSRecord{Type: "MX", Name: "@", Data: "mx.a.com", Priority: 10}
+	if _, err := svc.RecordsAdd(ctx, "a.com", mx, true); err != nil {
+		t.Fatalf("records add mx: %v", err)
+	}
+	mx.Priority = 0
+	res, err = svc.RecordsAdd(ctx, "a.com", mx, true)
+	if err != nil || res["changed"] != true || fc.lastRecords[2].Priority != 0 || len(fc.lastRecords) != 3 {
+		t.Fatalf("expected the MX priority updated in place, got %v %v %+v", res, err, fc.lastRecords)
+	}
+	if _, err := svc.RecordsAdd(ctx, "a.com", godaddy.DNSRecord{Type: "SRV", Name: "_sip._tls", Data: "sip.a.com", Port: 70000}, false); apperr.CodeOf(err) != apperr.CodeValidation {
+		t.Fatalf("expected an out-of-range port to be rejected, got %v", err)
+	}
+}
+
+func TestReserveFinalizeConcurrentOperationsFitCaps(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.MaxDailySpend = 30
+	rt.Cfg.MaxDomainsPerDay = 3
+	svc := New(rt, &fakeClient{})
+
+	const n = 3
+	now := time.Now()
+	var wg sync.WaitGroup
+	reserved := make(chan struct{}, n)
+	release := make(chan struct{})
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			opID := fmt.Sprintf("op-%d", i)
+			if _, err := svc.reserveOperation("purchase", fmt.Sprintf("d%d.com", i), 10, "USD", opID, now); err != nil {
+				errs <- err
+				reserved <- struct{}{}
+				return
+			}
+			reserved <- struct{}{}
+			// Finalize only once every operation is pending, so each sees the others' reservations.
+			<-release
+			errs <- svc.finalizeOperation(opID, 10, "USD", "succeeded", opResult{})
+		}(i)
+	}
+	for i := 0; i < n; i++ {
+		<-reserved
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected all operations within caps to succeed: %v", err)
+		}
+	}
+
+	ops, err := store.ReadOperations()
+	if err != nil {
+		t.Fatalf("read operations: %v", err)
+	}
+	for _, op := range ops {
+		if op.Status != "succeeded" {
+			t.Fatalf("expected succeeded operation, got %+v", op)
+		}
+	}
+}
+
+// reserveHelperEnv makes the test binary act as one gdcli process reserving a purchase; see
+// TestReserveOperationAcrossProcessesHonorsDailyCap.
+const reserveHelperEnv = "GDCLI_TEST_RESERVE_HELPER"
+
+func TestReserveOperationHelperProcess(t *testing.T) {
+	domain := os.Getenv(reserveHelperEnv)
+	if domain == "" {
+		t.Skip("helper process only")
+	}
+	rt, err := app.NewRuntime(context.Background(), io.Discard, io.Discard, true, false, true, "req-helper")
+	if err != nil {
+		os.Exit(1)
+	}
+	rt.Cfg.MaxDailySpend = 100
+	rt.Cfg.MaxDomainsPerDay = 3
+	svc := New(rt, &fakeClient{})
+	if _, err := svc.reserveOperation("purchase", domain, 10, "USD", "op-"+domain, time.Now()); err != nil {
+		var ae *apperr.AppError
+		if apperr.As(err, &ae) && ae.Code == apperr.CodeBudget {
+			os.Exit(3)
+		}
+		os.Exit(1)
+	}
+	if err := svc.finalizeOperation("op-"+domain, 10, "USD", "succeeded", opResult{}); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestReserveOperationAcrossProcessesHonorsDailyCap(t *testing.T) {
+	makeRuntime(t)
+
+	const n = 8
+	cmds := make([]*exec.Cmd, n)
+	for i := range cmds {
+		c := exec.Command(os.Args[0], "-test.run=^TestReserveOperationHelperProcess$")
+		c.Env = append(os.Environ(), fmt.Sprintf("%s=d%d.com", reserveHelperEnv, i))
+		if err := c.Start(); err != nil {
+			t.Fatalf("start helper %d: %v", i, err)
+		}
+		cmds[i] = c
+	}
+	succeeded, capped := 0, 0
+	for i, c := range cmds {
+		err := c.Wait()
+		var exit *exec.ExitError
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.As(err, &exit) && exit.ExitCode() == 3:
+			capped++
+		default:
+			t.Fatalf("helper %d failed: %v", i, err)
+		}
+	}
+	if succeeded != 3 || capped != n-3 {
+		t.Fatalf("expected 3 purchases within the daily cap and %d rejected, got %d and %d", n-3, succeeded, capped)
+	}
+
+	ops, err := store.ReadOperations()
+	if err != nil {
+		t.Fatalf("read operations: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("expected 3 logged operations, got %d: %+v", len(ops), ops)
+	}
+	for _, op := range ops {
+		if op.Status != "succeeded" {
+			t.Fatalf("expected succeeded operation, got %+v", op)
+		}
+	}
+}
+
+func TestFinalizeRejectsAmountAboveReservationPastCap(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.MaxDailySpend = 30
+	rt.Cfg.MaxDomainsPerDay = 3
+	svc := New(rt, &fakeClient{})
+	now := time.Now()
+
+	if _, err := svc.reserveOperation("purchase", "a.com", 10, "USD", "op-a", now); err != nil {
+		t.Fatalf("reserve a: %v", err)
+	}
+	if _, err := svc.reserveOperation("purchase", "b.com", 15, "USD", "op-b", now); err != nil {
+		t.Fatalf("reserve b: %v", err)
+	}
+	if err := svc.finalizeOperation("op-a", 20, "USD", "succeeded", opResult{}); err == nil {
+		t.Fatalf("expected finalize above reservation to exceed daily cap")
+	}
+	if err := svc.finalizeOperation("op-b", 12, "USD", "succeeded", opResult{}); err != nil {
+		t.Fatalf("expected finalize below reservation to succeed: %v", err)
+	}
+}
+
+func TestReserveRejectsPurchasePastMonthlyCap(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.MaxDailySpend = 100
+	rt.Cfg.MaxDomainsPerDay = 5
+	rt.Cfg.MaxMonthlySpend = 50
+	svc := New(rt, &fakeClient{})
+	now := time.Now()
+	monthStart, _ := budget.MonthBounds(now)
+
+	_ = store.AppendOperation(store.Operation{OperationID: "earlier", Type: "renew", Domain: "old.com", Amount: 40, Currency: "USD", CreatedAt: monthStart, Status: "succeeded"})
+	_ = store.AppendOperation(store.Operation{OperationID: "last-month", Type: "renew", Domain: "older.com", Amount: 40, Currency: "USD", CreatedAt: monthStart.Add(-time.Minute), Status: "succeeded"})
+
+	_, err := svc.reserveOperation("purchase", "a.com", 15, "USD", "op-a", now)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeBudget || ae.Details["max_monthly_spend"] != 50.0 {
+		t.Fatalf("expected monthly cap budget error, got %v", err)
+	}
+	if _, err := svc.reserveOperation("purchase", "a.com", 10, "USD", "op-b", now); err != nil {
+		t.Fatalf("expected purchase within monthly cap to be reserved: %v", err)
+	}
+}
+
+type definitiveClient struct {
+	fakeClient
+	definitiveAfter int
+	checks          int
+	purchases       int
+}
+
+func (f *definitiveClient) Available(ctx context.Context, domain string) (godaddy.Availability, error) {
+	f.checks++
+	return godaddy.Availability{Domain: domain, Available: true, Definitive: f.definitiveAfter > 0 && f.checks >= f.definitiveAfter, Price: 12.99, Currency: "USD"}, nil
+}
+
+func (f *definitiveClient) Purchase(ctx context.Context, domain string, years int, idempotencyKey string) (godaddy.PurchaseResult, error) {
+	f.purchases++
+	return f.fakeClient.Purchase(ctx, domain, years, idempotencyKey)
+}
+
+func TestRequireOTEFirstRefusesProdSpendWithoutOverride(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.AutoPurchaseEnabled = true
+	rt.Cfg.AcknowledgmentHash = "ack"
+	rt.Cfg.RequireOTEFirst = true
+	fc := &definitiveClient{definitiveAfter: 1}
+
+	_, err := New(rt, fc).PurchaseAuto(context.Background(), "example.com", 1, 0)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeSafety || ae.Details["override"] != "--allow-prod" {
+		t.Fatalf("expected safety refusal naming the override, got %v", err)
+	}
+	if fc.purchases != 0 {
+		t.Fatalf("expected no purchase, got %d", fc.purchases)
+	}
+
+	rt.APIEnvOverride = "ote"
+	if _, err := New(rt, fc).PurchaseAuto(context.Background(), "ote.com", 1, 0); err != nil {
+		t.Fatalf("expected OTE purchase to be allowed: %v", err)
+	}
+	rt.APIEnvOverride = ""
+	rt.AllowProd = true
+	if _, err := New(rt, fc).PurchaseAuto(context.Background(), "prod.com", 1, 0); err != nil {
+		t.Fatalf("expected --allow-prod to let the purchase through: %v", err)
+	}
+}
+
+// interruptingClient simulates Ctrl-C arriving while the purchase request is in flight.
+type interruptingClient struct {
+	definitiveClient
+	cancel  context.CancelFunc
+	fail    bool
+	ctxErrs []error
+}
+
+func (f *interruptingClient) Purchase(ctx context.Context, domain string, years int, idempotencyKey string) (godaddy.PurchaseResult, error) {
+	f.cancel()
+	f.ctxErrs = append(f.ctxErrs, ctx.Err())
+	if f.fail {
+		return godaddy.PurchaseResult{}, &apperr.AppError{Code: apperr.CodeNetwork, Message: "connection reset"}
+	}
+	return f.definitiveClient.Purchase(ctx, domain, years, idempotencyKey)
+}
+
+func TestPurchaseInterruptedMidRequestIsNeverMarkedFailed(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.AutoPurchaseEnabled = true
+	rt.Cfg.AcknowledgmentHash = "ack"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fc := &interruptingClient{definitiveClient: definitiveClient{definitiveAfter: 1}, cancel: cancel}
+	if _, err := New(rt, fc).PurchaseAuto(ctx, "done.com", 1, 0); err != nil {
+		t.Fatalf("expected the in-flight purchase to complete despite Ctrl-C: %v", err)
+	}
+	if fc.ctxErrs[0] != nil {
+		t.Fatalf("expected the purchase request to ignore cancellation, got %v", fc.ctxErrs[0])
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	fc = &interruptingClient{definitiveClient: definitiveClient{definitiveAfter: 1}, cancel: cancel, fail: true}
+	_, err := New(rt, fc).PurchaseAuto(ctx, "maybe.com", 1, 0)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Details["status"] != "pending" {
+		t.Fatalf("expected an interrupted purchase to be reported pending, got %v", err)
+	}
+	ops, err := store.ReadOperations()
+	if err != nil {
+		t.Fatalf("read operations: %v", err)
+	}
+	status := map[string]string{}
+	for _, op := range ops {
+		status[op.Domain] = op.Status
+	}
+	if status["done.com"] != "succeeded" || status["maybe.com"] != "pending" {
+		t.Fatalf("expected succeeded and pending operations, got %+v", status)
+	}
+
+	opKey, _ := ae.Details["operation_key"].(string)
+	svc := New(rt, nil)
+	if _, err := svc.ResolveOperation(opKey, "pending", ""); apperr.CodeOf(err) != apperr.CodeValidation {
+		t.Fatalf("expected only succeeded or failed to be accepted, got %v", err)
+	}
+	op, err := svc.ResolveOperation(opKey, "succeeded", "order-9")
+	if err != nil {
+		t.Fatalf("resolve pending operation: %v", err)
+	}
+	if op.Status != "succeeded" || op.OrderID != "order-9" || op.Domain != "maybe.com" {
+		t.Fatalf("expected the operation settled with its order, got %+v", op)
+	}
+	if _, err := svc.ResolveOperation(opKey, "failed", ""); apperr.CodeOf(err) != apperr.CodeValidation {
+		t.Fatalf("expected a settled operation to be left alone, got %v", err)
+	}
+}
+
+func TestPurchaseAutoRequiresDefinitiveAvailability(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.AutoPurchaseEnabled = true
+	rt.Cfg.AcknowledgmentHash = "ack"
+
+	never := &definitiveClient{}
+	_, err := New(rt, never).PurchaseAuto(context.Background(), "example.com", 1, 0)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeSafety {
+		t.Fatalf("expected safety error for non-definitive availability, got %v", err)
+	}
+	if never.checks != 2 || never.purchases != 0 {
+		t.Fatalf("expected one re-check and no purchase, got checks=%d purchases=%d", never.checks, never.purchases)
+	}
+
+	second := &definitiveClient{definitiveAfter: 2}
+	if _, err := New(rt, second).PurchaseAuto(context.Background(), "example.com", 1, 0); err != nil {
+		t.Fatalf("expected purchase after definitive re-check: %v", err)
+	}
+	ops, err := store.ReadOperations()
+	if err != nil {
+		t.Fatalf("read operations: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Definitive == nil || !*ops[0].Definitive || ops[0].Status != "succeeded" || ops[0].OrderID != "order-1" {
+		t.Fatalf("expected succeeded operation recording definitive=true and the order id, got %+v", ops)
+	}
+}
+
+type discoverClient struct {
+	fakeClient
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (f *discoverClient) Suggest(ctx context.Context, query string, tlds []string, limit int) ([]godaddy.Suggestion, error) {
+	if query == "broken" {
+		return nil, &apperr.AppError{Code: apperr.CodeProvider, Message: "suggest failed"}
+	}
+	return []godaddy.Suggestion{
+		{Domain: query + ".com", Score: 0.9},
+		{Domain: query + ".ai", Score: 0.8},
+		{Domain: query + ".net", Score: 0.7},
+		{Domain: "shared.com", Score: 0.5},
+	}, nil
+}
+
+func (f *discoverClient) AvailableBulk(ctx context.Context, domains []string) ([]godaddy.Availability, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, domains)
+	f.mu.Unlock()
+	out := make([]godaddy.Availability, 0, len(domains))
+	for _, d := range domains {
+		a := godaddy.Availability{Domain: d, Available: !strings.HasPrefix(d, "taken"), Price: 12.99, Currency: "USD"}
+		if strings.HasSuffix(d, ".ai") {
+			a.Price = 80
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// unsureClient answers FAST batches with non-definitive results and FULL lookups definitively,
+// reporting alpha.com as taken once it is looked at properly.
+type unsureClient struct {
+	discoverClient
+	full atomic.Int32
+}
+
+func (f *unsureClient) AvailableBulk(ctx context.Context, domains []string) ([]godaddy.Availability, error) {
+	out, err := f.discoverClient.AvailableBulk(ctx, domains)
+	for i := range out {
+		out[i].Definitive = out[i].Domain == "shared.com"
+	}
+	return out, err
+}
+
+func (f *unsureClient) Available(ctx context.Context, domain string) (godaddy.Availability, error) {
+	f.full.Add(1)
+	return godaddy.Availability{Domain: domain, Available: domain != "alpha.com", Definitive: true, Price: 12.99, Currency: "USD"}, nil
+}
+
+func TestDiscoverDefinitiveRechecksUnsureResults(t *testing.T) {
+	rt := makeRuntime(t)
+	fc := &unsureClient{}
+	svc := New(rt, fc)
+
+	out, err := svc.Discover(context.Background(), []string{"alpha"}, DiscoverOptions{TLDs: []string{"com"}, Limit: 10, CheckConcurrency: 2, Definitive: true})
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	got := map[string]DiscoverCandidate{}
+	for _, c := range out {
+		got[c.Domain] = c
+	}
+	if c := got["alpha.com"]; c.Available || c.Buyable || !c.Definitive || !c.Rechecked {
+		t.Fatalf("expected alpha.com settled as taken by the FULL check, got %+v", c)
+	}
+	if c := got["shared.com"]; c.Rechecked || !c.Buyable {
+		t.Fatalf("expected definitive shared.com left alone, got %+v", c)
+	}
+	if n := fc.full.Load(); n != 1 {
+		t.Fatalf("expected one FULL lookup, got %d", n)
+	}
+}
+
+func TestSuggestFiltersByScoreAvailabilityAndSorts(t *testing.T) {
+	rt := makeRuntime(t)
+	svc := New(rt, &discoverClient{})
+
+	res, err := svc.Suggest(context.Background(), "taken", nil, 10, SuggestFilter{MinScore: 0.6, SortBy: "domain", AvailableOnly: true, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+	list, _ := res["suggestions"].([]godaddy.Suggestion)
+	if len(list) != 2 || list[0].Domain != "taken.ai" || list[1].Domain != "taken.net" || res["count"] != 2 {
+		t.Fatalf("expected taken.ai and taken.net, got %+v", res)
+	}
+	if res["unchecked"] != 0 {
+		t.Fatalf("expected every suggestion checked, got %+v", res)
+	}
+
+	res, err = svc.Suggest(context.Background(), "alpha", nil, 10, SuggestFilter{SortBy: "score"})
+	if err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+	list, _ = res["suggestions"].([]godaddy.Suggestion)
+	if len(list) != 4 || list[0].Score < list[3].Score || res["count"] != 4 {
+		t.Fatalf("expected all suggestions by score, got %+v", res)
+	}
+}
+
+func TestBulkSuggestMergesAndRanksAcrossSeeds(t *testing.T) {
+	rt := makeRuntime(t)
+	svc := New(rt, &discoverClient{})
+
+	merged, failures, err := svc.BulkSuggest(context.Background(), []string{"alpha", "broken", "beta"}, nil, 10, 2)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial {
+		t.Fatalf("expected partial failure for the broken seed, got %v", err)
+	}
+	if len(failures) != 1 || failures[0].Seed != "broken" {
+		t.Fatalf("expected broken seed reported, got %+v", failures)
+	}
+	if failed, _ := ae.Details["failed_items"].([]FailedItem); len(failed) != 1 || failed[0].Seed != "broken" || failed[0].Domain != "" {
+		t.Fatalf("expected failed_items naming the broken seed, got %+v", ae.Details["failed_items"])
+	}
+	if len(merged) != 7 {
+		t.Fatalf("expected 7 unique suggestions, got %+v", merged)
+	}
+	for i := 1; i < len(merged); i++ {
+		if merged[i].Score > merged[i-1].Score {
+			t.Fatalf("expected ranking by score, got %+v", merged)
+		}
+	}
+	for _, m := range merged {
+		if m.Domain == "shared.com" && m.Seed != "alpha" {
+			t.Fatalf("expected shared.com credited to the first seed, got %+v", m)
+		}
+		if m.Domain == "beta.ai" && m.Seed != "beta" {
+			t.Fatalf("expected originating seed on beta.ai, got %+v", m)
+		}
+	}
+}
+
+func TestDiscoverFiltersByTLDPriceAndAvailability(t *testing.T) {
+	rt := makeRuntime(t)
+	fc := &discoverClient{}
+	svc := New(rt, fc)
+
+	out, err := svc.Discover(context.Background(), []string{"alpha", "taken", "broken"}, DiscoverOptions{
+		TLDs:               []string{"com", ".ai"},
+		Limit:              10,
+		MaxPrice:           50,
+		SuggestConcurrency: 2,
+		CheckConcurrency:   2,
+		BatchSize:          2,
+	})
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodePartial {
+		t.Fatalf("expected partial failure for broken seed, got %v", err)
+	}
+	if failed, _ := ae.Details["failed_items"].([]FailedItem); len(failed) != 1 || failed[0].Seed != "broken" {
+		t.Fatalf("expected failed_items naming the broken seed, got %+v", ae.Details["failed_items"])
+	}
+
+	got := map[string]DiscoverCandidate{}
+	for _, c := range out {
+		if c.Domain != "" {
+			got[c.Domain] = c
+		}
+	}
+	if _, ok := got["alpha.net"]; ok {
+		t.Fatalf("expected .net to be filtered out: %+v", out)
+	}
+	if len(got) != 5 {
+		t.Fatalf("expected 5 deduped candidates, got %+v", out)
+	}
+	if !got["alpha.com"].Buyable || !got["shared.com"].Buyable || got["shared.com"].Seed != "alpha" {
+		t.Fatalf("unexpected buyable candidates: %+v", got)
+	}
+	if got["alpha.ai"].Buyable || got["alpha.ai"].Reason != "over_max_price" {
+		t.Fatalf("expected alpha.ai over max price: %+v", got["alpha.ai"])
+	}
+	if got["taken.com"].Buyable || got["taken.com"].Reason != "unavailable" {
+		t.Fatalf("expected taken.com unavailable: %+v", got["taken.com"])
+	}
+	for _, b := range fc.batches {
+		if len(b) > 2 {
+			t.Fatalf("expected batches of at most 2, got %v", b)
+		}
+	}
+}
+
+type unreachableClient struct {
+	fakeClient
+	calls int
+}
+
+func (c *unreachableClient) Available(ctx context.Context, domain string) (godaddy.Availability, error) {
+	c.calls++
+	return godaddy.Availability{}, &apperr.AppError{Code: apperr.CodeNetwork, Message: "network request failed", Retryable: true, Details: map[string]any{"timeout": true}}
+}
+
+func TestAvailabilityKeepsNetworkErrorWhenRetriesRunOut(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Limiter = rate.NewLimiter(60000, 10)
+	rt.Cfg.RetryAttempts = 3
+	rt.Cfg.RetryBaseMs = 10
+	client := &unreachableClient{}
+	svc := New(rt, client)
+
+	_, err := svc.Availability(context.Background(), "example.com")
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeNetwork {
+		t.Fatalf("expected network_error after retries, got %v", err)
+	}
+	if ae.Details["attempts"] != 3 || ae.Details["timeout"] != true || client.calls != 3 {
+		t.Fatalf("expected attempts and timeout details after 3 calls, got %v (calls %d)", ae.Details, client.calls)
+	}
+}
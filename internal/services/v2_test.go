@@ -9,6 +9,7 @@ import (
 
 	apperr "github.com/sportwhiz/gdcli/internal/errors"
 	"github.com/sportwhiz/gdcli/internal/godaddy"
+	"github.com/sportwhiz/gdcli/internal/store"
 )
 
 type fakeV2Client struct {
@@ -18,6 +19,8 @@ type fakeV2Client struct {
 	v2RenewErr        error
 	v2Detail          map[string]any
 	lastRenewV2       godaddy.RenewV2Request
+	lastRenewKey      string
+	renewCurrency     string
 	requireCustomerID string
 	v1RenewErr        error
 }
@@ -45,13 +48,18 @@ func (f *fakeV2Client) DomainDetailV1(ctx context.Context, domain string) (map[s
 
 func (f *fakeV2Client) RenewV2(ctx context.Context, customerID, domain string, req godaddy.RenewV2Request, idempotencyKey string) (godaddy.RenewResult, error) {
 	f.lastRenewV2 = req
+	f.lastRenewKey = idempotencyKey
 	if f.requireCustomerID != "" && customerID != f.requireCustomerID {
 		return godaddy.RenewResult{}, errors.New("customer mismatch")
 	}
 	if f.v2RenewErr != nil {
 		return godaddy.RenewResult{}, f.v2RenewErr
 	}
-	return godaddy.RenewResult{Domain: domain, Price: 12.99, Currency: "USD"}, nil
+	currency := f.renewCurrency
+	if currency == "" {
+		currency = "USD"
+	}
+	return godaddy.RenewResult{Domain: domain, Price: 12.99, Currency: currency}, nil
 }
 
 func (f *fakeV2Client) Renew(ctx context.Context, domain string, years int, idempotencyKey string) (godaddy.RenewResult, error) {
@@ -108,6 +116,96 @@ func TestDomainDetailFallsBackToV1(t *testing.T) {
 	}
 }
 
+type countingDetailClient struct {
+	fakeV2Client
+	detailCalls int
+}
+
+func (f *countingDetailClient) DomainDetailV2(ctx context.Context, customerID, domain string, includes []string) (map[string]any, error) {
+	f.detailCalls++
+	return f.fakeV2Client.DomainDetailV2(ctx, customerID, domain, includes)
+}
+
+func TestDomainDetailIsCachedPerService(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CustomerID = "cust-123"
+	fc := &countingDetailClient{}
+	svc := New(rt, fc)
+	ctx := context.Background()
+
+	first, err := svc.DomainDetail(ctx, "example.com", nil)
+	if err != nil {
+		t.Fatalf("domain detail: %v", err)
+	}
+	first["mutated"] = true
+	second, err := svc.DomainDetail(ctx, "Example.com", nil)
+	if err != nil {
+		t.Fatalf("domain detail: %v", err)
+	}
+	if fc.detailCalls != 1 || second["mutated"] != nil {
+		t.Fatalf("expected one cached fetch returning an unshared map, calls=%d", fc.detailCalls)
+	}
+	if _, err := svc.DomainDetail(ctx, "example.com", []string{"contacts"}); err != nil {
+		t.Fatalf("domain detail with includes: %v", err)
+	}
+	if _, err := svc.DomainDetailFresh(ctx, "example.com", nil); err != nil {
+		t.Fatalf("fresh domain detail: %v", err)
+	}
+	if fc.detailCalls != 3 {
+		t.Fatalf("expected different includes and fresh reads to hit the API, calls=%d", fc.detailCalls)
+	}
+}
+
+type lockClient struct {
+	fakeV2Client
+	v2LockErr error
+	lastV1    *bool
+}
+
+func (f *lockClient) SetLockV2(ctx context.Context, customerID, domain string, locked bool) error {
+	if f.v2LockErr != nil {
+		return f.v2LockErr
+	}
+	f.v2Detail = map[string]any{"domain": domain, "locked": locked}
+	return nil
+}
+
+func (f *lockClient) SetLockV1(ctx context.Context, domain string, locked bool) error {
+	f.lastV1 = &locked
+	return nil
+}
+
+func TestSetDomainLockReportsStateAndVersion(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CustomerID = "cust-123"
+	fc := &lockClient{}
+	svc := New(rt, fc)
+
+	res, err := svc.SetDomainLock(context.Background(), "example.com", true)
+	if err != nil {
+		t.Fatalf("set lock: %v", err)
+	}
+	if res["api_version"] != "v2" || res["locked"] != true || res["verified"] != true {
+		t.Fatalf("unexpected v2 lock result: %+v", res)
+	}
+	got, err := svc.DomainLock(context.Background(), "example.com")
+	if err != nil || got["locked"] != true {
+		t.Fatalf("expected lock get to report locked, got %+v %v", got, err)
+	}
+
+	fc.v2LockErr = errors.New("v2 lock failed")
+	res, err = svc.SetDomainLock(context.Background(), "example.com", false)
+	if err != nil {
+		t.Fatalf("set lock v1 fallback: %v", err)
+	}
+	if res["api_version"] != "v1" || fc.lastV1 == nil || *fc.lastV1 {
+		t.Fatalf("expected v1 fallback unlocking, got %+v", res)
+	}
+	if res["verified"] != false {
+		t.Fatalf("expected unverified when detail still reports locked, got %+v", res)
+	}
+}
+
 func TestSetNameserversSmartFallsBackToV1(t *testing.T) {
 	rt := makeRuntime(t)
 	rt.Cfg.CustomerID = "cust-123"
@@ -169,7 +267,7 @@ func TestRenewV2BuildsConsentRequest(t *testing.T) {
 	}
 }
 
-func TestRenewFallsBackToV1WhenV2PayloadUnavailable(t *testing.T) {
+func TestRenewRefusesWhenV2PayloadHasNoPrice(t *testing.T) {
 	rt := makeRuntime(t)
 	rt.Cfg.CustomerID = "cust-123"
 	svc := New(rt, &fakeV2Client{
@@ -182,12 +280,17 @@ func TestRenewFallsBackToV1WhenV2PayloadUnavailable(t *testing.T) {
 		},
 	})
 
-	out, err := svc.Renew(context.Background(), "example.com", 1, false, true)
+	_, err := svc.Renew(context.Background(), "example.com", 1, false, true)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeSafety || ae.Details["price_source"] != "estimate" {
+		t.Fatalf("expected refusal to renew at the estimate, got %v", err)
+	}
+	ops, err := store.ReadOperations()
 	if err != nil {
-		t.Fatalf("renew fallback: %v", err)
+		t.Fatalf("load operations: %v", err)
 	}
-	if out["api_version"] != "v1" {
-		t.Fatalf("expected v1 fallback, got %v", out["api_version"])
+	if len(ops) != 0 {
+		t.Fatalf("expected no operation reserved, got %+v", ops)
 	}
 }
 
@@ -252,3 +355,154 @@ func TestRenewReturnsLatestV1PaymentErrorAndGuidance(t *testing.T) {
 		t.Fatalf("expected good as gold guidance, got: %v", err)
 	}
 }
+
+type renewReceiptClient struct {
+	fakeV2Client
+	expiresBefore string
+	expiresAfter  string
+	renewed       bool
+	renewalMicros float64
+}
+
+func (f *renewReceiptClient) DomainDetailV2(ctx context.Context, customerID, domain string, includes []string) (map[string]any, error) {
+	expires := f.expiresBefore
+	if f.renewed {
+		expires = f.expiresAfter
+	}
+	price := f.renewalMicros
+	if price == 0 {
+		price = 10990000
+	}
+	return map[string]any{
+		"domain":    domain,
+		"expiresAt": expires,
+		"renewal":   map[string]any{"price": price, "currency": "USD"},
+	}, nil
+}
+
+func (f *renewReceiptClient) RenewV2(ctx context.Context, customerID, domain string, req godaddy.RenewV2Request, idempotencyKey string) (godaddy.RenewResult, error) {
+	f.renewed = true
+	return f.fakeV2Client.RenewV2(ctx, customerID, domain, req, idempotencyKey)
+}
+
+func TestRenewReportsExpirationBeforeAndAfter(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CustomerID = "cust-123"
+	svc := New(rt, &renewReceiptClient{expiresBefore: "2026-05-27T15:01:38.000Z", expiresAfter: "2027-05-27T15:01:38.000Z"})
+
+	out, err := svc.Renew(context.Background(), "example.com", 1, false, true)
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if out["expires_before"] != "2026-05-27T15:01:38.000Z" || out["expires_after"] != "2027-05-27T15:01:38.000Z" {
+		t.Fatalf("unexpected expiration receipt: %+v", out)
+	}
+	if out["expiration_verified"] != true || out["warning"] != nil {
+		t.Fatalf("expected verified expiration without warning: %+v", out)
+	}
+}
+
+func TestRenewWarnsWhenExpirationDoesNotAdvance(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CustomerID = "cust-123"
+	svc := New(rt, &renewReceiptClient{expiresBefore: "2026-05-27T15:01:38.000Z", expiresAfter: "2026-05-27T15:01:38.000Z"})
+
+	out, err := svc.Renew(context.Background(), "other.com", 1, false, true)
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if out["expiration_verified"] != false {
+		t.Fatalf("expected unverified expiration: %+v", out)
+	}
+	if w, _ := out["warning"].(string); !strings.Contains(w, "did not advance") {
+		t.Fatalf("expected did-not-advance warning, got %+v", out)
+	}
+}
+
+func TestRenewDryRunReportsProviderPrice(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CustomerID = "cust-123"
+	svc := New(rt, &renewReceiptClient{expiresBefore: "2026-05-27T15:01:38.000Z"})
+
+	out, err := svc.Renew(context.Background(), "example.com", 1, true, false)
+	if err != nil {
+		t.Fatalf("renew dry run: %v", err)
+	}
+	if out["price"] != 10.99 || out["currency"] != "USD" || out["price_source"] != "provider" {
+		t.Fatalf("expected provider renewal price, got %+v", out)
+	}
+
+	rt.Cfg.MaxPricePerDomain = 10
+	if _, err := svc.Renew(context.Background(), "example.com", 1, true, false); err == nil {
+		t.Fatalf("expected provider price above max_price_per_domain to fail")
+	}
+
+	rt.Cfg.MaxPricePerDomain = 25
+	estimate, err := New(rt, &fakeV2Client{}).Renew(context.Background(), "example.com", 1, true, false)
+	if err != nil {
+		t.Fatalf("renew dry run without detail: %v", err)
+	}
+	if estimate["price"] != 12.99 || estimate["price_source"] != "estimate" {
+		t.Fatalf("expected estimate fallback, got %+v", estimate)
+	}
+}
+
+func TestRenewConfirmTokenFlow(t *testing.T) {
+	rt := makeRuntime(t)
+	rt.Cfg.CustomerID = "cust-123"
+	client := &renewReceiptClient{expiresBefore: "2026-05-27T15:01:38.000Z", expiresAfter: "2027-05-27T15:01:38.000Z"}
+	svc := New(rt, client)
+	ctx := context.Background()
+
+	dry, err := svc.RenewDryRun(ctx, "example.com", 1)
+	if err != nil {
+		t.Fatalf("renew dry run: %v", err)
+	}
+	tok, _ := dry["confirmation_token"].(string)
+	if tok == "" || dry["price"] != 10.99 {
+		t.Fatalf("expected token for the provider price, got %+v", dry)
+	}
+	if _, err := svc.PurchaseConfirm(ctx, "example.com", tok, 1); err == nil {
+		t.Fatalf("expected renew token to be rejected for a purchase")
+	}
+
+	client.renewalMicros = 14990000
+	_, err = svc.RenewConfirm(ctx, "example.com", tok, 1)
+	var ae *apperr.AppError
+	if !apperr.As(err, &ae) || ae.Code != apperr.CodeConfirmation || ae.Details["current_price"] != 14.99 {
+		t.Fatalf("expected price change to be rejected, got %v", err)
+	}
+	if client.renewed {
+		t.Fatalf("renewal should not be placed after a price change")
+	}
+
+	client.renewalMicros = 0
+	out, err := svc.RenewConfirm(ctx, "example.com", tok, 1)
+	if err != nil {
+		t.Fatalf("renew confirm: %v", err)
+	}
+	if !client.renewed || out["expiration_verified"] != true {
+		t.Fatalf("expected confirmed renewal, got %+v", out)
+	}
+	if _, err := svc.RenewConfirm(ctx, "example.com", tok, 1); err == nil {
+		t.Fatalf("expected used token to be rejected")
+	}
+}
+
+func TestPlanSpendsIgnoresCommand(t *testing.T) {
+	cases := []struct {
+		plan Plan
+		want bool
+	}{
+		{Plan{Command: "harmless", Method: "POST", Path: "/v2/customers/{customerId}/domains/register"}, true},
+		{Plan{Command: "domains register validate", Method: "POST", Path: "/v2/customers/{customerId}/domains/register/validate"}, false},
+		{Plan{Command: "x", Method: "POST", Path: "/v2/customers/{customerId}/domains/example.com/redeem"}, true},
+		{Plan{Command: "x", Method: "post", Path: "/v2/customers/{customerId}/domains/example.com/transferInRetry"}, true},
+		{Plan{Command: "domains redeem", Method: "PATCH", Path: "/v2/customers/{customerId}/domains/example.com/contacts"}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.plan.Spends(); got != tc.want {
+			t.Fatalf("Spends(%s %s) = %v, want %v", tc.plan.Method, tc.plan.Path, got, tc.want)
+		}
+	}
+}
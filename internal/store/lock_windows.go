@@ -2,14 +2,38 @@
 
 package store
 
-import "os"
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
 
-// Windows builds use process-local serialization for token writes.
-// Cross-process locking can be added with LockFileEx if needed.
-func lockFile(_ *os.File) error {
+// The lock calls are loaded from kernel32 directly so the module keeps no dependencies
+// outside the standard library.
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const lockfileExclusiveLock = 0x00000002
+
+// lockFile blocks until it holds an exclusive lock on the first byte of f, which gives the
+// same whole-file semantics as flock(LOCK_EX) because every caller locks the same range.
+func lockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	r1, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r1 == 0 {
+		return err
+	}
 	return nil
 }
 
-func unlockFile(_ *os.File) error {
+func unlockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	r1, _, err := procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r1 == 0 {
+		return err
+	}
 	return nil
 }
@@ -2,6 +2,7 @@ package store
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
@@ -25,11 +26,15 @@ type Operation struct {
 	Currency    string    `json:"currency"`
 	CreatedAt   time.Time `json:"created_at"`
 	Status      string    `json:"status"`
+	Definitive  *bool     `json:"definitive,omitempty"`
+	OrderID     string    `json:"order_id,omitempty"`
 }
 
 type ConfirmToken struct {
 	TokenID      string    `json:"token_id"`
+	Action       string    `json:"action,omitempty"`
 	Domain       string    `json:"domain"`
+	Years        int       `json:"years,omitempty"`
 	QuotedPrice  float64   `json:"quoted_price"`
 	Currency     string    `json:"currency"`
 	IssuedAt     time.Time `json:"issued_at"`
@@ -65,6 +70,9 @@ func AppendOperation(op Operation) error {
 	})
 }
 
+// ReadOperations returns the operations log without taking the lock. Writers replace the file by
+// rename, so readers always see a complete log; anything that decides based on the log (budget
+// caps, reservations) must go through LoadAndSaveOperations instead.
 func ReadOperations() ([]Operation, error) {
 	path, err := operationsPath()
 	if err != nil {
@@ -96,34 +104,64 @@ func ReadOperations() ([]Operation, error) {
 	return ops, nil
 }
 
+// LoadAndSaveOperations rewrites the operations log under lock. The new log replaces the old one
+// atomically and the previous version is kept as operations.jsonl.bak.
 func LoadAndSaveOperations(mutator func(*[]Operation) error) error {
 	path, err := operationsPath()
 	if err != nil {
 		return err
 	}
 	path = filepath.Clean(path)
-	// #nosec G304 -- path is scoped to ~/.gdcli with fixed filename.
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if err := lockFile(f); err != nil {
-		return err
-	}
-	defer func() { _ = unlockFile(f) }()
+	return withFileLock(path, func() error {
+		ops, err := ReadOperations()
+		if err != nil {
+			return err
+		}
+		if err := mutator(&ops); err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := encodeOperations(&buf, ops); err != nil {
+			return err
+		}
+		return config.WriteFileAtomic(path, buf.Bytes(), 0o600, true)
+	})
+}
 
-	ops, err := readOperationsFromFile(f)
+// CompactOperations rewrites the operations log without the terminal (non-pending) operations
+// created before cutoff and returns how many were dropped. Pending operations are always kept
+// because reservations and retries still look them up. Nothing is written when nothing is
+// dropped, so the .bak copy keeps the previous version.
+func CompactOperations(cutoff time.Time, dryRun bool) (int, error) {
+	path, err := operationsPath()
 	if err != nil {
-		return err
-	}
-	if err := mutator(&ops); err != nil {
-		return err
+		return 0, err
 	}
-	if err := writeOperationsToFile(f, ops); err != nil {
-		return err
-	}
-	return f.Sync()
+	path = filepath.Clean(path)
+	removed := 0
+	err = withFileLock(path, func() error {
+		ops, err := ReadOperations()
+		if err != nil {
+			return err
+		}
+		kept := make([]Operation, 0, len(ops))
+		for _, op := range ops {
+			if op.Status != "pending" && op.CreatedAt.Before(cutoff) {
+				removed++
+				continue
+			}
+			kept = append(kept, op)
+		}
+		if removed == 0 || dryRun {
+			return nil
+		}
+		var buf bytes.Buffer
+		if err := encodeOperations(&buf, kept); err != nil {
+			return err
+		}
+		return config.WriteFileAtomic(path, buf.Bytes(), 0o600, true)
+	})
+	return removed, err
 }
 
 func LoadTokens() (*TokenStore, error) {
@@ -157,7 +195,7 @@ func SaveTokens(ts *TokenStore) error {
 		return err
 	}
 	b = append(b, '\n')
-	return os.WriteFile(path, b, 0o600)
+	return config.WriteFileAtomic(path, b, 0o600, false)
 }
 
 func LoadAndSaveTokens(mutator func(*TokenStore) error) error {
@@ -166,8 +204,35 @@ func LoadAndSaveTokens(mutator func(*TokenStore) error) error {
 		return err
 	}
 	path = filepath.Clean(path)
+	return withFileLock(path, func() error {
+		// #nosec G304 -- path is scoped to ~/.gdcli with fixed filename.
+		b, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		ts := &TokenStore{}
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, ts); err != nil {
+				return err
+			}
+		}
+		if err := mutator(ts); err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(ts, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(out, '\n')
+		return config.WriteFileAtomic(path, out, 0o600, false)
+	})
+}
+
+// withFileLock holds an exclusive lock on path+".lock" while fn runs. The lock lives in a
+// sidecar file because the data file itself is replaced by rename on every write.
+func withFileLock(path string, fn func() error) error {
 	// #nosec G304 -- path is scoped to ~/.gdcli with fixed filename.
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
 	if err != nil {
 		return err
 	}
@@ -176,67 +241,11 @@ func LoadAndSaveTokens(mutator func(*TokenStore) error) error {
 		return err
 	}
 	defer func() { _ = unlockFile(f) }()
-
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return err
-	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		return err
-	}
-	ts := &TokenStore{}
-	if len(b) > 0 {
-		if err := json.Unmarshal(b, ts); err != nil {
-			return err
-		}
-	}
-	if err := mutator(ts); err != nil {
-		return err
-	}
-	out, err := json.MarshalIndent(ts, "", "  ")
-	if err != nil {
-		return err
-	}
-	out = append(out, '\n')
-	if err := f.Truncate(0); err != nil {
-		return err
-	}
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return err
-	}
-	if _, err := f.Write(out); err != nil {
-		return err
-	}
-	return f.Sync()
+	return fn()
 }
 
-func readOperationsFromFile(f *os.File) ([]Operation, error) {
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return nil, err
-	}
-	var ops []Operation
-	s := bufio.NewScanner(f)
-	for s.Scan() {
-		var op Operation
-		if err := json.Unmarshal(s.Bytes(), &op); err != nil {
-			return nil, err
-		}
-		ops = append(ops, op)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
-	}
-	return ops, nil
-}
-
-func writeOperationsToFile(f *os.File, ops []Operation) error {
-	if err := f.Truncate(0); err != nil {
-		return err
-	}
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return err
-	}
-	enc := json.NewEncoder(f)
+func encodeOperations(w io.Writer, ops []Operation) error {
+	enc := json.NewEncoder(w)
 	for _, op := range ops {
 		if err := enc.Encode(op); err != nil {
 			return err
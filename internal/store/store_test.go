@@ -0,0 +1,73 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sportwhiz/gdcli/internal/config"
+)
+
+func TestLoadAndSaveOperationsKeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(config.HomeEnvVar, dir)
+
+	if err := AppendOperation(Operation{OperationID: "op-1", Type: "purchase", Status: "succeeded"}); err != nil {
+		t.Fatalf("append first: %v", err)
+	}
+	if err := AppendOperation(Operation{OperationID: "op-2", Type: "purchase", Status: "succeeded"}); err != nil {
+		t.Fatalf("append second: %v", err)
+	}
+	ops, err := ReadOperations()
+	if err != nil || len(ops) != 2 {
+		t.Fatalf("expected 2 operations, got %d (%v)", len(ops), err)
+	}
+	bak, err := os.ReadFile(filepath.Join(dir, OperationsFile+config.BackupSuffix))
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	if !strings.Contains(string(bak), "op-1") || strings.Contains(string(bak), "op-2") {
+		t.Fatalf("expected backup to hold the previous log, got %s", bak)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Fatalf("temp file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestCompactOperationsKeepsPendingAndRecent(t *testing.T) {
+	t.Setenv(config.HomeEnvVar, t.TempDir())
+	now := time.Now()
+	old := now.AddDate(0, 0, -120)
+	for _, op := range []Operation{
+		{OperationID: "old-done", Status: "succeeded", CreatedAt: old},
+		{OperationID: "old-failed", Status: "failed", CreatedAt: old},
+		{OperationID: "old-pending", Status: "pending", CreatedAt: old},
+		{OperationID: "recent", Status: "succeeded", CreatedAt: now},
+	} {
+		if err := AppendOperation(op); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	cutoff := now.AddDate(0, 0, -90)
+	if n, err := CompactOperations(cutoff, true); err != nil || n != 2 {
+		t.Fatalf("dry run: expected 2 removable, got %d (%v)", n, err)
+	}
+	if ops, _ := ReadOperations(); len(ops) != 4 {
+		t.Fatalf("dry run must not rewrite the log, got %d entries", len(ops))
+	}
+	if n, err := CompactOperations(cutoff, false); err != nil || n != 2 {
+		t.Fatalf("compact: expected 2 removed, got %d (%v)", n, err)
+	}
+	ops, err := ReadOperations()
+	if err != nil || len(ops) != 2 || ops[0].OperationID != "old-pending" || ops[1].OperationID != "recent" {
+		t.Fatalf("unexpected log after compaction: %+v (%v)", ops, err)
+	}
+}
@@ -0,0 +1,290 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ChecksumsAsset is the goreleaser checksum file attached to every release.
+const ChecksumsAsset = "checksums.txt"
+
+// maxAssetBytes bounds release downloads; gdcli archives are a few MB.
+const maxAssetBytes = 100 << 20
+
+// downloadTimeout is the backstop for asset downloads when the context has no deadline.
+const downloadTimeout = 2 * time.Minute
+
+// Applied describes a binary replaced by Apply.
+type Applied struct {
+	Path    string
+	Asset   string
+	SHA256  string
+	Version string
+}
+
+// AssetName returns the goreleaser archive name for a platform, e.g. gdcli_Linux_x86_64.tar.gz.
+func AssetName(goos, goarch string) string {
+	arch := goarch
+	if arch == "amd64" {
+		arch = "x86_64"
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return "gdcli_" + strings.ToUpper(goos[:1]) + goos[1:] + "_" + arch + ext
+}
+
+// CheckWritable reports whether the binary at path can be replaced: the file must exist and
+// its directory must accept new files, since Apply renames a fresh copy over it.
+func CheckWritable(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gdcli-write-check-*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	_ = tmp.Close()
+	return os.Remove(name)
+}
+
+// ManagedError reports a binary owned by a package manager, which Apply leaves alone so the
+// manager's own records stay accurate.
+type ManagedError struct {
+	Path    string
+	Manager string
+	Upgrade string
+}
+
+func (e *ManagedError) Error() string {
+	return fmt.Sprintf("%s is managed by %s; upgrade with `%s`", e.Path, e.Manager, e.Upgrade)
+}
+
+// managedPrefixes maps path fragments of package-manager installs to the manager and the
+// command that upgrades gdcli through it.
+var managedPrefixes = []struct{ fragment, manager, upgrade string }{
+	{"/Cellar/", "Homebrew", "brew upgrade gdcli"},
+	{"/opt/homebrew/", "Homebrew", "brew upgrade gdcli"},
+	{"/.linuxbrew/", "Homebrew", "brew upgrade gdcli"},
+	{"/nix/store/", "Nix", "nix profile upgrade gdcli"},
+	{"/snap/", "snap", "snap refresh gdcli"},
+	{"/scoop/apps/", "Scoop", "scoop update gdcli"},
+}
+
+// CheckManaged returns a *ManagedError when path lies under a package-manager prefix.
+func CheckManaged(path string) error {
+	p := filepath.ToSlash(path)
+	for _, m := range managedPrefixes {
+		if strings.Contains(p, m.fragment) {
+			return &ManagedError{Path: path, Manager: m.manager, Upgrade: m.upgrade}
+		}
+	}
+	return nil
+}
+
+// Apply downloads the release archive for goos/goarch listed in res, verifies it against the
+// release checksums, and atomically replaces the binary at path. Package-manager installs are
+// refused with a *ManagedError.
+func Apply(ctx context.Context, res Result, path, goos, goarch string) (Applied, error) {
+	if err := CheckManaged(path); err != nil {
+		return Applied{}, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, downloadTimeout)
+		defer cancel()
+	}
+	name := AssetName(goos, goarch)
+	archiveURL, ok := findAsset(res.Assets, name)
+	if !ok {
+		return Applied{}, fmt.Errorf("release %s has no asset %s", res.LatestVersion, name)
+	}
+	sumsURL, ok := findAsset(res.Assets, ChecksumsAsset)
+	if !ok {
+		return Applied{}, fmt.Errorf("release %s has no %s", res.LatestVersion, ChecksumsAsset)
+	}
+	sums, err := download(ctx, sumsURL, res.CurrentVersion)
+	if err != nil {
+		return Applied{}, err
+	}
+	want, ok := checksumFor(sums, name)
+	if !ok {
+		return Applied{}, fmt.Errorf("%s does not list %s", ChecksumsAsset, name)
+	}
+	archive, err := download(ctx, archiveURL, res.CurrentVersion)
+	if err != nil {
+		return Applied{}, err
+	}
+	sum := sha256.Sum256(archive)
+	got := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(got, want) {
+		return Applied{}, fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+
+	binName := "gdcli"
+	if goos == "windows" {
+		binName += ".exe"
+	}
+	var bin []byte
+	if strings.HasSuffix(name, ".zip") {
+		bin, err = extractZip(archive, binName)
+	} else {
+		bin, err = extractTarGz(archive, binName)
+	}
+	if err != nil {
+		return Applied{}, err
+	}
+	if err := replaceBinary(path, bin, goos); err != nil {
+		return Applied{}, err
+	}
+	return Applied{Path: path, Asset: name, SHA256: got, Version: res.LatestVersion}, nil
+}
+
+func findAsset(assets []Asset, name string) (string, bool) {
+	for _, a := range assets {
+		if a.Name == name && a.URL != "" {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// checksumFor finds name in a sha256sum-style listing ("<hex>  <file>" per line).
+func checksumFor(sums []byte, name string) (string, bool) {
+	s := bufio.NewScanner(bytes.NewReader(sums))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
+
+func download(ctx context.Context, url, currentVersion string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "gdcli/"+currentVersion)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxAssetBytes {
+		return nil, fmt.Errorf("release asset exceeds %d bytes", maxAssetBytes)
+	}
+	return b, nil
+}
+
+func extractTarGz(archive []byte, binName string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag == tar.TypeReg && filepath.Base(h.Name) == binName {
+			return io.ReadAll(io.LimitReader(tr, maxAssetBytes))
+		}
+	}
+	return nil, fmt.Errorf("archive does not contain %s", binName)
+}
+
+func extractZip(archive []byte, binName string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || filepath.Base(f.Name) != binName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, maxAssetBytes))
+	}
+	return nil, fmt.Errorf("archive does not contain %s", binName)
+}
+
+// replaceBinary writes bin next to path and renames it into place. Windows will not
+// overwrite a running executable, so the old one is moved aside to path+".old" first.
+func replaceBinary(path string, bin []byte, goos string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpName)
+		}
+	}()
+	// #nosec G302 -- the replacement keeps the mode of the binary it replaces.
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bin); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if goos == "windows" {
+		old := path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
@@ -15,10 +15,12 @@ const CacheFile = "update_check.json"
 type Cache struct {
 	LastCheckedAt   time.Time `json:"last_checked_at"`
 	CurrentVersion  string    `json:"current_version"`
+	Channel         string    `json:"channel,omitempty"`
 	LatestVersion   string    `json:"latest_version,omitempty"`
 	UpdateAvailable *bool     `json:"update_available,omitempty"`
 	ReleaseURL      string    `json:"release_url,omitempty"`
 	LastError       string    `json:"last_error,omitempty"`
+	NextCheckAt     time.Time `json:"next_check_at,omitzero"`
 }
 
 func LoadCache() (*Cache, error) {
@@ -65,6 +67,17 @@ func ShouldCheck(now, lastChecked time.Time, interval time.Duration) bool {
 	return now.Sub(lastChecked) >= interval
 }
 
+// ShouldCheckCache is ShouldCheck plus the backoff recorded after a rate-limited check.
+func ShouldCheckCache(now time.Time, c *Cache, interval time.Duration) bool {
+	if c == nil {
+		return true
+	}
+	if !c.NextCheckAt.IsZero() && now.Before(c.NextCheckAt) {
+		return false
+	}
+	return ShouldCheck(now, c.LastCheckedAt, interval)
+}
+
 func IsDisabledByEnv() bool {
 	v := strings.TrimSpace(strings.ToLower(os.Getenv("GDCLI_DISABLE_UPDATE_CHECK")))
 	return v == "1" || v == "true" || v == "yes"
@@ -38,3 +38,14 @@ func TestIsDisabledByEnv(t *testing.T) {
 		}
 	}
 }
+
+func TestShouldCheckCacheHonorsBackoff(t *testing.T) {
+	now := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+	c := &Cache{LastCheckedAt: now.Add(-48 * time.Hour), NextCheckAt: now.Add(time.Hour)}
+	if ShouldCheckCache(now, c, 24*time.Hour) {
+		t.Fatalf("expected backoff window to suppress check")
+	}
+	if !ShouldCheckCache(now.Add(2*time.Hour), c, 24*time.Hour) {
+		t.Fatalf("expected check after backoff expires")
+	}
+}
@@ -3,13 +3,36 @@ package update
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
-const latestReleaseURL = "https://api.github.com/repos/sportwhiz/gdcli/releases/latest"
+var releasesURL = "https://api.github.com/repos/sportwhiz/gdcli/releases?per_page=30"
+
+// Release channels. Stable only considers plain semver tags; beta also considers
+// pre-release tags such as 1.3.0-rc1.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// NormalizeChannel maps a configured channel to ChannelStable or ChannelBeta; empty means stable.
+func NormalizeChannel(v string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", ChannelStable:
+		return ChannelStable, true
+	case ChannelBeta:
+		return ChannelBeta, true
+	default:
+		return "", false
+	}
+}
+
+// DefaultRateLimitBackoff is used when GitHub rate-limits us without a usable reset header.
+const DefaultRateLimitBackoff = time.Hour
 
 type Result struct {
 	OK              bool
@@ -17,13 +40,33 @@ type Result struct {
 	LatestVersion   string
 	UpdateAvailable *bool
 	ReleaseURL      string
-	CheckedAt       time.Time
-	Error           string
+	// Assets lists the downloadable files attached to the latest release.
+	Assets    []Asset
+	CheckedAt time.Time
+	Error     string
+	// Skipped is set when the check was not performed (for example GitHub rate limiting);
+	// callers should treat it as "try again after NextCheckAt" rather than a failure.
+	Skipped     bool
+	SkipReason  string
+	NextCheckAt time.Time
+}
+
+// Asset is a file attached to a GitHub release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+type release struct {
+	Version string
+	URL     string
+	Assets  []Asset
 }
 
 var latestReleaseFetcher = fetchLatestReleaseHTTP
 
-func CheckWithTimeout(ctx context.Context, current string, timeout time.Duration) Result {
+// CheckWithTimeout finds the newest release on channel and compares it with current.
+func CheckWithTimeout(ctx context.Context, current, channel string, timeout time.Duration) Result {
 	now := time.Now().UTC()
 	res := Result{
 		OK:             false,
@@ -38,46 +81,101 @@ func CheckWithTimeout(ctx context.Context, current string, timeout time.Duration
 	}
 	defer cancel()
 
-	latest, releaseURL, err := latestReleaseFetcher(checkCtx, res.CurrentVersion)
+	if ch, ok := NormalizeChannel(channel); ok {
+		channel = ch
+	} else {
+		res.Error = "unknown update channel " + strconv.Quote(channel)
+		return res
+	}
+	latest, err := latestReleaseFetcher(checkCtx, res.CurrentVersion, channel)
 	if err != nil {
+		var rl *RateLimitedError
+		if errors.As(err, &rl) {
+			res.Skipped = true
+			res.SkipReason = "rate_limited"
+			res.NextCheckAt = rl.nextCheckAt(now)
+			return res
+		}
+		if errors.Is(err, context.DeadlineExceeded) && timeout > 0 {
+			res.Error = "update check timed out after " + timeout.String()
+			return res
+		}
 		res.Error = err.Error()
 		return res
 	}
 
 	res.OK = true
-	res.LatestVersion = latest
-	res.ReleaseURL = releaseURL
+	res.LatestVersion = latest.Version
+	res.ReleaseURL = latest.URL
+	res.Assets = latest.Assets
 	res.UpdateAvailable = IsVersionNewer(res.CurrentVersion, res.LatestVersion)
 	return res
 }
 
-func fetchLatestReleaseHTTP(ctx context.Context, currentVersion string) (string, string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseURL, nil)
+func fetchLatestReleaseHTTP(ctx context.Context, currentVersion, channel string) (release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releasesURL, nil)
 	if err != nil {
-		return "", "", err
+		return release{}, err
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("User-Agent", "gdcli/"+currentVersion)
 
-	client := &http.Client{Timeout: 8 * time.Second}
+	// The caller's context deadline governs the request; the client timeout is only a backstop
+	// for contexts without one.
+	client := &http.Client{}
+	if _, ok := ctx.Deadline(); !ok {
+		client.Timeout = 8 * time.Second
+	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", "", err
+		return release{}, err
 	}
 	defer resp.Body.Close()
 
+	if isRateLimitedResponse(resp) {
+		return release{}, &RateLimitedError{StatusCode: resp.StatusCode, ResetAt: rateLimitResetAt(resp)}
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", "", &HTTPStatusError{StatusCode: resp.StatusCode}
+		return release{}, &HTTPStatusError{StatusCode: resp.StatusCode}
 	}
 
-	var payload struct {
-		TagName string `json:"tag_name"`
-		HTMLURL string `json:"html_url"`
-	}
+	var payload []githubRelease
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return "", "", err
+		return release{}, err
+	}
+	best, ok := newestOnChannel(payload, channel)
+	if !ok {
+		return release{}, errors.New("no " + channel + " release found")
+	}
+	return release{Version: NormalizeVersion(best.TagName), URL: best.HTMLURL, Assets: best.Assets}, nil
+}
+
+type githubRelease struct {
+	TagName    string  `json:"tag_name"`
+	HTMLURL    string  `json:"html_url"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// newestOnChannel picks the highest semver release on channel. A release counts as beta when
+// its tag has a pre-release suffix or GitHub marks it as a pre-release; drafts are ignored.
+func newestOnChannel(releases []githubRelease, channel string) (githubRelease, bool) {
+	var best githubRelease
+	found := false
+	for _, r := range releases {
+		v, ok := parseSemver(r.TagName)
+		if r.Draft || !ok {
+			continue
+		}
+		if channel != ChannelBeta && (v.pre != "" || r.Prerelease) {
+			continue
+		}
+		if newer := IsVersionNewer(best.TagName, r.TagName); !found || (newer != nil && *newer) {
+			best, found = r, true
+		}
 	}
-	return NormalizeVersion(payload.TagName), payload.HTMLURL, nil
+	return best, found
 }
 
 type HTTPStatusError struct {
@@ -88,6 +186,44 @@ func (e *HTTPStatusError) Error() string {
 	return "update check failed with status " + strconv.Itoa(e.StatusCode)
 }
 
+// RateLimitedError reports that GitHub refused the check because the caller is rate limited.
+type RateLimitedError struct {
+	StatusCode int
+	ResetAt    time.Time
+}
+
+func (e *RateLimitedError) Error() string {
+	return "update check rate limited with status " + strconv.Itoa(e.StatusCode)
+}
+
+func (e *RateLimitedError) nextCheckAt(now time.Time) time.Time {
+	if !e.ResetAt.IsZero() && e.ResetAt.After(now) {
+		return e.ResetAt.UTC()
+	}
+	return now.Add(DefaultRateLimitBackoff).UTC()
+}
+
+func isRateLimitedResponse(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden && strings.TrimSpace(resp.Header.Get("X-RateLimit-Remaining")) == "0"
+}
+
+func rateLimitResetAt(resp *http.Response) time.Time {
+	if v := strings.TrimSpace(resp.Header.Get("X-RateLimit-Reset")); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs > 0 {
+			return time.Unix(secs, 0).UTC()
+		}
+	}
+	if v := strings.TrimSpace(resp.Header.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			return time.Now().UTC().Add(time.Duration(secs) * time.Second)
+		}
+	}
+	return time.Time{}
+}
+
 func NormalizeVersion(v string) string {
 	v = strings.TrimSpace(v)
 	v = strings.TrimPrefix(v, "v")
@@ -1,8 +1,19 @@
 package update
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -22,11 +33,11 @@ func TestNormalizeAndCompareVersion(t *testing.T) {
 func TestCheckWithTimeoutSuccess(t *testing.T) {
 	orig := latestReleaseFetcher
 	t.Cleanup(func() { latestReleaseFetcher = orig })
-	latestReleaseFetcher = func(ctx context.Context, currentVersion string) (string, string, error) {
-		return "1.2.4", "https://example.com/release", nil
+	latestReleaseFetcher = func(ctx context.Context, currentVersion, channel string) (release, error) {
+		return release{Version: "1.2.4", URL: "https://example.com/release"}, nil
 	}
 
-	res := CheckWithTimeout(context.Background(), "v1.2.3", 50*time.Millisecond)
+	res := CheckWithTimeout(context.Background(), "v1.2.3", ChannelStable, 50*time.Millisecond)
 	if !res.OK {
 		t.Fatalf("expected success, got error=%q", res.Error)
 	}
@@ -41,17 +52,17 @@ func TestCheckWithTimeoutSuccess(t *testing.T) {
 func TestCheckWithTimeoutRespectsDeadline(t *testing.T) {
 	orig := latestReleaseFetcher
 	t.Cleanup(func() { latestReleaseFetcher = orig })
-	latestReleaseFetcher = func(ctx context.Context, currentVersion string) (string, string, error) {
+	latestReleaseFetcher = func(ctx context.Context, currentVersion, channel string) (release, error) {
 		select {
 		case <-time.After(200 * time.Millisecond):
-			return "", "", errors.New("should have timed out")
+			return release{}, errors.New("should have timed out")
 		case <-ctx.Done():
-			return "", "", ctx.Err()
+			return release{}, ctx.Err()
 		}
 	}
 
 	start := time.Now()
-	res := CheckWithTimeout(context.Background(), "v1.2.3", 25*time.Millisecond)
+	res := CheckWithTimeout(context.Background(), "v1.2.3", ChannelStable, 25*time.Millisecond)
 	elapsed := time.Since(start)
 	if res.OK {
 		t.Fatalf("expected timeout failure")
@@ -63,3 +74,186 @@ func TestCheckWithTimeoutRespectsDeadline(t *testing.T) {
 		t.Fatalf("timeout path took too long: %v", elapsed)
 	}
 }
+
+func TestCheckWithTimeoutSkipsOnGitHubRateLimit(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+	}))
+	defer srv.Close()
+
+	origURL := releasesURL
+	t.Cleanup(func() { releasesURL = origURL })
+	releasesURL = srv.URL
+
+	res := CheckWithTimeout(context.Background(), "v1.2.3", ChannelStable, time.Second)
+	if res.OK {
+		t.Fatalf("expected rate-limited check to not be OK")
+	}
+	if !res.Skipped || res.SkipReason != "rate_limited" {
+		t.Fatalf("expected skipped rate_limited result, got %+v", res)
+	}
+	if res.Error != "" {
+		t.Fatalf("rate limit should not surface as error, got %q", res.Error)
+	}
+	if res.NextCheckAt.Unix() != reset {
+		t.Fatalf("expected next check at reset %d, got %v", reset, res.NextCheckAt)
+	}
+}
+
+func TestCheckWithTimeoutForbiddenWithoutRateLimitIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	origURL := releasesURL
+	t.Cleanup(func() { releasesURL = origURL })
+	releasesURL = srv.URL
+
+	res := CheckWithTimeout(context.Background(), "v1.2.3", ChannelStable, time.Second)
+	if res.Skipped || res.Error == "" {
+		t.Fatalf("expected plain 403 to be reported as error, got %+v", res)
+	}
+}
+
+func TestCheckWithTimeoutAbortsInFlightRequest(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+	origURL := releasesURL
+	t.Cleanup(func() { releasesURL = origURL })
+	releasesURL = srv.URL
+
+	start := time.Now()
+	res := CheckWithTimeout(context.Background(), "v1.2.3", ChannelStable, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("hung request was not aborted by the deadline: %v", elapsed)
+	}
+	if res.OK || !strings.Contains(res.Error, "timed out after 50ms") {
+		t.Fatalf("expected timeout error, got %+v", res)
+	}
+}
+
+func TestApplyVerifiesChecksumAndReplacesBinary(t *testing.T) {
+	archive := tarGz(t, "gdcli", "new binary")
+	sum := sha256.Sum256(archive)
+	asset := AssetName("linux", "amd64")
+	sums := hex.EncodeToString(sum[:]) + "  " + asset + "\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + asset:
+			_, _ = w.Write(archive)
+		case "/" + ChecksumsAsset:
+			_, _ = w.Write([]byte(sums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "gdcli")
+	if err := os.WriteFile(path, []byte("old binary"), 0o750); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	if err := CheckWritable(path); err != nil {
+		t.Fatalf("expected scratch binary to be writable: %v", err)
+	}
+	res := Result{LatestVersion: "1.2.4", Assets: []Asset{
+		{Name: asset, URL: srv.URL + "/" + asset},
+		{Name: ChecksumsAsset, URL: srv.URL + "/" + ChecksumsAsset},
+	}}
+	applied, err := Apply(context.Background(), res, path, "linux", "amd64")
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new binary" {
+		t.Fatalf("expected binary to be replaced, got %q", got)
+	}
+	if applied.Asset != "gdcli_Linux_x86_64.tar.gz" || applied.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected apply result: %+v", applied)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o750 {
+		t.Fatalf("expected the original mode 0750 to be kept, got %v", info.Mode().Perm())
+	}
+
+	sums = strings.Repeat("0", 64) + "  " + asset + "\n"
+	if _, err := Apply(context.Background(), res, path, "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new binary" {
+		t.Fatalf("binary must be untouched after a failed update, got %q", got)
+	}
+}
+
+func TestApplyRefusesPackageManagerInstalls(t *testing.T) {
+	for _, path := range []string{"/opt/homebrew/Cellar/gdcli/1.2.3/bin/gdcli", "/usr/local/Cellar/gdcli/1.2.3/bin/gdcli", "/home/linuxbrew/.linuxbrew/bin/gdcli", "/nix/store/abc-gdcli/bin/gdcli"} {
+		_, err := Apply(context.Background(), Result{LatestVersion: "1.2.4"}, path, "linux", "amd64")
+		var managed *ManagedError
+		if !errors.As(err, &managed) {
+			t.Fatalf("expected %s to be refused as a package-manager install, got %v", path, err)
+		}
+	}
+	if err := CheckManaged("/usr/local/bin/gdcli"); err != nil {
+		t.Fatalf("expected a plain install to be allowed, got %v", err)
+	}
+}
+
+func tarGz(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("tar header: %v", err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatalf("tar write: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckWithTimeoutPicksNewestOnChannel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"tag_name":"v1.4.0","draft":true},
+			{"tag_name":"v1.3.0-rc1","prerelease":true,"html_url":"https://example.com/rc1"},
+			{"tag_name":"v1.2.4","html_url":"https://example.com/1.2.4"},
+			{"tag_name":"v1.2.5","prerelease":true},
+			{"tag_name":"nightly"}
+		]`))
+	}))
+	defer srv.Close()
+	origURL := releasesURL
+	t.Cleanup(func() { releasesURL = origURL })
+	releasesURL = srv.URL
+
+	res := CheckWithTimeout(context.Background(), "v1.2.3", ChannelStable, time.Second)
+	if !res.OK || res.LatestVersion != "1.2.4" || res.ReleaseURL != "https://example.com/1.2.4" {
+		t.Fatalf("expected stable channel to pick 1.2.4, got %+v", res)
+	}
+	res = CheckWithTimeout(context.Background(), "v1.2.3", ChannelBeta, time.Second)
+	if !res.OK || res.LatestVersion != "1.3.0-rc1" || res.UpdateAvailable == nil || !*res.UpdateAvailable {
+		t.Fatalf("expected beta channel to pick 1.3.0-rc1, got %+v", res)
+	}
+	if res := CheckWithTimeout(context.Background(), "v1.2.3", "nightly", time.Second); res.OK || res.Error == "" {
+		t.Fatalf("expected unknown channel to be reported, got %+v", res)
+	}
+}